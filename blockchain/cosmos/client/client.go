@@ -43,6 +43,7 @@ type Client struct {
 
 var _ xclient.IClient = &Client{}
 var _ xclient.StakingClient = &Client{}
+var _ xclient.BalanceAtClient = &Client{}
 
 func ReplaceIncompatiableCosmosResponses(body []byte) []byte {
 	bodyStr := string(body)
@@ -174,7 +175,7 @@ func (client *Client) FetchBaseTxInput(ctx context.Context, from xc.Address, ass
 		txInput.GasPrice = gasPrice
 	}
 
-	_, assetType, err := client.fetchBalanceAndType(ctx, from, assetI.GetContract())
+	_, assetType, err := client.fetchBalanceAndType(ctx, from, assetI.GetContract(), 0)
 	if err != nil {
 		return txInput, err
 	}
@@ -333,7 +334,10 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (*xc
 	chain := client.Chain.Chain
 
 	// remap to new tx
-	return xclient.TxInfoFromLegacy(chain, legacyTx, xclient.Account), nil
+	txInfo := xclient.TxInfoFromLegacy(chain, legacyTx, xclient.Account)
+	// tendermint has instant finality
+	txInfo.Finalized = txInfo.Block.Height > 0
+	return txInfo, nil
 }
 
 // GetAccount returns a Cosmos account
@@ -358,23 +362,35 @@ func (client *Client) GetAccount(ctx context.Context, address xc.Address) (clien
 
 // FetchBalance fetches balance for input asset for a Cosmos address
 func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (*xc.BigInt, error) {
-	bal, _, err := client.fetchBalanceAndType(ctx, address, client.Chain.GetContract())
+	bal, _, err := client.fetchBalanceAndType(ctx, address, client.Chain.GetContract(), 0)
 	return bal, err
 }
 
 func (client *Client) FetchBalanceForAsset(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress) (*xc.BigInt, error) {
-	bal, _, err := client.fetchBalanceAndType(ctx, address, contractAddress)
+	bal, _, err := client.fetchBalanceAndType(ctx, address, contractAddress, 0)
 	return bal, err
 }
 
-func (client *Client) fetchBalanceAndType(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress) (*xc.BigInt, tx_input.CosmoAssetType, error) {
+// FetchBalanceAt fetches the balance at a historical height, which requires a node that has not pruned it.
+// Cosmos chains have instant finality, so all commitment levels read the latest block.
+func (client *Client) FetchBalanceAt(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress, args xclient.ReadArgs) (*xc.BigInt, error) {
+	if contractAddress == "" {
+		contractAddress = client.Chain.GetContract()
+	}
+	height, _ := args.GetHeight()
+	bal, _, err := client.fetchBalanceAndType(ctx, address, contractAddress, int64(height))
+	return bal, err
+}
+
+// A height of 0 reads the latest block
+func (client *Client) fetchBalanceAndType(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress, height int64) (*xc.BigInt, tx_input.CosmoAssetType, error) {
 	// attempt getting the x/bank module balance first.
-	bal, bankErr := client.fetchBankModuleBalance(ctx, address, contractAddress)
+	bal, bankErr := client.fetchBankModuleBalance(ctx, address, contractAddress, height)
 	if bankErr == nil {
 		if bal.Uint64() == 0 {
 			// sometimes x/bank will incorrectly return 0 balance for invalid bank assets (like on terra chain).
 			// so if there's 0 bal, we double check if there's an cw20 balance.
-			bal, cw20Err := client.FetchCw20Balance(ctx, address, contractAddress)
+			bal, cw20Err := client.fetchCw20Balance(ctx, address, contractAddress, height)
 			if cw20Err == nil && bal.Uint64() > 0 {
 				return &bal, tx_input.CW20, nil
 			}
@@ -383,7 +399,7 @@ func (client *Client) fetchBalanceAndType(ctx context.Context, address xc.Addres
 	}
 
 	// attempt getting the cw20 balance.
-	bal, cw20Err := client.FetchCw20Balance(ctx, address, contractAddress)
+	bal, cw20Err := client.fetchCw20Balance(ctx, address, contractAddress, height)
 	if cw20Err == nil {
 		return &bal, tx_input.CW20, nil
 	}
@@ -392,6 +408,10 @@ func (client *Client) fetchBalanceAndType(ctx context.Context, address xc.Addres
 }
 
 func (client *Client) FetchCw20Balance(ctx context.Context, address xc.Address, contract xc.ContractAddress) (xc.BigInt, error) {
+	return client.fetchCw20Balance(ctx, address, contract, 0)
+}
+
+func (client *Client) fetchCw20Balance(ctx context.Context, address xc.Address, contract xc.ContractAddress, height int64) (xc.BigInt, error) {
 	zero := xc.NewBigIntFromUint64(0)
 	contractAddress := contract
 
@@ -406,7 +426,7 @@ func (client *Client) FetchCw20Balance(ctx context.Context, address xc.Address,
 	}
 	var balResult TokenBalance
 
-	balResp, err := wasmtypes.NewQueryClient(client.Ctx).SmartContractState(ctx, &wasmtypes.QuerySmartContractStateRequest{
+	balResp, err := wasmtypes.NewQueryClient(client.Ctx.WithHeight(height)).SmartContractState(ctx, &wasmtypes.QuerySmartContractStateRequest{
 		QueryData: wasmtypes.RawContractMessage(input),
 		Address:   string(contractAddress),
 	})
@@ -424,12 +444,12 @@ func (client *Client) FetchCw20Balance(ctx context.Context, address xc.Address,
 
 // FetchNativeBalance fetches account balance for a Cosmos address
 func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.BigInt, error) {
-	return client.fetchBankModuleBalance(ctx, address, "")
+	return client.fetchBankModuleBalance(ctx, address, "", 0)
 }
 
 // Cosmos chains can have multiple native assets.  This helper is necessary to query the
 // native bank module for a given asset.
-func (client *Client) fetchBankModuleBalance(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress) (xc.BigInt, error) {
+func (client *Client) fetchBankModuleBalance(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress, height int64) (xc.BigInt, error) {
 	zero := xc.NewBigIntFromUint64(0)
 
 	_, err := types.GetFromBech32(string(address), client.Prefix)
@@ -448,7 +468,7 @@ func (client *Client) fetchBankModuleBalance(ctx context.Context, address xc.Add
 		return zero, fmt.Errorf("failed to account balance: no denom on contractAddress %s", contractAddress)
 	}
 
-	queryClient := banktypes.NewQueryClient(client.Ctx)
+	queryClient := banktypes.NewQueryClient(client.Ctx.WithHeight(height))
 	balResp, err := queryClient.Balance(ctx, &banktypes.QueryBalanceRequest{
 		Address: string(address),
 		Denom:   denom,
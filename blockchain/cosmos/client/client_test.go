@@ -3,14 +3,18 @@ package client_test
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/CustodyOne/chainkit/blockchain/cosmos/client"
 	"github.com/CustodyOne/chainkit/blockchain/cosmos/tx"
 	"github.com/CustodyOne/chainkit/blockchain/cosmos/tx_input"
 	"github.com/CustodyOne/chainkit/blockchain/cosmos/tx_input/gas"
+	xclient "github.com/CustodyOne/chainkit/client"
 	testtypes "github.com/CustodyOne/chainkit/testutil/types"
 	xc "github.com/CustodyOne/chainkit/types"
 	"github.com/stretchr/testify/require"
@@ -608,3 +612,42 @@ func TestFetchBalance(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchBalanceAt(t *testing.T) {
+	heights := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Params struct {
+				Height string `json:"height"`
+			} `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		heights = append(heights, request.Params.Height)
+		rw.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"response": {"code": 0,"log": "","info": "","index": "0","key": null,"value": "ChAKBXVsdW5hEgc0OTc5MDYz","proofOps": null,"height": "2803726","codespace": ""}}}`, request.ID)))
+	}))
+	defer server.Close()
+
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:       "LUNA",
+		ChainCoin:   "uluna",
+		ChainPrefix: "terra",
+		Client:      &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+	address := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+
+	args, err := xclient.NewReadArgs(xclient.ReadOptionHeight(2803726))
+	require.NoError(t, err)
+	balance, err := cli.FetchBalanceAt(context.Background(), address, "", args)
+	require.NoError(t, err)
+	require.Equal(t, "4979063", balance.String())
+
+	// instant finality, so the latest block is already finalized
+	args, err = xclient.NewReadArgs(xclient.ReadOptionCommitment(xclient.CommitmentFinalized))
+	require.NoError(t, err)
+	_, err = cli.FetchBalanceAt(context.Background(), address, "", args)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"2803726", "0"}, heights)
+}
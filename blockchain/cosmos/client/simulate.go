@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/cosmos/tx"
+	xclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	comettypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/grpc/status"
+)
+
+var _ xclient.SimulationClient = &Client{}
+
+// Simulate runs the transaction through the tx service of the node and reports the transfers
+// from the emitted events.  Signatures are not verified when simulating.
+func (client *Client) Simulate(ctx context.Context, trans xc.Tx) (*xclient.TxInfo, error) {
+	cosmosTx, ok := trans.(*tx.Tx)
+	if !ok {
+		return nil, fmt.Errorf("unsupported transaction type %T", trans)
+	}
+	txBytes, err := cosmosTx.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize tx: %v", err)
+	}
+	chain := client.Chain.Chain
+
+	serviceClient := txtypes.NewServiceClient(client.Ctx)
+	resp, err := serviceClient.Simulate(ctx, &txtypes.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		// failures during execution are returned as a grpc status with the log
+		if s, ok := status.FromError(err); ok {
+			msg := s.Message()
+			return xclient.NewSimulatedTxInfo(chain, string(cosmosTx.Hash()), &msg), nil
+		}
+		return nil, fmt.Errorf("could not simulate tx: %v", err)
+	}
+
+	info := xclient.NewSimulatedTxInfo(chain, string(cosmosTx.Hash()), nil)
+	events := []comettypes.Event{}
+	if resp.Result != nil {
+		events = resp.Result.Events
+	}
+	for _, ev := range ParseEvents(events).Transfers {
+		info.AddSimpleTransfer(xc.Address(ev.Sender), xc.Address(ev.Recipient), xc.ContractAddress(ev.Contract), ev.Amount, nil, "")
+	}
+
+	var sdkTx types.Tx = cosmosTx.CosmosTx
+	if cosmosTx.CosmosTxBuilder != nil {
+		sdkTx = cosmosTx.CosmosTxBuilder.GetTx()
+	}
+	if feeTx, ok := sdkTx.(types.FeeTx); ok && len(feeTx.GetFee()) > 0 {
+		payer := simulatedFeePayer(events)
+		if len(feeTx.FeePayer()) > 0 {
+			payer, err = types.Bech32ifyAddressBytes(client.Prefix, feeTx.FeePayer())
+			if err != nil {
+				return nil, fmt.Errorf("invalid fee payer: %v", err)
+			}
+		}
+		for _, coin := range feeTx.GetFee() {
+			info.AddFee(xc.Address(payer), xc.ContractAddress(coin.Denom), xc.BigInt(*coin.Amount.BigInt()), nil)
+		}
+	}
+	info.Fees = info.CalculateFees()
+	return info, nil
+}
+
+// The signers of an unsigned tx may not be known, so fallback to the events of the ante handler
+func simulatedFeePayer(events []comettypes.Event) string {
+	sender := ""
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			if event.Type == "tx" && attr.Key == "fee_payer" {
+				return attr.Value
+			}
+			if event.Type == "message" && attr.Key == "sender" && sender == "" {
+				sender = attr.Value
+			}
+		}
+	}
+	return sender
+}
@@ -0,0 +1,123 @@
+package client_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/cosmos/builder"
+	"github.com/CustodyOne/chainkit/blockchain/cosmos/client"
+	"github.com/CustodyOne/chainkit/blockchain/cosmos/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulate(t *testing.T) {
+	from := "xpla1hdvf6vv5amc7wp84js0ls27apekwxpr0ge96kg"
+	to := "xpla1a8f3wnn7qwvwdzxkc9w849kfzhrr6gdvy4c8wv"
+	feeCollector := "xpla17xpfvakm2amg962yls6f84z3kell8c5lw7muqw"
+	attrs := func(kv ...string) []abci.EventAttribute {
+		attrs := []abci.EventAttribute{}
+		for i := 0; i < len(kv); i += 2 {
+			attrs = append(attrs, abci.EventAttribute{Key: kv[i], Value: kv[i+1]})
+		}
+		return attrs
+	}
+	simulated, err := (&txtypes.SimulateResponse{
+		GasInfo: &types.GasInfo{GasWanted: 110000, GasUsed: 93146},
+		Result: &types.Result{
+			Events: []abci.Event{
+				// the fee is deducted before the message events
+				{Type: "transfer", Attributes: attrs("recipient", feeCollector, "sender", from, "amount", "1000axpla")},
+				{Type: "tx", Attributes: attrs("fee", "1000axpla", "fee_payer", from)},
+				{Type: "message", Attributes: attrs("action", "/cosmos.bank.v1beta1.MsgSend", "sender", from)},
+				{Type: "transfer", Attributes: attrs("recipient", to, "sender", from, "amount", "500axpla")},
+			},
+		},
+	}).Marshal()
+	require.NoError(t, err)
+
+	vectors := []struct {
+		name     string
+		response string
+		err      string
+	}{
+		{
+			name:     "success",
+			response: fmt.Sprintf(`{"code":0,"value":"%s","height":"100"}`, base64.StdEncoding.EncodeToString(simulated)),
+		},
+		{
+			name:     "failure",
+			response: `{"code":5,"log":"spendable balance 10axpla is smaller than 500axpla: insufficient funds","codespace":"sdk","height":"100"}`,
+			err:      "insufficient funds",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			paths := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				var request struct {
+					ID     json.RawMessage `json:"id"`
+					Params struct {
+						Path string `json:"path"`
+					} `json:"params"`
+				}
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+				paths = append(paths, request.Params.Path)
+				rw.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"response":%s}}`, request.ID, v.response)))
+			}))
+			defer server.Close()
+
+			chain := &xc.ChainConfig{
+				Chain:       xc.XPLA,
+				ChainCoin:   "axpla",
+				ChainPrefix: "xpla",
+				// allow the gas price of the test
+				ChainMaxGasPrice: 1,
+				Client:           &xc.ClientConfig{URL: server.URL},
+			}
+			cli, err := client.NewClient(chain)
+			require.NoError(t, err)
+			txBuilder, err := builder.NewTxBuilder(chain)
+			require.NoError(t, err)
+			args, err := xcbuilder.NewTransferArgs(xc.Address(from), xc.Address(to), xc.NewBigIntFromUint64(500))
+			require.NoError(t, err)
+			input := tx_input.NewTxInput()
+			input.AssetType = tx_input.BANK
+			input.GasLimit = 100000
+			input.GasPrice = 0.01
+			trans, err := txBuilder.NewTransfer(args, input)
+			require.NoError(t, err)
+
+			info, err := cli.Simulate(context.Background(), trans)
+			require.NoError(t, err)
+			require.Equal(t, []string{"/cosmos.tx.v1beta1.Service/Simulate"}, paths)
+			require.EqualValues(t, 0, info.Block.Height)
+			if v.err != "" {
+				require.NotNil(t, info.Error)
+				require.Contains(t, *info.Error, v.err)
+				require.Empty(t, info.Transfers)
+				return
+			}
+			require.Nil(t, info.Error)
+			// the fee is only reported once, as a fee movement
+			require.Len(t, info.Transfers, 2)
+			require.Equal(t, xclient.NewAddressName(xc.XPLA, from), info.Transfers[0].From[0].Address)
+			require.Equal(t, xclient.NewAddressName(xc.XPLA, to), info.Transfers[0].To[0].Address)
+			require.Equal(t, "500", info.Transfers[0].To[0].Balance.String())
+			require.Equal(t, xclient.NewAddressName(xc.XPLA, from), info.Transfers[1].From[0].Address)
+			require.Len(t, info.Fees, 1)
+			require.Equal(t, "1000", info.Fees[0].Balance.String())
+			require.EqualValues(t, "axpla", info.Fees[0].Contract)
+		})
+	}
+}
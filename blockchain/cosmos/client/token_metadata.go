@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	xclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/cosmos/cosmos-sdk/types"
+)
+
+var _ xclient.TokenMetadataClient = &Client{}
+
+// FetchTokenMetadata queries the CW20 token_info of a token contract
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc.ContractAddress) (*xclient.TokenMetadata, error) {
+	_, err := types.GetFromBech32(string(contract), client.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("bad contract address: '%v': %v", contract, err)
+	}
+
+	input := json.RawMessage(`{"token_info": {}}`)
+	type TokenInfo struct {
+		Name     string `json:"name"`
+		Symbol   string `json:"symbol"`
+		Decimals int32  `json:"decimals"`
+	}
+	var tokenInfo TokenInfo
+
+	resp, err := wasmtypes.NewQueryClient(client.Ctx).SmartContractState(ctx, &wasmtypes.QuerySmartContractStateRequest{
+		QueryData: wasmtypes.RawContractMessage(input),
+		Address:   string(contract),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token info: '%v': %v", contract, err)
+	}
+	err = json.Unmarshal(resp.Data.Bytes(), &tokenInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token info: '%v': %v", contract, err)
+	}
+
+	return &xclient.TokenMetadata{
+		Contract: contract,
+		Name:     tokenInfo.Name,
+		Symbol:   tokenInfo.Symbol,
+		Decimals: tokenInfo.Decimals,
+	}, nil
+}
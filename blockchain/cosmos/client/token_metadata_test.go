@@ -0,0 +1,62 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/cosmos/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchTokenMetadata(t *testing.T) {
+	vectors := []struct {
+		contract string
+		resp     interface{}
+		symbol   string
+		decimals int32
+		err      string
+	}{
+		{
+			"terra1pepwcav40nvj3kh60qqgrk8k07ydmc00xyat06",
+			`{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"ClR7Im5hbWUiOiJBc3Ryb3BvcnQiLCJzeW1ib2wiOiJBU1RSTyIsImRlY2ltYWxzIjo2LCJ0b3RhbF9zdXBwbHkiOiIxMDAwMDAwMDAwMDAwMDAwIn0=","proofOps":null,"height":"12817698","codespace":""}}`,
+			"ASTRO",
+			6,
+			"",
+		},
+		{
+			"terra-invalid",
+			`null`,
+			"",
+			0,
+			"bad contract address",
+		},
+		{
+			"terra1pepwcav40nvj3kh60qqgrk8k07ydmc00xyat06",
+			`{"response":{"code":1,"log":"no such contract","info":"","index":"0","key":null,"value":"","proofOps":null,"height":"12817698","codespace":""}}`,
+			"",
+			0,
+			"failed to get token info",
+		},
+	}
+
+	for _, v := range vectors {
+		server, close := testtypes.MockJSONRPC(t, v.resp)
+		defer close()
+
+		client, _ := client.NewClient(&xc.ChainConfig{
+			Chain: "LUNC", ChainCoin: "uluna", ChainPrefix: "terra",
+			Client: &xc.ClientConfig{URL: server.URL},
+		})
+
+		metadata, err := client.FetchTokenMetadata(context.Background(), xc.ContractAddress(v.contract))
+		if v.err != "" {
+			require.ErrorContains(t, err, v.err)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, v.symbol, metadata.Symbol)
+			require.Equal(t, v.decimals, metadata.Decimals)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package gnosis_safe
+
+import (
+	_ "embed"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:embed abi.json
+var abiJson string
+var safeAbi abi.ABI
+
+func NewAbi() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+func init() {
+	safeAbi = NewAbi()
+}
+
+func Method(name string) abi.Method {
+	return safeAbi.Methods[name]
+}
+
+func EventByID(topic common.Hash) (*abi.Event, error) {
+	return safeAbi.EventByID(topic)
+}
@@ -0,0 +1,113 @@
+package lido
+
+import (
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Combined ABI of the stETH, wstETH and WithdrawalQueueERC721 contracts
+//
+//go:embed abi.json
+var abiJson string
+var lidoAbi abi.ABI
+
+// Limits on the stETH in a single withdrawal request, enforced by the withdrawal queue
+var MinWithdrawalAmount = big.NewInt(100)
+var MaxWithdrawalAmount = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+
+func NewAbi() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+func init() {
+	lidoAbi = NewAbi()
+}
+
+// Method returns a method of one of the Lido contracts, e.g. for read-only calls
+func Method(name string) abi.Method {
+	return lidoAbi.Methods[name]
+}
+
+// stETH.submit, which mints stETH for the ether sent
+func SerializeSubmit(referral common.Address) ([]byte, error) {
+	return lidoAbi.Pack("submit", referral)
+}
+
+// stETH.approve or wstETH.approve
+func SerializeApprove(spender common.Address, amount *big.Int) ([]byte, error) {
+	return lidoAbi.Pack("approve", spender, amount)
+}
+
+// wstETH.wrap, which converts stETH to wstETH.  The wstETH contract must be approved to spend the stETH.
+func SerializeWrap(amount *big.Int) ([]byte, error) {
+	return lidoAbi.Pack("wrap", amount)
+}
+
+// wstETH.unwrap, which converts wstETH back to stETH
+func SerializeUnwrap(amount *big.Int) ([]byte, error) {
+	return lidoAbi.Pack("unwrap", amount)
+}
+
+// WithdrawalQueue.requestWithdrawals, which locks stETH and mints a withdrawal NFT to the owner for each amount
+func SerializeRequestWithdrawals(amounts []*big.Int, owner common.Address) ([]byte, error) {
+	return lidoAbi.Pack("requestWithdrawals", amounts, owner)
+}
+
+// WithdrawalQueue.claimWithdrawals, which burns finalized withdrawal NFTs and sends the ether to the caller
+func SerializeClaimWithdrawals(requestIds []*big.Int, hints []*big.Int) ([]byte, error) {
+	return lidoAbi.Pack("claimWithdrawals", requestIds, hints)
+}
+
+// SplitWithdrawalAmount splits an amount of stETH into requests within the limits of the withdrawal queue
+func SplitWithdrawalAmount(amount *big.Int) ([]*big.Int, error) {
+	if amount.Cmp(MinWithdrawalAmount) < 0 {
+		return nil, fmt.Errorf("must withdraw at least %s wei of stETH", MinWithdrawalAmount)
+	}
+	amounts := []*big.Int{}
+	remaining := new(big.Int).Set(amount)
+	for remaining.Cmp(MaxWithdrawalAmount) > 0 {
+		chunk := new(big.Int).Set(MaxWithdrawalAmount)
+		left := new(big.Int).Sub(remaining, chunk)
+		if left.Cmp(MinWithdrawalAmount) < 0 {
+			// keep the last request above the minimum
+			chunk.Sub(chunk, MinWithdrawalAmount)
+		}
+		amounts = append(amounts, chunk)
+		remaining.Sub(remaining, chunk)
+	}
+	return append(amounts, remaining), nil
+}
+
+type WithdrawalRequestStatus struct {
+	AmountOfStETH  *big.Int       `json:"amountOfStETH"`
+	AmountOfShares *big.Int       `json:"amountOfShares"`
+	Owner          common.Address `json:"owner"`
+	Timestamp      *big.Int       `json:"timestamp"`
+	IsFinalized    bool           `json:"isFinalized"`
+	IsClaimed      bool           `json:"isClaimed"`
+}
+
+// ParseWithdrawalStatus decodes the result of WithdrawalQueue.getWithdrawalStatus
+func ParseWithdrawalStatus(data []byte) ([]WithdrawalRequestStatus, error) {
+	values, err := lidoAbi.Unpack("getWithdrawalStatus", data)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("expected 1 value, got %d", len(values))
+	}
+	return ToWithdrawalStatuses(values[0]), nil
+}
+
+// ToWithdrawalStatuses converts the decoded statuses returned by getWithdrawalStatus
+func ToWithdrawalStatuses(value interface{}) []WithdrawalRequestStatus {
+	return *abi.ConvertType(value, new([]WithdrawalRequestStatus)).(*[]WithdrawalRequestStatus)
+}
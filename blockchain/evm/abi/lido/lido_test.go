@@ -0,0 +1,88 @@
+package lido_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/abi/lido"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func ether(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e18))
+}
+
+func TestSplitWithdrawalAmount(t *testing.T) {
+	for _, v := range []struct {
+		amount  *big.Int
+		amounts []*big.Int
+		err     string
+	}{
+		{amount: big.NewInt(99), err: "must withdraw at least 100 wei"},
+		{amount: big.NewInt(100), amounts: []*big.Int{big.NewInt(100)}},
+		{amount: ether(1000), amounts: []*big.Int{ether(1000)}},
+		{amount: ether(2500), amounts: []*big.Int{ether(1000), ether(1000), ether(500)}},
+		{
+			// the remainder would be below the minimum
+			amount:  new(big.Int).Add(ether(1000), big.NewInt(1)),
+			amounts: []*big.Int{new(big.Int).Sub(ether(1000), big.NewInt(100)), big.NewInt(101)},
+		},
+	} {
+		amounts, err := lido.SplitWithdrawalAmount(v.amount)
+		if v.err != "" {
+			require.ErrorContains(t, err, v.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, v.amounts, amounts)
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	data, err := lido.SerializeSubmit(common.Address{})
+	require.NoError(t, err)
+	require.Equal(t, "a1903eab", common.Bytes2Hex(data[:4]))
+
+	data, err = lido.SerializeRequestWithdrawals([]*big.Int{ether(1)}, common.HexToAddress("0x1"))
+	require.NoError(t, err)
+	require.Equal(t, "d6681042", common.Bytes2Hex(data[:4]))
+
+	data, err = lido.SerializeClaimWithdrawals([]*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(2)})
+	require.NoError(t, err)
+	require.Equal(t, "e3afe0a3", common.Bytes2Hex(data[:4]))
+
+	data, err = lido.SerializeWrap(ether(1))
+	require.NoError(t, err)
+	require.Equal(t, "ea598cb0", common.Bytes2Hex(data[:4]))
+
+	data, err = lido.SerializeUnwrap(ether(1))
+	require.NoError(t, err)
+	require.Equal(t, "de0e9a3e", common.Bytes2Hex(data[:4]))
+}
+
+func TestParseWithdrawalStatus(t *testing.T) {
+	owner := common.HexToAddress("0x2")
+	method := lido.Method("getWithdrawalStatus")
+	data, err := method.Outputs.Pack([]struct {
+		AmountOfStETH  *big.Int
+		AmountOfShares *big.Int
+		Owner          common.Address
+		Timestamp      *big.Int
+		IsFinalized    bool
+		IsClaimed      bool
+	}{
+		{ether(1), ether(1), owner, big.NewInt(1700000000), true, false},
+		{ether(2), ether(2), owner, big.NewInt(1700000001), false, false},
+	})
+	require.NoError(t, err)
+
+	statuses, err := lido.ParseWithdrawalStatus(data)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, ether(1), statuses[0].AmountOfStETH)
+	require.Equal(t, owner, statuses[0].Owner)
+	require.True(t, statuses[0].IsFinalized)
+	require.Equal(t, ether(2), statuses[1].AmountOfStETH)
+	require.False(t, statuses[1].IsFinalized)
+}
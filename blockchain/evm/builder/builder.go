@@ -24,6 +24,8 @@ var DefaultMaxTipCapGwei uint64 = 5
 
 type GethTxBuilder interface {
 	BuildTxWithPayload(chain *xc.ChainConfig, to xc.Address, value xc.BigInt, data []byte, input xc.TxInput) (xc.Tx, error)
+	// Creates a contract, using the data as the init code
+	BuildContractCreation(chain *xc.ChainConfig, value xc.BigInt, data []byte, input xc.TxInput) (xc.Tx, error)
 }
 
 // supports evm after london merge
@@ -104,7 +106,7 @@ func (txBuilder TxBuilder) NewTransfer(args *xcbuilder.TransferArgs, input xc.Tx
 
 // NewNativeTransfer creates a new transfer for a native asset
 func (txBuilder TxBuilder) NewNativeTransfer(args *xcbuilder.TransferArgs, input xc.TxInput) (xc.Tx, error) {
-	return txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, args.GetTo(), args.GetAmount(), []byte{}, input)
+	return withSender(args.GetFrom())(txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, args.GetTo(), args.GetAmount(), []byte{}, input))
 }
 
 // NewTokenTransfer creates a new transfer for a token asset
@@ -120,7 +122,22 @@ func (txBuilder TxBuilder) NewTokenTransfer(args *xcbuilder.TransferArgs, input
 	if err != nil {
 		return nil, err
 	}
-	return txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, xc.Address(contract), zero, payload, input)
+	return withSender(args.GetFrom())(txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, xc.Address(contract), zero, payload, input))
+}
+
+// Records the expected signer on the transaction, so it can be simulated before signing
+func withSender(from xc.Address) func(xc.Tx, error) (xc.Tx, error) {
+	return func(trans xc.Tx, err error) (xc.Tx, error) {
+		if err != nil {
+			return trans, err
+		}
+		if ethTx, ok := trans.(*tx.Tx); ok {
+			if sender, err := address.FromHex(from); err == nil {
+				ethTx.Sender = sender
+			}
+		}
+		return trans, nil
+	}
 }
 
 func BuildERC20Payload(to xc.Address, amount xc.BigInt) ([]byte, error) {
@@ -149,11 +166,22 @@ func BuildERC20Payload(to xc.Address, amount xc.BigInt) ([]byte, error) {
 }
 
 func (*EvmTxBuilder) BuildTxWithPayload(chain *xc.ChainConfig, to xc.Address, value xc.BigInt, data []byte, inputRaw xc.TxInput) (xc.Tx, error) {
+	if to == "" {
+		return nil, errors.New("missing destination address")
+	}
 	address, err := address.FromHex(to)
 	if err != nil {
 		return nil, err
 	}
+	return buildDynamicFeeTx(chain, &address, value, data, inputRaw)
+}
 
+func (*EvmTxBuilder) BuildContractCreation(chain *xc.ChainConfig, value xc.BigInt, data []byte, inputRaw xc.TxInput) (xc.Tx, error) {
+	return buildDynamicFeeTx(chain, nil, value, data, inputRaw)
+}
+
+// A nil `to` address creates a contract
+func buildDynamicFeeTx(chain *xc.ChainConfig, toAddress *common.Address, value xc.BigInt, data []byte, inputRaw xc.TxInput) (xc.Tx, error) {
 	input := inputRaw.(*tx_input.TxInput)
 	var chainId *big.Int = input.ChainId.Int()
 	if input.ChainId.Uint64() == 0 {
@@ -173,6 +201,15 @@ func (*EvmTxBuilder) BuildTxWithPayload(chain *xc.ChainConfig, to xc.Address, va
 		gasTipCap = maxTipWei
 	}
 
+	var feeCurrency *common.Address
+	if input.FeeCurrency != "" {
+		address, err := address.FromHex(xc.Address(input.FeeCurrency))
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee currency: %v", err)
+		}
+		feeCurrency = &address
+	}
+
 	return &tx.Tx{
 		EthTx: types.NewTx(&types.DynamicFeeTx{
 			ChainID:   chainId,
@@ -180,11 +217,12 @@ func (*EvmTxBuilder) BuildTxWithPayload(chain *xc.ChainConfig, to xc.Address, va
 			GasTipCap: gasTipCap.Int(),
 			GasFeeCap: input.GasFeeCap.Int(),
 			Gas:       input.GasLimit,
-			To:        &address,
+			To:        toAddress,
 			Value:     value.Int(),
 			Data:      data,
 		}),
-		Signer: types.LatestSignerForChainID(chainId),
+		Signer:      types.LatestSignerForChainID(chainId),
+		FeeCurrency: feeCurrency,
 	}, nil
 }
 
@@ -231,6 +269,8 @@ func (txBuilder TxBuilder) Stake(stakeArgs xcbuilder.StakeArgs, input xc.StakeTx
 			return nil, fmt.Errorf("could not build tx for %T: %v", input, err)
 		}
 		return tx, nil
+	case *tx_input.LidoStakeInput:
+		return txBuilder.stakeLido(stakeArgs, input)
 	default:
 		return nil, fmt.Errorf("unsupported staking type %T", input)
 	}
@@ -259,11 +299,21 @@ func (txBuilder TxBuilder) Unstake(stakeArgs xcbuilder.StakeArgs, input xc.Unsta
 			return nil, fmt.Errorf("could not build tx for %T: %v", input, err)
 		}
 		return tx, nil
+	case *tx_input.LidoUnstakeInput:
+		return txBuilder.unstakeLido(stakeArgs, input)
+	case *tx_input.WithdrawalRequestInput:
+		return txBuilder.unstakeWithWithdrawalRequest(stakeArgs, input)
 	default:
 		return nil, fmt.Errorf("unsupported unstaking type %T", input)
 	}
 }
 
 func (txBuilder TxBuilder) Withdraw(stakeArgs xcbuilder.StakeArgs, input xc.WithdrawTxInput) (xc.Tx, error) {
-	return nil, fmt.Errorf("ethereum stakes are claimed automatically")
+	switch input := input.(type) {
+	case *tx_input.LidoWithdrawInput:
+		return txBuilder.withdrawLido(stakeArgs, input)
+	default:
+		// validator stakes are withdrawn to the withdrawal credentials
+		return nil, fmt.Errorf("ethereum stakes are claimed automatically")
+	}
 }
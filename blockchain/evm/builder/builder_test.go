@@ -68,7 +68,9 @@ func TestStakingTxUsesCredential(t *testing.T) {
 		hexutil.MustDecode("0x010000000000000000000000273b437645ba723299d07b1bdffcf508be64771f"),
 	}
 
-	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{
+		Staking: xc_types.StakingConfig{StakeContract: "0x576834cB068e677db4aFF6ca245c7bde16C3867e"},
+	})
 	owner := xc.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
 	args, _ := xcbuilder.NewStakeArgs(xc_types.ETH, owner, xc_types.NewBigIntFromUint64(1))
 	trans, err := txBuilder.Stake(args, input)
@@ -88,7 +90,9 @@ func TestUnstakingTx(t *testing.T) {
 		hexutil.MustDecode("0xa776cfc875b15a1444bbda22e47e759ade11b39912a3e210807204f410d43baa332acb38aab206bc8ac7ad476a42839b"),
 	}
 
-	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{
+		Staking: xc_types.StakingConfig{UnstakeContract: "0x004c226fff73aa94b78a4df1a0e861797ba16819"},
+	})
 	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
 	human, _ := xc_types.NewAmountHumanReadableFromStr("64")
 
@@ -104,3 +108,20 @@ func TestUnstakingTx(t *testing.T) {
 
 	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(data))
 }
+
+func TestTransferWithFeeCurrency(t *testing.T) {
+	b, _ := builder.NewTxBuilder(&xc_types.ChainConfig{Chain: xc_types.CELO})
+	from := xc_types.Address("0x724435CC1B2821362c2CD425F2744Bd7347bf299")
+	to := xc_types.Address("0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F")
+	args, err := xcbuilder.NewTransferArgs(from, to, xc_types.NewBigIntFromUint64(100))
+	require.NoError(t, err)
+
+	input := tx_input.NewTxInput()
+	input.FeeCurrency = "0x765DE816845861e75A25fCA122bb6898B8B1282a"
+	trans, err := b.NewTransfer(args, input)
+	require.NoError(t, err)
+	require.Equal(t, "0x765DE816845861e75A25fCA122bb6898B8B1282a", trans.(*tx.Tx).FeeCurrency.String())
+	bz, err := trans.(*tx.Tx).Serialize()
+	require.NoError(t, err)
+	require.EqualValues(t, tx.CeloFeeCurrencyTxType, bz[0])
+}
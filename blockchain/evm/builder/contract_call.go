@@ -0,0 +1,476 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ContractCall is a call to a single contract method, described by an ABI.
+type ContractCall struct {
+	Contract xc.Address
+	// Native value to attach to the call
+	Value  xc.BigInt
+	Method abi.Method
+	Args   []interface{}
+}
+
+// NewContractCall prepares a call to `method` on `contract`.
+// The ABI may either be an ABI JSON document, or a human readable signature like
+// "function balanceOf(address owner) view returns (uint256)".  When a signature is used,
+// the method may be left empty.
+// Arguments may be passed as native go-ethereum types, or as strings/numbers which are
+// converted according to the ABI type.
+func NewContractCall(contract xc.Address, abiOrSignature string, method string, args ...interface{}) (*ContractCall, error) {
+	contractAbi, err := ParseContractAbi(abiOrSignature)
+	if err != nil {
+		return nil, err
+	}
+	abiMethod, err := lookupMethod(contractAbi, method)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != len(abiMethod.Inputs) {
+		return nil, fmt.Errorf("method %s expects %d arguments, got %d", abiMethod.Sig, len(abiMethod.Inputs), len(args))
+	}
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		converted[i], err = ConvertAbiArg(abiMethod.Inputs[i].Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d (%s) for %s: %v", i, abiMethod.Inputs[i].Name, abiMethod.Sig, err)
+		}
+	}
+	return &ContractCall{
+		Contract: contract,
+		Value:    xc.NewBigIntFromUint64(0),
+		Method:   abiMethod,
+		Args:     converted,
+	}, nil
+}
+
+// Calldata returns the ABI encoded method ID and arguments
+func (call *ContractCall) Calldata() ([]byte, error) {
+	packed, err := call.Method.Inputs.Pack(call.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode arguments for %s: %v", call.Method.Sig, err)
+	}
+	return append(append([]byte{}, call.Method.ID...), packed...), nil
+}
+
+// DecodeResult decodes the return data of the method, e.g. from an eth_call
+func (call *ContractCall) DecodeResult(data []byte) ([]interface{}, error) {
+	if len(call.Method.Outputs) == 0 {
+		return []interface{}{}, nil
+	}
+	values, err := call.Method.Outputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode result of %s: %v", call.Method.Sig, err)
+	}
+	return values, nil
+}
+
+// NewContractCall creates a transaction calling a contract method
+func (txBuilder TxBuilder) NewContractCall(call *ContractCall, input xc.TxInput) (xc.Tx, error) {
+	data, err := call.Calldata()
+	if err != nil {
+		return nil, err
+	}
+	return txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, call.Contract, call.Value, data, input)
+}
+
+// ParseContractAbi parses either an ABI JSON document or a human readable function signature
+func ParseContractAbi(abiOrSignature string) (abi.ABI, error) {
+	abiOrSignature = strings.TrimSpace(abiOrSignature)
+	if strings.HasPrefix(abiOrSignature, "[") || strings.HasPrefix(abiOrSignature, "{") {
+		if strings.HasPrefix(abiOrSignature, "{") {
+			// single entry
+			abiOrSignature = "[" + abiOrSignature + "]"
+		}
+		contractAbi, err := abi.JSON(strings.NewReader(abiOrSignature))
+		if err != nil {
+			return abi.ABI{}, fmt.Errorf("invalid abi: %v", err)
+		}
+		return contractAbi, nil
+	}
+	return parseSignature(abiOrSignature)
+}
+
+func lookupMethod(contractAbi abi.ABI, method string) (abi.Method, error) {
+	if method == "" {
+		if len(contractAbi.Methods) == 1 {
+			for _, m := range contractAbi.Methods {
+				return m, nil
+			}
+		}
+		return abi.Method{}, errors.New("must specify a method name")
+	}
+	if m, ok := contractAbi.Methods[method]; ok {
+		return m, nil
+	}
+	// permit looking up by full signature, e.g. for overloaded methods
+	for _, m := range contractAbi.Methods {
+		if m.Sig == strings.ReplaceAll(method, " ", "") {
+			return m, nil
+		}
+	}
+	return abi.Method{}, fmt.Errorf("method '%s' not found in abi", method)
+}
+
+// ConvertAbiArg converts a loosely typed argument (strings, numbers, lists, maps) into the
+// go type expected by go-ethereum for the given ABI type.  Values that already have the
+// expected type are returned unchanged.
+func ConvertAbiArg(t abi.Type, value interface{}) (interface{}, error) {
+	goType := t.GetType()
+	wideInt := (t.T == abi.IntTy || t.T == abi.UintTy) && t.Size > 64
+	// wide integers are *big.Int and still need a range check
+	if value != nil && reflect.TypeOf(value) == goType && !wideInt {
+		return value, nil
+	}
+	switch v := value.(type) {
+	case xc.Address:
+		value = string(v)
+	case xc.ContractAddress:
+		value = string(v)
+	case xc.BigInt:
+		value = v.Int()
+	case *xc.BigInt:
+		value = v.Int()
+	}
+
+	switch t.T {
+	case abi.AddressTy:
+		switch v := value.(type) {
+		case string:
+			return address.FromHex(xc.Address(v))
+		case []byte:
+			return common.BytesToAddress(v), nil
+		}
+	case abi.IntTy, abi.UintTy:
+		bigValue, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		if t.T == abi.UintTy && bigValue.Sign() < 0 {
+			return nil, fmt.Errorf("negative value for %s", t.String())
+		}
+		if wideInt {
+			if !fitsAbiInt(t, bigValue) {
+				return nil, fmt.Errorf("value %s overflows %s", bigValue.String(), t.String())
+			}
+			return bigValue, nil
+		}
+		result := reflect.New(goType).Elem()
+		if t.T == abi.UintTy {
+			if !bigValue.IsUint64() || result.OverflowUint(bigValue.Uint64()) {
+				return nil, fmt.Errorf("value %s overflows %s", bigValue.String(), t.String())
+			}
+			result.SetUint(bigValue.Uint64())
+		} else {
+			if !bigValue.IsInt64() || result.OverflowInt(bigValue.Int64()) {
+				return nil, fmt.Errorf("value %s overflows %s", bigValue.String(), t.String())
+			}
+			result.SetInt(bigValue.Int64())
+		}
+		return result.Interface(), nil
+	case abi.BoolTy:
+		if v, ok := value.(string); ok {
+			return strconv.ParseBool(v)
+		}
+	case abi.StringTy:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case abi.BytesTy:
+		if v, ok := value.(string); ok {
+			return hexutil.Decode(v)
+		}
+	case abi.FixedBytesTy:
+		var bz []byte
+		switch v := value.(type) {
+		case string:
+			var err error
+			bz, err = hexutil.Decode(v)
+			if err != nil {
+				return nil, err
+			}
+		case []byte:
+			bz = v
+		case common.Hash:
+			bz = v.Bytes()
+		default:
+			return nil, fmt.Errorf("unsupported value %T for %s", value, t.String())
+		}
+		if len(bz) != t.Size {
+			return nil, fmt.Errorf("expected %d bytes for %s, got %d", t.Size, t.String(), len(bz))
+		}
+		result := reflect.New(goType).Elem()
+		reflect.Copy(result, reflect.ValueOf(bz))
+		return result.Interface(), nil
+	case abi.SliceTy, abi.ArrayTy:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, fmt.Errorf("expected a list for %s, got %T", t.String(), value)
+		}
+		if t.T == abi.ArrayTy && rv.Len() != t.Size {
+			return nil, fmt.Errorf("expected %d elements for %s, got %d", t.Size, t.String(), rv.Len())
+		}
+		var result reflect.Value
+		if t.T == abi.ArrayTy {
+			result = reflect.New(goType).Elem()
+		} else {
+			result = reflect.MakeSlice(goType, rv.Len(), rv.Len())
+		}
+		for i := 0; i < rv.Len(); i++ {
+			elem, err := ConvertAbiArg(*t.Elem, rv.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			result.Index(i).Set(reflect.ValueOf(elem))
+		}
+		return result.Interface(), nil
+	case abi.TupleTy:
+		result := reflect.New(goType).Elem()
+		switch v := value.(type) {
+		case []interface{}:
+			if len(v) != len(t.TupleElems) {
+				return nil, fmt.Errorf("expected %d fields for %s, got %d", len(t.TupleElems), t.String(), len(v))
+			}
+			for i := range t.TupleElems {
+				elem, err := ConvertAbiArg(*t.TupleElems[i], v[i])
+				if err != nil {
+					return nil, fmt.Errorf("field %d: %v", i, err)
+				}
+				result.Field(i).Set(reflect.ValueOf(elem))
+			}
+		case map[string]interface{}:
+			for i, name := range t.TupleRawNames {
+				field, ok := v[name]
+				if !ok {
+					return nil, fmt.Errorf("missing field '%s' for %s", name, t.String())
+				}
+				elem, err := ConvertAbiArg(*t.TupleElems[i], field)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %v", name, err)
+				}
+				result.Field(i).Set(reflect.ValueOf(elem))
+			}
+		default:
+			return nil, fmt.Errorf("expected a list or map for %s, got %T", t.String(), value)
+		}
+		return result.Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported value %T for %s", value, t.String())
+}
+
+// fitsAbiInt reports whether the value is in the range of the intN or uintN type
+func fitsAbiInt(t abi.Type, value *big.Int) bool {
+	if t.T == abi.UintTy {
+		return value.Sign() >= 0 && value.BitLen() <= t.Size
+	}
+	if value.Sign() < 0 {
+		// -2^(N-1) is the smallest intN
+		return new(big.Int).Add(value, big.NewInt(1)).BitLen() <= t.Size-1
+	}
+	return value.BitLen() <= t.Size-1
+}
+
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case big.Int:
+		return &v, nil
+	case string:
+		result, ok := new(big.Int).SetString(strings.TrimSpace(v), 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer '%s'", v)
+		}
+		return result, nil
+	case json.Number:
+		return toBigInt(string(v))
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, fmt.Errorf("invalid integer '%v'", v)
+		}
+		return big.NewInt(int64(v)), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("unsupported integer value %T", value)
+}
+
+// parseSignature converts a human readable signature into an ABI with a single method
+func parseSignature(signature string) (abi.ABI, error) {
+	sig := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(signature), "function "))
+	open := strings.Index(sig, "(")
+	if open <= 0 {
+		return abi.ABI{}, fmt.Errorf("invalid function signature '%s'", signature)
+	}
+	name := strings.TrimSpace(sig[:open])
+	inputsRaw, rest, err := splitParens(sig[open:])
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("invalid function signature '%s': %v", signature, err)
+	}
+	inputs, err := parseParams(inputsRaw)
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("invalid function signature '%s': %v", signature, err)
+	}
+
+	mutability := "nonpayable"
+	outputs := []abi.ArgumentMarshaling{}
+	rest = strings.TrimSpace(rest)
+	for rest != "" {
+		var word string
+		if idx := strings.IndexAny(rest, " ("); idx >= 0 {
+			word, rest = rest[:idx], strings.TrimSpace(rest[idx:])
+		} else {
+			word, rest = rest, ""
+		}
+		switch word {
+		case "view", "pure", "payable", "nonpayable":
+			mutability = word
+		case "external", "public":
+		case "returns":
+			var outputsRaw string
+			outputsRaw, rest, err = splitParens(rest)
+			if err != nil {
+				return abi.ABI{}, fmt.Errorf("invalid function signature '%s': %v", signature, err)
+			}
+			outputs, err = parseParams(outputsRaw)
+			if err != nil {
+				return abi.ABI{}, fmt.Errorf("invalid function signature '%s': %v", signature, err)
+			}
+			rest = strings.TrimSpace(rest)
+		default:
+			return abi.ABI{}, fmt.Errorf("invalid function signature '%s': unexpected '%s'", signature, word)
+		}
+	}
+
+	entry := []map[string]interface{}{
+		{
+			"type":            "function",
+			"name":            name,
+			"inputs":          inputs,
+			"outputs":         outputs,
+			"stateMutability": mutability,
+		},
+	}
+	bz, _ := json.Marshal(entry)
+	return abi.JSON(strings.NewReader(string(bz)))
+}
+
+// splitParens returns the contents of the leading parenthesized group and the remainder
+func splitParens(s string) (string, string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") {
+		return "", "", errors.New("expected '('")
+	}
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], nil
+			}
+		}
+	}
+	return "", "", errors.New("unbalanced parentheses")
+}
+
+// splitTopLevel splits on commas that are not nested in parentheses
+func splitTopLevel(s string) []string {
+	parts := []string{}
+	depth := 0
+	last := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
+
+func parseParams(raw string) ([]abi.ArgumentMarshaling, error) {
+	params := []abi.ArgumentMarshaling{}
+	if strings.TrimSpace(raw) == "" {
+		return params, nil
+	}
+	for i, part := range splitTopLevel(raw) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty parameter %d", i)
+		}
+		param := abi.ArgumentMarshaling{}
+		var rest string
+		if strings.HasPrefix(part, "tuple(") {
+			part = strings.TrimPrefix(part, "tuple")
+		}
+		if strings.HasPrefix(part, "(") {
+			inner, after, err := splitParens(part)
+			if err != nil {
+				return nil, err
+			}
+			components, err := parseParams(inner)
+			if err != nil {
+				return nil, err
+			}
+			// array suffix on the tuple, e.g. (address,uint256)[]
+			suffixEnd := strings.IndexAny(after, " \t")
+			if suffixEnd < 0 {
+				suffixEnd = len(after)
+			}
+			param.Type = "tuple" + after[:suffixEnd]
+			param.Components = components
+			rest = after[suffixEnd:]
+		} else {
+			fields := strings.Fields(part)
+			param.Type = normalizeType(fields[0])
+			rest = strings.Join(fields[1:], " ")
+		}
+		for _, word := range strings.Fields(rest) {
+			switch word {
+			case "indexed", "memory", "calldata", "storage", "payable":
+			default:
+				param.Name = word
+			}
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// expand the solidity shorthand integer types
+func normalizeType(t string) string {
+	for _, short := range []string{"uint", "int"} {
+		if t == short || strings.HasPrefix(t, short+"[") {
+			return short + "256" + strings.TrimPrefix(t, short)
+		}
+	}
+	return t
+}
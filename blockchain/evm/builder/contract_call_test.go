@@ -0,0 +1,131 @@
+package builder_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+const erc20TransferAbi = `[{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}]`
+
+func TestContractCallCalldata(t *testing.T) {
+	to := "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F"
+	expected, err := builder.BuildERC20Payload(xc.Address(to), xc.NewBigIntFromUint64(1000))
+	require.NoError(t, err)
+
+	vectors := []struct {
+		name   string
+		abi    string
+		method string
+		args   []interface{}
+	}{
+		{"abi json", erc20TransferAbi, "transfer", []interface{}{to, "1000"}},
+		{"abi json typed", erc20TransferAbi, "transfer", []interface{}{common.HexToAddress(to), big.NewInt(1000)}},
+		{"signature", "transfer(address,uint256)", "", []interface{}{xc.Address(to), 1000}},
+		{"named signature", "function transfer(address to, uint amount) returns (bool)", "transfer", []interface{}{to, "0x3e8"}},
+		{"lookup by signature", erc20TransferAbi, "transfer(address,uint256)", []interface{}{to, xc.NewBigIntFromUint64(1000)}},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			call, err := builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", v.abi, v.method, v.args...)
+			require.NoError(t, err)
+			data, err := call.Calldata()
+			require.NoError(t, err)
+			require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(data))
+		})
+	}
+}
+
+func TestContractCallArgs(t *testing.T) {
+	call, err := builder.NewContractCall(
+		"0x779877A7B0D9E8603169DdbD7836e478b4624789",
+		"function submit(uint8 kind, bytes32 id, address[] targets, (address to, uint64 amount)[] legs, bool flag) payable",
+		"",
+		"3",
+		"0x0000000000000000000000000000000000000000000000000000000000000001",
+		[]string{"0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F"},
+		[]interface{}{
+			map[string]interface{}{"to": "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F", "amount": 5},
+			[]interface{}{"0x724435CC1B2821362c2CD425F2744Bd7347bf299", "6"},
+		},
+		"true",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "submit(uint8,bytes32,address[],(address,uint64)[],bool)", call.Method.Sig)
+	require.True(t, call.Method.IsPayable())
+	_, err = call.Calldata()
+	require.NoError(t, err)
+
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "approve(address,uint8)", "", "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F", 256)
+	require.ErrorContains(t, err, "overflows uint8")
+
+	// values wider than 64 bits are checked against the size of the type
+	maxInt128 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	minInt128 := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "set(uint128,int128,int128)", "", new(big.Int).Lsh(big.NewInt(1), 128), "0", "0")
+	require.ErrorContains(t, err, "overflows uint128")
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "set(uint128,int128,int128)", "", "0", new(big.Int).Add(maxInt128, big.NewInt(1)), "0")
+	require.ErrorContains(t, err, "overflows int128")
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "set(uint128,int128,int128)", "", "0", "0", new(big.Int).Sub(minInt128, big.NewInt(1)))
+	require.ErrorContains(t, err, "overflows int128")
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "set(uint128,int128,int128)", "", "0xffffffffffffffffffffffffffffffff", maxInt128, minInt128)
+	require.NoError(t, err)
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "set(uint256)", "", new(big.Int).Lsh(big.NewInt(1), 256))
+	require.ErrorContains(t, err, "overflows uint256")
+
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "approve(address,uint256)", "", "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F")
+	require.ErrorContains(t, err, "expects 2 arguments")
+
+	_, err = builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", erc20TransferAbi, "approve")
+	require.ErrorContains(t, err, "not found")
+}
+
+func TestContractCallDecodeResult(t *testing.T) {
+	call, err := builder.NewContractCall(
+		"0x779877A7B0D9E8603169DdbD7836e478b4624789",
+		"function getReserves() view returns (uint112 reserve0, uint112 reserve1, uint32 timestamp)",
+		"",
+	)
+	require.NoError(t, err)
+	result, err := call.DecodeResult(hexutil.MustDecode(
+		"0x" +
+			"00000000000000000000000000000000000000000000000000000000000003e8" +
+			"00000000000000000000000000000000000000000000000000000000000007d0" +
+			"0000000000000000000000000000000000000000000000000000000000000064",
+	))
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	require.EqualValues(t, 1000, result[0].(*big.Int).Int64())
+	require.EqualValues(t, 2000, result[1].(*big.Int).Int64())
+	require.EqualValues(t, uint32(100), result[2])
+
+	_, err = call.DecodeResult([]byte{1, 2})
+	require.Error(t, err)
+}
+
+func TestContractCallTx(t *testing.T) {
+	b, _ := builder.NewTxBuilder(&xc.ChainConfig{ChainID: 1})
+	call, err := builder.NewContractCall("0x779877A7B0D9E8603169DdbD7836e478b4624789", "deposit()", "")
+	require.NoError(t, err)
+	call.Value = xc.NewBigIntFromUint64(55)
+
+	input := tx_input.NewTxInput()
+	input.GasLimit = 50_000
+	input.Nonce = 7
+	trans, err := b.NewContractCall(call, input)
+	require.NoError(t, err)
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, "0xd0e30db0", hexutil.Encode(ethTx.Data()))
+	require.EqualValues(t, 55, ethTx.Value().Int64())
+	require.EqualValues(t, 7, ethTx.Nonce())
+	require.EqualValues(t, 50_000, ethTx.Gas())
+	require.Equal(t, common.HexToAddress("0x779877A7B0D9E8603169DdbD7836e478b4624789"), *ethTx.To())
+}
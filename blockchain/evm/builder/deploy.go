@@ -0,0 +1,126 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The deterministic deployment proxy, which is deployed at the same address on most EVM chains.
+// It deploys the init code in the calldata using CREATE2, with the first 32 bytes as the salt.
+// See https://github.com/Arachnid/deterministic-deployment-proxy
+const DeterministicDeploymentProxy xc.Address = "0x4e59b44847b379578588920ca78fbf26c0b4956c"
+
+// ContractDeployment deploys a new contract, either directly with CREATE or through a CREATE2 factory
+type ContractDeployment struct {
+	// The creation bytecode followed by the ABI encoded constructor arguments
+	InitCode []byte
+	// Native value to send to the constructor
+	Value xc.BigInt
+	// Only set when deploying with CREATE2
+	Factory xc.Address
+	Salt    *common.Hash
+}
+
+// NewContractDeployment prepares to deploy `bytecode`, encoding the constructor arguments.
+// The ABI may either be an ABI JSON document with a constructor, or a signature like
+// "constructor(address owner, uint256 threshold)".  It may be left empty when there are no arguments.
+func NewContractDeployment(bytecode []byte, abiOrSignature string, args ...interface{}) (*ContractDeployment, error) {
+	if len(bytecode) == 0 {
+		return nil, fmt.Errorf("contract bytecode is empty")
+	}
+	inputs := abi.Arguments{}
+	if abiOrSignature != "" {
+		contractAbi, err := ParseContractAbi(abiOrSignature)
+		if err != nil {
+			return nil, err
+		}
+		inputs = contractAbi.Constructor.Inputs
+		if method, ok := contractAbi.Methods["constructor"]; ok {
+			// from a signature
+			inputs = method.Inputs
+		}
+	}
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("constructor expects %d arguments, got %d", len(inputs), len(args))
+	}
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		var err error
+		converted[i], err = ConvertAbiArg(inputs[i].Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constructor argument %d (%s): %v", i, inputs[i].Name, err)
+		}
+	}
+	packed, err := inputs.Pack(converted...)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode constructor arguments: %v", err)
+	}
+	return &ContractDeployment{
+		InitCode: append(append([]byte{}, bytecode...), packed...),
+		Value:    xc.NewBigIntFromUint64(0),
+	}, nil
+}
+
+// WithCreate2 deploys deterministically through a CREATE2 factory.  If no factory is given,
+// the deterministic deployment proxy is used.
+func (deployment *ContractDeployment) WithCreate2(factory xc.Address, salt common.Hash) *ContractDeployment {
+	if factory == "" {
+		factory = DeterministicDeploymentProxy
+	}
+	deployment.Factory = factory
+	deployment.Salt = &salt
+	return deployment
+}
+
+func (deployment *ContractDeployment) IsCreate2() bool {
+	return deployment.Salt != nil
+}
+
+// Calldata returns the data of the deployment transaction
+func (deployment *ContractDeployment) Calldata() []byte {
+	if deployment.IsCreate2() {
+		return append(deployment.Salt.Bytes(), deployment.InitCode...)
+	}
+	return deployment.InitCode
+}
+
+// PredictAddress returns the address the contract will be deployed to.  The sender and nonce
+// of the deployment transaction are only needed for CREATE.
+func (deployment *ContractDeployment) PredictAddress(from xc.Address, nonce uint64) (xc.Address, error) {
+	if deployment.IsCreate2() {
+		return PredictCreate2Address(deployment.Factory, *deployment.Salt, deployment.InitCode)
+	}
+	return PredictCreateAddress(from, nonce)
+}
+
+// PredictCreateAddress returns the address of a contract created by `from` with the given nonce
+func PredictCreateAddress(from xc.Address, nonce uint64) (xc.Address, error) {
+	fromAddr, err := address.FromHex(from)
+	if err != nil {
+		return "", fmt.Errorf("bad from address '%v': %v", from, err)
+	}
+	return xc.Address(crypto.CreateAddress(fromAddr, nonce).Hex()), nil
+}
+
+// PredictCreate2Address returns the address of a contract created by `factory` using CREATE2
+func PredictCreate2Address(factory xc.Address, salt common.Hash, initCode []byte) (xc.Address, error) {
+	factoryAddr, err := address.FromHex(factory)
+	if err != nil {
+		return "", fmt.Errorf("bad factory address '%v': %v", factory, err)
+	}
+	return xc.Address(crypto.CreateAddress2(factoryAddr, salt, crypto.Keccak256(initCode)).Hex()), nil
+}
+
+// NewContractDeployment creates a transaction deploying a contract
+func (txBuilder TxBuilder) NewContractDeployment(deployment *ContractDeployment, input xc.TxInput) (xc.Tx, error) {
+	if deployment.IsCreate2() {
+		// the factory creates the contract
+		return txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, deployment.Factory, deployment.Value, deployment.Calldata(), input)
+	}
+	return txBuilder.gethTxBuilder.BuildContractCreation(txBuilder.Chain, deployment.Value, deployment.Calldata(), input)
+}
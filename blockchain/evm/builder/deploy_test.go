@@ -0,0 +1,101 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+var forwarderBytecode = hexutil.MustDecode("0x6080604052348015600f57600080fd5b50")
+
+func TestContractDeploymentArgs(t *testing.T) {
+	owner := "0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8"
+	expected := "0x6080604052348015600f57600080fd5b50" +
+		"00000000000000000000000050b0c2b3bcad53eb45b57c4e5df8a9890d002cc8" +
+		"0000000000000000000000000000000000000000000000000000000000000002"
+
+	deployment, err := builder.NewContractDeployment(forwarderBytecode, "constructor(address owner, uint256 threshold)", owner, 2)
+	require.NoError(t, err)
+	require.Equal(t, expected, hexutil.Encode(deployment.InitCode))
+
+	abiJson := `[{"type":"constructor","stateMutability":"nonpayable","inputs":[{"name":"owner","type":"address"},{"name":"threshold","type":"uint256"}]}]`
+	deployment, err = builder.NewContractDeployment(forwarderBytecode, abiJson, owner, "2")
+	require.NoError(t, err)
+	require.Equal(t, expected, hexutil.Encode(deployment.InitCode))
+
+	deployment, err = builder.NewContractDeployment(forwarderBytecode, "")
+	require.NoError(t, err)
+	require.Equal(t, forwarderBytecode, deployment.InitCode)
+
+	_, err = builder.NewContractDeployment(forwarderBytecode, "constructor(address owner)")
+	require.ErrorContains(t, err, "expects 1 arguments")
+	_, err = builder.NewContractDeployment(nil, "")
+	require.ErrorContains(t, err, "empty")
+}
+
+func TestPredictContractAddress(t *testing.T) {
+	// https://ethereum.stackexchange.com/questions/760/how-is-the-address-of-an-ethereum-contract-computed
+	addr, err := builder.PredictCreateAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", 0)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d").Hex(), string(addr))
+	addr, err = builder.PredictCreateAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", 1)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0x343c43a37d37dff08ae8c4a11544c718abb4fcf8").Hex(), string(addr))
+
+	// examples from EIP-1014
+	addr, err = builder.PredictCreate2Address("0x0000000000000000000000000000000000000000", common.Hash{}, []byte{0x00})
+	require.NoError(t, err)
+	require.EqualValues(t, "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38", addr)
+	addr, err = builder.PredictCreate2Address(
+		"0x00000000000000000000000000000000deadbeef",
+		common.HexToHash("0x00000000000000000000000000000000000000000000000000000000cafebabe"),
+		hexutil.MustDecode("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"),
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, "0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C", addr)
+}
+
+func TestContractDeploymentTx(t *testing.T) {
+	b, _ := builder.NewTxBuilder(&xc.ChainConfig{ChainID: 1})
+	from := xc.Address("0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8")
+	input := tx_input.NewTxInput()
+	input.GasLimit = 500_000
+	input.Nonce = 3
+
+	// CREATE has no destination
+	deployment, err := builder.NewContractDeployment(forwarderBytecode, "")
+	require.NoError(t, err)
+	trans, err := b.NewContractDeployment(deployment, input)
+	require.NoError(t, err)
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Nil(t, ethTx.To())
+	require.Equal(t, forwarderBytecode, ethTx.Data())
+	predicted, err := deployment.PredictAddress(from, input.Nonce)
+	require.NoError(t, err)
+	expected, _ := builder.PredictCreateAddress(from, 3)
+	require.Equal(t, expected, predicted)
+
+	// only a deployment creates a contract, other payloads need a destination
+	_, err = builder.NewEvmTxBuilder().BuildTxWithPayload(&xc.ChainConfig{ChainID: 1}, "", xc.NewBigIntFromUint64(0), forwarderBytecode, input)
+	require.ErrorContains(t, err, "missing destination address")
+
+	// CREATE2 calls the factory with the salt and init code
+	salt := common.HexToHash("0x01")
+	deployment.WithCreate2("", salt)
+	trans, err = b.NewContractDeployment(deployment, input)
+	require.NoError(t, err)
+	ethTx = trans.(*tx.Tx).EthTx
+	require.Equal(t, common.HexToAddress(string(builder.DeterministicDeploymentProxy)), *ethTx.To())
+	require.Equal(t, append(salt.Bytes(), forwarderBytecode...), ethTx.Data())
+	// the sender and nonce do not matter
+	predicted, err = deployment.PredictAddress("", 0)
+	require.NoError(t, err)
+	expected, _ = builder.PredictCreate2Address(builder.DeterministicDeploymentProxy, salt, forwarderBytecode)
+	require.Equal(t, expected, predicted)
+}
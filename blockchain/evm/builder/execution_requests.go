@@ -0,0 +1,71 @@
+package builder
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc "github.com/CustodyOne/chainkit/types"
+)
+
+// System contracts added in Pectra, which let the execution address in a validator's withdrawal
+// credentials request exits, partial withdrawals and consolidations.  Calling either contract with
+// no data returns the current fee.
+const (
+	// EIP-7002
+	WithdrawalRequestContract = "0x00000961Ef480Eb55e80D19ad83579A64c007002"
+	// EIP-7251
+	ConsolidationRequestContract = "0x0000BBdDc7CE488642fb579F8B00f3a590007251"
+)
+
+const ValidatorPublicKeyLen = 48
+
+// WithdrawalRequestCalldata is the validator public key followed by the amount in gwei as a
+// big-endian uint64.  An amount of 0 requests a full exit.
+func WithdrawalRequestCalldata(publicKey []byte, amountGwei uint64) ([]byte, error) {
+	if len(publicKey) != ValidatorPublicKeyLen {
+		return nil, fmt.Errorf("wrong length for validator public key, expected %d, received %d", ValidatorPublicKeyLen, len(publicKey))
+	}
+	data := make([]byte, ValidatorPublicKeyLen+8)
+	copy(data, publicKey)
+	binary.BigEndian.PutUint64(data[ValidatorPublicKeyLen:], amountGwei)
+	return data, nil
+}
+
+// ConsolidationRequestCalldata is the source validator public key followed by the target.  Using
+// the same key for both converts the validator to compounding (0x02) withdrawal credentials.
+func ConsolidationRequestCalldata(source []byte, target []byte) ([]byte, error) {
+	for _, publicKey := range [][]byte{source, target} {
+		if len(publicKey) != ValidatorPublicKeyLen {
+			return nil, fmt.Errorf("wrong length for validator public key, expected %d, received %d", ValidatorPublicKeyLen, len(publicKey))
+		}
+	}
+	return append(append([]byte{}, source...), target...), nil
+}
+
+func (txBuilder TxBuilder) unstakeWithWithdrawalRequest(stakeArgs xcbuilder.StakeArgs, input *tx_input.WithdrawalRequestInput) (xc.Tx, error) {
+	data, err := WithdrawalRequestCalldata(input.PublicKey, input.AmountGwei)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input for %T: %v", input, err)
+	}
+	tx, err := txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, WithdrawalRequestContract, input.Fee, data, &input.TxInput)
+	if err != nil {
+		return nil, fmt.Errorf("could not build tx for %T: %v", input, err)
+	}
+	return withSender(stakeArgs.GetFrom())(tx, nil)
+}
+
+// NewConsolidationRequest moves the balance of the source validator to the target validator.  It must be
+// sent from the execution address in the source validator's withdrawal credentials.
+func (txBuilder TxBuilder) NewConsolidationRequest(from xc.Address, input *tx_input.ConsolidationRequestInput) (xc.Tx, error) {
+	data, err := ConsolidationRequestCalldata(input.SourcePublicKey, input.TargetPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, ConsolidationRequestContract, input.Fee, data, &input.TxInput)
+	if err != nil {
+		return nil, fmt.Errorf("could not build tx for %T: %v", input, err)
+	}
+	return withSender(from)(tx, nil)
+}
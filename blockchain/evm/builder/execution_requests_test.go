@@ -0,0 +1,75 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+var validatorKey = hexutil.MustDecode("0xa776cfc875b15a1444bbda22e47e759ade11b39912a3e210807204f410d43baa332acb38aab206bc8ac7ad476a42839a")
+var otherValidatorKey = hexutil.MustDecode("0xa776cfc875b15a1444bbda22e47e759ade11b39912a3e210807204f410d43baa332acb38aab206bc8ac7ad476a42839b")
+
+func TestWithdrawalRequestCalldata(t *testing.T) {
+	data, err := builder.WithdrawalRequestCalldata(validatorKey, 0)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(validatorKey)+"0000000000000000", hexutil.Encode(data))
+
+	data, err = builder.WithdrawalRequestCalldata(validatorKey, 1_000_000_000)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(validatorKey)+"000000003b9aca00", hexutil.Encode(data))
+
+	_, err = builder.WithdrawalRequestCalldata(validatorKey[:47], 0)
+	require.ErrorContains(t, err, "wrong length")
+
+	data, err = builder.ConsolidationRequestCalldata(validatorKey, otherValidatorKey)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(validatorKey)+hexutil.Encode(otherValidatorKey)[2:], hexutil.Encode(data))
+
+	_, err = builder.ConsolidationRequestCalldata(validatorKey, nil)
+	require.ErrorContains(t, err, "wrong length")
+}
+
+func TestWithdrawalRequestTx(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
+	human, _ := xc_types.NewAmountHumanReadableFromStr("32")
+	args, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, human.ToBlockchain(18), xcbuilder.WithValidator(hexutil.Encode(validatorKey)))
+	require.NoError(t, err)
+
+	input := tx_input.NewWithdrawalRequestInput()
+	input.PublicKey = validatorKey
+	input.Fee = xc_types.NewBigIntFromUint64(1)
+	trans, err := txBuilder.Unstake(args, input)
+	require.NoError(t, err)
+
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, builder.WithdrawalRequestContract, ethTx.To().String())
+	require.EqualValues(t, 1, ethTx.Value().Uint64(), "the fee is sent as value")
+	expected, _ := builder.WithdrawalRequestCalldata(validatorKey, 0)
+	require.Equal(t, expected, ethTx.Data())
+	require.Equal(t, owner, trans.(*tx.Tx).From())
+}
+
+func TestConsolidationRequestTx(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
+
+	input := tx_input.NewConsolidationRequestInput()
+	input.SourcePublicKey = validatorKey
+	input.TargetPublicKey = otherValidatorKey
+	input.Fee = xc_types.NewBigIntFromUint64(2)
+	trans, err := txBuilder.NewConsolidationRequest(owner, input)
+	require.NoError(t, err)
+
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, builder.ConsolidationRequestContract, ethTx.To().String())
+	require.EqualValues(t, 2, ethTx.Value().Uint64())
+	expected, _ := builder.ConsolidationRequestCalldata(validatorKey, otherValidatorKey)
+	require.Equal(t, expected, ethTx.Data())
+}
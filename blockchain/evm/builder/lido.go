@@ -0,0 +1,167 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/abi/lido"
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// LidoContracts are the stETH, wstETH and withdrawal queue contracts configured for the chain
+type LidoContracts struct {
+	StEth           common.Address
+	WstEth          common.Address
+	WithdrawalQueue common.Address
+}
+
+func NewLidoContracts(chain *xc.ChainConfig) (*LidoContracts, error) {
+	staking := chain.Staking
+	if staking.LiquidToken == "" || staking.WrappedLiquidToken == "" || staking.WithdrawalQueue == "" {
+		return nil, fmt.Errorf("lido contracts are not configured for chain %s", chain.Chain)
+	}
+	contracts := &LidoContracts{}
+	var err error
+	for _, contract := range []struct {
+		addr   *common.Address
+		config string
+	}{
+		{&contracts.StEth, staking.LiquidToken},
+		{&contracts.WstEth, staking.WrappedLiquidToken},
+		{&contracts.WithdrawalQueue, staking.WithdrawalQueue},
+	} {
+		*contract.addr, err = address.FromHex(xc.Address(contract.config))
+		if err != nil {
+			return nil, fmt.Errorf("invalid lido contract '%s': %v", contract.config, err)
+		}
+	}
+	return contracts, nil
+}
+
+func (txBuilder TxBuilder) stakeLido(stakeArgs xcbuilder.StakeArgs, input *tx_input.LidoStakeInput) (xc.Tx, error) {
+	contracts, err := NewLidoContracts(txBuilder.Chain)
+	if err != nil {
+		return nil, err
+	}
+	referral := common.Address{}
+	if input.Referral != "" {
+		referral, err = address.FromHex(xc.Address(input.Referral))
+		if err != nil {
+			return nil, fmt.Errorf("invalid referral address '%s': %v", input.Referral, err)
+		}
+	}
+	data, err := lido.SerializeSubmit(referral)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input for %T: %v", input, err)
+	}
+	return txBuilder.buildLidoTx(stakeArgs.GetFrom(), contracts.StEth, stakeArgs.GetAmount(), data, &input.TxInput)
+}
+
+func (txBuilder TxBuilder) unstakeLido(stakeArgs xcbuilder.StakeArgs, input *tx_input.LidoUnstakeInput) (xc.Tx, error) {
+	contracts, err := NewLidoContracts(txBuilder.Chain)
+	if err != nil {
+		return nil, err
+	}
+	owner, ok := stakeArgs.GetStakeOwner()
+	if !ok {
+		owner = stakeArgs.GetFrom()
+	}
+	ownerAddr, err := address.FromHex(owner)
+	if err != nil {
+		return nil, err
+	}
+	amounts, err := lido.SplitWithdrawalAmount(stakeArgs.GetAmount().Int())
+	if err != nil {
+		return nil, err
+	}
+	data, err := lido.SerializeRequestWithdrawals(amounts, ownerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input for %T: %v", input, err)
+	}
+	zero := xc.NewBigIntFromUint64(0)
+	return txBuilder.buildLidoTx(stakeArgs.GetFrom(), contracts.WithdrawalQueue, zero, data, &input.TxInput)
+}
+
+func (txBuilder TxBuilder) withdrawLido(stakeArgs xcbuilder.StakeArgs, input *tx_input.LidoWithdrawInput) (xc.Tx, error) {
+	contracts, err := NewLidoContracts(txBuilder.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if len(input.RequestIds) == 0 {
+		return nil, fmt.Errorf("no withdrawal requests to claim")
+	}
+	if len(input.RequestIds) != len(input.Hints) {
+		return nil, fmt.Errorf("expected a hint for each of the %d withdrawal requests, got %d", len(input.RequestIds), len(input.Hints))
+	}
+	ids := make([]*big.Int, len(input.RequestIds))
+	hints := make([]*big.Int, len(input.Hints))
+	for i := range ids {
+		ids[i] = input.RequestIds[i].Int()
+		hints[i] = input.Hints[i].Int()
+	}
+	data, err := lido.SerializeClaimWithdrawals(ids, hints)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input for %T: %v", input, err)
+	}
+	zero := xc.NewBigIntFromUint64(0)
+	return txBuilder.buildLidoTx(stakeArgs.GetFrom(), contracts.WithdrawalQueue, zero, data, &input.TxInput)
+}
+
+// NewLidoApproval approves a spender for stETH, which the withdrawal queue and wstETH
+// contracts require before requesting a withdrawal or wrapping.
+func (txBuilder TxBuilder) NewLidoApproval(from xc.Address, spender xc.Address, amount xc.BigInt, input xc.TxInput) (xc.Tx, error) {
+	contracts, err := NewLidoContracts(txBuilder.Chain)
+	if err != nil {
+		return nil, err
+	}
+	spenderAddr, err := address.FromHex(spender)
+	if err != nil {
+		return nil, err
+	}
+	data, err := lido.SerializeApprove(spenderAddr, amount.Int())
+	if err != nil {
+		return nil, err
+	}
+	zero := xc.NewBigIntFromUint64(0)
+	return txBuilder.buildLidoTx(from, contracts.StEth, zero, data, input)
+}
+
+// NewLidoWrap converts stETH to the non-rebasing wstETH
+func (txBuilder TxBuilder) NewLidoWrap(from xc.Address, amount xc.BigInt, input xc.TxInput) (xc.Tx, error) {
+	contracts, err := NewLidoContracts(txBuilder.Chain)
+	if err != nil {
+		return nil, err
+	}
+	data, err := lido.SerializeWrap(amount.Int())
+	if err != nil {
+		return nil, err
+	}
+	zero := xc.NewBigIntFromUint64(0)
+	return txBuilder.buildLidoTx(from, contracts.WstEth, zero, data, input)
+}
+
+// NewLidoUnwrap converts wstETH back to stETH
+func (txBuilder TxBuilder) NewLidoUnwrap(from xc.Address, amount xc.BigInt, input xc.TxInput) (xc.Tx, error) {
+	contracts, err := NewLidoContracts(txBuilder.Chain)
+	if err != nil {
+		return nil, err
+	}
+	data, err := lido.SerializeUnwrap(amount.Int())
+	if err != nil {
+		return nil, err
+	}
+	zero := xc.NewBigIntFromUint64(0)
+	return txBuilder.buildLidoTx(from, contracts.WstEth, zero, data, input)
+}
+
+func (txBuilder TxBuilder) buildLidoTx(from xc.Address, contract common.Address, value xc.BigInt, data []byte, input xc.TxInput) (xc.Tx, error) {
+	tx, err := txBuilder.gethTxBuilder.BuildTxWithPayload(txBuilder.Chain, xc.Address(contract.String()), value, data, input)
+	if err != nil {
+		return nil, fmt.Errorf("could not build tx for lido: %v", err)
+	}
+	return withSender(from)(tx, nil)
+}
@@ -0,0 +1,137 @@
+package builder_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/abi/lido"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var lidoChain = &xc_types.ChainConfig{
+	Chain: xc_types.ETH,
+	Staking: xc_types.StakingConfig{
+		LiquidToken:        "0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84",
+		WrappedLiquidToken: "0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0",
+		WithdrawalQueue:    "0x889edC2eDab5f40e902b864aD4d7AdE8E412F9B1",
+	},
+}
+
+func TestLidoStakeArgs(t *testing.T) {
+	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
+	human, _ := xc_types.NewAmountHumanReadableFromStr("1.5")
+	amount := human.ToBlockchain(18)
+
+	_, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, amount)
+	require.ErrorContains(t, err, "32 ether")
+	_, err = xcbuilder.NewStakeArgs(xc_types.ETH, owner, amount, xcbuilder.WithStakingProvider(xc_types.Kiln))
+	require.ErrorContains(t, err, "32 ether")
+
+	args, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, amount, xcbuilder.WithStakingProvider(xc_types.Lido))
+	require.NoError(t, err)
+	provider, ok := args.GetStakingProvider()
+	require.True(t, ok)
+	require.Equal(t, xc_types.Lido, provider)
+}
+
+func TestLidoStakingTx(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(lidoChain)
+	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
+	human, _ := xc_types.NewAmountHumanReadableFromStr("1.5")
+	args, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, human.ToBlockchain(18), xcbuilder.WithStakingProvider(xc_types.Lido))
+	require.NoError(t, err)
+
+	trans, err := txBuilder.Stake(args, tx_input.NewLidoStakeInput())
+	require.NoError(t, err)
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, lidoChain.Staking.LiquidToken, ethTx.To().String())
+	require.Equal(t, "1500000000000000000", ethTx.Value().String())
+	expected, _ := lido.SerializeSubmit(common.Address{})
+	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(ethTx.Data()))
+	require.Equal(t, owner, trans.(*tx.Tx).From())
+
+	// no contracts configured
+	txBuilder, _ = builder.NewTxBuilder(&xc_types.ChainConfig{Chain: xc_types.ETH})
+	_, err = txBuilder.Stake(args, tx_input.NewLidoStakeInput())
+	require.ErrorContains(t, err, "lido contracts are not configured")
+}
+
+func TestLidoUnstakingTx(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(lidoChain)
+	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
+	human, _ := xc_types.NewAmountHumanReadableFromStr("1500")
+	args, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, human.ToBlockchain(18), xcbuilder.WithStakingProvider(xc_types.Lido))
+	require.NoError(t, err)
+
+	trans, err := txBuilder.Unstake(args, tx_input.NewLidoUnstakeInput())
+	require.NoError(t, err)
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, lidoChain.Staking.WithdrawalQueue, ethTx.To().String())
+	require.EqualValues(t, 0, ethTx.Value().Uint64(), "unstake should not send any eth")
+
+	// split into requests of at most 1000 stETH
+	ether := big.NewInt(1e18)
+	expected, _ := lido.SerializeRequestWithdrawals([]*big.Int{
+		new(big.Int).Mul(big.NewInt(1000), ether),
+		new(big.Int).Mul(big.NewInt(500), ether),
+	}, common.HexToAddress(string(owner)))
+	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(ethTx.Data()))
+}
+
+func TestLidoWithdrawTx(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(lidoChain)
+	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
+	args, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, xc_types.NewBigIntFromUint64(0), xcbuilder.WithStakingProvider(xc_types.Lido))
+	require.NoError(t, err)
+
+	input := tx_input.NewLidoWithdrawInput()
+	_, err = txBuilder.Withdraw(args, input)
+	require.ErrorContains(t, err, "no withdrawal requests")
+
+	input.RequestIds = []xc_types.BigInt{xc_types.NewBigIntFromUint64(10), xc_types.NewBigIntFromUint64(12)}
+	input.Hints = []xc_types.BigInt{xc_types.NewBigIntFromUint64(3)}
+	_, err = txBuilder.Withdraw(args, input)
+	require.ErrorContains(t, err, "expected a hint for each")
+
+	input.Hints = append(input.Hints, xc_types.NewBigIntFromUint64(4))
+	trans, err := txBuilder.Withdraw(args, input)
+	require.NoError(t, err)
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, lidoChain.Staking.WithdrawalQueue, ethTx.To().String())
+	expected, _ := lido.SerializeClaimWithdrawals([]*big.Int{big.NewInt(10), big.NewInt(12)}, []*big.Int{big.NewInt(3), big.NewInt(4)})
+	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(ethTx.Data()))
+}
+
+func TestLidoWrapTx(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(lidoChain)
+	owner := xc_types.Address("0x273b437645Ba723299d07B1BdFFcf508bE64771f")
+	amount := xc_types.NewBigIntFromUint64(1_000_000)
+
+	trans, err := txBuilder.NewLidoApproval(owner, xc_types.Address(lidoChain.Staking.WrappedLiquidToken), amount, tx_input.NewTxInput())
+	require.NoError(t, err)
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, lidoChain.Staking.LiquidToken, ethTx.To().String())
+	expected, _ := lido.SerializeApprove(common.HexToAddress(lidoChain.Staking.WrappedLiquidToken), amount.Int())
+	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(ethTx.Data()))
+
+	trans, err = txBuilder.NewLidoWrap(owner, amount, tx_input.NewTxInput())
+	require.NoError(t, err)
+	ethTx = trans.(*tx.Tx).EthTx
+	require.Equal(t, lidoChain.Staking.WrappedLiquidToken, ethTx.To().String())
+	expected, _ = lido.SerializeWrap(amount.Int())
+	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(ethTx.Data()))
+
+	trans, err = txBuilder.NewLidoUnwrap(owner, amount, tx_input.NewTxInput())
+	require.NoError(t, err)
+	ethTx = trans.(*tx.Tx).EthTx
+	require.Equal(t, lidoChain.Staking.WrappedLiquidToken, ethTx.To().String())
+	expected, _ = lido.SerializeUnwrap(amount.Int())
+	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(ethTx.Data()))
+}
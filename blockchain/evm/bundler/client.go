@@ -0,0 +1,175 @@
+package bundler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/userop"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client for an ERC-4337 bundler JSON-RPC endpoint
+type Client struct {
+	rpc        *rpc.Client
+	EntryPoint xc.Address
+	Version    userop.EntryPointVersion
+}
+
+type GasEstimate struct {
+	PreVerificationGas            *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit          *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit                  *hexutil.Big `json:"callGasLimit"`
+	PaymasterVerificationGasLimit *hexutil.Big `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big `json:"paymasterPostOpGasLimit,omitempty"`
+}
+
+type UserOperationReceipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	EntryPoint    common.Address `json:"entryPoint"`
+	Sender        common.Address `json:"sender"`
+	Nonce         *hexutil.Big   `json:"nonce"`
+	Paymaster     common.Address `json:"paymaster"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+	Success       bool           `json:"success"`
+	Reason        string         `json:"reason"`
+	Receipt       struct {
+		TransactionHash common.Hash  `json:"transactionHash"`
+		BlockHash       common.Hash  `json:"blockHash"`
+		BlockNumber     *hexutil.Big `json:"blockNumber"`
+		GasUsed         *hexutil.Big `json:"gasUsed"`
+	} `json:"receipt"`
+}
+
+// NewClient returns a bundler client submitting to the canonical EntryPoint of the given version
+func NewClient(url string, version userop.EntryPointVersion) (*Client, error) {
+	entryPoint := version.Address()
+	if entryPoint == "" {
+		return nil, fmt.Errorf("unsupported entrypoint version '%s'", version)
+	}
+	return NewClientWithEntryPoint(url, version, entryPoint)
+}
+
+func NewClientWithEntryPoint(url string, version userop.EntryPointVersion, entryPoint xc.Address) (*Client, error) {
+	c, err := rpc.DialHTTP(url)
+	if err != nil {
+		return nil, fmt.Errorf("dialing url: %v", url)
+	}
+	return &Client{
+		rpc:        c,
+		EntryPoint: entryPoint,
+		Version:    version,
+	}, nil
+}
+
+func (client *Client) ChainId(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	if err := client.rpc.CallContext(ctx, &result, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	return result.ToInt(), nil
+}
+
+func (client *Client) SupportedEntryPoints(ctx context.Context) ([]xc.Address, error) {
+	var result []common.Address
+	if err := client.rpc.CallContext(ctx, &result, "eth_supportedEntryPoints"); err != nil {
+		return nil, err
+	}
+	entryPoints := []xc.Address{}
+	for _, entryPoint := range result {
+		entryPoints = append(entryPoints, xc.Address(entryPoint.Hex()))
+	}
+	return entryPoints, nil
+}
+
+// EstimateUserOperationGas estimates the gas limits of the operation.  The operation should
+// have a dummy signature of the correct format set (see userop.DummySignature).
+func (client *Client) EstimateUserOperationGas(ctx context.Context, op *userop.UserOperation) (*GasEstimate, error) {
+	var result GasEstimate
+	if err := client.rpc.CallContext(ctx, &result, "eth_estimateUserOperationGas", op, client.EntryPoint); err != nil {
+		return nil, fmt.Errorf("could not estimate user operation: %v", err)
+	}
+	return &result, nil
+}
+
+// ApplyGasEstimate estimates the gas limits and sets them on the operation
+func (client *Client) ApplyGasEstimate(ctx context.Context, op *userop.UserOperation) error {
+	estimate, err := client.EstimateUserOperationGas(ctx, op)
+	if err != nil {
+		return err
+	}
+	if estimate.PreVerificationGas != nil {
+		op.PreVerificationGas = xc.BigInt(*estimate.PreVerificationGas)
+	}
+	if estimate.VerificationGasLimit != nil {
+		op.VerificationGasLimit = xc.BigInt(*estimate.VerificationGasLimit)
+	}
+	if estimate.CallGasLimit != nil {
+		op.CallGasLimit = xc.BigInt(*estimate.CallGasLimit)
+	}
+	if estimate.PaymasterVerificationGasLimit != nil {
+		op.PaymasterVerificationGasLimit = xc.BigInt(*estimate.PaymasterVerificationGasLimit)
+	}
+	if estimate.PaymasterPostOpGasLimit != nil {
+		op.PaymasterPostOpGasLimit = xc.BigInt(*estimate.PaymasterPostOpGasLimit)
+	}
+	return nil
+}
+
+// UserOperationHash returns the hash of the operation for the configured EntryPoint
+func (client *Client) UserOperationHash(ctx context.Context, op *userop.UserOperation) ([]byte, error) {
+	chainId, err := client.ChainId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return op.Hash(client.EntryPoint, chainId)
+}
+
+// SendUserOperation submits a signed operation and returns the userOpHash
+func (client *Client) SendUserOperation(ctx context.Context, op *userop.UserOperation) (xc.TxHash, error) {
+	var result common.Hash
+	if err := client.rpc.CallContext(ctx, &result, "eth_sendUserOperation", op, client.EntryPoint); err != nil {
+		return "", fmt.Errorf("could not send user operation: %v", err)
+	}
+	return xc.TxHash(result.Hex()), nil
+}
+
+// GetUserOperationReceipt returns the receipt of the operation, or nil if it is not yet included
+func (client *Client) GetUserOperationReceipt(ctx context.Context, hash xc.TxHash) (*UserOperationReceipt, error) {
+	var raw json.RawMessage
+	if err := client.rpc.CallContext(ctx, &raw, "eth_getUserOperationReceipt", common.HexToHash(string(hash))); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var receipt UserOperationReceipt
+	if err := json.Unmarshal(raw, &receipt); err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// WaitForUserOperationReceipt polls for the receipt until it is available or the context ends
+func (client *Client) WaitForUserOperationReceipt(ctx context.Context, hash xc.TxHash, interval time.Duration) (*UserOperationReceipt, error) {
+	for {
+		receipt, err := client.GetUserOperationReceipt(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package bundler_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/bundler"
+	"github.com/CustodyOne/chainkit/blockchain/evm/userop"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserOperationFlow(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, []string{
+		// eth_estimateUserOperationGas
+		`{"preVerificationGas":"0xafc8","verificationGasLimit":"0x11170","callGasLimit":"0xc350"}`,
+		// eth_chainId
+		`"0x1"`,
+		// eth_sendUserOperation
+		`"0x7e2b51d3e1f1d2d1b2b0c8ff6d4a1d5e2ad8a5b0f5ea3f9d63b8d9dfb0b6f6a1"`,
+		// eth_getUserOperationReceipt (pending)
+		`null`,
+		// eth_getUserOperationReceipt
+		`{"userOpHash":"0x7e2b51d3e1f1d2d1b2b0c8ff6d4a1d5e2ad8a5b0f5ea3f9d63b8d9dfb0b6f6a1","entryPoint":"0x0000000071727de22e5e9d8baf0edac6f37da032","sender":"0x50b0c2b3bcad53eb45b57c4e5df8a9890d002cc8","nonce":"0x4","paymaster":"0x0000000000000000000000000000000000000000","actualGasCost":"0x2386f26fc10000","actualGasUsed":"0x1d4c0","success":true,"reason":"","logs":[],"receipt":{"transactionHash":"0x1fa0d4c2b2d1f0e9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5","blockHash":"0x2fa0d4c2b2d1f0e9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5","blockNumber":"0x10","gasUsed":"0x1d4c0"}}`,
+	})
+	defer close()
+
+	client, err := bundler.NewClient(server.URL, userop.EntryPointV07)
+	require.NoError(t, err)
+	require.Equal(t, userop.EntryPointV07Address, client.EntryPoint)
+
+	op, err := userop.NewNativeTransfer(userop.EntryPointV07, "0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8", xc.NewBigIntFromUint64(4), "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F", xc.NewBigIntFromUint64(1000))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	err = client.ApplyGasEstimate(ctx, op)
+	require.NoError(t, err)
+	require.EqualValues(t, 45000, op.PreVerificationGas.Uint64())
+	require.EqualValues(t, 70000, op.VerificationGasLimit.Uint64())
+	require.EqualValues(t, 50000, op.CallGasLimit.Uint64())
+
+	hash, err := client.UserOperationHash(ctx, op)
+	require.NoError(t, err)
+	expected, _ := op.Hash(userop.EntryPointV07Address, big.NewInt(1))
+	require.Equal(t, expected, hash)
+
+	opHash, err := client.SendUserOperation(ctx, op)
+	require.NoError(t, err)
+	require.Equal(t, xc.TxHash("0x7e2b51d3e1f1d2d1b2b0c8ff6d4a1d5e2ad8a5b0f5ea3f9d63b8d9dfb0b6f6a1"), opHash)
+
+	receipt, err := client.GetUserOperationReceipt(ctx, opHash)
+	require.NoError(t, err)
+	require.Nil(t, receipt)
+
+	receipt, err = client.WaitForUserOperationReceipt(ctx, opHash, 0)
+	require.NoError(t, err)
+	require.True(t, receipt.Success)
+	require.EqualValues(t, 16, receipt.Receipt.BlockNumber.ToInt().Int64())
+}
@@ -3,11 +3,13 @@ package client
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/CustodyOne/chainkit/blockchain/evm/abi/erc20"
 	"github.com/CustodyOne/chainkit/blockchain/evm/abi/exit_request"
@@ -47,9 +49,15 @@ type Client struct {
 	EthClient   *ethclient.Client
 	ChainId     *big.Int
 	Interceptor *utils.HttpInterceptor
+
+	// detected on first use, see TraceBackends
+	traceBackend TraceBackend
+	traceLock    sync.Mutex
 }
 
 var _ xclient.IClient = &Client{}
+var _ xclient.BalanceAtClient = &Client{}
+var _ xclient.SimulationClient = &Client{}
 
 // Ethereum does not support full delegated staking, so we can only report balance information.
 // A 3rd party 'staking provider' is required to do the rest.
@@ -68,6 +76,10 @@ func ReplaceIncompatiableEvmResponses(body []byte) []byte {
 		newStr = strings.Replace(newStr, "\"signatures\":[{", "", 1)
 		newStr = strings.Replace(newStr, "}]", ",\"cumulativeGasUsed\":\"0x0\"", 1)
 	}
+	if strings.Contains(bodyStr, "\"type\":\"0x7b\"") {
+		// the fee currency is read separately
+		bodyStr = strings.Replace(bodyStr, "\"type\":\"0x7b\"", "\"type\":\"0x2\"", -1)
+	}
 	if strings.Contains(bodyStr, "parentHash") {
 		log.Print("Adding KLAY/CELO sha3Uncles")
 		newStr = strings.Replace(bodyStr, "parentHash", "gasLimit\":\"0x0\",\"difficulty\":\"0x0\",\"miner\":\"0x0000000000000000000000000000000000000000\",\"sha3Uncles\":\"0x0000000000000000000000000000000000000000000000000000000000000000\",\"parentHash", 1)
@@ -113,6 +125,18 @@ func NewClient(cfg *xc.ChainConfig) (*Client, error) {
 func (client *Client) BroadcastTx(ctx context.Context, trans xc.Tx) error {
 	switch tx := trans.(type) {
 	case *tx.Tx:
+		if tx.FeeCurrency != nil {
+			// not supported by go-ethereum
+			bz, err := tx.Serialize()
+			if err != nil {
+				return err
+			}
+			err = client.EthClient.Client().CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(bz))
+			if err != nil {
+				return fmt.Errorf("sending transaction '%v': %v", tx.Hash(), err)
+			}
+			return nil
+		}
 		err := client.EthClient.SendTransaction(ctx, tx.EthTx)
 		if err != nil {
 			return fmt.Errorf("sending transaction '%v': %v", tx.Hash(), err)
@@ -212,6 +236,14 @@ func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHashStr xc.TxHash
 		EthTx:  trans,
 		Signer: types.LatestSignerForChainID(chainID),
 	}
+	if client.Chain.Chain == xc.CELO {
+		feeCurrencyTx, err := client.fetchFeeCurrency(ctx, txHash)
+		if err != nil {
+			return result, fmt.Errorf("fetching fee currency of tx %v: %v", txHashStr, err)
+		}
+		confirmedTx.FeeCurrency = feeCurrencyTx.FeeCurrency
+		confirmedTx.Sender = feeCurrencyTx.From
+	}
 
 	tokenMovements := confirmedTx.ParseTokenLogs(receipt, xc.NativeAsset(nativeAsset.Chain))
 	ethMovements, err := client.TraceEthMovements(ctx, txHash)
@@ -247,6 +279,30 @@ func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHashStr xc.TxHash
 	result.ContractAddress = confirmedTx.ContractAddress()
 	result.Amount = confirmedTx.Amount()
 	result.Fee = confirmedTx.Fee(baseFee, gasUsed)
+	if confirmedTx.FeeCurrency != nil && receipt.EffectiveGasPrice != nil {
+		// the gas price is denominated in the fee currency
+		token, scale, err := client.resolveFeeCurrency(ctx, *confirmedTx.FeeCurrency)
+		if err != nil {
+			return result, fmt.Errorf("resolving fee currency of tx %v: %v", txHashStr, err)
+		}
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), receipt.EffectiveGasPrice)
+		result.Fee = xc.BigInt(*fee.Div(fee, scale))
+		result.FeeContract = xc.ContractAddress(token.String())
+	}
+	l1Fee, err := client.FetchL1Fee(ctx, txHash)
+	if err != nil {
+		zap.S().Warn("could not fetch l1 fee",
+			zap.String("tx_hash", string(txHashStr)),
+			zap.String("chain", string(nativeAsset.Chain)),
+			zap.Error(err),
+		)
+	} else if l1Fee != nil {
+		result.L1Fee = l1Fee.Fee
+		if l1Fee.Gas == 0 {
+			// charged in addition to the L2 gas
+			result.Fee = result.Fee.Add(&l1Fee.Fee)
+		}
+	}
 	result.Sources = append(ethMovements.Sources, tokenMovements.Sources...)
 	result.Destinations = append(ethMovements.Destinations, tokenMovements.Destinations...)
 
@@ -301,22 +357,42 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (*xc
 	chain := client.Chain.Chain
 
 	// remap to new tx
-	return xclient.TxInfoFromLegacy(chain, legacyTx, xclient.Account), nil
+	txInfo := xclient.TxInfoFromLegacy(chain, legacyTx, xclient.Account)
+
+	if txInfo.Block.Height > 0 && !client.Chain.NoFinalityTag {
+		finalized, err := client.EthClient.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+		if err != nil {
+			// not all chains support the finalized tag
+			zap.S().Debug("could not fetch finalized block",
+				zap.String("chain", string(chain)),
+				zap.Error(err),
+			)
+		} else {
+			txInfo.Finalized = finalized.Number.Uint64() >= txInfo.Block.Height
+		}
+	}
+	return txInfo, nil
 }
 
-// Fetch the balance of the native asset that this client is configured for
-func (client *Client) FetchNativeBalance(ctx context.Context, addr xc.Address) (*xc.BigInt, error) {
-	zero := xc.NewBigIntFromUint64(0)
-	targetAddr, err := address.FromHex(addr)
-	if err != nil {
-		return &zero, fmt.Errorf("bad to address '%v': %v", addr, err)
+// Block number to read at.  Geth maps the negative numbers to the `safe` and `finalized` tags.
+func blockNumberForArgs(args xclient.ReadArgs) *big.Int {
+	if height, ok := args.GetHeight(); ok {
+		return new(big.Int).SetUint64(height)
 	}
-	balance, err := client.EthClient.BalanceAt(ctx, targetAddr, nil)
-	if err != nil {
-		return &zero, fmt.Errorf("failed to get balance for '%v': %v", addr, err)
+	commitment, _ := args.GetCommitment()
+	switch commitment {
+	case xclient.CommitmentConfirmed:
+		return big.NewInt(int64(rpc.SafeBlockNumber))
+	case xclient.CommitmentFinalized:
+		return big.NewInt(int64(rpc.FinalizedBlockNumber))
 	}
+	// latest
+	return nil
+}
 
-	return (*xc.BigInt)(balance), nil
+// Fetch the balance of the native asset that this client is configured for
+func (client *Client) FetchNativeBalance(ctx context.Context, addr xc.Address) (*xc.BigInt, error) {
+	return client.FetchBalanceAt(ctx, addr, "", xclient.ReadArgs{})
 }
 
 // Fetch the balance of the asset that this client is configured for
@@ -327,8 +403,28 @@ func (client *Client) FetchBalance(ctx context.Context, addr xc.Address) (*xc.Bi
 
 // Fetch the balance of the asset that this client is configured for
 func (client *Client) FetchBalanceForAsset(ctx context.Context, addr xc.Address, contractAddress xc.ContractAddress) (*xc.BigInt, error) {
-	// token
+	return client.FetchBalanceAt(ctx, addr, contractAddress, xclient.ReadArgs{})
+}
+
+// Fetch the balance of the native asset or a token at a given commitment (`safe`/`finalized`) or historical block.
+// Historical blocks may only be available on archive nodes.
+func (client *Client) FetchBalanceAt(ctx context.Context, addr xc.Address, contractAddress xc.ContractAddress, args xclient.ReadArgs) (*xc.BigInt, error) {
 	zero := xc.NewBigIntFromUint64(0)
+	blockNumber := blockNumberForArgs(args)
+	if contractAddress == "" || string(contractAddress) == string(client.Chain.Chain) {
+		// native
+		targetAddr, err := address.FromHex(addr)
+		if err != nil {
+			return &zero, fmt.Errorf("bad to address '%v': %v", addr, err)
+		}
+		balance, err := client.EthClient.BalanceAt(ctx, targetAddr, blockNumber)
+		if err != nil {
+			return &zero, fmt.Errorf("failed to get balance for '%v': %v", addr, err)
+		}
+		return (*xc.BigInt)(balance), nil
+	}
+
+	// token
 	tokenAddress, _ := address.FromHex(xc.Address(contractAddress))
 	instance, err := erc20.NewErc20(tokenAddress, client.EthClient)
 	if err != nil {
@@ -336,36 +432,87 @@ func (client *Client) FetchBalanceForAsset(ctx context.Context, addr xc.Address,
 	}
 
 	dstAddress, _ := address.FromHex(addr)
-	balance, err := instance.BalanceOf(&bind.CallOpts{}, dstAddress)
+	balance, err := instance.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: blockNumber}, dstAddress)
 	if err != nil {
 		return &zero, err
 	}
 	return (*xc.BigInt)(balance), nil
 }
 
-func (client *Client) EstimateGasFee(ctx context.Context, _tx xc.Tx) (*xc.BigInt, error) {
-	tx := _tx.(*tx.Tx)
+// Kaia charges extra gas for fee-delegated transactions, which is not included in estimates
+const KaiaFeeDelegationGas = 10_000
+
+// GasFeeEstimate breaks down an estimated fee on chains that charge for posting data to L1
+type GasFeeEstimate struct {
+	Total xc.BigInt
+	// Fee for L2 execution (or L1 execution, if not a rollup)
+	Execution xc.BigInt
+	// Fee for posting the tx data to L1, on rollups
+	L1Fee xc.BigInt
+}
 
-	from, err := types.Sender(tx.Signer, tx.EthTx)
+func (client *Client) EstimateGasFee(ctx context.Context, _tx xc.Tx) (*xc.BigInt, error) {
+	estimate, err := client.EstimateGasFeeComponents(ctx, _tx)
 	if err != nil {
 		return nil, err
 	}
+	return &estimate.Total, nil
+}
+
+func (client *Client) EstimateGasFeeComponents(ctx context.Context, _tx xc.Tx) (*GasFeeEstimate, error) {
+	tx := _tx.(*tx.Tx)
+
+	// recovered from the signature, or the expected sender if not signed yet
+	from := tx.From()
+	if from == "" {
+		return nil, errors.New("could not determine the sender of the transaction")
+	}
 
-	gasLimit, err := client.SimulateGasWithLimit(ctx, xc.Address(from.Hex()), tx, &xc.TokenAssetConfig{
+	gasLimit, err := client.SimulateGasWithLimit(ctx, from, tx, &xc.TokenAssetConfig{
 		Contract: tx.ContractAddress(),
 	})
 	if err != nil {
 		return nil, err
 	}
+	if tx.FeeCurrency != nil {
+		gasLimit += FeeCurrencyIntrinsicGas
+	}
+	if tx.FeePayer != nil {
+		gasLimit += KaiaFeeDelegationGas
+	}
 
-	gasPrice, err := client.EthClient.SuggestGasPrice(ctx)
+	gasPrice, err := client.SuggestGasPrice(ctx, tx.FeeCurrency)
 	if err != nil {
 		return nil, err
 	}
 
 	gasCost := new(big.Int).Mul(big.NewInt(int64(gasLimit)), gasPrice)
 
-	retCost := xc.NewBigIntFromStr(gasCost.String())
+	estimate := &GasFeeEstimate{
+		Total:     xc.NewBigIntFromStr(gasCost.String()),
+		Execution: xc.NewBigIntFromStr(gasCost.String()),
+		L1Fee:     xc.NewBigIntFromUint64(0),
+	}
+	l1Fee, err := client.EstimateL1Fee(ctx, tx)
+	if err != nil {
+		zap.S().Warn("could not estimate l1 fee",
+			zap.String("chain", string(client.Chain.Chain)),
+			zap.Error(err),
+		)
+	} else if l1Fee != nil {
+		if l1Fee.Gas > 0 {
+			// already part of the gas limit
+			l1Cost := new(big.Int).Mul(new(big.Int).SetUint64(l1Fee.Gas), gasPrice)
+			if l1Cost.Cmp(gasCost) > 0 {
+				l1Cost = gasCost
+			}
+			estimate.L1Fee = xc.BigInt(*l1Cost)
+			estimate.Execution = xc.BigInt(*new(big.Int).Sub(gasCost, l1Cost))
+		} else {
+			estimate.L1Fee = l1Fee.Fee
+			estimate.Total = estimate.Total.Add(&l1Fee.Fee)
+		}
+	}
 
-	return &retCost, nil
+	return estimate, nil
 }
@@ -7,14 +7,21 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	xcclient "github.com/CustodyOne/chainkit/client"
 	xc "github.com/CustodyOne/chainkit/types"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/sirupsen/logrus"
 )
 
+// Epoch used by the beacon chain for exits that have not been scheduled
+const FarFutureEpoch = "18446744073709551615"
+
+const SlotsPerEpoch = 32
+
 type GetValidatorResponse struct {
 	ExecutionOptimistic bool      `json:"execution_optimistic"`
 	Finalized           bool      `json:"finalized"`
@@ -58,6 +65,54 @@ type ValidatorDetails struct {
 	WithdrawableEpoch          string `json:"withdrawable_epoch"`
 }
 
+// ExecutionAddress returns the address in 0x01 or 0x02 withdrawal credentials, which may request exits
+func (details ValidatorDetails) ExecutionAddress() (common.Address, bool) {
+	credentials := strings.TrimPrefix(details.WithdrawalCredentials, "0x")
+	if len(credentials) != 64 || (!strings.HasPrefix(credentials, "01") && !strings.HasPrefix(credentials, "02")) {
+		return common.Address{}, false
+	}
+	return common.HexToAddress(credentials[24:]), true
+}
+
+// Compounding (0x02) validators may withdraw part of their balance above 32 ether
+func (details ValidatorDetails) IsCompounding() bool {
+	return strings.HasPrefix(strings.TrimPrefix(details.WithdrawalCredentials, "0x"), "02")
+}
+
+type GetHeaderResponse struct {
+	Data struct {
+		Header struct {
+			Message struct {
+				Slot string `json:"slot"`
+			} `json:"message"`
+		} `json:"header"`
+	} `json:"data"`
+}
+
+// ExitProgress tracks a validator through the exit queue after an exit is requested
+type ExitProgress struct {
+	Status       ValidatorStatus `json:"status"`
+	CurrentEpoch uint64          `json:"current_epoch"`
+	// Not set until the beacon chain processes the exit request
+	ExitEpoch         *uint64 `json:"exit_epoch,omitempty"`
+	WithdrawableEpoch *uint64 `json:"withdrawable_epoch,omitempty"`
+}
+
+// The exit has been processed and the validator is scheduled to stop validating
+func (progress *ExitProgress) ExitScheduled() bool {
+	return progress.ExitEpoch != nil
+}
+
+// The validator has stopped validating
+func (progress *ExitProgress) Exited() bool {
+	return progress.ExitEpoch != nil && progress.CurrentEpoch >= *progress.ExitEpoch
+}
+
+// The balance will be swept to the withdrawal credentials
+func (progress *ExitProgress) Withdrawable() bool {
+	return progress.WithdrawableEpoch != nil && progress.CurrentEpoch >= *progress.WithdrawableEpoch
+}
+
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -90,6 +145,53 @@ func (client *Client) FetchValidatorBalance(ctx context.Context, validator strin
 	return xcclient.NewStakedBalance(amount, status, validator, ""), nil
 }
 
+// Fetch the current epoch using the head of the beacon chain
+func (client *Client) FetchCurrentEpoch(ctx context.Context) (uint64, error) {
+	var header GetHeaderResponse
+	err := client.Get("eth/v1/beacon/headers/head", &header)
+	if err != nil {
+		return 0, err
+	}
+	slot, err := strconv.ParseUint(header.Data.Header.Message.Slot, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid slot '%s': %v", header.Data.Header.Message.Slot, err)
+	}
+	return slot / SlotsPerEpoch, nil
+}
+
+// FetchExitProgress reports how far a validator is through exiting, e.g. after a withdrawal request
+func (client *Client) FetchExitProgress(ctx context.Context, validator string) (*ExitProgress, error) {
+	val, err := client.FetchValidator(ctx, validator)
+	if err != nil {
+		return nil, err
+	}
+	currentEpoch, err := client.FetchCurrentEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress := &ExitProgress{
+		Status:       val.Data.Status,
+		CurrentEpoch: currentEpoch,
+	}
+	for _, epoch := range []struct {
+		value string
+		dest  **uint64
+	}{
+		{val.Data.Validator.ExitEpoch, &progress.ExitEpoch},
+		{val.Data.Validator.WithdrawableEpoch, &progress.WithdrawableEpoch},
+	} {
+		if epoch.value == "" || epoch.value == FarFutureEpoch {
+			continue
+		}
+		parsed, err := strconv.ParseUint(epoch.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid epoch '%s': %v", epoch.value, err)
+		}
+		*epoch.dest = &parsed
+	}
+	return progress, nil
+}
+
 func (cli *Client) Get(path string, response any) error {
 	return cli.Send("GET", path, nil, response)
 }
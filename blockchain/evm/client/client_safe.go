@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/abi/gnosis_safe"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SafeInfo is the current configuration of a Safe multisig contract
+type SafeInfo struct {
+	Nonce     uint64
+	Threshold uint64
+	Owners    []xc.Address
+}
+
+func (client *Client) callSafe(ctx context.Context, safe xc.Address, method string) (interface{}, error) {
+	result, err := client.CallContract(ctx, "", &builder.ContractCall{
+		Contract: safe,
+		Value:    xc.NewBigIntFromUint64(0),
+		Method:   gnosis_safe.Method(method),
+		Args:     []interface{}{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 1 {
+		return nil, fmt.Errorf("unexpected result from %s: %v", method, result)
+	}
+	return result[0], nil
+}
+
+func (client *Client) FetchSafeNonce(ctx context.Context, safe xc.Address) (uint64, error) {
+	nonce, err := client.callSafe(ctx, safe, "nonce")
+	if err != nil {
+		return 0, err
+	}
+	return nonce.(*big.Int).Uint64(), nil
+}
+
+func (client *Client) FetchSafeThreshold(ctx context.Context, safe xc.Address) (uint64, error) {
+	threshold, err := client.callSafe(ctx, safe, "getThreshold")
+	if err != nil {
+		return 0, err
+	}
+	return threshold.(*big.Int).Uint64(), nil
+}
+
+func (client *Client) FetchSafeOwners(ctx context.Context, safe xc.Address) ([]xc.Address, error) {
+	result, err := client.callSafe(ctx, safe, "getOwners")
+	if err != nil {
+		return nil, err
+	}
+	owners := []xc.Address{}
+	for _, owner := range result.([]common.Address) {
+		owners = append(owners, xc.Address(owner.Hex()))
+	}
+	return owners, nil
+}
+
+func (client *Client) FetchSafeInfo(ctx context.Context, safe xc.Address) (*SafeInfo, error) {
+	nonce, err := client.FetchSafeNonce(ctx, safe)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := client.FetchSafeThreshold(ctx, safe)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := client.FetchSafeOwners(ctx, safe)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeInfo{
+		Nonce:     nonce,
+		Threshold: threshold,
+		Owners:    owners,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchSafeInfo(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, []string{
+		// nonce
+		`"0x0000000000000000000000000000000000000000000000000000000000000011"`,
+		// getThreshold
+		`"0x0000000000000000000000000000000000000000000000000000000000000002"`,
+		// getOwners
+		`"0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002` +
+			`00000000000000000000000050b0c2b3bcad53eb45b57c4e5df8a9890d002cc8` +
+			`0000000000000000000000003ad57b83b2e3dc5648f32e98e386935a9b10bb9f"`,
+	})
+	defer close()
+
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain: xc.ETH,
+		Client: &xc.ClientConfig{
+			URL: server.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	info, err := cli.FetchSafeInfo(context.Background(), "0x5AFE3855358E112B5647B952709E6165e1c1eEEe")
+	require.NoError(t, err)
+	require.EqualValues(t, 17, info.Nonce)
+	require.EqualValues(t, 2, info.Threshold)
+	require.Equal(t, []xc.Address{
+		"0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8",
+		"0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F",
+	}, info.Owners)
+}
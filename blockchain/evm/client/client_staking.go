@@ -25,8 +25,10 @@ func (cli *Client) FetchStakeBalance(ctx context.Context, args xcclient.StakedBa
 func (cli *Client) FetchStakingInput(ctx context.Context, args xcbuilder.StakeArgs) (xc.StakeTxInput, error) {
 	return nil, fmt.Errorf("EVM does not yet natively support delegated staking, must use a 3rd party provider")
 }
+
+// Validators may be exited natively by the execution address in their withdrawal credentials
 func (cli *Client) FetchUnstakingInput(ctx context.Context, args xcbuilder.StakeArgs) (xc.UnstakeTxInput, error) {
-	return nil, fmt.Errorf("EVM does not yet natively support delegated staking, must use a 3rd party provider")
+	return cli.FetchWithdrawalRequestInput(ctx, args)
 }
 func (cli *Client) FetchWithdrawInput(ctx context.Context, args xcbuilder.StakeArgs) (xc.WithdrawTxInput, error) {
 	return nil, fmt.Errorf("EVM does not yet natively support delegated staking, must use a 3rd party provider")
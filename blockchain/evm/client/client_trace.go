@@ -24,11 +24,25 @@ type TraceTransactionResult struct {
 	Input   hexutil.Bytes             `json:"input"`
 	Value   hexutil.Big               `json:"value"`
 	Type    TraceTransactionType      `json:"type"`
+	Error   string                    `json:"error,omitempty"`
 	Calls   []*TraceTransactionResult `json:"calls"`
+	// only set by the callTracer
+	RevertReason string     `json:"revertReason,omitempty"`
+	Logs         []TraceLog `json:"logs,omitempty"`
+}
+
+// Log emitted by a call, included by the callTracer when `withLog` is set
+type TraceLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
 }
 
 type TraceTransactionArgs struct {
 	Tracer string `json:"tracer"`
+	// optional
+	TracerConfig   map[string]any `json:"tracerConfig,omitempty"`
+	StateOverrides StateOverride  `json:"stateOverrides,omitempty"`
 }
 
 // Recurse through all of the traces and provide them as a linear set of traces.
@@ -41,16 +55,17 @@ func FlattenTraceResult(result *TraceTransactionResult, traces []*TraceTransacti
 	return traces
 }
 
-// Implements debug_traceTransaction, which is supported on GETH and most RPC providers,
-// but likely not implemented on public nodes.
+// Traces the internal calls of a transaction using whichever tracing API the node supports:
+// debug_traceTransaction (geth), trace_transaction (parity/erigon/nethermind), or ots_traceTransaction (otterscan).
 // This will reveal ETH transfers in internal transactions and removes the need for us
 // to manually parse "multi transfers".
 func (client *Client) TraceTransaction(ctx context.Context, txHash common.Hash) (*TraceTransactionResult, error) {
-	var result TraceTransactionResult
-	err := client.EthClient.Client().CallContext(ctx, &result, "debug_traceTransaction", txHash, &TraceTransactionArgs{
-		Tracer: "callTracer",
+	var result *TraceTransactionResult
+	err := client.withTraceBackend(func(backend TraceBackend) (err error) {
+		result, err = backend.TraceTransaction(ctx, client, txHash)
+		return err
 	})
-	return &result, err
+	return result, err
 }
 
 func (client *Client) TraceEthMovements(ctx context.Context, txHash common.Hash) (tx.SourcesAndDests, error) {
@@ -58,15 +73,20 @@ func (client *Client) TraceEthMovements(ctx context.Context, txHash common.Hash)
 	if err != nil {
 		return tx.SourcesAndDests{}, err
 	}
-	traces := FlattenTraceResult(result, []*TraceTransactionResult{})
 	sourcesAndDests := tx.SourcesAndDests{}
+	// calls that reverted did not move any funds
+	executed := pruneRevertedCalls(result)
+	if executed == nil {
+		return sourcesAndDests, nil
+	}
+	traces := FlattenTraceResult(executed, []*TraceTransactionResult{})
 	zero := big.NewInt(0)
 	native := client.Chain.Chain
 
 	for _, trace := range traces {
-		if trace.Value.ToInt().Cmp(zero) > 0 {
+		if movesValue(trace) && trace.Value.ToInt().Cmp(zero) > 0 {
 			amount := xc_types.BigInt(*trace.Value.ToInt())
-			sourcesAndDests.Sources = append(sourcesAndDests.Destinations, &xc_types.LegacyTxInfoEndpoint{
+			sourcesAndDests.Sources = append(sourcesAndDests.Sources, &xc_types.LegacyTxInfoEndpoint{
 				Address:     xc_types.Address(trace.From.String()),
 				Amount:      amount,
 				NativeAsset: native,
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum"
+)
+
+// FetchContractCallInput returns the tx input for a contract call, with the gas limit set by simulation
+func (client *Client) FetchContractCallInput(ctx context.Context, from xc.Address, call *builder.ContractCall) (*tx_input.TxInput, error) {
+	txInput, err := client.FetchUnsimulatedInput(ctx, from)
+	if err != nil {
+		return txInput, err
+	}
+
+	txBuilder, err := builder.NewTxBuilder(client.Chain)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	exampleTx, err := txBuilder.NewContractCall(call, txInput)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+
+	asset := &xc.TokenAssetConfig{Contract: xc.ContractAddress(call.Contract)}
+	gasLimit, err := client.SimulateGasWithLimit(ctx, from, exampleTx.(*tx.Tx), asset)
+	if err != nil {
+		return nil, err
+	}
+	txInput.GasLimit = gasLimit
+	client.SetL1Fee(ctx, txInput, exampleTx.(*tx.Tx))
+	return txInput, nil
+}
+
+// CallContract performs a read-only eth_call at the latest block and decodes the return values
+func (client *Client) CallContract(ctx context.Context, from xc.Address, call *builder.ContractCall) ([]interface{}, error) {
+	result, err := client.callContractRaw(ctx, from, call)
+	if err != nil {
+		return nil, err
+	}
+	return call.DecodeResult(result)
+}
+
+func (client *Client) callContractRaw(ctx context.Context, from xc.Address, call *builder.ContractCall) ([]byte, error) {
+	data, err := call.Calldata()
+	if err != nil {
+		return nil, err
+	}
+	contract, err := address.FromHex(call.Contract)
+	if err != nil {
+		return nil, fmt.Errorf("bad contract address '%v': %v", call.Contract, err)
+	}
+	msg := ethereum.CallMsg{
+		To:    &contract,
+		Value: call.Value.Int(),
+		Data:  data,
+	}
+	if from != "" {
+		msg.From, err = address.FromHex(from)
+		if err != nil {
+			return nil, fmt.Errorf("bad from address '%v': %v", from, err)
+		}
+	}
+	result, err := client.EthClient.CallContract(ctx, msg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not call %s: %v", call.Method.Sig, err)
+	}
+	return result, nil
+}
+
+// FetchEntryPointNonce returns the next ERC-4337 nonce of an account for the given nonce key
+func (client *Client) FetchEntryPointNonce(ctx context.Context, entryPoint xc.Address, sender xc.Address, key uint64) (xc.BigInt, error) {
+	call, err := builder.NewContractCall(entryPoint, "function getNonce(address sender, uint192 key) view returns (uint256 nonce)", "", sender, key)
+	if err != nil {
+		return xc.BigInt{}, err
+	}
+	result, err := client.CallContract(ctx, "", call)
+	if err != nil {
+		return xc.BigInt{}, err
+	}
+	return xc.BigInt(*result[0].(*big.Int)), nil
+}
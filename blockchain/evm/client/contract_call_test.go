@@ -0,0 +1,40 @@
+package client_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallContract(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, `"0x00000000000000000000000000000000000000000000003635c9adc5dea00000"`)
+	defer close()
+
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain: xc.ETH,
+		Client: &xc.ClientConfig{
+			URL: server.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	call, err := builder.NewContractCall(
+		"0x779877A7B0D9E8603169DdbD7836e478b4624789",
+		"function balanceOf(address owner) view returns (uint256)",
+		"",
+		"0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8",
+	)
+	require.NoError(t, err)
+
+	result, err := cli.CallContract(context.Background(), "", call)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	expected, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	require.Equal(t, expected.String(), result[0].(*big.Int).String())
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xc "github.com/CustodyOne/chainkit/types"
+)
+
+// FetchContractDeploymentInput returns the tx input for deploying a contract, with the gas limit set by simulation
+func (client *Client) FetchContractDeploymentInput(ctx context.Context, from xc.Address, deployment *builder.ContractDeployment) (*tx_input.TxInput, error) {
+	txInput, err := client.FetchUnsimulatedInput(ctx, from)
+	if err != nil {
+		return txInput, err
+	}
+
+	txBuilder, err := builder.NewTxBuilder(client.Chain)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	exampleTx, err := txBuilder.NewContractDeployment(deployment, txInput)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+
+	gasLimit, err := client.SimulateGasWithLimit(ctx, from, exampleTx.(*tx.Tx), client.Chain)
+	if err != nil {
+		return nil, err
+	}
+	txInput.GasLimit = gasLimit
+	client.SetL1Fee(ctx, txInput, exampleTx.(*tx.Tx))
+	return txInput, nil
+}
+
+// IsDeployed returns if there is contract code at the address, e.g. to confirm a deployment
+func (client *Client) IsDeployed(ctx context.Context, contract xc.Address) (bool, error) {
+	contractAddr, err := address.FromHex(contract)
+	if err != nil {
+		return false, fmt.Errorf("bad contract address '%v': %v", contract, err)
+	}
+	code, err := client.EthClient.CodeAt(ctx, contractAddr, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not get code of %s: %v", contract, err)
+	}
+	return len(code) > 0, nil
+}
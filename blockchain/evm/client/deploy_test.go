@@ -0,0 +1,60 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchContractDeploymentInput(t *testing.T) {
+	server, calls := mockTraceRpc(t, map[string]string{
+		"eth_getTransactionCount":  `"0x5"`,
+		"eth_chainId":              `"0x1"`,
+		"eth_getBlockByNumber":     fmt.Sprintf(simulatedHeader, 0),
+		"eth_maxPriorityFeePerGas": `"0x3b9aca00"`,
+		"eth_estimateGas":          `"0x30d40"`,
+		"eth_getBalance":           `"0x0"`,
+	})
+	defer server.Close()
+	cli := newTraceClient(t, server.URL)
+	from := xc.Address(traceSender)
+
+	deployment, err := builder.NewContractDeployment(hexutil.MustDecode("0x6080604052348015600f57600080fd5b50"), "")
+	require.NoError(t, err)
+	input, err := cli.FetchContractDeploymentInput(context.Background(), from, deployment)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, input.Nonce)
+	// the estimate plus the margin for contract calls
+	require.EqualValues(t, 200_000+1_000, input.GasLimit)
+	require.Equal(t, 1, calls["eth_estimateGas"])
+
+	// the input nonce determines the address
+	predicted, err := deployment.PredictAddress(from, input.Nonce)
+	require.NoError(t, err)
+	expected, _ := builder.PredictCreateAddress(from, 5)
+	require.Equal(t, expected, predicted)
+}
+
+func TestIsDeployed(t *testing.T) {
+	for _, v := range []struct {
+		code     string
+		deployed bool
+	}{
+		{code: `"0x6080604052"`, deployed: true},
+		{code: `"0x"`, deployed: false},
+	} {
+		server, _ := mockTraceRpc(t, map[string]string{
+			"eth_getCode": v.code,
+		})
+		cli := newTraceClient(t, server.URL)
+		deployed, err := cli.IsDeployed(context.Background(), traceContract)
+		require.NoError(t, err)
+		require.Equal(t, v.deployed, deployed)
+		server.Close()
+	}
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum"
+)
+
+// Partial withdrawals must leave at least this much staked
+var minActivationBalanceGwei = uint64(32_000_000_000)
+
+// Each request raises the fee of the system contract for the next block, so requests pay this much more than
+// the current fee in case it rises before they are included.  The excess is not refunded.
+var executionRequestFeeHeadroomPercent = int64(50)
+
+// FetchExecutionRequestFee reads the current fee of the EIP-7002 or EIP-7251 system contract.
+// The fee only changes between blocks, as requests in a block increase it for the next.
+func (client *Client) FetchExecutionRequestFee(ctx context.Context, contract string) (xc.BigInt, error) {
+	contractAddr, err := address.FromHex(xc.Address(contract))
+	if err != nil {
+		return xc.BigInt{}, err
+	}
+	result, err := client.EthClient.CallContract(ctx, ethereum.CallMsg{To: &contractAddr}, nil)
+	if err != nil {
+		return xc.BigInt{}, fmt.Errorf("could not read fee of %s: %v", contract, err)
+	}
+	if len(result) != 32 {
+		return xc.BigInt{}, fmt.Errorf("unexpected fee from %s: 0x%x", contract, result)
+	}
+	return xc.BigInt(*new(big.Int).SetBytes(result)), nil
+}
+
+// Add headroom to the fee of an execution request, rounding up so that even the minimum fee of 1 wei has some
+func withFeeHeadroom(fee xc.BigInt) xc.BigInt {
+	headroom := new(big.Int).Mul(fee.Int(), big.NewInt(executionRequestFeeHeadroomPercent))
+	headroom.Add(headroom, big.NewInt(99))
+	headroom.Div(headroom, big.NewInt(100))
+	return xc.BigInt(*headroom.Add(headroom, fee.Int()))
+}
+
+// Look up a validator and check that the execution address can make requests for it
+func (client *Client) fetchOwnedValidator(ctx context.Context, from xc.Address, validator string) (*Validator, []byte, error) {
+	publicKey, err := address.DecodeHex(validator)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid validator public key %s: %v", validator, err)
+	}
+	fromAddr, err := address.FromHex(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	val, err := client.FetchValidator(ctx, validator)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not lookup validator %s: %v", validator, err)
+	}
+	owner, ok := val.Data.Validator.ExecutionAddress()
+	if !ok || owner != fromAddr {
+		return nil, nil, fmt.Errorf("withdrawal credentials %s of validator %s are not controlled by %s", val.Data.Validator.WithdrawalCredentials, validator, from)
+	}
+	if val.Data.Status != "active_ongoing" {
+		return nil, nil, fmt.Errorf("validator %s cannot make requests while %s", validator, val.Data.Status)
+	}
+	return &val.Data, publicKey, nil
+}
+
+// FetchWithdrawalRequestInput prepares an EIP-7002 withdrawal request for the validator set in the arguments.
+// Validators with 0x01 credentials can only fully exit.  Compounding validators may withdraw part of their
+// balance, as long as 32 ether remains, or fully exit when the amount covers the balance.
+func (client *Client) FetchWithdrawalRequestInput(ctx context.Context, args xcbuilder.StakeArgs) (*tx_input.WithdrawalRequestInput, error) {
+	validator, ok := args.GetValidator()
+	if !ok {
+		return nil, fmt.Errorf("must provide a validator to unstake from")
+	}
+	val, publicKey, err := client.fetchOwnedValidator(ctx, args.GetFrom(), validator)
+	if err != nil {
+		return nil, err
+	}
+
+	input := tx_input.NewWithdrawalRequestInput()
+	input.PublicKey = publicKey
+	amountGwei := new(big.Int).Div(args.GetAmount().Int(), big.NewInt(1_000_000_000)).Uint64()
+	if amountGwei == 0 {
+		return nil, fmt.Errorf("must withdraw at least 1 gwei")
+	}
+	balanceGwei, ok := new(big.Int).SetString(val.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance '%s' of validator %s", val.Balance, validator)
+	}
+	if val.Validator.IsCompounding() && amountGwei < balanceGwei.Uint64() {
+		if balanceGwei.Uint64()-amountGwei < minActivationBalanceGwei {
+			return nil, fmt.Errorf("a partial withdrawal must leave 32 ether staked, the validator balance is %s gwei", val.Balance)
+		}
+		input.AmountGwei = amountGwei
+	} else if !val.Validator.IsCompounding() && amountGwei != minActivationBalanceGwei {
+		return nil, fmt.Errorf("a withdrawal request exits a single validator of 32 ether, use one request per validator")
+	}
+
+	fee, err := client.FetchExecutionRequestFee(ctx, builder.WithdrawalRequestContract)
+	if err != nil {
+		return nil, err
+	}
+	input.Fee = withFeeHeadroom(fee)
+	partialTxInput, err := client.FetchUnsimulatedInput(ctx, args.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	input.TxInput = *partialTxInput
+
+	txBuilder, err := builder.NewTxBuilder(client.Chain)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	exampleTx, err := txBuilder.Unstake(args, input)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	input.GasLimit, err = client.SimulateGasWithLimit(ctx, args.GetFrom(), exampleTx.(*tx.Tx), client.Chain)
+	if err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// FetchConsolidationInput prepares an EIP-7251 request to consolidate the source validator into the target.
+// The source must be controlled by the from address, and the target must have compounding credentials unless
+// it is the source, which converts the source to compounding.
+func (client *Client) FetchConsolidationInput(ctx context.Context, from xc.Address, source string, target string) (*tx_input.ConsolidationRequestInput, error) {
+	input := tx_input.NewConsolidationRequestInput()
+	_, sourceKey, err := client.fetchOwnedValidator(ctx, from, source)
+	if err != nil {
+		return nil, err
+	}
+	input.SourcePublicKey = sourceKey
+	input.TargetPublicKey = sourceKey
+	if source != target {
+		targetVal, targetKey, err := client.fetchOwnedValidator(ctx, from, target)
+		if err != nil {
+			return nil, err
+		}
+		if !targetVal.Validator.IsCompounding() {
+			return nil, fmt.Errorf("target validator %s must have compounding credentials", target)
+		}
+		input.TargetPublicKey = targetKey
+	}
+
+	fee, err := client.FetchExecutionRequestFee(ctx, builder.ConsolidationRequestContract)
+	if err != nil {
+		return nil, err
+	}
+	input.Fee = withFeeHeadroom(fee)
+	partialTxInput, err := client.FetchUnsimulatedInput(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	input.TxInput = *partialTxInput
+
+	txBuilder, err := builder.NewTxBuilder(client.Chain)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	exampleTx, err := txBuilder.NewConsolidationRequest(from, input)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	input.GasLimit, err = client.SimulateGasWithLimit(ctx, from, exampleTx.(*tx.Tx), client.Chain)
+	if err != nil {
+		return nil, err
+	}
+	return input, nil
+}
@@ -0,0 +1,174 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	exitValidator      = "0xa776cfc875b15a1444bbda22e47e759ade11b39912a3e210807204f410d43baa332acb38aab206bc8ac7ad476a42839a"
+	exitOtherValidator = "0xa776cfc875b15a1444bbda22e47e759ade11b39912a3e210807204f410d43baa332acb38aab206bc8ac7ad476a42839b"
+)
+
+func beaconValidator(status string, credentials string, balance string, exitEpoch string) string {
+	return fmt.Sprintf(`{"execution_optimistic":false,"finalized":false,"data":{"index":"1","balance":"%s","status":"%s","validator":{"pubkey":"0x00","withdrawal_credentials":"%s","effective_balance":"%s","slashed":false,"activation_eligibility_epoch":"0","activation_epoch":"0","exit_epoch":"%s","withdrawable_epoch":"%s"}}}`,
+		balance, status, credentials, balance, exitEpoch, exitEpoch)
+}
+
+// Serves beacon API requests by path, and JSON-RPC requests by method
+func mockBeaconRpc(t *testing.T, beacon map[string]string, responses map[string]string) *httptest.Server {
+	rpc := testtypes.JSONRPCMethodsHandler(t, testtypes.ByMethod(responses))
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			result, ok := beacon[strings.TrimPrefix(req.URL.Path, "/")]
+			if !ok {
+				rw.WriteHeader(http.StatusNotFound)
+				rw.Write([]byte(`{"code":404,"message":"Validator not found"}`))
+				return
+			}
+			rw.Write([]byte(result))
+			return
+		}
+		rpc(rw, req)
+	}))
+}
+
+var requestResponses = map[string]string{
+	// the fee of the system contract
+	"eth_call":                 `"0x0000000000000000000000000000000000000000000000000000000000000001"`,
+	"eth_getTransactionCount":  `"0x3"`,
+	"eth_chainId":              `"0x1"`,
+	"eth_getBlockByNumber":     fmt.Sprintf(simulatedHeader, 0),
+	"eth_maxPriorityFeePerGas": `"0x3b9aca00"`,
+	"eth_estimateGas":          `"0x1d4c0"`,
+	"eth_getBalance":           `"0x0"`,
+}
+
+func credentialsFor(prefix string, addr string) string {
+	return "0x" + prefix + strings.Repeat("0", 22) + strings.ToLower(strings.TrimPrefix(addr, "0x"))
+}
+
+func TestFetchWithdrawalRequestInput(t *testing.T) {
+	thirtyTwo, _ := xc.NewAmountHumanReadableFromStr("32")
+	sixtyFour, _ := xc.NewAmountHumanReadableFromStr("64")
+	fiveAndAHalf, _ := xc.NewAmountHumanReadableFromStr("5.5")
+	for _, v := range []struct {
+		name        string
+		credentials string
+		status      string
+		balance     string
+		amount      xc.BigInt
+		amountGwei  uint64
+		err         string
+	}{
+		{name: "full exit", credentials: credentialsFor("01", traceSender), status: "active_ongoing", balance: "32000000000", amount: thirtyTwo.ToBlockchain(18)},
+		{name: "multiple validators", credentials: credentialsFor("01", traceSender), status: "active_ongoing", balance: "32000000000", amount: sixtyFour.ToBlockchain(18), err: "use one request per validator"},
+		{name: "partial withdrawal", credentials: credentialsFor("02", traceSender), status: "active_ongoing", balance: "64000000000", amount: thirtyTwo.ToBlockchain(18), amountGwei: 32_000_000_000},
+		{name: "partial withdrawal of less than 32", credentials: credentialsFor("02", traceSender), status: "active_ongoing", balance: "64000000000", amount: fiveAndAHalf.ToBlockchain(18), amountGwei: 5_500_000_000},
+		{name: "less than a gwei", credentials: credentialsFor("02", traceSender), status: "active_ongoing", balance: "64000000000", amount: xc.NewBigIntFromUint64(1), err: "must withdraw at least 1 gwei"},
+		{name: "invalid balance", credentials: credentialsFor("02", traceSender), status: "active_ongoing", balance: "", amount: thirtyTwo.ToBlockchain(18), err: "invalid balance"},
+		{name: "compounding full exit", credentials: credentialsFor("02", traceSender), status: "active_ongoing", balance: "64000000000", amount: sixtyFour.ToBlockchain(18)},
+		{name: "below minimum", credentials: credentialsFor("02", traceSender), status: "active_ongoing", balance: "40000000000", amount: thirtyTwo.ToBlockchain(18), err: "must leave 32 ether"},
+		{name: "other owner", credentials: credentialsFor("01", traceDest), status: "active_ongoing", balance: "32000000000", amount: thirtyTwo.ToBlockchain(18), err: "are not controlled by"},
+		{name: "bls credentials", credentials: "0x00" + strings.Repeat("1", 62), status: "active_ongoing", balance: "32000000000", amount: thirtyTwo.ToBlockchain(18), err: "are not controlled by"},
+		{name: "exiting", credentials: credentialsFor("01", traceSender), status: "active_exiting", balance: "32000000000", amount: thirtyTwo.ToBlockchain(18), err: "cannot make requests while active_exiting"},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			server := mockBeaconRpc(t, map[string]string{
+				"eth/v1/beacon/states/head/validators/" + exitValidator: beaconValidator(v.status, v.credentials, v.balance, "18446744073709551615"),
+			}, requestResponses)
+			defer server.Close()
+			cli := newTraceClient(t, server.URL)
+
+			args, err := xcbuilder.NewStakeArgs(xc.ETH, traceSender, v.amount, xcbuilder.WithValidator(exitValidator))
+			require.NoError(t, err)
+			input, err := cli.FetchUnstakingInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			request := input.(*tx_input.WithdrawalRequestInput)
+			require.Equal(t, exitValidator, hexutil.Encode(request.PublicKey))
+			require.Equal(t, v.amountGwei, request.AmountGwei)
+			// the fee of 1 wei, with headroom in case it rises
+			require.EqualValues(t, 2, request.Fee.Uint64())
+			require.EqualValues(t, 3, request.Nonce)
+			require.EqualValues(t, 120_000+1_000, request.GasLimit)
+		})
+	}
+}
+
+func TestFetchConsolidationInput(t *testing.T) {
+	server := mockBeaconRpc(t, map[string]string{
+		"eth/v1/beacon/states/head/validators/" + exitValidator:      beaconValidator("active_ongoing", credentialsFor("01", traceSender), "32000000000", "18446744073709551615"),
+		"eth/v1/beacon/states/head/validators/" + exitOtherValidator: beaconValidator("active_ongoing", credentialsFor("02", traceSender), "32000000000", "18446744073709551615"),
+	}, requestResponses)
+	defer server.Close()
+	cli := newTraceClient(t, server.URL)
+
+	input, err := cli.FetchConsolidationInput(context.Background(), traceSender, exitValidator, exitOtherValidator)
+	require.NoError(t, err)
+	require.Equal(t, exitValidator, hexutil.Encode(input.SourcePublicKey))
+	require.Equal(t, exitOtherValidator, hexutil.Encode(input.TargetPublicKey))
+	require.EqualValues(t, 2, input.Fee.Uint64())
+
+	// converting to compounding credentials
+	input, err = cli.FetchConsolidationInput(context.Background(), traceSender, exitValidator, exitValidator)
+	require.NoError(t, err)
+	require.Equal(t, input.SourcePublicKey, input.TargetPublicKey)
+
+	// the target must be compounding
+	_, err = cli.FetchConsolidationInput(context.Background(), traceSender, exitOtherValidator, exitValidator)
+	require.ErrorContains(t, err, "must have compounding credentials")
+}
+
+func TestFetchExecutionRequestFee(t *testing.T) {
+	server := mockBeaconRpc(t, nil, map[string]string{
+		"eth_call": `"0x0000000000000000000000000000000000000000000000000000000000000011"`,
+	})
+	defer server.Close()
+	cli := newTraceClient(t, server.URL)
+	fee, err := cli.FetchExecutionRequestFee(context.Background(), builder.ConsolidationRequestContract)
+	require.NoError(t, err)
+	require.EqualValues(t, 17, fee.Uint64())
+}
+
+func TestFetchExitProgress(t *testing.T) {
+	for _, v := range []struct {
+		exitEpoch    string
+		scheduled    bool
+		exited       bool
+		withdrawable bool
+	}{
+		{exitEpoch: "18446744073709551615"},
+		{exitEpoch: "200", scheduled: true},
+		{exitEpoch: "100", scheduled: true, exited: true, withdrawable: true},
+	} {
+		server := mockBeaconRpc(t, map[string]string{
+			"eth/v1/beacon/states/head/validators/" + exitValidator: beaconValidator("active_exiting", credentialsFor("01", traceSender), "32000000000", v.exitEpoch),
+			// epoch 150
+			"eth/v1/beacon/headers/head": `{"data":{"root":"0x00","canonical":true,"header":{"message":{"slot":"4800","proposer_index":"1"}}}}`,
+		}, nil)
+		cli := newTraceClient(t, server.URL)
+		progress, err := cli.FetchExitProgress(context.Background(), exitValidator)
+		require.NoError(t, err)
+		require.EqualValues(t, 150, progress.CurrentEpoch)
+		require.Equal(t, v.scheduled, progress.ExitScheduled())
+		require.Equal(t, v.exited, progress.Exited())
+		require.Equal(t, v.withdrawable, progress.Withdrawable())
+		server.Close()
+	}
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+// Celo charges extra gas for debiting and crediting the fee currency, which is not included in estimates
+const FeeCurrencyIntrinsicGas = 50_000
+
+// SetFeeCurrency prices the input in an ERC-20 fee currency, using the fee currency
+// parameter that Celo nodes accept for the gas price methods.
+func (client *Client) SetFeeCurrency(ctx context.Context, input *tx_input.TxInput, feeCurrency xc.ContractAddress) error {
+	if client.Chain.Chain != xc.CELO {
+		return fmt.Errorf("fee currencies are not supported on %s", client.Chain.Chain)
+	}
+	feeCurrencyAddr, err := address.FromHex(xc.Address(feeCurrency))
+	if err != nil {
+		return fmt.Errorf("invalid fee currency: %v", err)
+	}
+
+	gasPrice, err := client.SuggestGasPrice(ctx, &feeCurrencyAddr)
+	if err != nil {
+		return err
+	}
+	var gasTipCap hexutil.Big
+	err = client.EthClient.Client().CallContext(ctx, &gasTipCap, "eth_maxPriorityFeePerGas", feeCurrencyAddr)
+	if err != nil {
+		zap.S().Debug("could not fetch priority fee in fee currency, using none", zap.Error(err))
+		gasTipCap = hexutil.Big{}
+	}
+
+	input.FeeCurrency = feeCurrency
+	input.GasFeeCap = xc.BigInt(*gasPrice)
+	input.GasTipCap = xc.BigInt(*gasTipCap.ToInt()).ApplyGasPriceMultiplier(client.Chain)
+	if input.GasFeeCap.Cmp(&input.GasTipCap) < 0 {
+		input.GasFeeCap = input.GasTipCap
+	}
+	return nil
+}
+
+// SuggestGasPrice returns the gas price, denominated in the fee currency if one is given
+func (client *Client) SuggestGasPrice(ctx context.Context, feeCurrency *common.Address) (*big.Int, error) {
+	if feeCurrency == nil {
+		return client.EthClient.SuggestGasPrice(ctx)
+	}
+	var gasPrice hexutil.Big
+	err := client.EthClient.Client().CallContext(ctx, &gasPrice, "eth_gasPrice", *feeCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch gas price in %s: %v", feeCurrency, err)
+	}
+	return gasPrice.ToInt(), nil
+}
+
+// Celo fee currencies with other than 18 decimals are used through an adapter, which
+// scales the fee up.  Returns the token that is debited and the scale to remove.
+func (client *Client) resolveFeeCurrency(ctx context.Context, feeCurrency common.Address) (common.Address, *big.Int, error) {
+	one := big.NewInt(1)
+	adaptedCall, _ := builder.NewContractCall(xc.Address(feeCurrency.String()), "function adaptedToken() view returns (address)", "")
+	values, err := client.CallContract(ctx, "", adaptedCall)
+	if err != nil {
+		// not an adapter
+		return feeCurrency, one, nil
+	}
+	differenceCall, _ := builder.NewContractCall(xc.Address(feeCurrency.String()), "function digitDifference() view returns (uint96)", "")
+	differences, err := client.CallContract(ctx, "", differenceCall)
+	if err != nil {
+		zap.S().Warn("could not read digit difference of fee currency adapter", zap.String("adapter", feeCurrency.String()), zap.Error(err))
+		return feeCurrency, one, nil
+	}
+	if len(values) != 1 || len(differences) != 1 {
+		return common.Address{}, nil, fmt.Errorf("unexpected result from fee currency adapter %s", feeCurrency)
+	}
+	token, ok := values[0].(common.Address)
+	if !ok {
+		return common.Address{}, nil, fmt.Errorf("unexpected adapted token %T from fee currency adapter %s", values[0], feeCurrency)
+	}
+	difference, ok := differences[0].(*big.Int)
+	if !ok {
+		return common.Address{}, nil, fmt.Errorf("unexpected digit difference %T from fee currency adapter %s", differences[0], feeCurrency)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), difference, nil)
+	return token, scale, nil
+}
+
+type feeCurrencyTx struct {
+	From        common.Address  `json:"from"`
+	FeeCurrency *common.Address `json:"feeCurrency"`
+}
+
+// The fee currency of a transaction, which go-ethereum does not decode
+func (client *Client) fetchFeeCurrency(ctx context.Context, txHash common.Hash) (*feeCurrencyTx, error) {
+	var result feeCurrencyTx
+	err := client.EthClient.Client().CallContext(ctx, &result, "eth_getTransactionByHash", txHash)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,151 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const cUSD = "0x765DE816845861e75A25fCA122bb6898B8B1282a"
+
+// Prices gas differently when a fee currency is passed as the first parameter
+func mockFeeCurrencyRpc(t *testing.T, responses map[string]string, feeCurrencyResponses map[string]string) *httptest.Server {
+	return testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		if len(request.Params) > 0 && strings.EqualFold(string(request.Params[0]), `"`+cUSD+`"`) {
+			result, ok := feeCurrencyResponses[request.Method]
+			return result, ok
+		}
+		result, ok := responses[request.Method]
+		return result, ok
+	})
+}
+
+func TestFetchTransferInputWithFeeCurrency(t *testing.T) {
+	responses := map[string]string{
+		"eth_getTransactionCount":  `"0x3"`,
+		"eth_chainId":              `"0xa4ec"`,
+		"eth_getBlockByNumber":     fmt.Sprintf(simulatedHeader, 0),
+		"eth_maxPriorityFeePerGas": `"0x1"`,
+		"eth_estimateGas":          `"0x5208"`,
+		"eth_getBalance":           `"0x0"`,
+	}
+	feeCurrencyResponses := map[string]string{
+		"eth_gasPrice":             `"0x3b9aca00"`,
+		"eth_maxPriorityFeePerGas": `"0x2"`,
+	}
+	server := mockFeeCurrencyRpc(t, responses, feeCurrencyResponses)
+	defer server.Close()
+
+	for _, v := range []struct {
+		name     string
+		chain    xc.NativeAsset
+		options  []xcbuilder.BuilderOption
+		gasLimit uint64
+		err      string
+	}{
+		{
+			name:     "native fee",
+			chain:    xc.CELO,
+			gasLimit: 21_000,
+		},
+		{
+			name:     "fee currency",
+			chain:    xc.CELO,
+			options:  []xcbuilder.BuilderOption{xcbuilder.WithFeeCurrency(cUSD)},
+			gasLimit: 21_000 + client.FeeCurrencyIntrinsicGas,
+		},
+		{
+			name:    "unsupported chain",
+			chain:   xc.ETH,
+			options: []xcbuilder.BuilderOption{xcbuilder.WithFeeCurrency(cUSD)},
+			err:     "fee currencies are not supported on ETH",
+		},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			cli, err := client.NewClient(&xc.ChainConfig{
+				Chain:  v.chain,
+				Client: &xc.ClientConfig{URL: server.URL},
+			})
+			require.NoError(t, err)
+			args, err := xcbuilder.NewTransferArgs(traceSender, traceDest, xc.NewBigIntFromUint64(100), v.options...)
+			require.NoError(t, err)
+
+			input, err := cli.FetchTransferInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			evmInput := input.(*tx_input.TxInput)
+			require.Equal(t, v.gasLimit, evmInput.GasLimit)
+			if len(v.options) > 0 {
+				require.EqualValues(t, cUSD, evmInput.FeeCurrency)
+				require.EqualValues(t, "1000000000", evmInput.GasFeeCap.String())
+				require.EqualValues(t, "2", evmInput.GasTipCap.String())
+			} else {
+				require.Empty(t, evmInput.FeeCurrency)
+			}
+		})
+	}
+}
+
+func TestEstimateGasFeeWithFeeCurrency(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	estimatedFrom := []string{}
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		switch request.Method {
+		case "eth_estimateGas":
+			var msg struct {
+				From string `json:"from"`
+			}
+			require.NoError(t, json.Unmarshal(request.Params[0], &msg))
+			estimatedFrom = append(estimatedFrom, msg.From)
+			return `"0x5208"`, true
+		case "eth_gasPrice":
+			if len(request.Params) > 0 && strings.EqualFold(string(request.Params[0]), `"`+cUSD+`"`) {
+				return `"0x3b9aca00"`, true
+			}
+			return `"0x1"`, true
+		}
+		return "", false
+	})
+	defer server.Close()
+	chain := &xc.ChainConfig{Chain: xc.CELO, ChainID: 42220, Client: &xc.ClientConfig{URL: server.URL}}
+	cli, err := client.NewClient(chain)
+	require.NoError(t, err)
+
+	args, err := xcbuilder.NewTransferArgs(xc.Address(from.String()), traceDest, xc.NewBigIntFromUint64(100), xcbuilder.WithFeeCurrency(cUSD))
+	require.NoError(t, err)
+	input := tx_input.NewTxInput()
+	input.FeeCurrency = cUSD
+	input.GasLimit = 21_000
+	txBuilder, err := builder.NewTxBuilder(chain)
+	require.NoError(t, err)
+	trans, err := txBuilder.NewTransfer(args, input)
+	require.NoError(t, err)
+	sighashes, err := trans.Sighashes()
+	require.NoError(t, err)
+	sig, err := crypto.Sign(sighashes[0], key)
+	require.NoError(t, err)
+	require.NoError(t, trans.AddSignatures(sig))
+
+	// the sender is recovered from the CIP-64 signature, and gas is priced in the fee currency
+	fee, err := cli.EstimateGasFee(context.Background(), trans)
+	require.NoError(t, err)
+	require.Equal(t, []string{strings.ToLower(from.String())}, estimatedFrom)
+	require.EqualValues(t, (21_000+client.FeeCurrencyIntrinsicGas)*1_000_000_000, fee.Uint64())
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
 	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
 	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xclient "github.com/CustodyOne/chainkit/client"
 	xc "github.com/CustodyOne/chainkit/types"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -95,13 +96,18 @@ func (client *Client) SimulateGasWithLimit(ctx context.Context, from xc.Address,
 }
 
 func (client *Client) GetNonce(ctx context.Context, from xc.Address) (uint64, error) {
+	return client.GetNonceAt(ctx, from, xclient.ReadArgs{})
+}
+
+// Get the nonce of an address at a given commitment or historical block
+func (client *Client) GetNonceAt(ctx context.Context, from xc.Address, args xclient.ReadArgs) (uint64, error) {
 	var fromAddr common.Address
 	var err error
 	fromAddr, err = address.FromHex(from)
 	if err != nil {
 		return 0, fmt.Errorf("bad to address '%v': %v", from, err)
 	}
-	nonce, err := client.EthClient.NonceAt(ctx, fromAddr, nil)
+	nonce, err := client.EthClient.NonceAt(ctx, fromAddr, blockNumberForArgs(args))
 	if err != nil {
 		return 0, err
 	}
@@ -113,6 +119,12 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 	if err != nil {
 		return txInput, err
 	}
+	if feeCurrency, ok := args.GetFeeCurrency(); ok {
+		err = client.SetFeeCurrency(ctx, txInput, feeCurrency)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	var asset xc.IAsset
 	if as, ok := args.GetAsset(); ok {
@@ -134,10 +146,31 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 	if err != nil {
 		return nil, err
 	}
+	if txInput.FeeCurrency != "" {
+		gasLimit += FeeCurrencyIntrinsicGas
+	}
 	txInput.GasLimit = gasLimit
+	client.SetL1Fee(ctx, txInput, exampleTf.(*tx.Tx))
 	return txInput, nil
 }
 
+// SetL1Fee sets the L1 data fee on the input, for rollups that charge it in addition to the L2 gas.
+// The L1 fee is only part of the fee quote, so if the oracle can't be reached it is left at zero
+// rather than failing the transaction.
+func (client *Client) SetL1Fee(ctx context.Context, txInput *tx_input.TxInput, exampleTx *tx.Tx) {
+	l1Fee, err := client.EstimateL1Fee(ctx, exampleTx)
+	if err != nil {
+		zap.S().Warn("could not estimate l1 fee",
+			zap.String("chain", string(client.Chain.Chain)),
+			zap.Error(err),
+		)
+		return
+	}
+	if l1Fee != nil && l1Fee.Gas == 0 {
+		txInput.L1Fee = l1Fee.Fee
+	}
+}
+
 func (client *Client) FetchLegacyTxInput(ctx context.Context, from xc.Address, to xc.Address, asset xc.IAsset) (xc.TxInput, error) {
 	// No way to pass the amount in the input using legacy interface, so we estimate using min amount.
 	args, _ := xcbuilder.NewTransferArgs(from, to, xc.NewBigIntFromUint64(1), xcbuilder.WithAsset(asset))
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// L1Fee is the portion of a rollup transaction fee that pays for posting its data to L1
+type L1Fee struct {
+	Fee xc.BigInt
+	// Set when the L1 fee is charged as additional L2 gas (e.g. Arbitrum), rather than
+	// in addition to the L2 gas (e.g. OP-stack).  In this case it's already included in the gas estimate.
+	Gas uint64
+}
+
+// L1FeeComponent calculates the L1 data fee for a rollup
+type L1FeeComponent interface {
+	// Estimate the L1 fee of an unsigned transaction
+	EstimateL1Fee(ctx context.Context, client *Client, trans *tx.Tx) (*L1Fee, error)
+	// Read the L1 fee that was paid from a raw transaction receipt
+	ParseL1Fee(receipt json.RawMessage) (*L1Fee, error)
+}
+
+var l1FeeComponents = map[xc.L1FeeModel]L1FeeComponent{}
+
+// RegisterL1FeeComponent adds support for another rollup, selected via `l1_fee_model` on the chain
+func RegisterL1FeeComponent(model xc.L1FeeModel, component L1FeeComponent) {
+	l1FeeComponents[model] = component
+}
+
+func init() {
+	RegisterL1FeeComponent(xc.L1FeeModelOpStack, &OpStackL1FeeComponent{})
+	RegisterL1FeeComponent(xc.L1FeeModelArbitrum, &ArbitrumL1FeeComponent{})
+}
+
+// GetL1FeeComponent returns the L1 fee component configured for the chain, if any
+func (client *Client) GetL1FeeComponent() (L1FeeComponent, bool, error) {
+	if client.Chain.L1FeeModel == "" {
+		return nil, false, nil
+	}
+	component, ok := l1FeeComponents[client.Chain.L1FeeModel]
+	if !ok {
+		return nil, false, fmt.Errorf("unsupported l1 fee model '%s'", client.Chain.L1FeeModel)
+	}
+	return component, true, nil
+}
+
+// EstimateL1Fee estimates the L1 data fee of a transaction.  Returns nil if the chain has no L1 fee.
+func (client *Client) EstimateL1Fee(ctx context.Context, trans *tx.Tx) (*L1Fee, error) {
+	component, ok, err := client.GetL1FeeComponent()
+	if err != nil || !ok {
+		return nil, err
+	}
+	l1Fee, err := component.EstimateL1Fee(ctx, client, trans)
+	if err != nil {
+		return nil, fmt.Errorf("could not estimate l1 fee: %v", err)
+	}
+	return l1Fee, nil
+}
+
+// FetchL1Fee reads the L1 data fee paid by a confirmed transaction.  Returns nil if the chain has no L1 fee.
+func (client *Client) FetchL1Fee(ctx context.Context, txHash common.Hash) (*L1Fee, error) {
+	component, ok, err := client.GetL1FeeComponent()
+	if err != nil || !ok {
+		return nil, err
+	}
+	var receipt json.RawMessage
+	err = client.EthClient.Client().CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash)
+	if err != nil {
+		return nil, err
+	}
+	return component.ParseL1Fee(receipt)
+}
+
+// OpStackL1FeeComponent uses the GasPriceOracle predeploy present on OP-stack chains
+type OpStackL1FeeComponent struct{}
+
+var _ L1FeeComponent = &OpStackL1FeeComponent{}
+
+const OpStackGasPriceOracle xc.Address = "0x420000000000000000000000000000000000000F"
+
+func (*OpStackL1FeeComponent) EstimateL1Fee(ctx context.Context, client *Client, trans *tx.Tx) (*L1Fee, error) {
+	// The oracle expects the unsigned RLP encoded tx, and pads for the signature itself
+	data, err := trans.EthTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	call, err := builder.NewContractCall(OpStackGasPriceOracle, "function getL1Fee(bytes data) view returns (uint256)", "", data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.CallContract(ctx, "", call)
+	if err != nil {
+		return nil, err
+	}
+	return &L1Fee{
+		Fee: xc.BigInt(*result[0].(*big.Int)),
+	}, nil
+}
+
+func (*OpStackL1FeeComponent) ParseL1Fee(receipt json.RawMessage) (*L1Fee, error) {
+	var fields struct {
+		L1Fee *hexutil.Big `json:"l1Fee"`
+	}
+	if err := json.Unmarshal(receipt, &fields); err != nil {
+		return nil, err
+	}
+	if fields.L1Fee == nil {
+		// e.g. deposit transactions
+		return &L1Fee{Fee: xc.NewBigIntFromUint64(0)}, nil
+	}
+	return &L1Fee{
+		Fee: xc.BigInt(*fields.L1Fee),
+	}, nil
+}
+
+// ArbitrumL1FeeComponent uses the NodeInterface virtual contract present on Arbitrum chains
+type ArbitrumL1FeeComponent struct{}
+
+var _ L1FeeComponent = &ArbitrumL1FeeComponent{}
+
+const ArbitrumNodeInterface xc.Address = "0x00000000000000000000000000000000000000C8"
+
+func (*ArbitrumL1FeeComponent) EstimateL1Fee(ctx context.Context, client *Client, trans *tx.Tx) (*L1Fee, error) {
+	to := common.Address{}
+	if trans.EthTx.To() != nil {
+		to = *trans.EthTx.To()
+	}
+	call, err := builder.NewContractCall(
+		ArbitrumNodeInterface,
+		"function gasEstimateComponents(address to, bool contractCreation, bytes data) payable returns (uint64 gasEstimate, uint64 gasEstimateForL1, uint256 baseFee, uint256 l1BaseFeeEstimate)",
+		"",
+		to,
+		trans.EthTx.To() == nil,
+		trans.EthTx.Data(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.CallContract(ctx, "", call)
+	if err != nil {
+		return nil, err
+	}
+	gasForL1 := result[1].(uint64)
+	baseFee := result[2].(*big.Int)
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasForL1), baseFee)
+	return &L1Fee{
+		Fee: xc.BigInt(*fee),
+		Gas: gasForL1,
+	}, nil
+}
+
+func (*ArbitrumL1FeeComponent) ParseL1Fee(receipt json.RawMessage) (*L1Fee, error) {
+	var fields struct {
+		GasUsedForL1      *hexutil.Big `json:"gasUsedForL1"`
+		EffectiveGasPrice *hexutil.Big `json:"effectiveGasPrice"`
+	}
+	if err := json.Unmarshal(receipt, &fields); err != nil {
+		return nil, err
+	}
+	if fields.GasUsedForL1 == nil || fields.EffectiveGasPrice == nil {
+		return &L1Fee{Fee: xc.NewBigIntFromUint64(0)}, nil
+	}
+	fee := new(big.Int).Mul(fields.GasUsedForL1.ToInt(), fields.EffectiveGasPrice.ToInt())
+	return &L1Fee{
+		Fee: xc.BigInt(*fee),
+		Gas: fields.GasUsedForL1.ToInt().Uint64(),
+	}, nil
+}
@@ -0,0 +1,159 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newExampleTx(t *testing.T, chain *xc.ChainConfig) *tx.Tx {
+	txBuilder, _ := builder.NewTxBuilder(chain)
+	args, err := xcbuilder.NewTransferArgs("0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8", "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F", xc.NewBigIntFromUint64(1000))
+	require.NoError(t, err)
+	input := tx_input.NewTxInput()
+	input.GasLimit = 21_000
+	trans, err := txBuilder.NewTransfer(args, input)
+	require.NoError(t, err)
+	return trans.(*tx.Tx)
+}
+
+func TestEstimateL1Fee(t *testing.T) {
+	vectors := []struct {
+		name     string
+		model    xc.L1FeeModel
+		resp     interface{}
+		expected *client.L1Fee
+		err      string
+	}{
+		{
+			name:     "no l1 fee",
+			model:    "",
+			resp:     []string{},
+			expected: nil,
+		},
+		{
+			name:  "op-stack",
+			model: xc.L1FeeModelOpStack,
+			resp: []string{
+				`"0x00000000000000000000000000000000000000000000000000000a3ee2c3b6f0"`,
+			},
+			expected: &client.L1Fee{Fee: xc.NewBigIntFromUint64(0xa3ee2c3b6f0)},
+		},
+		{
+			name:  "arbitrum",
+			model: xc.L1FeeModelArbitrum,
+			resp: []string{
+				`"0x` +
+					`00000000000000000000000000000000000000000000000000000000000186a0` +
+					`0000000000000000000000000000000000000000000000000000000000007530` +
+					`0000000000000000000000000000000000000000000000000000000000989680` +
+					`000000000000000000000000000000000000000000000000000000003b9aca00"`,
+			},
+			expected: &client.L1Fee{Fee: xc.NewBigIntFromUint64(30_000 * 10_000_000), Gas: 30_000},
+		},
+		{
+			name:  "unknown",
+			model: "zk-something",
+			resp:  []string{},
+			err:   "unsupported l1 fee model",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, v.resp)
+			defer close()
+			chain := &xc.ChainConfig{
+				Chain:      xc.ETH,
+				ChainID:    10,
+				L1FeeModel: v.model,
+				Client: &xc.ClientConfig{
+					URL: server.URL,
+				},
+			}
+			cli, err := client.NewClient(chain)
+			require.NoError(t, err)
+
+			l1Fee, err := cli.EstimateL1Fee(context.Background(), newExampleTx(t, chain))
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v.expected, l1Fee)
+		})
+	}
+}
+
+func TestParseL1Fee(t *testing.T) {
+	op := &client.OpStackL1FeeComponent{}
+	l1Fee, err := op.ParseL1Fee([]byte(`{"gasUsed":"0x5208","l1Fee":"0x1c6bf52634000"}`))
+	require.NoError(t, err)
+	require.EqualValues(t, "500000000000000", l1Fee.Fee.String())
+	require.EqualValues(t, 0, l1Fee.Gas)
+
+	l1Fee, err = op.ParseL1Fee([]byte(`{"gasUsed":"0x5208"}`))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, l1Fee.Fee.Uint64())
+
+	arb := &client.ArbitrumL1FeeComponent{}
+	l1Fee, err = arb.ParseL1Fee([]byte(`{"gasUsed":"0x1d4c0","gasUsedForL1":"0x7530","effectiveGasPrice":"0x989680"}`))
+	require.NoError(t, err)
+	require.EqualValues(t, big.NewInt(30_000*10_000_000).String(), l1Fee.Fee.String())
+	require.EqualValues(t, 30_000, l1Fee.Gas)
+}
+
+func TestSetL1FeeOracleError(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, errors.New("oracle unavailable"))
+	defer close()
+	chain := &xc.ChainConfig{
+		Chain:      xc.ETH,
+		ChainID:    10,
+		L1FeeModel: xc.L1FeeModelOpStack,
+		Client: &xc.ClientConfig{
+			URL: server.URL,
+		},
+	}
+	cli, err := client.NewClient(chain)
+	require.NoError(t, err)
+
+	// the transfer can still be built, without the l1 fee
+	input := tx_input.NewTxInput()
+	input.L1Fee = xc.NewBigIntFromUint64(0)
+	cli.SetL1Fee(context.Background(), input, newExampleTx(t, chain))
+	require.EqualValues(t, "0", input.L1Fee.String())
+}
+
+func TestSetL1Fee(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, []string{
+		`"0x00000000000000000000000000000000000000000000000000000a3ee2c3b6f0"`,
+	})
+	defer close()
+	chain := &xc.ChainConfig{
+		Chain:      xc.ETH,
+		ChainID:    10,
+		L1FeeModel: xc.L1FeeModelOpStack,
+		Client: &xc.ClientConfig{
+			URL: server.URL,
+		},
+	}
+	cli, err := client.NewClient(chain)
+	require.NoError(t, err)
+
+	input := tx_input.NewTxInput()
+	input.GasLimit = 21_000
+	input.GasFeeCap = xc.NewBigIntFromUint64(1_000_000)
+	cli.SetL1Fee(context.Background(), input, newExampleTx(t, chain))
+	require.EqualValues(t, 0xa3ee2c3b6f0, input.L1Fee.Uint64())
+	// the l1 fee is paid on top of the gas
+	require.EqualValues(t, 21_000*1_000_000+0xa3ee2c3b6f0, input.GetMaxFee().Uint64())
+}
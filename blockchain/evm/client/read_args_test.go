@@ -0,0 +1,71 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	xclient "github.com/CustodyOne/chainkit/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchBalanceAt(t *testing.T) {
+	blockTags := []string{}
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		var tag string
+		require.NoError(t, json.Unmarshal(request.Params[len(request.Params)-1], &tag))
+		blockTags = append(blockTags, request.Method+"@"+tag)
+		switch request.Method {
+		case "eth_call":
+			return `"0x0000000000000000000000000000000000000000000000000000000000000064"`, true
+		case "eth_getTransactionCount":
+			return `"0x7"`, true
+		}
+		return `"0x64"`, true
+	})
+	defer server.Close()
+
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+	address := xc.Address(scanWatched)
+
+	vectors := []struct {
+		options  []xclient.ReadOption
+		contract xc.ContractAddress
+		expected string
+	}{
+		{nil, "", "eth_getBalance@latest"},
+		{[]xclient.ReadOption{xclient.ReadOptionCommitment(xclient.CommitmentConfirmed)}, "", "eth_getBalance@safe"},
+		{[]xclient.ReadOption{xclient.ReadOptionCommitment(xclient.CommitmentFinalized)}, "", "eth_getBalance@finalized"},
+		{[]xclient.ReadOption{xclient.ReadOptionHeight(100)}, "", "eth_getBalance@0x64"},
+		{[]xclient.ReadOption{xclient.ReadOptionCommitment(xclient.CommitmentFinalized)}, scanContract, "eth_call@finalized"},
+	}
+	for _, v := range vectors {
+		blockTags = []string{}
+		args, err := xclient.NewReadArgs(v.options...)
+		require.NoError(t, err)
+		balance, err := cli.FetchBalanceAt(context.Background(), address, v.contract, args)
+		require.NoError(t, err)
+		require.Equal(t, "100", balance.String())
+		require.Equal(t, []string{v.expected}, blockTags)
+	}
+
+	blockTags = []string{}
+	args, err := xclient.NewReadArgs(xclient.ReadOptionCommitment(xclient.CommitmentFinalized))
+	require.NoError(t, err)
+	nonce, err := cli.GetNonceAt(context.Background(), address, args)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, nonce)
+	require.Equal(t, []string{"eth_getTransactionCount@finalized"}, blockTags)
+
+	_, err = xclient.NewReadArgs(xclient.ReadOptionCommitment(xclient.CommitmentFinalized), xclient.ReadOptionHeight(100))
+	require.Error(t, err)
+	_, err = xclient.NewReadArgs(xclient.ReadOptionCommitment("pending"))
+	require.Error(t, err)
+}
@@ -0,0 +1,342 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	xclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+// Number of blocks to query logs for at once, unless the provider rejects the range
+const DefaultScanChunkSize = 1000
+
+// ScanArgs selects the block range and the deposits to look for
+type ScanArgs struct {
+	// First block to scan, e.g. the checkpoint of a previous scan
+	FromBlock uint64
+	// Last block to scan (inclusive).  Defaults to the latest block.
+	ToBlock uint64
+	// Watched addresses that receive deposits
+	Addresses []xc.Address
+	// ERC-20 contracts to look for.  If empty, transfers of any token are included.
+	Contracts []xc.ContractAddress
+	// Maximum number of blocks to query at once, defaults to DefaultScanChunkSize
+	ChunkSize uint64
+	// Skip looking for native transfers, which requires tracing every block
+	TokensOnly bool
+}
+
+type ScanResult struct {
+	// One entry per transaction with a deposit, containing only the transfers to watched addresses
+	Transactions []*xclient.TxInfo
+	// The first block that has not been scanned yet.  Pass as FromBlock to resume.
+	Checkpoint uint64
+}
+
+type rpcBlockHeader struct {
+	Hash      common.Hash    `json:"hash"`
+	Number    hexutil.Uint64 `json:"number"`
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+}
+
+type rpcBlockWithTxHashes struct {
+	rpcBlockHeader
+	Transactions []common.Hash `json:"transactions"`
+}
+
+type rpcBlockWithTxs struct {
+	rpcBlockHeader
+	Transactions []struct {
+		Hash             common.Hash     `json:"hash"`
+		From             common.Address  `json:"from"`
+		To               *common.Address `json:"to"`
+		Value            hexutil.Big     `json:"value"`
+		TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+	} `json:"transactions"`
+}
+
+type deposit struct {
+	block    uint64
+	txIndex  uint64
+	txHash   common.Hash
+	from     common.Address
+	to       common.Address
+	contract xc.ContractAddress
+	amount   xc.BigInt
+}
+
+// ScanDeposits returns all transfers of native asset (including internal transfers) and ERC-20 tokens
+// to the watched addresses in a range of blocks.  If the scan fails part way through, the result
+// contains everything found up to the checkpoint, so it can be resumed from there.
+func (client *Client) ScanDeposits(ctx context.Context, args ScanArgs) (*ScanResult, error) {
+	result := &ScanResult{
+		Transactions: []*xclient.TxInfo{},
+		Checkpoint:   args.FromBlock,
+	}
+	if len(args.Addresses) == 0 {
+		return result, errors.New("must watch at least one address")
+	}
+	watched := map[common.Address]bool{}
+	for _, addr := range args.Addresses {
+		parsed, err := address.FromHex(addr)
+		if err != nil {
+			return result, fmt.Errorf("bad address '%v': %v", addr, err)
+		}
+		watched[parsed] = true
+	}
+	latest, err := client.EthClient.BlockNumber(ctx)
+	if err != nil {
+		return result, fmt.Errorf("could not get latest block: %v", err)
+	}
+	toBlock := args.ToBlock
+	if toBlock == 0 || toBlock > latest {
+		toBlock = latest
+	}
+	maxChunk := args.ChunkSize
+	if maxChunk == 0 {
+		maxChunk = DefaultScanChunkSize
+	}
+
+	chunk := maxChunk
+	tracesSupported := !args.TokensOnly
+	for start := args.FromBlock; start <= toBlock; {
+		end := start + chunk - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		deposits, err := client.scanTokenDeposits(ctx, start, end, watched, args.Contracts)
+		if err != nil {
+			if chunk > 1 && ctx.Err() == nil {
+				// providers limit the range or number of logs returned
+				chunk = chunk / 2
+				zap.S().Debug("reducing log range", zap.Uint64("from", start), zap.Uint64("chunk", chunk), zap.Error(err))
+				continue
+			}
+			return result, err
+		}
+		if !args.TokensOnly {
+			for block := start; block <= end; block++ {
+				var native []*deposit
+				if tracesSupported {
+					native, err = client.scanTracedDeposits(ctx, block, watched)
+					if err != nil && isMethodUnsupported(err) {
+						zap.S().Warn("tracing is not supported, only top level transfers will be scanned",
+							zap.String("chain", string(client.Chain.Chain)),
+							zap.Error(err),
+						)
+						tracesSupported = false
+					}
+				}
+				if !tracesSupported {
+					native, err = client.scanTopLevelDeposits(ctx, block, watched)
+				}
+				if err != nil {
+					return result, err
+				}
+				deposits = append(deposits, native...)
+			}
+		}
+
+		infos, err := client.depositsToTxInfos(ctx, deposits, latest)
+		if err != nil {
+			return result, err
+		}
+		result.Transactions = append(result.Transactions, infos...)
+		result.Checkpoint = end + 1
+		start = end + 1
+		if chunk < maxChunk {
+			chunk = min(chunk*2, maxChunk)
+		}
+	}
+	return result, nil
+}
+
+func (client *Client) scanTokenDeposits(ctx context.Context, fromBlock uint64, toBlock uint64, watched map[common.Address]bool, contracts []xc.ContractAddress) ([]*deposit, error) {
+	recipients := []common.Hash{}
+	for addr := range watched {
+		recipients = append(recipients, common.BytesToHash(addr.Bytes()))
+	}
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Topics: [][]common.Hash{
+			{tx.ERC20.Events["Transfer"].ID},
+			{},
+			recipients,
+		},
+	}
+	for _, contract := range contracts {
+		parsed, err := address.FromHex(xc.Address(contract))
+		if err != nil {
+			return nil, fmt.Errorf("bad contract '%v': %v", contract, err)
+		}
+		query.Addresses = append(query.Addresses, parsed)
+	}
+	logs, err := client.EthClient.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("could not get logs for blocks %d-%d: %v", fromBlock, toBlock, err)
+	}
+	deposits := []*deposit{}
+	for _, log := range logs {
+		// ERC-721 uses the same event signature, but with an indexed token id
+		if log.Removed || len(log.Topics) != 3 || len(log.Data) != 32 {
+			continue
+		}
+		to := common.BytesToAddress(log.Topics[2].Bytes())
+		if !watched[to] {
+			continue
+		}
+		deposits = append(deposits, &deposit{
+			block:    log.BlockNumber,
+			txIndex:  uint64(log.TxIndex),
+			txHash:   log.TxHash,
+			from:     common.BytesToAddress(log.Topics[1].Bytes()),
+			to:       to,
+			contract: xc.ContractAddress(log.Address.String()),
+			amount:   xc.BigInt(*new(big.Int).SetBytes(log.Data)),
+		})
+	}
+	return deposits, nil
+}
+
+// Traces the block to find native transfers, including internal transactions
+func (client *Client) scanTracedDeposits(ctx context.Context, block uint64, watched map[common.Address]bool) ([]*deposit, error) {
+	traces, err := client.TraceBlock(ctx, block)
+	if err != nil {
+		return nil, fmt.Errorf("could not trace block %d: %w", block, err)
+	}
+	deposits := []*deposit{}
+	for i, trace := range traces {
+		if trace.Result == nil {
+			continue
+		}
+		executed := pruneRevertedCalls(trace.Result)
+		if executed == nil {
+			continue
+		}
+		for _, call := range FlattenTraceResult(executed, []*TraceTransactionResult{}) {
+			if !movesValue(call) || !watched[call.To] {
+				continue
+			}
+			deposits = append(deposits, &deposit{
+				block:   block,
+				txIndex: uint64(i),
+				txHash:  trace.TxHash,
+				from:    call.From,
+				to:      call.To,
+				amount:  xc.BigInt(*call.Value.ToInt()),
+			})
+		}
+	}
+	return deposits, nil
+}
+
+// Fallback for nodes without tracing, which can only see the value of the transactions themselves
+func (client *Client) scanTopLevelDeposits(ctx context.Context, block uint64, watched map[common.Address]bool) ([]*deposit, error) {
+	var result rpcBlockWithTxs
+	err := client.EthClient.Client().CallContext(ctx, &result, "eth_getBlockByNumber", hexutil.EncodeUint64(block), true)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block %d: %v", block, err)
+	}
+	deposits := []*deposit{}
+	for _, trans := range result.Transactions {
+		if trans.To == nil || !watched[*trans.To] || trans.Value.ToInt().Sign() <= 0 {
+			continue
+		}
+		receipt, err := client.EthClient.TransactionReceipt(ctx, trans.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("could not get receipt for %s: %v", trans.Hash.Hex(), err)
+		}
+		if receipt.Status == 0 {
+			continue
+		}
+		deposits = append(deposits, &deposit{
+			block:   block,
+			txIndex: uint64(trans.TransactionIndex),
+			txHash:  trans.Hash,
+			from:    trans.From,
+			to:      *trans.To,
+			amount:  xc.BigInt(*trans.Value.ToInt()),
+		})
+	}
+	return deposits, nil
+}
+
+func (client *Client) depositsToTxInfos(ctx context.Context, deposits []*deposit, latest uint64) ([]*xclient.TxInfo, error) {
+	sort.SliceStable(deposits, func(i, j int) bool {
+		if deposits[i].block != deposits[j].block {
+			return deposits[i].block < deposits[j].block
+		}
+		return deposits[i].txIndex < deposits[j].txIndex
+	})
+	chain := client.Chain.Chain
+	headers := map[uint64]*rpcBlockHeader{}
+	infos := []*xclient.TxInfo{}
+	var info *xclient.TxInfo
+	for _, dep := range deposits {
+		header, ok := headers[dep.block]
+		if !ok {
+			header = &rpcBlockHeader{}
+			err := client.EthClient.Client().CallContext(ctx, header, "eth_getBlockByNumber", hexutil.EncodeUint64(dep.block), false)
+			if err != nil {
+				return nil, fmt.Errorf("could not get block %d: %v", dep.block, err)
+			}
+			headers[dep.block] = header
+		}
+		if info == nil || info.Hash != dep.txHash.Hex() {
+			block := xclient.NewBlock(dep.block, header.Hash.Hex(), time.Unix(int64(header.Timestamp), 0))
+			info = xclient.NewTxInfo(block, chain, dep.txHash.Hex(), latest-dep.block, nil)
+			infos = append(infos, info)
+		}
+		info.AddSimpleTransfer(xc.Address(dep.from.String()), xc.Address(dep.to.String()), dep.contract, dep.amount, nil, "")
+	}
+	return infos, nil
+}
+
+// Drop calls that were reverted, along with everything they called
+func pruneRevertedCalls(result *TraceTransactionResult) *TraceTransactionResult {
+	if result.Error != "" {
+		return nil
+	}
+	pruned := *result
+	pruned.Calls = []*TraceTransactionResult{}
+	for _, call := range result.Calls {
+		if inner := pruneRevertedCalls(call); inner != nil {
+			pruned.Calls = append(pruned.Calls, inner)
+		}
+	}
+	return &pruned
+}
+
+func movesValue(call *TraceTransactionResult) bool {
+	switch strings.ToUpper(string(call.Type)) {
+	case string(DELEGATE_CALL), "STATICCALL", "CALLCODE":
+		return false
+	}
+	return call.Value.ToInt().Sign() > 0
+}
+
+func isMethodUnsupported(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "method not found") ||
+		strings.Contains(msg, "does not exist") ||
+		strings.Contains(msg, "not supported") ||
+		strings.Contains(msg, "not available")
+}
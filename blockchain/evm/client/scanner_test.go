@@ -0,0 +1,206 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	scanWatched  = "0x388C818CA8B9251b393131C08a736A67ccB19297"
+	scanSender   = "0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8"
+	scanContract = "0x779877A7B0D9E8603169DdbD7836e478b4624789"
+	scanTokenTx  = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	scanNftTx    = "0x2222222222222222222222222222222222222222222222222222222222222222"
+	scanNativeTx = "0x3333333333333333333333333333333333333333333333333333333333333333"
+	scanFailedTx = "0x4444444444444444444444444444444444444444444444444444444444444444"
+)
+
+func topicAddress(addr string) string {
+	return "0x000000000000000000000000" + strings.ToLower(addr[2:])
+}
+
+// Responds by method, rejecting log queries over more than 4 blocks like a capped provider
+func mockScanRpc(t *testing.T, traceSupported bool) (*httptest.Server, *[]string) {
+	logRanges := []string{}
+	transferTopic := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	logs := []string{
+		fmt.Sprintf(`{"address":"%s","topics":["%s","%s","%s"],"data":"0x00000000000000000000000000000000000000000000000000000000000003e8","blockNumber":"0x3","transactionHash":"%s","transactionIndex":"0x1","blockHash":"0x0000000000000000000000000000000000000000000000000000000000000003","logIndex":"0x0","removed":false}`,
+			scanContract, transferTopic, topicAddress(scanSender), topicAddress(scanWatched), scanTokenTx),
+		// erc-721 transfer
+		fmt.Sprintf(`{"address":"%s","topics":["%s","%s","%s","0x0000000000000000000000000000000000000000000000000000000000000001"],"data":"0x","blockNumber":"0x3","transactionHash":"%s","transactionIndex":"0x2","blockHash":"0x0000000000000000000000000000000000000000000000000000000000000003","logIndex":"0x1","removed":false}`,
+			scanContract, transferTopic, topicAddress(scanSender), topicAddress(scanWatched), scanNftTx),
+	}
+	blockTrace := fmt.Sprintf(`[
+		{"txHash":"%s","result":{"type":"CALL","from":"%s","to":"%s","value":"0x0","calls":[
+			{"type":"CALL","from":"%s","to":"%s","value":"0xde0b6b3a7640000"},
+			{"type":"CALL","from":"%s","to":"%s","value":"0x1","error":"execution reverted"},
+			{"type":"DELEGATECALL","from":"%s","to":"%s","value":"0x5"}
+		]}},
+		{"txHash":"%s","result":{"type":"CALL","from":"%s","to":"%s","value":"0x10","error":"out of gas"}}
+	]`,
+		scanNativeTx, scanSender, scanContract,
+		scanContract, scanWatched,
+		scanContract, scanWatched,
+		scanContract, scanWatched,
+		scanFailedTx, scanSender, scanWatched,
+	)
+
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		switch request.Method {
+		case "eth_blockNumber":
+			return `"0x64"`, true
+		case "eth_getLogs":
+			var filter struct {
+				FromBlock hexutil.Uint64 `json:"fromBlock"`
+				ToBlock   hexutil.Uint64 `json:"toBlock"`
+			}
+			require.NoError(t, json.Unmarshal(request.Params[0], &filter))
+			logRanges = append(logRanges, fmt.Sprintf("%d-%d", filter.FromBlock, filter.ToBlock))
+			if filter.ToBlock-filter.FromBlock >= 4 {
+				return `error:{"code":-32005,"message":"query returned more than 10000 results"}`, true
+			}
+			matched := []string{}
+			if filter.FromBlock <= 3 && filter.ToBlock >= 3 {
+				matched = logs
+			}
+			return "[" + strings.Join(matched, ",") + "]", true
+		case "trace_block":
+			return "", false
+		case "debug_traceBlockByNumber":
+			if !traceSupported {
+				return "", false
+			}
+			var block string
+			require.NoError(t, json.Unmarshal(request.Params[0], &block))
+			if block == "0x5" {
+				return blockTrace, true
+			}
+			return "[]", true
+		case "eth_getBlockByNumber":
+			var block string
+			require.NoError(t, json.Unmarshal(request.Params[0], &block))
+			number, _ := strconv.ParseUint(block[2:], 16, 64)
+			txs := "[]"
+			if block == "0x5" {
+				txs = fmt.Sprintf(`[{"hash":"%s","from":"%s","to":"%s","value":"0x7","transactionIndex":"0x0"}]`, scanNativeTx, scanSender, scanWatched)
+			}
+			return fmt.Sprintf(`{"hash":"0x%064x","number":"%s","timestamp":"0x%x","transactions":%s}`, number, block, 1700000000+number*12, txs), true
+		case "eth_getTransactionReceipt":
+			return fmt.Sprintf(`{"status":"0x1","transactionHash":"%s","cumulativeGasUsed":"0x5208","logsBloom":"0x%0512x","logs":[],"gasUsed":"0x5208","blockNumber":"0x5"}`, scanNativeTx, 0), true
+		}
+		require.Fail(t, "unexpected method "+request.Method)
+		return "", false
+	})
+	return server, &logRanges
+}
+
+func TestScanDeposits(t *testing.T) {
+	server, logRanges := mockScanRpc(t, true)
+	defer server.Close()
+
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+
+	result, err := cli.ScanDeposits(context.Background(), client.ScanArgs{
+		FromBlock: 1,
+		ToBlock:   10,
+		Addresses: []xc.Address{scanWatched},
+		ChunkSize: 8,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 11, result.Checkpoint)
+	require.Equal(t, []string{"1-8", "1-4", "5-10", "5-8", "9-10"}, *logRanges)
+
+	require.Len(t, result.Transactions, 2)
+	token := result.Transactions[0]
+	require.Equal(t, scanTokenTx, token.Hash)
+	require.EqualValues(t, 3, token.Block.Height)
+	require.EqualValues(t, 1700000036, token.Block.Time.Unix())
+	require.EqualValues(t, 97, token.Confirmations)
+	require.Len(t, token.Transfers, 1)
+	require.EqualValues(t, scanContract, token.Transfers[0].To[0].Contract)
+	require.Equal(t, "1000", token.Transfers[0].To[0].Balance.String())
+	require.Contains(t, string(token.Transfers[0].To[0].Address), strings.ToLower(scanWatched))
+
+	native := result.Transactions[1]
+	require.Equal(t, scanNativeTx, native.Hash)
+	require.EqualValues(t, 5, native.Block.Height)
+	// only the executed internal call
+	require.Len(t, native.Transfers, 1)
+	require.Equal(t, "1000000000000000000", native.Transfers[0].To[0].Balance.String())
+	require.Contains(t, string(native.Transfers[0].From[0].Address), strings.ToLower(scanContract))
+}
+
+func TestScanDepositsResume(t *testing.T) {
+	server, _ := mockScanRpc(t, true)
+	defer server.Close()
+
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+
+	args := client.ScanArgs{
+		FromBlock: 1,
+		ToBlock:   4,
+		Addresses: []xc.Address{scanWatched},
+		ChunkSize: 2,
+	}
+	first, err := cli.ScanDeposits(context.Background(), args)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, first.Checkpoint)
+	require.Len(t, first.Transactions, 1)
+
+	args.FromBlock = first.Checkpoint
+	args.ToBlock = 0
+	second, err := cli.ScanDeposits(context.Background(), args)
+	require.NoError(t, err)
+	require.EqualValues(t, 101, second.Checkpoint)
+	require.Len(t, second.Transactions, 1)
+	require.Equal(t, scanNativeTx, second.Transactions[0].Hash)
+}
+
+func TestScanDepositsWithoutTraces(t *testing.T) {
+	server, _ := mockScanRpc(t, false)
+	defer server.Close()
+
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+
+	result, err := cli.ScanDeposits(context.Background(), client.ScanArgs{
+		FromBlock: 5,
+		ToBlock:   6,
+		Addresses: []xc.Address{scanWatched},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Transactions, 1)
+	// falls back to the value of the transaction itself
+	require.Equal(t, "7", result.Transactions[0].Transfers[0].To[0].Balance.String())
+
+	result, err = cli.ScanDeposits(context.Background(), client.ScanArgs{
+		FromBlock:  5,
+		ToBlock:    6,
+		Addresses:  []xc.Address{scanWatched},
+		TokensOnly: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Transactions, 0)
+}
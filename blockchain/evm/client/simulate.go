@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	xclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+// StateOverride replaces the state of accounts while simulating, e.g. to fund the sender
+type StateOverride map[common.Address]OverrideAccount
+
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code      *hexutil.Bytes              `json:"code,omitempty"`
+	Balance   *hexutil.Big                `json:"balance,omitempty"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+// Arguments for eth_call and debug_traceCall
+type callArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Data                 hexutil.Bytes   `json:"data"`
+}
+
+func newCallArgs(from common.Address, ethTx *types.Transaction) callArgs {
+	args := callArgs{
+		From:  from,
+		To:    ethTx.To(),
+		Gas:   hexutil.Uint64(ethTx.Gas()),
+		Value: (*hexutil.Big)(ethTx.Value()),
+		Data:  ethTx.Data(),
+	}
+	if ethTx.Type() == types.LegacyTxType {
+		args.GasPrice = (*hexutil.Big)(ethTx.GasPrice())
+	} else {
+		args.MaxFeePerGas = (*hexutil.Big)(ethTx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(ethTx.GasTipCap())
+	}
+	return args
+}
+
+// Simulate predicts the balance changes and fee of a transaction against the latest block.
+func (client *Client) Simulate(ctx context.Context, trans xc.Tx) (*xclient.TxInfo, error) {
+	return client.SimulateWithOverrides(ctx, trans, nil)
+}
+
+// SimulateWithOverrides simulates using debug_traceCall so internal transfers and token transfers
+// are included, falling back to eth_call on nodes without tracing.
+func (client *Client) SimulateWithOverrides(ctx context.Context, trans xc.Tx, overrides StateOverride) (*xclient.TxInfo, error) {
+	ethTx, ok := trans.(*tx.Tx)
+	if !ok || ethTx.EthTx == nil {
+		return nil, fmt.Errorf("unsupported transaction type %T", trans)
+	}
+	from, err := ethTx.RecoverSender()
+	if err != nil {
+		if ethTx.Sender == (common.Address{}) {
+			return nil, errors.New("cannot simulate an unsigned transaction without a sender")
+		}
+		from = ethTx.Sender
+	}
+	args := newCallArgs(from, ethTx.EthTx)
+
+	var result TraceTransactionResult
+	err = client.EthClient.Client().CallContext(ctx, &result, "debug_traceCall", args, "latest", &TraceTransactionArgs{
+		Tracer:         "callTracer",
+		TracerConfig:   map[string]any{"withLog": true},
+		StateOverrides: overrides,
+	})
+	var info *xclient.TxInfo
+	var gasUsed uint64
+	if err == nil {
+		info, gasUsed = client.simulatedTraceToTxInfo(ethTx, &result)
+	} else if isMethodUnsupported(err) {
+		zap.S().Debug("debug_traceCall is not supported, simulating with eth_call", zap.Error(err))
+		info, gasUsed, err = client.simulateWithCall(ctx, ethTx, args, overrides)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("could not simulate tx: %v", err)
+	}
+
+	header, err := client.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch latest header: %v", err)
+	}
+	baseFee := uint64(0)
+	if header.BaseFee != nil {
+		baseFee = header.BaseFee.Uint64()
+	}
+	fee := ethTx.Fee(baseFee, gasUsed)
+	info.AddFee(xc.Address(from.String()), "", fee, nil)
+	info.Fees = info.CalculateFees()
+	return info, nil
+}
+
+func (client *Client) simulatedTraceToTxInfo(ethTx *tx.Tx, result *TraceTransactionResult) (*xclient.TxInfo, uint64) {
+	chain := client.Chain.Chain
+	var errMsg *string
+	if result.Error != "" {
+		msg := result.Error
+		if result.RevertReason != "" {
+			msg = fmt.Sprintf("%s: %s", result.Error, result.RevertReason)
+		}
+		errMsg = &msg
+	}
+	info := xclient.NewSimulatedTxInfo(chain, string(ethTx.Hash()), errMsg)
+
+	executed := pruneRevertedCalls(result)
+	if executed == nil {
+		return info, uint64(result.GasUsed)
+	}
+	transferEvent := tx.ERC20.Events["Transfer"].ID
+	for _, call := range FlattenTraceResult(executed, []*TraceTransactionResult{}) {
+		if movesValue(call) {
+			info.AddSimpleTransfer(xc.Address(call.From.String()), xc.Address(call.To.String()), "", xc.BigInt(*call.Value.ToInt()), nil, "")
+		}
+		for _, log := range call.Logs {
+			// erc-721 transfers share the signature but index the token id
+			if len(log.Topics) != 3 || log.Topics[0] != transferEvent || len(log.Data) != 32 {
+				continue
+			}
+			info.AddSimpleTransfer(
+				xc.Address(common.BytesToAddress(log.Topics[1].Bytes()).String()),
+				xc.Address(common.BytesToAddress(log.Topics[2].Bytes()).String()),
+				xc.ContractAddress(log.Address.String()),
+				xc.BigInt(*new(big.Int).SetBytes(log.Data)),
+				nil,
+				"",
+			)
+		}
+	}
+	return info, uint64(result.GasUsed)
+}
+
+// Without tracing, only the value of the transaction and erc-20 transfer calls can be predicted
+func (client *Client) simulateWithCall(ctx context.Context, ethTx *tx.Tx, args callArgs, overrides StateOverride) (*xclient.TxInfo, uint64, error) {
+	chain := client.Chain.Chain
+	var output hexutil.Bytes
+	callParams := []any{args, "latest"}
+	if len(overrides) > 0 {
+		callParams = append(callParams, overrides)
+	}
+	err := client.EthClient.Client().CallContext(ctx, &output, "eth_call", callParams...)
+	if err != nil && !isRevert(err) {
+		// e.g. rate limits or unsupported parameters, which say nothing about the transaction
+		return nil, 0, fmt.Errorf("could not simulate tx: %v", err)
+	}
+	if err != nil {
+		// the transaction reverted, assume all of the gas is used
+		msg := err.Error()
+		return xclient.NewSimulatedTxInfo(chain, string(ethTx.Hash()), &msg), ethTx.EthTx.Gas(), nil
+	}
+
+	info := xclient.NewSimulatedTxInfo(chain, string(ethTx.Hash()), nil)
+	from := xc.Address(args.From.String())
+	if ethTx.EthTx.Value().Sign() > 0 && args.To != nil {
+		info.AddSimpleTransfer(from, xc.Address(args.To.String()), "", xc.BigInt(*ethTx.EthTx.Value()), nil, "")
+	}
+	if movements, err := ethTx.ParseERC20TransferTx(chain); err == nil {
+		for _, dest := range movements.Destinations {
+			info.AddSimpleTransfer(from, dest.Address, dest.ContractAddress, dest.Amount, nil, "")
+		}
+	}
+
+	var gasUsed hexutil.Uint64
+	err = client.EthClient.Client().CallContext(ctx, &gasUsed, "eth_estimateGas", args)
+	if err != nil {
+		zap.S().Debug("could not estimate gas, using the limit of the tx", zap.Error(err))
+		gasUsed = hexutil.Uint64(ethTx.EthTx.Gas())
+	}
+	return info, uint64(gasUsed), nil
+}
+
+// Nodes report reverts with code 3, or for older nodes just the message
+func isRevert(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return rpcErr.ErrorCode() == 3 || strings.Contains(strings.ToLower(rpcErr.Error()), "execution reverted")
+}
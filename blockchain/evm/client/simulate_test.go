@@ -0,0 +1,188 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+const simulatedHeader = `{"parentHash":"0x0000000000000000000000000000000000000000000000000000000000000000","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","miner":"0x0000000000000000000000000000000000000000","stateRoot":"0x0000000000000000000000000000000000000000000000000000000000000000","transactionsRoot":"0x0000000000000000000000000000000000000000000000000000000000000000","receiptsRoot":"0x0000000000000000000000000000000000000000000000000000000000000000","logsBloom":"0x%0512x","difficulty":"0x0","number":"0x64","gasLimit":"0x1c9c380","gasUsed":"0x0","timestamp":"0x6553f100","extraData":"0x","mixHash":"0x0000000000000000000000000000000000000000000000000000000000000000","nonce":"0x0000000000000000","baseFeePerGas":"0x3b9aca00","hash":"0x0000000000000000000000000000000000000000000000000000000000000064"}`
+
+// Responds by method, with debug_traceCall optionally unsupported
+func mockSimulateRpc(t *testing.T, responses map[string]string) (*httptest.Server, *[]json.RawMessage) {
+	traceConfigs := []json.RawMessage{}
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		if request.Method == "debug_traceCall" {
+			traceConfigs = append(traceConfigs, request.Params[2])
+		}
+		if request.Method == "eth_getBlockByNumber" {
+			return fmt.Sprintf(simulatedHeader, 0), true
+		}
+		result, ok := responses[request.Method]
+		return result, ok
+	})
+	return server, &traceConfigs
+}
+
+func buildSimulatedTransfer(t *testing.T, asset xc.IAsset) xc.Tx {
+	txBuilder, err := builder.NewTxBuilder(&xc.ChainConfig{Chain: xc.ETH, ChainID: 1})
+	require.NoError(t, err)
+	input := tx_input.NewTxInput()
+	input.GasLimit = 100_000
+	input.GasFeeCap = xc.NewBigIntFromUint64(3_000_000_000)
+	input.GasTipCap = xc.NewBigIntFromUint64(1_000_000_000)
+	options := []xcbuilder.BuilderOption{}
+	if asset != nil {
+		options = append(options, xcbuilder.WithAsset(asset))
+	}
+	args, err := xcbuilder.NewTransferArgs(scanSender, scanWatched, xc.NewBigIntFromUint64(1000), options...)
+	require.NoError(t, err)
+	trans, err := txBuilder.NewTransfer(args, input)
+	require.NoError(t, err)
+	return trans
+}
+
+func TestSimulate(t *testing.T) {
+	transferTopic := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	server, traceConfigs := mockSimulateRpc(t, map[string]string{
+		"debug_traceCall": fmt.Sprintf(`{"type":"CALL","from":"%s","to":"%s","value":"0x0","gasUsed":"0x7530","logs":[
+			{"address":"%s","topics":["%s","%s","%s"],"data":"0x00000000000000000000000000000000000000000000000000000000000003e8"}
+		],"calls":[
+			{"type":"CALL","from":"%s","to":"%s","value":"0x5"},
+			{"type":"CALL","from":"%s","to":"%s","value":"0x6","error":"execution reverted"}
+		]}`,
+			scanSender, scanContract,
+			scanContract, transferTopic, topicAddress(scanSender), topicAddress(scanWatched),
+			scanContract, scanWatched,
+			scanContract, scanWatched,
+		),
+	})
+	defer server.Close()
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+
+	// unsigned
+	trans := buildSimulatedTransfer(t, &xc.TokenAssetConfig{Chain: xc.ETH, Contract: scanContract, Decimals: 18})
+	info, err := cli.Simulate(context.Background(), trans)
+	require.NoError(t, err)
+	require.Nil(t, info.Error)
+	require.EqualValues(t, 0, info.Block.Height)
+
+	// token transfer, internal native transfer, and fee
+	require.Len(t, info.Transfers, 3)
+	require.EqualValues(t, scanContract, info.Transfers[0].To[0].Contract)
+	require.Equal(t, "1000", info.Transfers[0].To[0].Balance.String())
+	require.EqualValues(t, xc.ETH, info.Transfers[1].To[0].Contract)
+	require.Equal(t, "5", info.Transfers[1].To[0].Balance.String())
+	require.Len(t, info.Fees, 1)
+	// 30000 gas at min(3 gwei, 1 gwei base fee + 1 gwei tip)
+	require.Equal(t, new(big.Int).Mul(big.NewInt(30000), big.NewInt(2_000_000_000)).String(), info.Fees[0].Balance.String())
+
+	require.Len(t, *traceConfigs, 1)
+	require.JSONEq(t, `{"tracer":"callTracer","tracerConfig":{"withLog":true}}`, string((*traceConfigs)[0]))
+}
+
+func TestSimulateFailure(t *testing.T) {
+	server, _ := mockSimulateRpc(t, map[string]string{
+		"debug_traceCall": fmt.Sprintf(`{"type":"CALL","from":"%s","to":"%s","value":"0x0","gasUsed":"0x5208","error":"execution reverted","revertReason":"ERC20: transfer amount exceeds balance","calls":[]}`,
+			scanSender, scanContract),
+	})
+	defer server.Close()
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+
+	trans := buildSimulatedTransfer(t, &xc.TokenAssetConfig{Chain: xc.ETH, Contract: scanContract, Decimals: 18})
+	info, err := cli.Simulate(context.Background(), trans)
+	require.NoError(t, err)
+	require.NotNil(t, info.Error)
+	require.Equal(t, "execution reverted: ERC20: transfer amount exceeds balance", *info.Error)
+	// only the fee is paid
+	require.Len(t, info.Transfers, 1)
+	require.Len(t, info.Fees, 1)
+}
+
+func TestSimulateWithoutTraces(t *testing.T) {
+	server, _ := mockSimulateRpc(t, map[string]string{
+		"eth_call":        `"0x"`,
+		"eth_estimateGas": `"0x5208"`,
+	})
+	defer server.Close()
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+
+	trans := buildSimulatedTransfer(t, nil)
+	info, err := cli.Simulate(context.Background(), trans)
+	require.NoError(t, err)
+	require.Nil(t, info.Error)
+	require.Len(t, info.Transfers, 2)
+	require.Equal(t, "1000", info.Transfers[0].To[0].Balance.String())
+	require.Equal(t, new(big.Int).Mul(big.NewInt(21000), big.NewInt(2_000_000_000)).String(), info.Fees[0].Balance.String())
+
+	server, _ = mockSimulateRpc(t, map[string]string{
+		"eth_call": `error:{"code":3,"message":"execution reverted: paused","data":"0x"}`,
+	})
+	defer server.Close()
+	cli, err = client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+	info, err = cli.Simulate(context.Background(), trans)
+	require.NoError(t, err)
+	require.Equal(t, "execution reverted: paused", *info.Error)
+}
+
+func TestSimulateCallError(t *testing.T) {
+	trans := buildSimulatedTransfer(t, nil)
+	vectors := []struct {
+		name   string
+		err    string
+		revert string
+	}{
+		{"revert", `{"code":3,"message":"execution reverted: paused","data":"0x"}`, "execution reverted: paused"},
+		{"revert without code", `{"code":-32000,"message":"execution reverted"}`, "execution reverted"},
+		{"rate limited", `{"code":-32005,"message":"limit exceeded"}`, ""},
+		{"bad params", `{"code":-32602,"message":"invalid argument 2: state override is not supported"}`, ""},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, _ := mockSimulateRpc(t, map[string]string{
+				"eth_call": "error:" + v.err,
+			})
+			defer server.Close()
+			cli, err := client.NewClient(&xc.ChainConfig{
+				Chain:  xc.ETH,
+				Client: &xc.ClientConfig{URL: server.URL},
+			})
+			require.NoError(t, err)
+			info, err := cli.Simulate(context.Background(), trans)
+			if v.revert == "" {
+				// the node failed, not the transaction
+				require.ErrorContains(t, err, "could not simulate tx")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v.revert, *info.Error)
+		})
+	}
+}
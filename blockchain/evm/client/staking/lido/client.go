@@ -0,0 +1,288 @@
+package lido
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/abi/lido"
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	evmclient "github.com/CustodyOne/chainkit/blockchain/evm/client"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+)
+
+// Client for liquid staking with Lido.  Staked ether is held as stETH (or wrapped as wstETH),
+// and is redeemed through the withdrawal queue, which issues an NFT for each request.
+type Client struct {
+	rpcClient *evmclient.Client
+	chain     *xc_types.ChainConfig
+	contracts *builder.LidoContracts
+}
+
+var _ xcclient.StakingClient = &Client{}
+
+func NewClient(rpcClient *evmclient.Client, chain *xc_types.ChainConfig) (xcclient.StakingClient, error) {
+	contracts, err := builder.NewLidoContracts(chain)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rpcClient, chain, contracts}, nil
+}
+
+// FetchStakeBalance reports stETH and wstETH (in stETH) as active, unfinalized withdrawal requests as
+// deactivating, and finalized requests that are not yet claimed as inactive.  Each withdrawal
+// request is reported separately, with its NFT ID as the account.
+func (cli *Client) FetchStakeBalance(ctx context.Context, args xcclient.StakedBalanceArgs) ([]*xcclient.StakedBalance, error) {
+	owner, err := address.FromHex(args.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	balances := []*xcclient.StakedBalance{}
+
+	stEthBalance, err := cli.callUint(ctx, cli.contracts.StEth.String(), "balanceOf", owner)
+	if err != nil {
+		return nil, err
+	}
+	if stEthBalance.Sign() > 0 {
+		balances = append(balances, xcclient.NewStakedBalance(xc_types.BigInt(*stEthBalance), xcclient.Active, cli.contracts.StEth.String(), ""))
+	}
+
+	wstEthBalance, err := cli.callUint(ctx, cli.contracts.WstEth.String(), "balanceOf", owner)
+	if err != nil {
+		return nil, err
+	}
+	if wstEthBalance.Sign() > 0 {
+		wrapped, err := cli.callUint(ctx, cli.contracts.WstEth.String(), "getStETHByWstETH", wstEthBalance)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, xcclient.NewStakedBalance(xc_types.BigInt(*wrapped), xcclient.Active, cli.contracts.WstEth.String(), ""))
+	}
+
+	requestIds, statuses, err := cli.FetchWithdrawalRequests(ctx, args.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	for i, status := range statuses {
+		if status.IsClaimed {
+			continue
+		}
+		state := xcclient.Deactivating
+		if status.IsFinalized {
+			state = xcclient.Inactive
+		}
+		balances = append(balances, xcclient.NewStakedBalance(xc_types.BigInt(*status.AmountOfStETH), state, cli.contracts.WithdrawalQueue.String(), requestIds[i].String()))
+	}
+	return balances, nil
+}
+
+// FetchWithdrawalRequests returns the IDs of the withdrawal NFTs held by the owner and their status
+func (cli *Client) FetchWithdrawalRequests(ctx context.Context, owner xc_types.Address) ([]*big.Int, []lido.WithdrawalRequestStatus, error) {
+	ownerAddr, err := address.FromHex(owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	requestIds, err := cli.callUints(ctx, cli.contracts.WithdrawalQueue.String(), "getWithdrawalRequests", ownerAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(requestIds) == 0 {
+		return requestIds, nil, nil
+	}
+	// the status must be requested in ascending order
+	sort.Slice(requestIds, func(i, j int) bool {
+		return requestIds[i].Cmp(requestIds[j]) < 0
+	})
+	values, err := cli.call(ctx, cli.contracts.WithdrawalQueue.String(), "getWithdrawalStatus", requestIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	statuses := lido.ToWithdrawalStatuses(values[0])
+	if len(statuses) != len(requestIds) {
+		return nil, nil, fmt.Errorf("expected %d withdrawal statuses, got %d", len(requestIds), len(statuses))
+	}
+	return requestIds, statuses, nil
+}
+
+func (cli *Client) FetchStakingInput(ctx context.Context, args xcbuilder.StakeArgs) (xc_types.StakeTxInput, error) {
+	stakingInput := tx_input.NewLidoStakeInput()
+	err := cli.simulate(ctx, args.GetFrom(), &stakingInput.TxInput, func(txBuilder builder.TxBuilder) (xc_types.Tx, error) {
+		return txBuilder.Stake(args, stakingInput)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stakingInput, nil
+}
+
+// FetchUnstakingInput prepares a withdrawal request for the stETH amount.  The withdrawal queue
+// must first be approved to spend the stETH, see FetchApprovalInput.
+func (cli *Client) FetchUnstakingInput(ctx context.Context, args xcbuilder.StakeArgs) (xc_types.UnstakeTxInput, error) {
+	from, err := address.FromHex(args.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	allowance, err := cli.callUint(ctx, cli.contracts.StEth.String(), "allowance", from, cli.contracts.WithdrawalQueue)
+	if err != nil {
+		return nil, err
+	}
+	amount := args.GetAmount()
+	if allowance.Cmp(amount.Int()) < 0 {
+		return nil, fmt.Errorf("the withdrawal queue %s is approved for %s stETH, must approve at least %s first", cli.contracts.WithdrawalQueue, allowance, amount.String())
+	}
+
+	unstakingInput := tx_input.NewLidoUnstakeInput()
+	err = cli.simulate(ctx, args.GetFrom(), &unstakingInput.TxInput, func(txBuilder builder.TxBuilder) (xc_types.Tx, error) {
+		return txBuilder.Unstake(args, unstakingInput)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return unstakingInput, nil
+}
+
+// FetchWithdrawInput claims all finalized withdrawal requests, or only the request
+// set as the stake account.
+func (cli *Client) FetchWithdrawInput(ctx context.Context, args xcbuilder.StakeArgs) (xc_types.WithdrawTxInput, error) {
+	requestIds, statuses, err := cli.FetchWithdrawalRequests(ctx, args.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	onlyRequest, filtered := args.GetStakeAccount()
+	claimable := []*big.Int{}
+	for i, status := range statuses {
+		if !status.IsFinalized || status.IsClaimed {
+			continue
+		}
+		if filtered && requestIds[i].String() != onlyRequest {
+			continue
+		}
+		claimable = append(claimable, requestIds[i])
+	}
+	if len(claimable) == 0 {
+		return nil, fmt.Errorf("no finalized withdrawal requests to claim for %s", args.GetFrom())
+	}
+
+	lastIndex, err := cli.callUint(ctx, cli.contracts.WithdrawalQueue.String(), "getLastCheckpointIndex")
+	if err != nil {
+		return nil, err
+	}
+	hints, err := cli.callUints(ctx, cli.contracts.WithdrawalQueue.String(), "findCheckpointHints", claimable, big.NewInt(1), lastIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(hints) != len(claimable) {
+		return nil, fmt.Errorf("expected %d checkpoint hints, got %d", len(claimable), len(hints))
+	}
+
+	withdrawInput := tx_input.NewLidoWithdrawInput()
+	for i := range claimable {
+		withdrawInput.RequestIds = append(withdrawInput.RequestIds, xc_types.BigInt(*claimable[i]))
+		withdrawInput.Hints = append(withdrawInput.Hints, xc_types.BigInt(*hints[i]))
+	}
+	err = cli.simulate(ctx, args.GetFrom(), &withdrawInput.TxInput, func(txBuilder builder.TxBuilder) (xc_types.Tx, error) {
+		return txBuilder.Withdraw(args, withdrawInput)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawInput, nil
+}
+
+// FetchApprovalInput prepares approving the spender (the withdrawal queue or wstETH) for stETH
+func (cli *Client) FetchApprovalInput(ctx context.Context, from xc_types.Address, spender xc_types.Address, amount xc_types.BigInt) (*tx_input.TxInput, error) {
+	input := tx_input.NewTxInput()
+	err := cli.simulate(ctx, from, input, func(txBuilder builder.TxBuilder) (xc_types.Tx, error) {
+		return txBuilder.NewLidoApproval(from, spender, amount, input)
+	})
+	return input, err
+}
+
+// FetchWrapInput prepares wrapping stETH as wstETH.  The wstETH contract must first be approved to spend the stETH.
+func (cli *Client) FetchWrapInput(ctx context.Context, from xc_types.Address, amount xc_types.BigInt) (*tx_input.TxInput, error) {
+	input := tx_input.NewTxInput()
+	err := cli.simulate(ctx, from, input, func(txBuilder builder.TxBuilder) (xc_types.Tx, error) {
+		return txBuilder.NewLidoWrap(from, amount, input)
+	})
+	return input, err
+}
+
+// FetchUnwrapInput prepares unwrapping wstETH to stETH
+func (cli *Client) FetchUnwrapInput(ctx context.Context, from xc_types.Address, amount xc_types.BigInt) (*tx_input.TxInput, error) {
+	input := tx_input.NewTxInput()
+	err := cli.simulate(ctx, from, input, func(txBuilder builder.TxBuilder) (xc_types.Tx, error) {
+		return txBuilder.NewLidoUnwrap(from, amount, input)
+	})
+	return input, err
+}
+
+// Populate the partial input, then set the gas limit by simulating the transaction built from it
+func (cli *Client) simulate(ctx context.Context, from xc_types.Address, input *tx_input.TxInput, build func(txBuilder builder.TxBuilder) (xc_types.Tx, error)) error {
+	partialTxInput, err := cli.rpcClient.FetchUnsimulatedInput(ctx, from)
+	if err != nil {
+		return err
+	}
+	*input = *partialTxInput
+
+	txBuilder, err := builder.NewTxBuilder(cli.chain)
+	if err != nil {
+		return fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	exampleTx, err := build(txBuilder)
+	if err != nil {
+		return fmt.Errorf("could not prepare to simulate: %v", err)
+	}
+	gasLimit, err := cli.rpcClient.SimulateGasWithLimit(ctx, from, exampleTx.(*tx.Tx), cli.chain)
+	if err != nil {
+		return err
+	}
+	input.GasLimit = gasLimit
+	return nil
+}
+
+func (cli *Client) call(ctx context.Context, contract string, method string, args ...interface{}) ([]interface{}, error) {
+	call := &builder.ContractCall{
+		Contract: xc_types.Address(contract),
+		Value:    xc_types.NewBigIntFromUint64(0),
+		Method:   lido.Method(method),
+		Args:     args,
+	}
+	values, err := cli.rpcClient.CallContract(ctx, "", call)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("expected 1 value from %s, got %d", call.Method.Sig, len(values))
+	}
+	return values, nil
+}
+
+func (cli *Client) callUint(ctx context.Context, contract string, method string, args ...interface{}) (*big.Int, error) {
+	values, err := cli.call(ctx, contract, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %T from %s", values[0], method)
+	}
+	return value, nil
+}
+
+func (cli *Client) callUints(ctx context.Context, contract string, method string, args ...interface{}) ([]*big.Int, error) {
+	values, err := cli.call(ctx, contract, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := values[0].([]*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %T from %s", values[0], method)
+	}
+	return value, nil
+}
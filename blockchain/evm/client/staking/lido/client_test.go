@@ -0,0 +1,194 @@
+package lido_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	lidoabi "github.com/CustodyOne/chainkit/blockchain/evm/abi/lido"
+	evmclient "github.com/CustodyOne/chainkit/blockchain/evm/client"
+	"github.com/CustodyOne/chainkit/blockchain/evm/client/staking/lido"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+const owner = "0x273b437645Ba723299d07B1BdFFcf508bE64771f"
+
+var stakingConfig = xc_types.StakingConfig{
+	LiquidToken:        "0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84",
+	WrappedLiquidToken: "0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0",
+	WithdrawalQueue:    "0x889edC2eDab5f40e902b864aD4d7AdE8E412F9B1",
+	Providers:          []xc_types.StakingProvider{xc_types.Lido},
+}
+
+type withdrawalStatus struct {
+	AmountOfStETH  *big.Int
+	AmountOfShares *big.Int
+	Owner          common.Address
+	Timestamp      *big.Int
+	IsFinalized    bool
+	IsClaimed      bool
+}
+
+func ether(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e18))
+}
+
+// Serves eth_call by the called contract method, and other methods by name
+func mockLidoRpc(t *testing.T, calls map[string][]interface{}, responses map[string]string) *httptest.Server {
+	return testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		if request.Method != "eth_call" {
+			result, ok := responses[request.Method]
+			return result, ok
+		}
+		var msg struct {
+			Input hexutil.Bytes `json:"input"`
+			Data  hexutil.Bytes `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(request.Params[0], &msg))
+		if len(msg.Input) == 0 {
+			msg.Input = msg.Data
+		}
+		for name, values := range calls {
+			method := lidoabi.Method(name)
+			if string(method.ID) == string(msg.Input[:4]) {
+				bz, err := method.Outputs.Pack(values...)
+				require.NoError(t, err)
+				return fmt.Sprintf(`"%s"`, hexutil.Encode(bz)), true
+			}
+		}
+		return "", false
+	})
+}
+
+var inputResponses = map[string]string{
+	"eth_getTransactionCount":  `"0x2"`,
+	"eth_chainId":              `"0x1"`,
+	"eth_getBlockByNumber":     `{"number":"0x10","hash":"0x0000000000000000000000000000000000000000000000000000000000000001","parentHash":"0x0000000000000000000000000000000000000000000000000000000000000000","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","miner":"0x0000000000000000000000000000000000000000","stateRoot":"0x0000000000000000000000000000000000000000000000000000000000000000","transactionsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421","receiptsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421","logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","difficulty":"0x0","gasLimit":"0x1c9c380","gasUsed":"0x0","timestamp":"0x0","extraData":"0x","mixHash":"0x0000000000000000000000000000000000000000000000000000000000000000","nonce":"0x0000000000000000","baseFeePerGas":"0x3b9aca00","transactions":[],"uncles":[]}`,
+	"eth_maxPriorityFeePerGas": `"0x3b9aca00"`,
+	"eth_estimateGas":          `"0x186a0"`,
+	"eth_getBalance":           `"0x0"`,
+}
+
+func newLidoClient(t *testing.T, url string) *lido.Client {
+	chain := &xc_types.ChainConfig{
+		Chain:   xc_types.ETH,
+		Client:  &xc_types.ClientConfig{URL: url},
+		Staking: stakingConfig,
+	}
+	rpcClient, err := evmclient.NewClient(chain)
+	require.NoError(t, err)
+	cli, err := lido.NewClient(rpcClient, chain)
+	require.NoError(t, err)
+	return cli.(*lido.Client)
+}
+
+func TestNewClientRequiresContracts(t *testing.T) {
+	chain := &xc_types.ChainConfig{Chain: xc_types.ETH, Client: &xc_types.ClientConfig{URL: "http://localhost"}}
+	rpcClient, err := evmclient.NewClient(chain)
+	require.NoError(t, err)
+	_, err = lido.NewClient(rpcClient, chain)
+	require.ErrorContains(t, err, "lido contracts are not configured")
+}
+
+func TestFetchStakeBalance(t *testing.T) {
+	server := mockLidoRpc(t, map[string][]interface{}{
+		// the same method on stETH and wstETH
+		"balanceOf":             {ether(2)},
+		"getStETHByWstETH":      {ether(3)},
+		"getWithdrawalRequests": {[]*big.Int{big.NewInt(12), big.NewInt(10), big.NewInt(11)}},
+		"getWithdrawalStatus": {[]withdrawalStatus{
+			{ether(1), ether(1), common.HexToAddress(owner), big.NewInt(1), true, false},
+			{ether(4), ether(4), common.HexToAddress(owner), big.NewInt(2), true, true},
+			{ether(5), ether(5), common.HexToAddress(owner), big.NewInt(3), false, false},
+		}},
+	}, nil)
+	defer server.Close()
+	cli := newLidoClient(t, server.URL)
+
+	args, _ := xcclient.NewStakeBalanceArgs(owner)
+	balances, err := cli.FetchStakeBalance(context.Background(), args)
+	require.NoError(t, err)
+	require.Len(t, balances, 4)
+
+	require.Equal(t, stakingConfig.LiquidToken, balances[0].Validator)
+	require.Equal(t, ether(2).String(), balances[0].Balance.Active.String())
+	require.Equal(t, stakingConfig.WrappedLiquidToken, balances[1].Validator)
+	require.Equal(t, ether(3).String(), balances[1].Balance.Active.String())
+
+	// requests are sorted by id, and claimed requests are omitted
+	require.Equal(t, stakingConfig.WithdrawalQueue, balances[2].Validator)
+	require.Equal(t, "10", balances[2].Account)
+	require.Equal(t, ether(1).String(), balances[2].Balance.Inactive.String())
+	require.Equal(t, "12", balances[3].Account)
+	require.Equal(t, ether(5).String(), balances[3].Balance.Deactivating.String())
+}
+
+func TestFetchUnstakingInput(t *testing.T) {
+	args, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, xc_types.BigInt(*ether(2)), xcbuilder.WithStakingProvider(xc_types.Lido))
+	require.NoError(t, err)
+
+	server := mockLidoRpc(t, map[string][]interface{}{
+		"allowance": {ether(1)},
+	}, inputResponses)
+	cli := newLidoClient(t, server.URL)
+	_, err = cli.FetchUnstakingInput(context.Background(), args)
+	require.ErrorContains(t, err, "must approve at least 2000000000000000000 first")
+	server.Close()
+
+	server = mockLidoRpc(t, map[string][]interface{}{
+		"allowance": {ether(2)},
+	}, inputResponses)
+	defer server.Close()
+	cli = newLidoClient(t, server.URL)
+	input, err := cli.FetchUnstakingInput(context.Background(), args)
+	require.NoError(t, err)
+	require.IsType(t, &tx_input.LidoUnstakeInput{}, input)
+	require.EqualValues(t, 2, input.(*tx_input.LidoUnstakeInput).Nonce)
+	require.EqualValues(t, 100_000+1_000, input.(*tx_input.LidoUnstakeInput).GasLimit)
+}
+
+func TestFetchWithdrawInput(t *testing.T) {
+	statuses := []withdrawalStatus{
+		{ether(1), ether(1), common.HexToAddress(owner), big.NewInt(1), true, false},
+		{ether(4), ether(4), common.HexToAddress(owner), big.NewInt(2), true, true},
+		{ether(5), ether(5), common.HexToAddress(owner), big.NewInt(3), true, false},
+	}
+	server := mockLidoRpc(t, map[string][]interface{}{
+		"getWithdrawalRequests":  {[]*big.Int{big.NewInt(10), big.NewInt(11), big.NewInt(12)}},
+		"getWithdrawalStatus":    {statuses},
+		"getLastCheckpointIndex": {big.NewInt(30)},
+		"findCheckpointHints":    {[]*big.Int{big.NewInt(20), big.NewInt(21)}},
+	}, inputResponses)
+	defer server.Close()
+	cli := newLidoClient(t, server.URL)
+
+	args, err := xcbuilder.NewStakeArgs(xc_types.ETH, owner, xc_types.NewBigIntFromUint64(0), xcbuilder.WithStakingProvider(xc_types.Lido))
+	require.NoError(t, err)
+	input, err := cli.FetchWithdrawInput(context.Background(), args)
+	require.NoError(t, err)
+	withdrawInput := input.(*tx_input.LidoWithdrawInput)
+	require.Equal(t, []xc_types.BigInt{xc_types.NewBigIntFromUint64(10), xc_types.NewBigIntFromUint64(12)}, withdrawInput.RequestIds)
+	require.Equal(t, []xc_types.BigInt{xc_types.NewBigIntFromUint64(20), xc_types.NewBigIntFromUint64(21)}, withdrawInput.Hints)
+
+	// nothing to claim for a pending request
+	args, err = xcbuilder.NewStakeArgs(xc_types.ETH, owner, xc_types.NewBigIntFromUint64(0), xcbuilder.WithStakingProvider(xc_types.Lido), xcbuilder.WithStakeAccount("11"))
+	require.NoError(t, err)
+	_, err = cli.FetchWithdrawInput(context.Background(), args)
+	require.ErrorContains(t, err, "no finalized withdrawal requests")
+
+	// a hint is needed for each claimed request
+	args, err = xcbuilder.NewStakeArgs(xc_types.ETH, owner, xc_types.NewBigIntFromUint64(0), xcbuilder.WithStakingProvider(xc_types.Lido), xcbuilder.WithStakeAccount("10"))
+	require.NoError(t, err)
+	_, err = cli.FetchWithdrawInput(context.Background(), args)
+	require.ErrorContains(t, err, "expected 1 checkpoint hints, got 2")
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	xclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+)
+
+var _ xclient.TokenMetadataClient = &Client{}
+
+// FetchTokenMetadata reads the ERC-20 decimals(), symbol() and name() of a token contract
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc.ContractAddress) (*xclient.TokenMetadata, error) {
+	call, err := builder.NewContractCall(xc.Address(contract), "function decimals() view returns (uint8)", "")
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.CallContract(ctx, "", call)
+	if err != nil {
+		return nil, fmt.Errorf("could not read decimals of '%s': %v", contract, err)
+	}
+	symbol, err := client.callStringMethod(ctx, contract, "symbol")
+	if err != nil {
+		return nil, fmt.Errorf("could not read symbol of '%s': %v", contract, err)
+	}
+	// name is optional in ERC-20
+	name, _ := client.callStringMethod(ctx, contract, "name")
+
+	return &xclient.TokenMetadata{
+		Contract: contract,
+		Name:     name,
+		Symbol:   symbol,
+		Decimals: int32(result[0].(uint8)),
+	}, nil
+}
+
+// Some early tokens (e.g. MKR) return bytes32 rather than string
+func (client *Client) callStringMethod(ctx context.Context, contract xc.ContractAddress, method string) (string, error) {
+	call, err := builder.NewContractCall(xc.Address(contract), fmt.Sprintf("function %s() view returns (string)", method), "")
+	if err != nil {
+		return "", err
+	}
+	data, err := client.callContractRaw(ctx, "", call)
+	if err != nil {
+		return "", err
+	}
+	if result, err := call.DecodeResult(data); err == nil {
+		return strings.TrimSpace(result[0].(string)), nil
+	}
+	if len(data) != 32 {
+		return "", fmt.Errorf("could not decode %s() result", method)
+	}
+	return strings.TrimSpace(string(bytes.TrimRight(data, "\x00"))), nil
+}
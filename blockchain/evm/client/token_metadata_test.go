@@ -0,0 +1,78 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchTokenMetadata(t *testing.T) {
+	vectors := []struct {
+		name      string
+		responses []string
+		symbol    string
+		tokenName string
+		decimals  int32
+		err       string
+	}{
+		{
+			name: "erc20",
+			responses: []string{
+				`"0x0000000000000000000000000000000000000000000000000000000000000006"`,
+				`"0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000045553444300000000000000000000000000000000000000000000000000000000"`,
+				`"0x0000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000855534420436f696e000000000000000000000000000000000000000000000000"`,
+			},
+			symbol:    "USDC",
+			tokenName: "USD Coin",
+			decimals:  6,
+		},
+		{
+			name: "bytes32 symbol",
+			responses: []string{
+				`"0x0000000000000000000000000000000000000000000000000000000000000012"`,
+				`"0x4d4b520000000000000000000000000000000000000000000000000000000000"`,
+				`"0x4d616b6572000000000000000000000000000000000000000000000000000000"`,
+			},
+			symbol:    "MKR",
+			tokenName: "Maker",
+			decimals:  18,
+		},
+		{
+			name: "not a token",
+			responses: []string{
+				`"0x"`,
+			},
+			err: "could not read decimals",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, v.responses)
+			defer close()
+
+			cli, err := client.NewClient(&xc.ChainConfig{
+				Chain: xc.ETH,
+				Client: &xc.ClientConfig{
+					URL: server.URL,
+				},
+			})
+			require.NoError(t, err)
+
+			contract := xc.ContractAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+			metadata, err := cli.FetchTokenMetadata(context.Background(), contract)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, contract, metadata.Contract)
+			require.Equal(t, v.symbol, metadata.Symbol)
+			require.Equal(t, v.tokenName, metadata.Name)
+			require.Equal(t, v.decimals, metadata.Decimals)
+		})
+	}
+}
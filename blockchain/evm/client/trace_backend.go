@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+type TraceBackendName string
+
+const (
+	// geth style debug_traceTransaction with the callTracer
+	TraceBackendGeth TraceBackendName = "geth"
+	// parity style trace_transaction, as supported by Erigon, Nethermind, Reth and others
+	TraceBackendParity TraceBackendName = "parity"
+	// ots_traceTransaction from Otterscan, as supported by Erigon and Anvil
+	TraceBackendOtterscan TraceBackendName = "otterscan"
+)
+
+var ErrTraceBlockNotSupported = errors.New("tracing blocks is not supported by this trace backend")
+
+// TraceBackend normalizes the different tracing APIs of EVM nodes into a call tree
+type TraceBackend interface {
+	Name() TraceBackendName
+	TraceTransaction(ctx context.Context, client *Client, txHash common.Hash) (*TraceTransactionResult, error)
+	// Trace all transactions in a block, in order.  May return ErrTraceBlockNotSupported.
+	TraceBlock(ctx context.Context, client *Client, block uint64) ([]*BlockTraceResult, error)
+}
+
+type BlockTraceResult struct {
+	TxHash common.Hash             `json:"txHash"`
+	Result *TraceTransactionResult `json:"result"`
+}
+
+// Backends tried, in order, when detecting which one the node supports
+var TraceBackends = []TraceBackend{
+	&GethTraceBackend{},
+	&ParityTraceBackend{},
+	&OtterscanTraceBackend{},
+}
+
+// SetTraceBackend skips detection and always uses the given backend
+func (client *Client) SetTraceBackend(backend TraceBackend) {
+	client.traceLock.Lock()
+	defer client.traceLock.Unlock()
+	client.traceBackend = backend
+}
+
+// GetTraceBackend returns the backend in use, or nil if it has not been detected yet
+func (client *Client) GetTraceBackend() TraceBackend {
+	client.traceLock.Lock()
+	defer client.traceLock.Unlock()
+	return client.traceBackend
+}
+
+// Calls using the detected backend, or tries each until one is supported by the node
+func (client *Client) withTraceBackend(call func(backend TraceBackend) error) error {
+	if backend := client.GetTraceBackend(); backend != nil {
+		return call(backend)
+	}
+	var err error
+	for _, backend := range TraceBackends {
+		err = call(backend)
+		if err == nil {
+			zap.S().Debug("detected trace backend", zap.String("backend", string(backend.Name())))
+			client.SetTraceBackend(backend)
+			return nil
+		}
+		if !isMethodUnsupported(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// TraceBlock traces all transactions in a block using the detected backend
+func (client *Client) TraceBlock(ctx context.Context, block uint64) ([]*BlockTraceResult, error) {
+	var result []*BlockTraceResult
+	err := client.withTraceBackend(func(backend TraceBackend) (err error) {
+		result, err = backend.TraceBlock(ctx, client, block)
+		return err
+	})
+	return result, err
+}
+
+type GethTraceBackend struct{}
+
+var _ TraceBackend = &GethTraceBackend{}
+
+func (*GethTraceBackend) Name() TraceBackendName {
+	return TraceBackendGeth
+}
+
+func (*GethTraceBackend) TraceTransaction(ctx context.Context, client *Client, txHash common.Hash) (*TraceTransactionResult, error) {
+	var result TraceTransactionResult
+	err := client.EthClient.Client().CallContext(ctx, &result, "debug_traceTransaction", txHash, &TraceTransactionArgs{
+		Tracer: "callTracer",
+	})
+	return &result, err
+}
+
+func (*GethTraceBackend) TraceBlock(ctx context.Context, client *Client, block uint64) ([]*BlockTraceResult, error) {
+	var traces []*BlockTraceResult
+	err := client.EthClient.Client().CallContext(ctx, &traces, "debug_traceBlockByNumber", hexutil.EncodeUint64(block), &TraceTransactionArgs{
+		Tracer: "callTracer",
+	})
+	if err != nil {
+		return nil, err
+	}
+	// older nodes do not include the hash in the block trace
+	for _, trace := range traces {
+		if trace.TxHash == (common.Hash{}) {
+			var header rpcBlockWithTxHashes
+			err = client.EthClient.Client().CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(block), false)
+			if err != nil {
+				return nil, fmt.Errorf("could not get block %d: %v", block, err)
+			}
+			if len(header.Transactions) != len(traces) {
+				return nil, fmt.Errorf("trace of block %d does not match its transactions", block)
+			}
+			for i := range traces {
+				traces[i].TxHash = header.Transactions[i]
+			}
+			break
+		}
+	}
+	return traces, nil
+}
+
+type ParityTraceAction struct {
+	CallType      string         `json:"callType"`
+	From          common.Address `json:"from"`
+	To            common.Address `json:"to"`
+	Gas           hexutil.Uint   `json:"gas"`
+	Input         hexutil.Bytes  `json:"input"`
+	Value         *hexutil.Big   `json:"value"`
+	Address       common.Address `json:"address"`
+	RefundAddress common.Address `json:"refundAddress"`
+	Balance       *hexutil.Big   `json:"balance"`
+	Init          hexutil.Bytes  `json:"init"`
+}
+
+type ParityTraceResult struct {
+	GasUsed hexutil.Uint   `json:"gasUsed"`
+	Address common.Address `json:"address"`
+}
+
+type ParityTrace struct {
+	Action              ParityTraceAction  `json:"action"`
+	Result              *ParityTraceResult `json:"result"`
+	Error               string             `json:"error"`
+	TraceAddress        []int              `json:"traceAddress"`
+	Type                string             `json:"type"`
+	TransactionHash     *common.Hash       `json:"transactionHash"`
+	TransactionPosition *int               `json:"transactionPosition"`
+}
+
+// Converts a single parity trace frame to the geth call format
+func (trace *ParityTrace) toCall() *TraceTransactionResult {
+	call := &TraceTransactionResult{
+		From:  trace.Action.From,
+		To:    trace.Action.To,
+		Input: trace.Action.Input,
+		Gas:   trace.Action.Gas,
+		Error: trace.Error,
+		Calls: []*TraceTransactionResult{},
+	}
+	if trace.Action.Value != nil {
+		call.Value = *trace.Action.Value
+	}
+	if trace.Result != nil {
+		call.GasUsed = trace.Result.GasUsed
+	}
+	switch trace.Type {
+	case "create":
+		call.Type = "CREATE"
+		call.Input = trace.Action.Init
+		if trace.Result != nil {
+			call.To = trace.Result.Address
+		}
+	case "suicide":
+		call.Type = "SELFDESTRUCT"
+		call.From = trace.Action.Address
+		call.To = trace.Action.RefundAddress
+		if trace.Action.Balance != nil {
+			call.Value = *trace.Action.Balance
+		}
+	default:
+		call.Type = TraceTransactionType(strings.ToUpper(trace.Action.CallType))
+	}
+	return call
+}
+
+// Rebuilds the call tree of a transaction from the flat list of parity traces, using the trace addresses
+func ParityTracesToCallTree(traces []*ParityTrace) (*TraceTransactionResult, error) {
+	var root *TraceTransactionResult
+	nodes := map[string]*TraceTransactionResult{}
+	key := func(traceAddress []int) string {
+		return fmt.Sprint(traceAddress)
+	}
+	for _, trace := range traces {
+		call := trace.toCall()
+		if len(trace.TraceAddress) == 0 {
+			root = call
+		} else {
+			parent, ok := nodes[key(trace.TraceAddress[:len(trace.TraceAddress)-1])]
+			if !ok {
+				return nil, fmt.Errorf("trace %v is missing its parent", trace.TraceAddress)
+			}
+			parent.Calls = append(parent.Calls, call)
+		}
+		nodes[key(trace.TraceAddress)] = call
+	}
+	if root == nil {
+		return nil, errors.New("trace is missing the top level call")
+	}
+	return root, nil
+}
+
+type ParityTraceBackend struct{}
+
+var _ TraceBackend = &ParityTraceBackend{}
+
+func (*ParityTraceBackend) Name() TraceBackendName {
+	return TraceBackendParity
+}
+
+func (*ParityTraceBackend) TraceTransaction(ctx context.Context, client *Client, txHash common.Hash) (*TraceTransactionResult, error) {
+	var traces []*ParityTrace
+	err := client.EthClient.Client().CallContext(ctx, &traces, "trace_transaction", txHash)
+	if err != nil {
+		return nil, err
+	}
+	return ParityTracesToCallTree(traces)
+}
+
+func (*ParityTraceBackend) TraceBlock(ctx context.Context, client *Client, block uint64) ([]*BlockTraceResult, error) {
+	var traces []*ParityTrace
+	err := client.EthClient.Client().CallContext(ctx, &traces, "trace_block", hexutil.EncodeUint64(block))
+	if err != nil {
+		return nil, err
+	}
+	// group by transaction, dropping block rewards which have no transaction
+	results := []*BlockTraceResult{}
+	grouped := map[common.Hash][]*ParityTrace{}
+	for _, trace := range traces {
+		if trace.TransactionHash == nil {
+			continue
+		}
+		hash := *trace.TransactionHash
+		if _, ok := grouped[hash]; !ok {
+			results = append(results, &BlockTraceResult{TxHash: hash})
+		}
+		grouped[hash] = append(grouped[hash], trace)
+	}
+	for _, result := range results {
+		result.Result, err = ParityTracesToCallTree(grouped[result.TxHash])
+		if err != nil {
+			return nil, fmt.Errorf("invalid trace of %s: %v", result.TxHash.Hex(), err)
+		}
+	}
+	return results, nil
+}
+
+type OtterscanTrace struct {
+	Type  string         `json:"type"`
+	Depth int            `json:"depth"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Input hexutil.Bytes  `json:"input"`
+}
+
+type OtterscanTraceBackend struct{}
+
+var _ TraceBackend = &OtterscanTraceBackend{}
+
+func (*OtterscanTraceBackend) Name() TraceBackendName {
+	return TraceBackendOtterscan
+}
+
+// Otterscan does not report which calls reverted, so the receipt is checked to at least
+// drop everything from a failed transaction.
+func (*OtterscanTraceBackend) TraceTransaction(ctx context.Context, client *Client, txHash common.Hash) (*TraceTransactionResult, error) {
+	var traces []*OtterscanTrace
+	err := client.EthClient.Client().CallContext(ctx, &traces, "ots_traceTransaction", txHash)
+	if err != nil {
+		return nil, err
+	}
+	// rebuild the tree using the depth of each call
+	stack := []*TraceTransactionResult{}
+	var root *TraceTransactionResult
+	for _, trace := range traces {
+		call := &TraceTransactionResult{
+			Type:  TraceTransactionType(strings.ToUpper(trace.Type)),
+			From:  trace.From,
+			To:    trace.To,
+			Input: trace.Input,
+			Calls: []*TraceTransactionResult{},
+		}
+		if trace.Value != nil {
+			call.Value = *trace.Value
+		}
+		if trace.Depth > len(stack) {
+			return nil, fmt.Errorf("trace at depth %d is missing its parent", trace.Depth)
+		}
+		stack = stack[:trace.Depth]
+		if trace.Depth == 0 {
+			root = call
+		} else {
+			parent := stack[trace.Depth-1]
+			parent.Calls = append(parent.Calls, call)
+		}
+		stack = append(stack, call)
+	}
+	if root == nil {
+		return nil, errors.New("trace is missing the top level call")
+	}
+	receipt, err := client.EthClient.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status == 0 {
+		root.Error = "transaction reverted"
+	}
+	return root, nil
+}
+
+func (*OtterscanTraceBackend) TraceBlock(ctx context.Context, client *Client, block uint64) ([]*BlockTraceResult, error) {
+	return nil, ErrTraceBlockNotSupported
+}
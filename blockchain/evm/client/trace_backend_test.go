@@ -0,0 +1,168 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	traceSender   = "0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8"
+	traceContract = "0x779877A7B0D9E8603169DdbD7836e478b4624789"
+	traceDest     = "0x388C818CA8B9251b393131C08a736A67ccB19297"
+	traceCreated  = "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+	traceTx       = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	traceTx2      = "0x2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// Responds to the given methods, and rejects all others as unsupported
+func mockTraceRpc(t *testing.T, responses map[string]string) (*httptest.Server, map[string]int) {
+	calls := map[string]int{}
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		calls[request.Method]++
+		result, ok := responses[request.Method]
+		return result, ok
+	})
+	return server, calls
+}
+
+func newTraceClient(t *testing.T, url string) *client.Client {
+	cli, err := client.NewClient(&xc.ChainConfig{
+		Chain:  xc.ETH,
+		Client: &xc.ClientConfig{URL: url},
+	})
+	require.NoError(t, err)
+	return cli
+}
+
+var parityTraces = fmt.Sprintf(`[
+	{"action":{"callType":"call","from":"%s","to":"%s","gas":"0x10000","input":"0x","value":"0x0"},"result":{"gasUsed":"0x5000","output":"0x"},"subtraces":4,"traceAddress":[],"transactionHash":"%s","transactionPosition":0,"type":"call"},
+	{"action":{"callType":"call","from":"%s","to":"%s","gas":"0x100","input":"0x","value":"0xde0b6b3a7640000"},"result":{"gasUsed":"0x0","output":"0x"},"subtraces":0,"traceAddress":[0],"transactionHash":"%s","transactionPosition":0,"type":"call"},
+	{"action":{"callType":"call","from":"%s","to":"%s","gas":"0x100","input":"0x","value":"0x1"},"error":"Reverted","subtraces":0,"traceAddress":[1],"transactionHash":"%s","transactionPosition":0,"type":"call"},
+	{"action":{"from":"%s","gas":"0x100","init":"0x60","value":"0x2"},"result":{"address":"%s","code":"0x","gasUsed":"0x0"},"subtraces":1,"traceAddress":[2],"transactionHash":"%s","transactionPosition":0,"type":"create"},
+	{"action":{"address":"%s","refundAddress":"%s","balance":"0x2"},"result":null,"subtraces":0,"traceAddress":[2,0],"transactionHash":"%s","transactionPosition":0,"type":"suicide"},
+	{"action":{"callType":"delegatecall","from":"%s","to":"%s","gas":"0x100","input":"0x","value":"0x5"},"result":{"gasUsed":"0x0","output":"0x"},"subtraces":0,"traceAddress":[3],"transactionHash":"%s","transactionPosition":0,"type":"call"}
+]`,
+	traceSender, traceContract, traceTx,
+	traceContract, traceDest, traceTx,
+	traceContract, traceDest, traceTx,
+	traceContract, traceCreated, traceTx,
+	traceCreated, traceDest, traceTx,
+	traceContract, traceDest, traceTx,
+)
+
+func TestTraceParity(t *testing.T) {
+	server, calls := mockTraceRpc(t, map[string]string{
+		"trace_transaction": parityTraces,
+	})
+	defer server.Close()
+	cli := newTraceClient(t, server.URL)
+
+	result, err := cli.TraceTransaction(context.Background(), common.HexToHash(traceTx))
+	require.NoError(t, err)
+	require.Equal(t, client.TraceBackendParity, cli.GetTraceBackend().Name())
+	require.EqualValues(t, "CALL", result.Type)
+	require.Len(t, result.Calls, 4)
+	require.Equal(t, "Reverted", result.Calls[1].Error)
+	require.EqualValues(t, "CREATE", result.Calls[2].Type)
+	require.Equal(t, common.HexToAddress(traceCreated), result.Calls[2].To)
+	require.Len(t, result.Calls[2].Calls, 1)
+	selfdestruct := result.Calls[2].Calls[0]
+	require.EqualValues(t, "SELFDESTRUCT", selfdestruct.Type)
+	require.Equal(t, common.HexToAddress(traceCreated), selfdestruct.From)
+	require.Equal(t, common.HexToAddress(traceDest), selfdestruct.To)
+	require.EqualValues(t, "DELEGATECALL", result.Calls[3].Type)
+
+	movements, err := cli.TraceEthMovements(context.Background(), common.HexToHash(traceTx))
+	require.NoError(t, err)
+	// the reverted call and delegate call do not move funds
+	require.Len(t, movements.Sources, 3)
+	require.Len(t, movements.Destinations, 3)
+	require.Equal(t, "1000000000000000000", movements.Destinations[0].Amount.String())
+	require.EqualValues(t, common.HexToAddress(traceContract).String(), movements.Sources[0].Address)
+	require.EqualValues(t, common.HexToAddress(traceDest).String(), movements.Destinations[0].Address)
+	require.EqualValues(t, common.HexToAddress(traceCreated).String(), movements.Destinations[1].Address)
+	require.EqualValues(t, common.HexToAddress(traceDest).String(), movements.Destinations[2].Address)
+
+	// detection only happens once
+	require.Equal(t, 1, calls["debug_traceTransaction"])
+	require.Equal(t, 2, calls["trace_transaction"])
+}
+
+func TestTraceParityBlock(t *testing.T) {
+	blockTraces := strings.TrimSuffix(parityTraces, "]") + fmt.Sprintf(`,
+		{"action":{"callType":"call","from":"%s","to":"%s","gas":"0x100","input":"0x","value":"0x7"},"result":{"gasUsed":"0x0","output":"0x"},"subtraces":0,"traceAddress":[],"transactionHash":"%s","transactionPosition":1,"type":"call"},
+		{"action":{"author":"%s","rewardType":"block","value":"0x1bc16d674ec80000"},"result":null,"subtraces":0,"traceAddress":[],"transactionHash":null,"transactionPosition":null,"type":"reward"}
+	]`, traceSender, traceDest, traceTx2, traceSender)
+	server, _ := mockTraceRpc(t, map[string]string{
+		"trace_block": blockTraces,
+	})
+	defer server.Close()
+	cli := newTraceClient(t, server.URL)
+
+	traces, err := cli.TraceBlock(context.Background(), 5)
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+	require.Equal(t, common.HexToHash(traceTx), traces[0].TxHash)
+	require.Len(t, traces[0].Result.Calls, 4)
+	require.Equal(t, common.HexToHash(traceTx2), traces[1].TxHash)
+	require.Equal(t, "7", traces[1].Result.Value.ToInt().String())
+}
+
+func TestTraceOtterscan(t *testing.T) {
+	server, _ := mockTraceRpc(t, map[string]string{
+		"ots_traceTransaction": fmt.Sprintf(`[
+			{"type":"CALL","depth":0,"from":"%s","to":"%s","value":"0x0","input":"0x"},
+			{"type":"CALL","depth":1,"from":"%s","to":"%s","value":"0x3","input":"0x"},
+			{"type":"STATICCALL","depth":2,"from":"%s","to":"%s","value":null,"input":"0x"},
+			{"type":"CALL","depth":1,"from":"%s","to":"%s","value":"0x4","input":"0x"}
+		]`, traceSender, traceContract, traceContract, traceDest, traceDest, traceContract, traceContract, traceCreated),
+		"eth_getTransactionReceipt": fmt.Sprintf(`{"status":"0x1","transactionHash":"%s","cumulativeGasUsed":"0x5208","logsBloom":"0x%0512x","logs":[],"gasUsed":"0x5208","blockNumber":"0x5"}`, traceTx, 0),
+	})
+	defer server.Close()
+	cli := newTraceClient(t, server.URL)
+
+	result, err := cli.TraceTransaction(context.Background(), common.HexToHash(traceTx))
+	require.NoError(t, err)
+	require.Equal(t, client.TraceBackendOtterscan, cli.GetTraceBackend().Name())
+	require.Len(t, result.Calls, 2)
+	require.Len(t, result.Calls[0].Calls, 1)
+	require.EqualValues(t, "STATICCALL", result.Calls[0].Calls[0].Type)
+
+	movements, err := cli.TraceEthMovements(context.Background(), common.HexToHash(traceTx))
+	require.NoError(t, err)
+	require.Len(t, movements.Destinations, 2)
+	require.Equal(t, "3", movements.Destinations[0].Amount.String())
+	require.Equal(t, "4", movements.Destinations[1].Amount.String())
+
+	// otterscan cannot trace blocks
+	_, err = cli.TraceBlock(context.Background(), 5)
+	require.ErrorIs(t, err, client.ErrTraceBlockNotSupported)
+}
+
+func TestTraceDetectionError(t *testing.T) {
+	server, calls := mockTraceRpc(t, map[string]string{
+		"debug_traceTransaction": `error:{"code":-32000,"message":"transaction not found"}`,
+	})
+	defer server.Close()
+	cli := newTraceClient(t, server.URL)
+
+	// errors other than unsupported methods are returned without trying other backends
+	_, err := cli.TraceTransaction(context.Background(), common.HexToHash(traceTx))
+	require.ErrorContains(t, err, "transaction not found")
+	require.Nil(t, cli.GetTraceBackend())
+	require.Equal(t, 0, calls["trace_transaction"])
+
+	cli.SetTraceBackend(&client.ParityTraceBackend{})
+	_, err = cli.TraceTransaction(context.Background(), common.HexToHash(traceTx))
+	require.ErrorContains(t, err, "does not exist")
+	require.Equal(t, 1, calls["debug_traceTransaction"])
+}
@@ -0,0 +1,264 @@
+package safe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/abi/gnosis_safe"
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/signer"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type Operation uint8
+
+const (
+	OperationCall         Operation = 0
+	OperationDelegateCall Operation = 1
+)
+
+// keccak256("EIP712Domain(uint256 chainId,address verifyingContract)"), used by Safe >= 1.3.0
+var DomainSeparatorTypeHash = crypto.Keccak256([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+
+var SafeTxTypeHash = crypto.Keccak256([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+
+// SafeTx is a transaction to be executed by a Safe multisig contract
+type SafeTx struct {
+	Safe           xc.Address
+	To             xc.Address
+	Value          xc.BigInt
+	Data           []byte
+	Operation      Operation
+	SafeTxGas      xc.BigInt
+	BaseGas        xc.BigInt
+	GasPrice       xc.BigInt
+	GasToken       xc.Address
+	RefundReceiver xc.Address
+	Nonce          uint64
+}
+
+// OwnerSignature is a signature of the safeTxHash by one of the Safe owners
+type OwnerSignature struct {
+	Owner     common.Address
+	Signature []byte
+}
+
+// NewNativeTransfer creates a SafeTx sending native asset from the Safe
+func NewNativeTransfer(safe xc.Address, to xc.Address, amount xc.BigInt, nonce uint64) *SafeTx {
+	return &SafeTx{
+		Safe:  safe,
+		To:    to,
+		Value: amount,
+		Data:  []byte{},
+		Nonce: nonce,
+	}
+}
+
+// NewTokenTransfer creates a SafeTx sending an ERC-20 token from the Safe
+func NewTokenTransfer(safe xc.Address, contract xc.ContractAddress, to xc.Address, amount xc.BigInt, nonce uint64) (*SafeTx, error) {
+	data, err := builder.BuildERC20Payload(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeTx{
+		Safe:  safe,
+		To:    xc.Address(contract),
+		Value: xc.NewBigIntFromUint64(0),
+		Data:  data,
+		Nonce: nonce,
+	}, nil
+}
+
+// NewCall creates a SafeTx making an arbitrary contract call from the Safe
+func NewCall(safe xc.Address, call *builder.ContractCall, nonce uint64) (*SafeTx, error) {
+	data, err := call.Calldata()
+	if err != nil {
+		return nil, err
+	}
+	return &SafeTx{
+		Safe:  safe,
+		To:    call.Contract,
+		Value: call.Value,
+		Data:  data,
+		Nonce: nonce,
+	}, nil
+}
+
+func optionalAddress(addr xc.Address) (common.Address, error) {
+	if addr == "" {
+		return common.Address{}, nil
+	}
+	return address.FromHex(addr)
+}
+
+func uint256(value xc.BigInt) []byte {
+	return common.LeftPadBytes(value.Int().Bytes(), 32)
+}
+
+// DomainSeparator returns the EIP-712 domain separator of the Safe
+func (safeTx *SafeTx) DomainSeparator(chainId *big.Int) ([]byte, error) {
+	safe, err := address.FromHex(safeTx.Safe)
+	if err != nil {
+		return nil, fmt.Errorf("bad safe address '%v': %v", safeTx.Safe, err)
+	}
+	return crypto.Keccak256(
+		DomainSeparatorTypeHash,
+		common.LeftPadBytes(chainId.Bytes(), 32),
+		common.LeftPadBytes(safe.Bytes(), 32),
+	), nil
+}
+
+// Hash returns the EIP-712 safeTxHash that the owners sign
+func (safeTx *SafeTx) Hash(chainId *big.Int) ([]byte, error) {
+	domainSeparator, err := safeTx.DomainSeparator(chainId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := address.FromHex(safeTx.To)
+	if err != nil {
+		return nil, fmt.Errorf("bad to address '%v': %v", safeTx.To, err)
+	}
+	gasToken, err := optionalAddress(safeTx.GasToken)
+	if err != nil {
+		return nil, fmt.Errorf("bad gas token address '%v': %v", safeTx.GasToken, err)
+	}
+	refundReceiver, err := optionalAddress(safeTx.RefundReceiver)
+	if err != nil {
+		return nil, fmt.Errorf("bad refund receiver address '%v': %v", safeTx.RefundReceiver, err)
+	}
+
+	structHash := crypto.Keccak256(
+		SafeTxTypeHash,
+		common.LeftPadBytes(to.Bytes(), 32),
+		uint256(safeTx.Value),
+		crypto.Keccak256(safeTx.Data),
+		common.LeftPadBytes([]byte{byte(safeTx.Operation)}, 32),
+		uint256(safeTx.SafeTxGas),
+		uint256(safeTx.BaseGas),
+		uint256(safeTx.GasPrice),
+		common.LeftPadBytes(gasToken.Bytes(), 32),
+		common.LeftPadBytes(refundReceiver.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(safeTx.Nonce).Bytes(), 32),
+	)
+	return crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash), nil
+}
+
+// Sign collects a signature of the safeTxHash from each of the signers
+func (safeTx *SafeTx) Sign(chainId *big.Int, signers ...signer.Signer) ([]OwnerSignature, error) {
+	hash, err := safeTx.Hash(chainId)
+	if err != nil {
+		return nil, err
+	}
+	signatures := []OwnerSignature{}
+	for _, s := range signers {
+		sig, err := s.Sign(hash)
+		if err != nil {
+			return nil, err
+		}
+		ownerSig, err := NewOwnerSignature(hash, sig)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, *ownerSig)
+	}
+	return signatures, nil
+}
+
+// NewOwnerSignature validates a 65 byte ECDSA signature of the safeTxHash and recovers the owner.
+// The recovery id is normalized to 27/28 as expected by the Safe contract.
+func NewOwnerSignature(hash []byte, sig []byte) (*OwnerSignature, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	recoverable := append([]byte{}, sig...)
+	if recoverable[64] >= 27 {
+		recoverable[64] -= 27
+	}
+	pubkey, err := crypto.SigToPub(hash, recoverable)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover signer: %v", err)
+	}
+	normalized := append([]byte{}, recoverable...)
+	normalized[64] += 27
+	return &OwnerSignature{
+		Owner:     crypto.PubkeyToAddress(*pubkey),
+		Signature: normalized,
+	}, nil
+}
+
+// PackSignatures encodes the signatures in the format expected by execTransaction,
+// which requires them to be sorted by owner address.
+func PackSignatures(signatures []OwnerSignature) ([]byte, error) {
+	sorted := append([]OwnerSignature{}, signatures...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Owner.Bytes(), sorted[j].Owner.Bytes()) < 0
+	})
+	packed := []byte{}
+	for i, sig := range sorted {
+		if i > 0 && sig.Owner == sorted[i-1].Owner {
+			return nil, fmt.Errorf("duplicate signature for owner %s", sig.Owner.Hex())
+		}
+		packed = append(packed, sig.Signature...)
+	}
+	return packed, nil
+}
+
+// ExecTransactionCall returns the execTransaction call to submit the SafeTx with the given signatures
+func (safeTx *SafeTx) ExecTransactionCall(signatures []OwnerSignature) (*builder.ContractCall, error) {
+	if len(signatures) == 0 {
+		return nil, errors.New("must provide at least one signature")
+	}
+	packed, err := PackSignatures(signatures)
+	if err != nil {
+		return nil, err
+	}
+	to, err := address.FromHex(safeTx.To)
+	if err != nil {
+		return nil, fmt.Errorf("bad to address '%v': %v", safeTx.To, err)
+	}
+	gasToken, err := optionalAddress(safeTx.GasToken)
+	if err != nil {
+		return nil, fmt.Errorf("bad gas token address '%v': %v", safeTx.GasToken, err)
+	}
+	refundReceiver, err := optionalAddress(safeTx.RefundReceiver)
+	if err != nil {
+		return nil, fmt.Errorf("bad refund receiver address '%v': %v", safeTx.RefundReceiver, err)
+	}
+	return &builder.ContractCall{
+		Contract: safeTx.Safe,
+		Value:    xc.NewBigIntFromUint64(0),
+		Method:   gnosis_safe.Method("execTransaction"),
+		Args: []interface{}{
+			to,
+			safeTx.Value.Int(),
+			safeTx.Data,
+			uint8(safeTx.Operation),
+			safeTx.SafeTxGas.Int(),
+			safeTx.BaseGas.Int(),
+			safeTx.GasPrice.Int(),
+			gasToken,
+			refundReceiver,
+			packed,
+		},
+	}, nil
+}
+
+// BuildExecTransaction builds the execTransaction call as a normal transaction sent by the executor.
+// The input should be fetched for the executor, e.g. using FetchContractCallInput.
+func (safeTx *SafeTx) BuildExecTransaction(chain *xc.ChainConfig, signatures []OwnerSignature, input xc.TxInput) (xc.Tx, error) {
+	call, err := safeTx.ExecTransactionCall(signatures)
+	if err != nil {
+		return nil, err
+	}
+	txBuilder, err := builder.NewTxBuilder(chain)
+	if err != nil {
+		return nil, err
+	}
+	return txBuilder.NewContractCall(call, input)
+}
@@ -0,0 +1,147 @@
+package safe_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm"
+	"github.com/CustodyOne/chainkit/blockchain/evm/abi/gnosis_safe"
+	"github.com/CustodyOne/chainkit/blockchain/evm/safe"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
+	"github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
+	"github.com/CustodyOne/chainkit/signer"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+const safeAddress = xc.Address("0x5AFE3855358E112B5647B952709E6165e1c1eEEe")
+const tokenAddress = xc.ContractAddress("0x779877A7B0D9E8603169DdbD7836e478b4624789")
+const toAddress = xc.Address("0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F")
+
+func newSigner(t *testing.T, hexKey string) signer.Signer {
+	key, err := crypto.HexToECDSA(hexKey)
+	require.NoError(t, err)
+	return evm.NewLocalSigner(key)
+}
+
+func TestTypeHashes(t *testing.T) {
+	require.Equal(t, "0x47e79534a245952e8b16893a336b85a3d9ea9fa8c573f3d803afb92a79469218", hexutil.Encode(safe.DomainSeparatorTypeHash))
+	require.Equal(t, "0xbb8310d486368db6bd6f849402fdd73ad53d316b5a4b2644ad6efe0f941286d8", hexutil.Encode(safe.SafeTxTypeHash))
+}
+
+func TestSafeTxHashMatchesEIP712(t *testing.T) {
+	safeTx, err := safe.NewTokenTransfer(safeAddress, tokenAddress, toAddress, xc.NewBigIntFromUint64(1_000_000), 12)
+	require.NoError(t, err)
+	safeTx.SafeTxGas = xc.NewBigIntFromUint64(100)
+	chainId := big.NewInt(42161)
+
+	hash, err := safeTx.Hash(chainId)
+	require.NoError(t, err)
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"SafeTx": {
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "safeTxGas", Type: "uint256"},
+				{Name: "baseGas", Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"},
+				{Name: "gasToken", Type: "address"},
+				{Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain: apitypes.TypedDataDomain{
+			ChainId:           (*math.HexOrDecimal256)(chainId),
+			VerifyingContract: string(safeAddress),
+		},
+		Message: apitypes.TypedDataMessage{
+			"to":             string(tokenAddress),
+			"value":          "0",
+			"data":           hexutil.Encode(safeTx.Data),
+			"operation":      "0",
+			"safeTxGas":      "100",
+			"baseGas":        "0",
+			"gasPrice":       "0",
+			"gasToken":       common.Address{}.Hex(),
+			"refundReceiver": common.Address{}.Hex(),
+			"nonce":          "12",
+		},
+	}
+	expected, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(expected), hexutil.Encode(hash))
+
+	// different chain => different hash
+	other, err := safeTx.Hash(big.NewInt(1))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other)
+}
+
+func TestSignAndExec(t *testing.T) {
+	signers := []signer.Signer{
+		newSigner(t, "8e812436a0e3323166e1f0e8ba79e19e217b2c4a53c970d4cca0cfb1078979df"),
+		newSigner(t, "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"),
+		newSigner(t, "0f4c4c1ea5e1b5d3cc1b7e5a3c7f7a6a2b1c6c58a4bbdc8e9b2f7e1c2d3a4b5c"),
+	}
+	chainId := big.NewInt(1)
+	safeTx := safe.NewNativeTransfer(safeAddress, toAddress, xc.NewBigIntFromUint64(5), 3)
+
+	signatures, err := safeTx.Sign(chainId, signers...)
+	require.NoError(t, err)
+	require.Len(t, signatures, 3)
+	for _, sig := range signatures {
+		require.Len(t, sig.Signature, 65)
+		require.Contains(t, []byte{27, 28}, sig.Signature[64])
+	}
+
+	packed, err := safe.PackSignatures(signatures)
+	require.NoError(t, err)
+	require.Len(t, packed, 3*65)
+	// sorted by owner
+	hash, _ := safeTx.Hash(chainId)
+	var last common.Address
+	for i := 0; i < 3; i++ {
+		sig, err := safe.NewOwnerSignature(hash, packed[i*65:(i+1)*65])
+		require.NoError(t, err)
+		require.Equal(t, -1, bytes.Compare(last.Bytes(), sig.Owner.Bytes()))
+		last = sig.Owner
+	}
+
+	_, err = safe.PackSignatures(append(signatures, signatures[0]))
+	require.ErrorContains(t, err, "duplicate")
+
+	input := tx_input.NewTxInput()
+	input.GasLimit = 120_000
+	input.Nonce = 9
+	trans, err := safeTx.BuildExecTransaction(&xc.ChainConfig{ChainID: 1}, signatures, input)
+	require.NoError(t, err)
+	ethTx := trans.(*tx.Tx).EthTx
+	require.Equal(t, common.HexToAddress(string(safeAddress)), *ethTx.To())
+	require.EqualValues(t, 0, ethTx.Value().Int64())
+	require.EqualValues(t, 9, ethTx.Nonce())
+
+	method := gnosis_safe.Method("execTransaction")
+	require.Equal(t, method.ID, ethTx.Data()[:4])
+	args, err := method.Inputs.Unpack(ethTx.Data()[4:])
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(string(toAddress)), args[0])
+	require.EqualValues(t, 5, args[1].(*big.Int).Int64())
+	require.Equal(t, packed, args[9])
+
+	_, err = safeTx.ExecTransactionCall(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package tx
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Celo's CIP-64 transaction type, an EIP-1559 transaction that pays for gas in an ERC-20 fee currency.
+// The gas prices are denominated in the fee currency.
+const CeloFeeCurrencyTxType = 0x7b
+
+type cip64UnsignedTx struct {
+	ChainID     *big.Int
+	Nonce       uint64
+	GasTipCap   *big.Int
+	GasFeeCap   *big.Int
+	Gas         uint64
+	To          *common.Address `rlp:"nil"`
+	Value       *big.Int
+	Data        []byte
+	AccessList  types.AccessList
+	FeeCurrency common.Address
+}
+
+type cip64SignedTx struct {
+	ChainID     *big.Int
+	Nonce       uint64
+	GasTipCap   *big.Int
+	GasFeeCap   *big.Int
+	Gas         uint64
+	To          *common.Address `rlp:"nil"`
+	Value       *big.Int
+	Data        []byte
+	AccessList  types.AccessList
+	FeeCurrency common.Address
+	V           *big.Int
+	R           *big.Int
+	S           *big.Int
+}
+
+func newCip64UnsignedTx(ethTx *types.Transaction, feeCurrency common.Address) cip64UnsignedTx {
+	return cip64UnsignedTx{
+		ChainID:     ethTx.ChainId(),
+		Nonce:       ethTx.Nonce(),
+		GasTipCap:   ethTx.GasTipCap(),
+		GasFeeCap:   ethTx.GasFeeCap(),
+		Gas:         ethTx.Gas(),
+		To:          ethTx.To(),
+		Value:       ethTx.Value(),
+		Data:        ethTx.Data(),
+		AccessList:  ethTx.AccessList(),
+		FeeCurrency: feeCurrency,
+	}
+}
+
+func encodeTyped(txType byte, payload any) ([]byte, error) {
+	bz, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{txType}, bz...), nil
+}
+
+func (tx *Tx) cip64Sighash() ([]byte, error) {
+	bz, err := encodeTyped(CeloFeeCurrencyTxType, newCip64UnsignedTx(tx.EthTx, *tx.FeeCurrency))
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(bz), nil
+}
+
+func (tx *Tx) cip64Serialize() ([]byte, error) {
+	unsigned := newCip64UnsignedTx(tx.EthTx, *tx.FeeCurrency)
+	v, r, s := tx.EthTx.RawSignatureValues()
+	return encodeTyped(CeloFeeCurrencyTxType, cip64SignedTx{
+		ChainID:     unsigned.ChainID,
+		Nonce:       unsigned.Nonce,
+		GasTipCap:   unsigned.GasTipCap,
+		GasFeeCap:   unsigned.GasFeeCap,
+		Gas:         unsigned.Gas,
+		To:          unsigned.To,
+		Value:       unsigned.Value,
+		Data:        unsigned.Data,
+		AccessList:  unsigned.AccessList,
+		FeeCurrency: unsigned.FeeCurrency,
+		V:           v,
+		R:           r,
+		S:           s,
+	})
+}
+
+func (tx *Tx) cip64Sender() (common.Address, error) {
+	sighash, err := tx.cip64Sighash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	v, r, s := tx.EthTx.RawSignatureValues()
+	sig := make([]byte, crypto.SignatureLength)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	sig[64] = byte(v.Uint64())
+	pubkey, err := crypto.SigToPub(sighash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
@@ -0,0 +1,168 @@
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Kaia (formerly Klaytn) fee-delegated transaction types.  The sender signs the transaction and
+// the fee payer co-signs it, paying for the gas.
+const (
+	KaiaFeeDelegatedValueTransferType          = 0x09
+	KaiaFeeDelegatedSmartContractExecutionType = 0x31
+)
+
+// Kaia signatures are EIP-155 style, with V = recovery id + chain id * 2 + 35
+type kaiaSignature struct {
+	V *big.Int
+	R *big.Int
+	S *big.Int
+}
+
+func (tx *Tx) kaiaTxType() byte {
+	if len(tx.EthTx.Data()) > 0 {
+		return KaiaFeeDelegatedSmartContractExecutionType
+	}
+	return KaiaFeeDelegatedValueTransferType
+}
+
+// The transaction fields, in the order they are signed and serialized
+func (tx *Tx) kaiaFields() ([]interface{}, error) {
+	to := tx.EthTx.To()
+	if to == nil {
+		return nil, errors.New("fee-delegated contract deployments are not supported")
+	}
+	if tx.Sender == (common.Address{}) {
+		return nil, errors.New("fee-delegated transactions must set the sender")
+	}
+	fields := []interface{}{
+		tx.EthTx.Nonce(),
+		tx.EthTx.GasPrice(),
+		tx.EthTx.Gas(),
+		*to,
+		tx.EthTx.Value(),
+		tx.Sender,
+	}
+	if tx.kaiaTxType() == KaiaFeeDelegatedSmartContractExecutionType {
+		fields = append(fields, tx.EthTx.Data())
+	}
+	return fields, nil
+}
+
+// Returns the sighash of the sender and of the fee payer.  Both sign the RLP encoding of the typed
+// fields, and the fee payer additionally signs its address.
+func (tx *Tx) kaiaSighashes() ([]byte, []byte, error) {
+	fields, err := tx.kaiaFields()
+	if err != nil {
+		return nil, nil, err
+	}
+	encoded, err := rlp.EncodeToBytes(append([]interface{}{tx.kaiaTxType()}, fields...))
+	if err != nil {
+		return nil, nil, err
+	}
+	chainId := tx.Signer.ChainID()
+	senderBz, err := rlp.EncodeToBytes([]interface{}{encoded, chainId, uint(0), uint(0)})
+	if err != nil {
+		return nil, nil, err
+	}
+	feePayerBz, err := rlp.EncodeToBytes([]interface{}{encoded, *tx.FeePayer, chainId, uint(0), uint(0)})
+	if err != nil {
+		return nil, nil, err
+	}
+	return crypto.Keccak256(senderBz), crypto.Keccak256(feePayerBz), nil
+}
+
+// The sender signature is added first, then the fee payer's.  They may be added together, or
+// separately when the fee payer co-signs later.  Each signature must be from the expected account.
+func (tx *Tx) kaiaAddSignatures(signatures ...xc_types.TxSignature) error {
+	for _, sig := range signatures {
+		if len(sig) != crypto.SignatureLength {
+			return fmt.Errorf("invalid signature (%d): %x", len(sig), sig)
+		}
+	}
+	if len(signatures) == 0 || len(tx.Signatures)+len(signatures) > 2 {
+		return fmt.Errorf("expected signatures from the sender and fee payer, got %d more after %d", len(signatures), len(tx.Signatures))
+	}
+	senderSighash, feePayerSighash, err := tx.kaiaSighashes()
+	if err != nil {
+		return err
+	}
+	added := append([]xc_types.TxSignature{}, tx.Signatures...)
+	for _, sig := range signatures {
+		sighash, expected, role := senderSighash, tx.Sender, "sender"
+		if len(added) == 1 {
+			sighash, expected, role = feePayerSighash, *tx.FeePayer, "fee payer"
+		}
+		signer, err := recoverSigner(sighash, sig)
+		if err != nil {
+			return fmt.Errorf("invalid %s signature: %v", role, err)
+		}
+		if signer != expected {
+			return fmt.Errorf("%s signature is from %s, expected %s", role, signer, expected)
+		}
+		added = append(added, sig)
+	}
+	tx.Signatures = added
+	return nil
+}
+
+func recoverSigner(sighash []byte, signature []byte) (common.Address, error) {
+	sig := append([]byte{}, signature...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pubkey, err := crypto.SigToPub(sighash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+func (tx *Tx) kaiaSignature(index int) kaiaSignature {
+	if index >= len(tx.Signatures) {
+		return kaiaSignature{V: big.NewInt(0), R: big.NewInt(0), S: big.NewInt(0)}
+	}
+	sig := tx.Signatures[index]
+	recoveryId := int64(sig[64])
+	if recoveryId >= 27 {
+		recoveryId -= 27
+	}
+	v := new(big.Int).Mul(tx.Signer.ChainID(), big.NewInt(2))
+	v.Add(v, big.NewInt(35+recoveryId))
+	return kaiaSignature{
+		V: v,
+		R: new(big.Int).SetBytes(sig[:32]),
+		S: new(big.Int).SetBytes(sig[32:64]),
+	}
+}
+
+// Serialized as the type followed by the RLP encoded fields and signatures.  Missing signatures are left empty.
+func (tx *Tx) kaiaSerialize() ([]byte, error) {
+	fields, err := tx.kaiaFields()
+	if err != nil {
+		return nil, err
+	}
+	fields = append(fields,
+		[]kaiaSignature{tx.kaiaSignature(0)},
+		*tx.FeePayer,
+		[]kaiaSignature{tx.kaiaSignature(1)},
+	)
+	return encodeTyped(tx.kaiaTxType(), fields)
+}
+
+func (tx *Tx) kaiaSender() (common.Address, error) {
+	if len(tx.Signatures) == 0 {
+		return common.Address{}, errors.New("transaction is not signed")
+	}
+	sighash, _, err := tx.kaiaSighashes()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverSigner(sighash, tx.Signatures[0])
+}
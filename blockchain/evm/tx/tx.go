@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 var ERC20 abi.ABI
@@ -28,6 +29,12 @@ type Tx struct {
 	EthTx      *types.Transaction
 	Signer     types.Signer
 	Signatures []xc_types.TxSignature
+	// Optional: the expected signer, so that the transaction can be simulated before it is signed
+	Sender common.Address
+	// Optional: pay for gas in an ERC-20 token, using Celo's CIP-64 transaction type
+	FeeCurrency *common.Address
+	// Optional: the account co-signing and paying for gas, using Kaia's fee-delegated transaction types
+	FeePayer *common.Address
 }
 
 type SourcesAndDests struct {
@@ -36,6 +43,13 @@ type SourcesAndDests struct {
 }
 
 func (tx *Tx) Hash() xc_types.TxHash {
+	if tx.EthTx != nil && (tx.FeeCurrency != nil || tx.FeePayer != nil) {
+		bz, err := tx.Serialize()
+		if err != nil {
+			return xc_types.TxHash("")
+		}
+		return xc_types.TxHash(crypto.Keccak256Hash(bz).Hex())
+	}
 	if tx.EthTx != nil {
 		return xc_types.TxHash(tx.EthTx.Hash().Hex())
 	}
@@ -47,6 +61,24 @@ func (tx *Tx) Sighashes() ([]xc_types.TxDataToSign, error) {
 	if tx.EthTx == nil {
 		return []xc_types.TxDataToSign{}, errors.New("transaction not initialized")
 	}
+	if tx.FeeCurrency != nil {
+		sighash, err := tx.cip64Sighash()
+		if err != nil {
+			return []xc_types.TxDataToSign{}, err
+		}
+		return []xc_types.TxDataToSign{sighash}, nil
+	}
+	if tx.FeePayer != nil {
+		// the sender signs first, then the fee payer co-signs, so that one key is never asked to sign for both
+		senderSighash, feePayerSighash, err := tx.kaiaSighashes()
+		if err != nil {
+			return []xc_types.TxDataToSign{}, err
+		}
+		if len(tx.Signatures) == 0 {
+			return []xc_types.TxDataToSign{senderSighash}, nil
+		}
+		return []xc_types.TxDataToSign{feePayerSighash}, nil
+	}
 	sighash := tx.Signer.Hash(tx.EthTx).Bytes()
 	return []xc_types.TxDataToSign{sighash}, nil
 }
@@ -56,6 +88,9 @@ func (tx *Tx) AddSignatures(signatures ...xc_types.TxSignature) error {
 	if tx.EthTx == nil {
 		return errors.New("transaction not initialized")
 	}
+	if tx.FeePayer != nil {
+		return tx.kaiaAddSignatures(signatures...)
+	}
 
 	signedTx, err := tx.EthTx.WithSignature(tx.Signer, signatures[0])
 	if err != nil {
@@ -75,6 +110,12 @@ func (tx *Tx) Serialize() ([]byte, error) {
 	if tx.EthTx == nil {
 		return []byte{}, errors.New("transaction not initialized")
 	}
+	if tx.FeeCurrency != nil {
+		return tx.cip64Serialize()
+	}
+	if tx.FeePayer != nil {
+		return tx.kaiaSerialize()
+	}
 	return tx.EthTx.MarshalBinary()
 }
 
@@ -133,13 +174,27 @@ func (tx *Tx) From() xc_types.Address {
 		return xc_types.Address("")
 	}
 
-	from, err := types.Sender(tx.Signer, tx.EthTx)
+	from, err := tx.RecoverSender()
 	if err != nil {
+		if tx.Sender != (common.Address{}) {
+			return xc_types.Address(tx.Sender.String())
+		}
 		return xc_types.Address("")
 	}
 	return xc_types.Address(from.String())
 }
 
+// RecoverSender returns the signer of a signed transaction
+func (tx *Tx) RecoverSender() (common.Address, error) {
+	if tx.FeeCurrency != nil {
+		return tx.cip64Sender()
+	}
+	if tx.FeePayer != nil {
+		return tx.kaiaSender()
+	}
+	return types.Sender(tx.Signer, tx.EthTx)
+}
+
 // To is the account receiving a transfer
 func (tx *Tx) To() xc_types.Address {
 	if tx.EthTx == nil {
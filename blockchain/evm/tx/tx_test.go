@@ -1,10 +1,15 @@
 package tx_test
 
 import (
+	"encoding/hex"
+	"math/big"
 	"testing"
 
 	"github.com/CustodyOne/chainkit/blockchain/evm/tx"
 	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,3 +30,114 @@ func TestTxAddSignatureEmpty(t *testing.T) {
 	err := tx.AddSignatures([]xc_types.TxSignature{}...)
 	require.EqualError(t, err, "transaction not initialized")
 }
+
+func TestCip64FeeCurrencyTx(t *testing.T) {
+	key, _ := crypto.HexToECDSA("45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F")
+	feeCurrency := common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a")
+	chainId := big.NewInt(42220)
+
+	trans := &tx.Tx{
+		EthTx: types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainId,
+			Nonce:     1,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       71_000,
+			To:        &to,
+			Value:     big.NewInt(100),
+		}),
+		Signer:      types.LatestSignerForChainID(chainId),
+		FeeCurrency: &feeCurrency,
+	}
+	sighashes, err := trans.Sighashes()
+	require.NoError(t, err)
+	require.Len(t, sighashes, 1)
+	// 0x7b || rlp([chainId, nonce, gasTipCap, gasFeeCap, gas, to, value, data, accessList, feeCurrency])
+	fields := "82a4ec" + "01" + "01" + "02" + "83011558" +
+		"94" + hex.EncodeToString(to.Bytes()) +
+		"64" + "80" + "c0" +
+		"94" + hex.EncodeToString(feeCurrency.Bytes())
+	unsigned := common.FromHex("0x7bf7" + fields)
+	require.Equal(t, crypto.Keccak256(unsigned), []byte(sighashes[0]))
+	require.NotEqual(t, trans.Signer.Hash(trans.EthTx).Bytes(), []byte(sighashes[0]), "the fee currency is signed")
+
+	sig, err := crypto.Sign(sighashes[0], key)
+	require.NoError(t, err)
+	require.NoError(t, trans.AddSignatures(sig))
+
+	bz, err := trans.Serialize()
+	require.NoError(t, err)
+	// the same fields followed by the signature: [..., v, r, s]
+	v := "80"
+	if sig[64] == 1 {
+		v = "01"
+	}
+	signed := common.FromHex("0x7bf87a" + fields + v + "a0" + hex.EncodeToString(sig[0:32]) + "a0" + hex.EncodeToString(sig[32:64]))
+	require.Equal(t, signed, bz)
+	require.Equal(t, xc_types.TxHash(crypto.Keccak256Hash(bz).Hex()), trans.Hash())
+	require.Equal(t, xc_types.Address(signer.String()), trans.From())
+}
+
+func TestKaiaFeeDelegatedTx(t *testing.T) {
+	senderKey, _ := crypto.HexToECDSA("45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")
+	feePayerKey, _ := crypto.HexToECDSA("b9d5558443585bca6f225b935950e3f6e69f9da8a5809a83f51c3365dff53936")
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+	feePayer := crypto.PubkeyToAddress(feePayerKey.PublicKey)
+	to := common.HexToAddress("0x7b65B75d204aBed71587c9E519a89277766EE1d0")
+
+	trans := &tx.Tx{
+		EthTx:    types.NewTransaction(1234, to, big.NewInt(10), 1_000_000, big.NewInt(25), nil),
+		Signer:   types.LatestSignerForChainID(big.NewInt(1)),
+		Sender:   sender,
+		FeePayer: &feePayer,
+	}
+	// only the sender signs first, so that a single key is never asked to sign for both
+	sighashes, err := trans.Sighashes()
+	require.NoError(t, err)
+	require.Len(t, sighashes, 1)
+	// rlp([rlp([type, nonce, gasPrice, gas, to, value, from]), chainId, 0, 0])
+	senderSigRlp := common.FromHex("0xf839b5f4098204d219830f4240947b65b75d204abed71587c9e519a89277766ee1d00a94" + hex.EncodeToString(sender.Bytes()) + "018080")
+	require.Equal(t, crypto.Keccak256(senderSigRlp), []byte(sighashes[0]))
+
+	// the sender signs first, and the fee payer co-signs later
+	senderSig, err := crypto.Sign(sighashes[0], senderKey)
+	require.NoError(t, err)
+	notSenderSig, err := crypto.Sign(sighashes[0], feePayerKey)
+	require.NoError(t, err)
+	require.ErrorContains(t, trans.AddSignatures(notSenderSig), "sender signature is from "+feePayer.String())
+	require.Empty(t, trans.GetSignatures())
+	require.NoError(t, trans.AddSignatures(senderSig))
+	require.Equal(t, xc_types.Address(sender.String()), trans.From())
+
+	feePayerSighashes, err := trans.Sighashes()
+	require.NoError(t, err)
+	require.Len(t, feePayerSighashes, 1)
+	require.NotEqual(t, sighashes[0], feePayerSighashes[0])
+	// signing for the fee payer with the sender key is rejected
+	wrongSig, err := crypto.Sign(feePayerSighashes[0], senderKey)
+	require.NoError(t, err)
+	require.ErrorContains(t, trans.AddSignatures(wrongSig), "fee payer signature is from "+sender.String())
+	feePayerSig, err := crypto.Sign(feePayerSighashes[0], feePayerKey)
+	require.NoError(t, err)
+	require.NoError(t, trans.AddSignatures(feePayerSig))
+	require.Len(t, trans.GetSignatures(), 2)
+	require.ErrorContains(t, trans.AddSignatures(feePayerSig), "expected signatures from the sender and fee payer")
+
+	bz, err := trans.Serialize()
+	require.NoError(t, err)
+	require.EqualValues(t, tx.KaiaFeeDelegatedValueTransferType, bz[0])
+	require.Contains(t, string(bz), string(feePayer.Bytes()))
+	require.Equal(t, xc_types.TxHash(crypto.Keccak256Hash(bz).Hex()), trans.Hash())
+
+	// contract calls use the smart contract execution type
+	trans.EthTx = types.NewTransaction(1234, to, big.NewInt(0), 1_000_000, big.NewInt(25), []byte{0x1})
+	bz, err = trans.Serialize()
+	require.NoError(t, err)
+	require.EqualValues(t, tx.KaiaFeeDelegatedSmartContractExecutionType, bz[0])
+
+	trans.Sender = common.Address{}
+	_, err = trans.Sighashes()
+	require.ErrorContains(t, err, "must set the sender")
+}
@@ -0,0 +1,61 @@
+package tx_input
+
+import (
+	xc "github.com/CustodyOne/chainkit/types"
+)
+
+// Stake ether with Lido, minting stETH
+type LidoStakeInput struct {
+	TxInput
+	// Optional referral address recorded by Lido
+	Referral string `json:"referral,omitempty"`
+}
+
+// Request a withdrawal of stETH from the Lido withdrawal queue, minting a withdrawal NFT per request
+type LidoUnstakeInput struct {
+	TxInput
+}
+
+// Claim the ether of finalized Lido withdrawal requests
+type LidoWithdrawInput struct {
+	TxInput
+	RequestIds []xc.BigInt `json:"request_ids"`
+	// Checkpoint hints for each request, from WithdrawalQueue.findCheckpointHints
+	Hints []xc.BigInt `json:"hints"`
+}
+
+var _ xc.TxVariantInput = &LidoStakeInput{}
+var _ xc.StakeTxInput = &LidoStakeInput{}
+var _ xc.TxVariantInput = &LidoUnstakeInput{}
+var _ xc.UnstakeTxInput = &LidoUnstakeInput{}
+var _ xc.TxVariantInput = &LidoWithdrawInput{}
+var _ xc.WithdrawTxInput = &LidoWithdrawInput{}
+
+func NewLidoStakeInput() *LidoStakeInput {
+	return &LidoStakeInput{}
+}
+func NewLidoUnstakeInput() *LidoUnstakeInput {
+	return &LidoUnstakeInput{}
+}
+func NewLidoWithdrawInput() *LidoWithdrawInput {
+	return &LidoWithdrawInput{}
+}
+
+func (*LidoStakeInput) GetVariant() xc.TxVariantInputType {
+	return xc.NewStakingInputType(xc.ProtocolEVM, "lido")
+}
+func (*LidoUnstakeInput) GetVariant() xc.TxVariantInputType {
+	return xc.NewUnstakingInputType(xc.ProtocolEVM, "lido")
+}
+func (*LidoWithdrawInput) GetVariant() xc.TxVariantInputType {
+	return xc.NewWithdrawingInputType(xc.ProtocolEVM, "lido")
+}
+
+// Mark as valid for staking transactions
+func (*LidoStakeInput) Staking() {}
+
+// Mark as valid for un-staking transactions
+func (*LidoUnstakeInput) Unstaking() {}
+
+// Mark as valid for withdrawing transactions
+func (*LidoWithdrawInput) Withdrawing() {}
@@ -27,6 +27,15 @@ type TxInput struct {
 
 	// legacy only
 	Prices []*Price `json:"prices,omitempty"`
+
+	// Estimated fee for posting the tx data to L1, on rollups where it's charged in addition to the gas
+	L1Fee xc.BigInt `json:"l1_fee"`
+
+	// Token used to pay for gas on Celo, in which case the gas prices are denominated in it
+	FeeCurrency xc.ContractAddress `json:"fee_currency,omitempty"`
+
+	// Account that pays for gas on Kaia, with a fee-delegated transaction (legacy only)
+	FeePayer xc.Address `json:"fee_payer,omitempty"`
 }
 
 var _ xc.TxInput = &TxInput{}
@@ -35,6 +44,10 @@ func init() {
 	registry.RegisterTxBaseInput(&TxInput{})
 	registry.RegisterTxVariantInput(&BatchDepositInput{})
 	registry.RegisterTxVariantInput(&ExitRequestInput{})
+	registry.RegisterTxVariantInput(&WithdrawalRequestInput{})
+	registry.RegisterTxVariantInput(&LidoStakeInput{})
+	registry.RegisterTxVariantInput(&LidoUnstakeInput{})
+	registry.RegisterTxVariantInput(&LidoWithdrawInput{})
 }
 
 func NewTxInput() *TxInput {
@@ -69,6 +82,18 @@ func (input *TxInput) SetGasFeePriority(other xc.GasFeePriority) error {
 	return nil
 }
 
+// GetMaxFee returns the most the transaction can pay in fees: the gas limit at the max fee per gas
+// (or the legacy gas price), plus the L1 data fee on rollups.  At most the balance less this can be sent.
+func (input *TxInput) GetMaxFee() xc.BigInt {
+	gasPrice := input.GasFeeCap
+	if gasPrice.Cmp(&input.GasPrice) < 0 {
+		gasPrice = input.GasPrice
+	}
+	gasLimit := xc.NewBigIntFromUint64(input.GasLimit)
+	maxFee := gasLimit.Mul(&gasPrice)
+	return maxFee.Add(&input.L1Fee)
+}
+
 func (input *TxInput) IndependentOf(other xc.TxInput) (independent bool) {
 	// different sequence means independence
 	if evmOther, ok := other.(*TxInput); ok {
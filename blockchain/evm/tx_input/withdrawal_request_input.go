@@ -0,0 +1,43 @@
+package tx_input
+
+import (
+	xc "github.com/CustodyOne/chainkit/types"
+)
+
+// Exit a validator, or withdraw part of its balance, using an EIP-7002 withdrawal request sent from
+// the execution address in the validator's withdrawal credentials.
+type WithdrawalRequestInput struct {
+	TxInput
+	PublicKey []byte `json:"public_key"`
+	// Amount to withdraw in gwei, or 0 to fully exit the validator
+	AmountGwei uint64 `json:"amount_gwei"`
+	// Fee paid to the system contract, which is not refunded if overpaid
+	Fee xc.BigInt `json:"fee"`
+}
+
+// Consolidate a source validator into a target validator using an EIP-7251 consolidation request
+type ConsolidationRequestInput struct {
+	TxInput
+	SourcePublicKey []byte `json:"source_public_key"`
+	TargetPublicKey []byte `json:"target_public_key"`
+	// Fee paid to the system contract, which is not refunded if overpaid
+	Fee xc.BigInt `json:"fee"`
+}
+
+var _ xc.TxVariantInput = &WithdrawalRequestInput{}
+var _ xc.UnstakeTxInput = &WithdrawalRequestInput{}
+
+func NewWithdrawalRequestInput() *WithdrawalRequestInput {
+	return &WithdrawalRequestInput{}
+}
+
+func NewConsolidationRequestInput() *ConsolidationRequestInput {
+	return &ConsolidationRequestInput{}
+}
+
+func (*WithdrawalRequestInput) GetVariant() xc.TxVariantInputType {
+	return xc.NewUnstakingInputType(xc.ProtocolEVM, "withdrawal-request")
+}
+
+// Mark as valid for un-staking transactions
+func (*WithdrawalRequestInput) Unstaking() {}
@@ -0,0 +1,410 @@
+package userop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm/address"
+	"github.com/CustodyOne/chainkit/blockchain/evm/builder"
+	"github.com/CustodyOne/chainkit/signer"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type EntryPointVersion string
+
+const (
+	EntryPointV06 EntryPointVersion = "v0.6"
+	EntryPointV07 EntryPointVersion = "v0.7"
+)
+
+// Canonical EntryPoint deployments
+const (
+	EntryPointV06Address xc.Address = "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+	EntryPointV07Address xc.Address = "0x0000000071727De22E5E9d8BAf0edAc6f37da032"
+)
+
+func (version EntryPointVersion) Address() xc.Address {
+	switch version {
+	case EntryPointV06:
+		return EntryPointV06Address
+	case EntryPointV07:
+		return EntryPointV07Address
+	}
+	return ""
+}
+
+// UserOperation is an ERC-4337 user operation.  The v0.7 packing of the gas fields and the
+// factory/paymaster fields is handled when hashing and serializing, so the same struct is used for both versions.
+type UserOperation struct {
+	Version EntryPointVersion
+
+	Sender   xc.Address
+	Nonce    xc.BigInt
+	CallData []byte
+
+	// v0.6 uses InitCode (factory address + factory data)
+	// v0.7 uses Factory and FactoryData separately.  Either may be set and will be converted.
+	Factory     xc.Address
+	FactoryData []byte
+
+	CallGasLimit         xc.BigInt
+	VerificationGasLimit xc.BigInt
+	PreVerificationGas   xc.BigInt
+	MaxFeePerGas         xc.BigInt
+	MaxPriorityFeePerGas xc.BigInt
+
+	Paymaster                     xc.Address
+	PaymasterVerificationGasLimit xc.BigInt
+	PaymasterPostOpGasLimit       xc.BigInt
+	PaymasterData                 []byte
+
+	Signature []byte
+}
+
+// A dummy signature with valid structure, used by bundlers during gas estimation
+var DummySignature = hexutil.MustDecode("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+func NewUserOperation(version EntryPointVersion, sender xc.Address, nonce xc.BigInt, callData []byte) *UserOperation {
+	return &UserOperation{
+		Version:   version,
+		Sender:    sender,
+		Nonce:     nonce,
+		CallData:  callData,
+		Signature: DummySignature,
+	}
+}
+
+// NewNativeTransfer creates a user operation sending native asset from a SimpleAccount-compatible account
+func NewNativeTransfer(version EntryPointVersion, sender xc.Address, nonce xc.BigInt, to xc.Address, amount xc.BigInt) (*UserOperation, error) {
+	callData, err := ExecuteCalldata(to, amount, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	return NewUserOperation(version, sender, nonce, callData), nil
+}
+
+// NewTokenTransfer creates a user operation sending an ERC-20 token from a SimpleAccount-compatible account
+func NewTokenTransfer(version EntryPointVersion, sender xc.Address, nonce xc.BigInt, contract xc.ContractAddress, to xc.Address, amount xc.BigInt) (*UserOperation, error) {
+	payload, err := builder.BuildERC20Payload(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := ExecuteCalldata(xc.Address(contract), xc.NewBigIntFromUint64(0), payload)
+	if err != nil {
+		return nil, err
+	}
+	return NewUserOperation(version, sender, nonce, callData), nil
+}
+
+// ExecuteCalldata encodes `execute(address,uint256,bytes)` on the account
+func ExecuteCalldata(to xc.Address, value xc.BigInt, data []byte) ([]byte, error) {
+	call, err := builder.NewContractCall("", "function execute(address dest, uint256 value, bytes func)", "", to, value, data)
+	if err != nil {
+		return nil, err
+	}
+	return call.Calldata()
+}
+
+// InitCode returns the v0.6 style factory address + factory data
+func (op *UserOperation) InitCode() ([]byte, error) {
+	if op.Factory == "" {
+		return []byte{}, nil
+	}
+	factory, err := address.FromHex(op.Factory)
+	if err != nil {
+		return nil, fmt.Errorf("bad factory address '%v': %v", op.Factory, err)
+	}
+	return append(factory.Bytes(), op.FactoryData...), nil
+}
+
+// PaymasterAndData returns the paymaster address and data, packed according to the EntryPoint version
+func (op *UserOperation) PaymasterAndData() ([]byte, error) {
+	if op.Paymaster == "" {
+		return []byte{}, nil
+	}
+	paymaster, err := address.FromHex(op.Paymaster)
+	if err != nil {
+		return nil, fmt.Errorf("bad paymaster address '%v': %v", op.Paymaster, err)
+	}
+	result := paymaster.Bytes()
+	if op.Version == EntryPointV07 {
+		result = append(result, common.LeftPadBytes(op.PaymasterVerificationGasLimit.Int().Bytes(), 16)...)
+		result = append(result, common.LeftPadBytes(op.PaymasterPostOpGasLimit.Int().Bytes(), 16)...)
+	}
+	return append(result, op.PaymasterData...), nil
+}
+
+func word(value xc.BigInt) []byte {
+	return common.LeftPadBytes(value.Int().Bytes(), 32)
+}
+
+// packs two uint128 into a single word
+func packUint128(high xc.BigInt, low xc.BigInt) []byte {
+	return append(common.LeftPadBytes(high.Int().Bytes(), 16), common.LeftPadBytes(low.Int().Bytes(), 16)...)
+}
+
+// Hash returns the userOpHash, as calculated by EntryPoint.getUserOpHash
+func (op *UserOperation) Hash(entryPoint xc.Address, chainId *big.Int) ([]byte, error) {
+	sender, err := address.FromHex(op.Sender)
+	if err != nil {
+		return nil, fmt.Errorf("bad sender address '%v': %v", op.Sender, err)
+	}
+	entryPointAddr, err := address.FromHex(entryPoint)
+	if err != nil {
+		return nil, fmt.Errorf("bad entrypoint address '%v': %v", entryPoint, err)
+	}
+	initCode, err := op.InitCode()
+	if err != nil {
+		return nil, err
+	}
+	paymasterAndData, err := op.PaymasterAndData()
+	if err != nil {
+		return nil, err
+	}
+
+	var packed []byte
+	switch op.Version {
+	case EntryPointV06:
+		packed = concat(
+			common.LeftPadBytes(sender.Bytes(), 32),
+			word(op.Nonce),
+			crypto.Keccak256(initCode),
+			crypto.Keccak256(op.CallData),
+			word(op.CallGasLimit),
+			word(op.VerificationGasLimit),
+			word(op.PreVerificationGas),
+			word(op.MaxFeePerGas),
+			word(op.MaxPriorityFeePerGas),
+			crypto.Keccak256(paymasterAndData),
+		)
+	case EntryPointV07:
+		packed = concat(
+			common.LeftPadBytes(sender.Bytes(), 32),
+			word(op.Nonce),
+			crypto.Keccak256(initCode),
+			crypto.Keccak256(op.CallData),
+			packUint128(op.VerificationGasLimit, op.CallGasLimit),
+			word(op.PreVerificationGas),
+			packUint128(op.MaxPriorityFeePerGas, op.MaxFeePerGas),
+			crypto.Keccak256(paymasterAndData),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported entrypoint version '%s'", op.Version)
+	}
+
+	return crypto.Keccak256(
+		crypto.Keccak256(packed),
+		common.LeftPadBytes(entryPointAddr.Bytes(), 32),
+		common.LeftPadBytes(chainId.Bytes(), 32),
+	), nil
+}
+
+// Sign signs the userOpHash as an EIP-191 personal message, as expected by SimpleAccount
+func (op *UserOperation) Sign(entryPoint xc.Address, chainId *big.Int, s signer.Signer) error {
+	hash, err := op.Hash(entryPoint, chainId)
+	if err != nil {
+		return err
+	}
+	sig, err := s.Sign(accounts.TextHash(hash))
+	if err != nil {
+		return err
+	}
+	return op.SetSignature(sig)
+}
+
+// SetSignature sets a 65 byte ECDSA signature, normalizing the recovery id to 27/28
+func (op *UserOperation) SetSignature(sig []byte) error {
+	if len(sig) != crypto.SignatureLength {
+		return errors.New("invalid signature length")
+	}
+	sig = append([]byte{}, sig...)
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	op.Signature = sig
+	return nil
+}
+
+func concat(parts ...[]byte) []byte {
+	result := []byte{}
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
+
+func bigOrZero(value *hexutil.Big) xc.BigInt {
+	if value == nil {
+		return xc.NewBigIntFromUint64(0)
+	}
+	return xc.BigInt(*value)
+}
+
+func hexBig(value xc.BigInt) *hexutil.Big {
+	return (*hexutil.Big)(value.Int())
+}
+
+type userOperationV06Json struct {
+	Sender               string        `json:"sender"`
+	Nonce                *hexutil.Big  `json:"nonce"`
+	InitCode             hexutil.Bytes `json:"initCode"`
+	CallData             hexutil.Bytes `json:"callData"`
+	CallGasLimit         *hexutil.Big  `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big  `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big  `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big  `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big  `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes `json:"paymasterAndData"`
+	Signature            hexutil.Bytes `json:"signature"`
+}
+
+type userOperationV07Json struct {
+	Sender                        string        `json:"sender"`
+	Nonce                         *hexutil.Big  `json:"nonce"`
+	Factory                       string        `json:"factory,omitempty"`
+	FactoryData                   hexutil.Bytes `json:"factoryData,omitempty"`
+	CallData                      hexutil.Bytes `json:"callData"`
+	CallGasLimit                  *hexutil.Big  `json:"callGasLimit"`
+	VerificationGasLimit          *hexutil.Big  `json:"verificationGasLimit"`
+	PreVerificationGas            *hexutil.Big  `json:"preVerificationGas"`
+	MaxFeePerGas                  *hexutil.Big  `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          *hexutil.Big  `json:"maxPriorityFeePerGas"`
+	Paymaster                     string        `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit *hexutil.Big  `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big  `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 hexutil.Bytes `json:"paymasterData,omitempty"`
+	Signature                     hexutil.Bytes `json:"signature"`
+}
+
+// MarshalJSON serializes the operation in the bundler RPC format for its EntryPoint version
+func (op UserOperation) MarshalJSON() ([]byte, error) {
+	switch op.Version {
+	case EntryPointV06:
+		initCode, err := op.InitCode()
+		if err != nil {
+			return nil, err
+		}
+		paymasterAndData, err := op.PaymasterAndData()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(userOperationV06Json{
+			Sender:               string(op.Sender),
+			Nonce:                hexBig(op.Nonce),
+			InitCode:             initCode,
+			CallData:             op.CallData,
+			CallGasLimit:         hexBig(op.CallGasLimit),
+			VerificationGasLimit: hexBig(op.VerificationGasLimit),
+			PreVerificationGas:   hexBig(op.PreVerificationGas),
+			MaxFeePerGas:         hexBig(op.MaxFeePerGas),
+			MaxPriorityFeePerGas: hexBig(op.MaxPriorityFeePerGas),
+			PaymasterAndData:     paymasterAndData,
+			Signature:            op.Signature,
+		})
+	case EntryPointV07:
+		opJson := userOperationV07Json{
+			Sender:               string(op.Sender),
+			Nonce:                hexBig(op.Nonce),
+			Factory:              string(op.Factory),
+			CallData:             op.CallData,
+			CallGasLimit:         hexBig(op.CallGasLimit),
+			VerificationGasLimit: hexBig(op.VerificationGasLimit),
+			PreVerificationGas:   hexBig(op.PreVerificationGas),
+			MaxFeePerGas:         hexBig(op.MaxFeePerGas),
+			MaxPriorityFeePerGas: hexBig(op.MaxPriorityFeePerGas),
+			Paymaster:            string(op.Paymaster),
+			Signature:            op.Signature,
+		}
+		if op.Factory != "" {
+			opJson.FactoryData = op.FactoryData
+			if opJson.FactoryData == nil {
+				opJson.FactoryData = []byte{}
+			}
+		}
+		if op.Paymaster != "" {
+			opJson.PaymasterVerificationGasLimit = hexBig(op.PaymasterVerificationGasLimit)
+			opJson.PaymasterPostOpGasLimit = hexBig(op.PaymasterPostOpGasLimit)
+			opJson.PaymasterData = op.PaymasterData
+			if opJson.PaymasterData == nil {
+				opJson.PaymasterData = []byte{}
+			}
+		}
+		return json.Marshal(opJson)
+	}
+	return nil, fmt.Errorf("unsupported entrypoint version '%s'", op.Version)
+}
+
+// UnmarshalJSON parses either RPC format.  The version is detected from the fields present
+// unless already set on the operation.
+func (op *UserOperation) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	version := op.Version
+	if version == "" {
+		version = EntryPointV07
+		if _, ok := fields["initCode"]; ok {
+			version = EntryPointV06
+		}
+	}
+	switch version {
+	case EntryPointV06:
+		var opJson userOperationV06Json
+		if err := json.Unmarshal(data, &opJson); err != nil {
+			return err
+		}
+		*op = UserOperation{
+			Version:              version,
+			Sender:               xc.Address(opJson.Sender),
+			Nonce:                bigOrZero(opJson.Nonce),
+			CallData:             opJson.CallData,
+			CallGasLimit:         bigOrZero(opJson.CallGasLimit),
+			VerificationGasLimit: bigOrZero(opJson.VerificationGasLimit),
+			PreVerificationGas:   bigOrZero(opJson.PreVerificationGas),
+			MaxFeePerGas:         bigOrZero(opJson.MaxFeePerGas),
+			MaxPriorityFeePerGas: bigOrZero(opJson.MaxPriorityFeePerGas),
+			Signature:            opJson.Signature,
+		}
+		if len(opJson.InitCode) >= common.AddressLength {
+			op.Factory = xc.Address(common.BytesToAddress(opJson.InitCode[:common.AddressLength]).Hex())
+			op.FactoryData = opJson.InitCode[common.AddressLength:]
+		}
+		if len(opJson.PaymasterAndData) >= common.AddressLength {
+			op.Paymaster = xc.Address(common.BytesToAddress(opJson.PaymasterAndData[:common.AddressLength]).Hex())
+			op.PaymasterData = opJson.PaymasterAndData[common.AddressLength:]
+		}
+	case EntryPointV07:
+		var opJson userOperationV07Json
+		if err := json.Unmarshal(data, &opJson); err != nil {
+			return err
+		}
+		*op = UserOperation{
+			Version:                       version,
+			Sender:                        xc.Address(opJson.Sender),
+			Nonce:                         bigOrZero(opJson.Nonce),
+			Factory:                       xc.Address(opJson.Factory),
+			FactoryData:                   opJson.FactoryData,
+			CallData:                      opJson.CallData,
+			CallGasLimit:                  bigOrZero(opJson.CallGasLimit),
+			VerificationGasLimit:          bigOrZero(opJson.VerificationGasLimit),
+			PreVerificationGas:            bigOrZero(opJson.PreVerificationGas),
+			MaxFeePerGas:                  bigOrZero(opJson.MaxFeePerGas),
+			MaxPriorityFeePerGas:          bigOrZero(opJson.MaxPriorityFeePerGas),
+			Paymaster:                     xc.Address(opJson.Paymaster),
+			PaymasterVerificationGasLimit: bigOrZero(opJson.PaymasterVerificationGasLimit),
+			PaymasterPostOpGasLimit:       bigOrZero(opJson.PaymasterPostOpGasLimit),
+			PaymasterData:                 opJson.PaymasterData,
+			Signature:                     opJson.Signature,
+		}
+	default:
+		return fmt.Errorf("unsupported entrypoint version '%s'", version)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package userop_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/evm"
+	"github.com/CustodyOne/chainkit/blockchain/evm/userop"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const sender = xc.Address("0x50B0c2B3bcAd53Eb45B57C4e5dF8a9890d002Cc8")
+const to = xc.Address("0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F")
+
+func newOp(t *testing.T, version userop.EntryPointVersion) *userop.UserOperation {
+	op, err := userop.NewNativeTransfer(version, sender, xc.NewBigIntFromUint64(4), to, xc.NewBigIntFromUint64(1000))
+	require.NoError(t, err)
+	op.CallGasLimit = xc.NewBigIntFromUint64(50_000)
+	op.VerificationGasLimit = xc.NewBigIntFromUint64(70_000)
+	op.PreVerificationGas = xc.NewBigIntFromUint64(45_000)
+	op.MaxFeePerGas = xc.NewBigIntFromUint64(30_000_000_000)
+	op.MaxPriorityFeePerGas = xc.NewBigIntFromUint64(1_000_000_000)
+	return op
+}
+
+func mustType(t string) abi.Type {
+	ty, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return ty
+}
+
+func abiEncode(types []string, values ...interface{}) []byte {
+	args := abi.Arguments{}
+	for _, t := range types {
+		args = append(args, abi.Argument{Type: mustType(t)})
+	}
+	bz, err := args.Pack(values...)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func outerHash(inner []byte, entryPoint xc.Address, chainId *big.Int) []byte {
+	return crypto.Keccak256(abiEncode(
+		[]string{"bytes32", "address", "uint256"},
+		[32]byte(crypto.Keccak256(inner)), common.HexToAddress(string(entryPoint)), chainId,
+	))
+}
+
+func TestExecuteCalldata(t *testing.T) {
+	op := newOp(t, userop.EntryPointV06)
+	// execute(address,uint256,bytes)
+	require.Equal(t, "0xb61d27f6", hexutil.Encode(op.CallData[:4]))
+
+	op, err := userop.NewTokenTransfer(userop.EntryPointV07, sender, xc.NewBigIntFromUint64(0), "0x779877A7B0D9E8603169DdbD7836e478b4624789", to, xc.NewBigIntFromUint64(1))
+	require.NoError(t, err)
+	require.Equal(t, "0xb61d27f6", hexutil.Encode(op.CallData[:4]))
+	// contains the erc20 transfer
+	require.Contains(t, hexutil.Encode(op.CallData), "a9059cbb")
+}
+
+func TestHashV06(t *testing.T) {
+	op := newOp(t, userop.EntryPointV06)
+	op.Factory = "0x9406Cc6185a346906296840746125a0E44976454"
+	op.FactoryData = []byte{1, 2, 3}
+	chainId := big.NewInt(11155111)
+
+	hash, err := op.Hash(userop.EntryPointV06Address, chainId)
+	require.NoError(t, err)
+
+	inner := abiEncode(
+		[]string{"address", "uint256", "bytes32", "bytes32", "uint256", "uint256", "uint256", "uint256", "uint256", "bytes32"},
+		common.HexToAddress(string(sender)), big.NewInt(4),
+		[32]byte(crypto.Keccak256(append(common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454").Bytes(), 1, 2, 3))),
+		[32]byte(crypto.Keccak256(op.CallData)),
+		big.NewInt(50_000), big.NewInt(70_000), big.NewInt(45_000), big.NewInt(30_000_000_000), big.NewInt(1_000_000_000),
+		[32]byte(crypto.Keccak256([]byte{})),
+	)
+	require.Equal(t, hexutil.Encode(outerHash(inner, userop.EntryPointV06Address, chainId)), hexutil.Encode(hash))
+}
+
+func TestHashV07(t *testing.T) {
+	op := newOp(t, userop.EntryPointV07)
+	op.Paymaster = "0x0000000000325602a77416A16136FDafd04b299f"
+	op.PaymasterVerificationGasLimit = xc.NewBigIntFromUint64(30_000)
+	op.PaymasterPostOpGasLimit = xc.NewBigIntFromUint64(10_000)
+	op.PaymasterData = []byte{0xaa}
+	chainId := big.NewInt(1)
+
+	hash, err := op.Hash(userop.EntryPointV07Address, chainId)
+	require.NoError(t, err)
+
+	accountGasLimits := new(big.Int).Lsh(big.NewInt(70_000), 128)
+	accountGasLimits.Add(accountGasLimits, big.NewInt(50_000))
+	gasFees := new(big.Int).Lsh(big.NewInt(1_000_000_000), 128)
+	gasFees.Add(gasFees, big.NewInt(30_000_000_000))
+	paymasterAndData := common.HexToAddress("0x0000000000325602a77416A16136FDafd04b299f").Bytes()
+	paymasterAndData = append(paymasterAndData, common.LeftPadBytes(big.NewInt(30_000).Bytes(), 16)...)
+	paymasterAndData = append(paymasterAndData, common.LeftPadBytes(big.NewInt(10_000).Bytes(), 16)...)
+	paymasterAndData = append(paymasterAndData, 0xaa)
+
+	inner := abiEncode(
+		[]string{"address", "uint256", "bytes32", "bytes32", "uint256", "uint256", "uint256", "bytes32"},
+		common.HexToAddress(string(sender)), big.NewInt(4),
+		[32]byte(crypto.Keccak256([]byte{})),
+		[32]byte(crypto.Keccak256(op.CallData)),
+		accountGasLimits, big.NewInt(45_000), gasFees,
+		[32]byte(crypto.Keccak256(paymasterAndData)),
+	)
+	require.Equal(t, hexutil.Encode(outerHash(inner, userop.EntryPointV07Address, chainId)), hexutil.Encode(hash))
+}
+
+func TestSign(t *testing.T) {
+	key, err := crypto.HexToECDSA("8e812436a0e3323166e1f0e8ba79e19e217b2c4a53c970d4cca0cfb1078979df")
+	require.NoError(t, err)
+	op := newOp(t, userop.EntryPointV07)
+	chainId := big.NewInt(1)
+	err = op.Sign(userop.EntryPointV07Address, chainId, evm.NewLocalSigner(key))
+	require.NoError(t, err)
+	require.Len(t, op.Signature, 65)
+	require.Contains(t, []byte{27, 28}, op.Signature[64])
+
+	hash, _ := op.Hash(userop.EntryPointV07Address, chainId)
+	sig := append([]byte{}, op.Signature...)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(hash), sig)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*pub))
+
+	require.Error(t, op.SetSignature([]byte{1}))
+}
+
+func TestJson(t *testing.T) {
+	for _, version := range []userop.EntryPointVersion{userop.EntryPointV06, userop.EntryPointV07} {
+		op := newOp(t, version)
+		op.Factory = "0x9406Cc6185a346906296840746125a0E44976454"
+		op.FactoryData = []byte{1, 2, 3}
+		bz, err := json.Marshal(op)
+		require.NoError(t, err)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(bz, &fields))
+		if version == userop.EntryPointV06 {
+			require.Equal(t, "0x9406cc6185a346906296840746125a0e44976454010203", fields["initCode"])
+			require.NotContains(t, fields, "factory")
+		} else {
+			require.Equal(t, "0x9406Cc6185a346906296840746125a0E44976454", fields["factory"])
+			require.NotContains(t, fields, "initCode")
+			require.NotContains(t, fields, "paymaster")
+		}
+		require.Equal(t, "0xc350", fields["callGasLimit"])
+
+		var parsed userop.UserOperation
+		require.NoError(t, json.Unmarshal(bz, &parsed))
+		require.Equal(t, version, parsed.Version)
+		expectedHash, _ := op.Hash(version.Address(), big.NewInt(1))
+		parsedHash, _ := parsed.Hash(version.Address(), big.NewInt(1))
+		require.Equal(t, expectedHash, parsedHash)
+	}
+}
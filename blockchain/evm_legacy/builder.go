@@ -1,6 +1,7 @@
 package evm_legacy
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -9,11 +10,10 @@ import (
 	evminput "github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
 	xcbuilder "github.com/CustodyOne/chainkit/builder"
 	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
-var DefaultMaxTipCapGwei uint64 = 5
-
 // TxBuilder for EVM
 type TxBuilder evmbuilder.TxBuilder
 
@@ -50,28 +50,69 @@ func parseInput(input xc.TxInput) (*TxInput, error) {
 }
 
 func (*LegacyEvmTxBuilder) BuildTxWithPayload(chain *xc.ChainConfig, to xc.Address, value xc.BigInt, data []byte, inputRaw xc.TxInput) (xc.Tx, error) {
-	address, err := evmaddress.FromHex(to)
-	if err != nil {
-		return nil, err
+	if to == "" {
+		return nil, errors.New("missing destination address")
 	}
+	return buildLegacyTx(chain, to, value, data, inputRaw)
+}
+
+func (*LegacyEvmTxBuilder) BuildContractCreation(chain *xc.ChainConfig, value xc.BigInt, data []byte, inputRaw xc.TxInput) (xc.Tx, error) {
+	return buildLegacyTx(chain, "", value, data, inputRaw)
+}
+
+// An empty `to` address creates a contract
+func buildLegacyTx(chain *xc.ChainConfig, to xc.Address, value xc.BigInt, data []byte, inputRaw xc.TxInput) (xc.Tx, error) {
 	chainID := new(big.Int).SetInt64(chain.ChainID)
 	input, err := parseInput(inputRaw)
 	if err != nil {
 		return nil, err
 	}
-	// Protection from setting very high gas tip
-	// TODO
+	// Protection from setting very high gas price
+	gasPrice := input.GasPrice
+	if chain.ChainMaxGasPrice > 0 {
+		maxGasPrice := GweiToWei(chain.ChainMaxGasPrice)
+		if gasPrice.Cmp(&maxGasPrice) > 0 {
+			// limit to max
+			gasPrice = maxGasPrice
+		}
+	}
 
+	var feePayer *common.Address
+	if input.FeePayer != "" {
+		if chain.Chain != xc.KLAY {
+			return nil, fmt.Errorf("fee delegation is not supported on %s", chain.Chain)
+		}
+		if to == "" {
+			return nil, fmt.Errorf("fee-delegated contract deployments are not supported")
+		}
+		address, err := evmaddress.FromHex(input.FeePayer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee payer: %v", err)
+		}
+		feePayer = &address
+	}
+
+	if to == "" {
+		return &Tx{
+			EthTx:  types.NewContractCreation(input.Nonce, value.Int(), input.GasLimit, gasPrice.Int(), data),
+			Signer: types.LatestSignerForChainID(chainID),
+		}, nil
+	}
+	address, err := evmaddress.FromHex(to)
+	if err != nil {
+		return nil, err
+	}
 	return &Tx{
 		EthTx: types.NewTransaction(
 			input.Nonce,
 			address,
 			value.Int(),
 			input.GasLimit,
-			input.GasPrice.Int(),
+			gasPrice.Int(),
 			data,
 		),
-		Signer: types.LatestSignerForChainID(chainID),
+		Signer:   types.LatestSignerForChainID(chainID),
+		FeePayer: feePayer,
 	}, nil
 }
 
@@ -95,3 +136,8 @@ func (txBuilder TxBuilder) NewTask(args *xcbuilder.TransferArgs, input xc.TxInpu
 	inputEvm := (*evminput.TxInput)(input.(*TxInput))
 	return evmbuilder.TxBuilder(txBuilder).NewTask(args, inputEvm)
 }
+
+func (txBuilder TxBuilder) NewContractDeployment(deployment *evmbuilder.ContractDeployment, input xc.TxInput) (xc.Tx, error) {
+	inputEvm := (*evminput.TxInput)(input.(*TxInput))
+	return evmbuilder.TxBuilder(txBuilder).NewContractDeployment(deployment, inputEvm)
+}
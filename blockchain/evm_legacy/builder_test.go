@@ -28,7 +28,7 @@ func TestBuilderLegacyTransfer(t *testing.T) {
 	fmt.Println("--- ", input.GetProtocol())
 	fmt.Printf("--- %T\n", input)
 
-	input.GasTipCap = builder.GweiToWei(evm_legacy.DefaultMaxTipCapGwei - 1)
+	input.GasPrice = builder.GweiToWei(1)
 	trans, err := b.NewTransfer(args, input)
 	require.NoError(t, err)
 	require.NotNil(t, trans)
@@ -41,3 +41,60 @@ func TestBuilderLegacyTransfer(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, trans)
 }
+
+func TestBuilderFeeDelegatedTransfer(t *testing.T) {
+	from := xc.Address("0x724435CC1B2821362c2CD425F2744Bd7347bf299")
+	to := xc.Address("0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F")
+	feePayer := xc.Address("0x5A0043070275d9f6054307Ee7348bD660849D90f")
+	args, err := xcbuilder.NewTransferArgs(from, to, xc.NewBigIntFromUint64(100))
+	require.NoError(t, err)
+	input := evm_legacy.NewTxInput()
+	input.FeePayer = feePayer
+
+	b, _ := evm_legacy.NewTxBuilder(&xc.ChainConfig{Chain: xc.KLAY, ChainID: 8217})
+	trans, err := b.NewTransfer(args, input)
+	require.NoError(t, err)
+	require.EqualValues(t, feePayer, trans.(*evm_legacy.Tx).FeePayer.String())
+	sighashes, err := trans.Sighashes()
+	require.NoError(t, err)
+	require.Len(t, sighashes, 1, "the sender signs before the fee payer")
+
+	b, _ = evm_legacy.NewTxBuilder(&xc.ChainConfig{Chain: xc.BNB})
+	_, err = b.NewTransfer(args, input)
+	require.Contains(t, err.Error(), "fee delegation is not supported on BNB")
+}
+
+func TestBuilderMaxGasPrice(t *testing.T) {
+	from := xc.Address("0x724435CC1B2821362c2CD425F2744Bd7347bf299")
+	to := xc.Address("0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F")
+	args, err := xcbuilder.NewTransferArgs(from, to, xc.NewBigIntFromUint64(100))
+	require.NoError(t, err)
+	input := evm_legacy.NewTxInput()
+	input.GasPrice = evm_legacy.GweiToWei(150)
+
+	b, _ := evm_legacy.NewTxBuilder(&xc.ChainConfig{Chain: xc.BNB, ChainMaxGasPrice: 100})
+	trans, err := b.NewNativeTransfer(args, input)
+	require.NoError(t, err)
+	require.Equal(t, evm_legacy.GweiToWei(100).String(), trans.(*evm_legacy.Tx).EthTx.GasPrice().String())
+
+	b, _ = evm_legacy.NewTxBuilder(&xc.ChainConfig{Chain: xc.BNB})
+	trans, err = b.NewNativeTransfer(args, input)
+	require.NoError(t, err)
+	require.Equal(t, evm_legacy.GweiToWei(150).String(), trans.(*evm_legacy.Tx).EthTx.GasPrice().String())
+}
+
+func TestBuilderContractDeployment(t *testing.T) {
+	input := evm_legacy.NewTxInput()
+	input.GasLimit = 500_000
+	deployment, err := builder.NewContractDeployment([]byte{0x60, 0x80}, "")
+	require.NoError(t, err)
+
+	b, _ := evm_legacy.NewTxBuilder(&xc.ChainConfig{Chain: xc.BNB, ChainID: 56})
+	trans, err := b.NewContractDeployment(deployment, input)
+	require.NoError(t, err)
+	require.Nil(t, trans.(*evm_legacy.Tx).EthTx.To())
+
+	// only a deployment creates a contract, other payloads need a destination
+	_, err = (&evm_legacy.LegacyEvmTxBuilder{}).BuildTxWithPayload(&xc.ChainConfig{Chain: xc.BNB, ChainID: 56}, "", xc.NewBigIntFromUint64(0), []byte{0x60, 0x80}, input)
+	require.Contains(t, err.Error(), "missing destination address")
+}
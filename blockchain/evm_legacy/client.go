@@ -11,6 +11,8 @@ import (
 	xclient "github.com/CustodyOne/chainkit/client"
 	"github.com/CustodyOne/chainkit/factory/protocols/registry"
 	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shopspring/decimal"
 )
 
 type Client struct {
@@ -35,14 +37,34 @@ func (input *TxInput) GetProtocol() xc.Protocol {
 	return xc.ProtocolEVMLegacy
 }
 
+// Legacy transactions only have a gas price to adjust
 func (input *TxInput) SetGasFeePriority(other xc.GasFeePriority) error {
-	return ((*evminput.TxInput)(input)).SetGasFeePriority(other)
+	multiplier, err := other.GetDefault()
+	if err != nil {
+		return err
+	}
+	multipliedGasPrice := multiplier.Mul(decimal.NewFromBigInt(input.GasPrice.Int(), 0)).BigInt()
+	input.GasPrice = xc.BigInt(*multipliedGasPrice)
+	return nil
 }
+
+// Nonce conflicts are the same as for evm transactions, which the checks are shared with
 func (input *TxInput) IndependentOf(other xc.TxInput) (independent bool) {
-	return ((*evminput.TxInput)(input)).IndependentOf(other)
+	if legacyOther, ok := other.(*TxInput); ok {
+		return (*evminput.TxInput)(input).IndependentOf((*evminput.TxInput)(legacyOther))
+	}
+	return
 }
-func (input *TxInput) SafeFromDoubleSend(other ...xc.TxInput) (independent bool) {
-	return ((*evminput.TxInput)(input)).SafeFromDoubleSend(other...)
+
+func (input *TxInput) SafeFromDoubleSend(others ...xc.TxInput) (safe bool) {
+	if !xc.SameTxInputTypes(input, others...) {
+		return false
+	}
+	evmOthers := make([]xc.TxInput, len(others))
+	for i, other := range others {
+		evmOthers[i] = (*evminput.TxInput)(other.(*TxInput))
+	}
+	return (*evminput.TxInput)(input).SafeFromDoubleSend(evmOthers...)
 }
 
 func NewClient(cfg *xc.ChainConfig) (*Client, error) {
@@ -62,6 +84,9 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 	zero := xc.NewBigIntFromUint64(0)
 	result := NewTxInput()
 	result.GasPrice = zero
+	if feePayer, ok := args.GetFeePayer(); ok {
+		result.FeePayer = feePayer
+	}
 
 	// Nonce
 	nonce, err := client.evmClient.GetNonce(ctx, args.GetFrom())
@@ -74,11 +99,11 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 		result.GasPrice = zero
 	} else {
 		// legacy gas fees
-		baseFee, err := client.evmClient.EthClient.SuggestGasPrice(ctx)
+		gasPrice, err := client.FetchGasPrice(ctx)
 		if err != nil {
 			return result, err
 		}
-		result.GasPrice = xc.BigInt(*baseFee).ApplyGasPriceMultiplier(nativeAsset)
+		result.GasPrice = client.replacePendingGasPrice(ctx, args.GetFrom(), gasPrice)
 	}
 	builder, err := NewTxBuilder(client.evmClient.Chain)
 	if err != nil {
@@ -92,6 +117,9 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 	if err != nil {
 		return nil, err
 	}
+	if result.FeePayer != "" {
+		gasLimit += evmclient.KaiaFeeDelegationGas
+	}
 	result.GasLimit = gasLimit
 
 	return result, nil
@@ -104,6 +132,18 @@ func (client *Client) FetchLegacyTxInput(ctx context.Context, from xc.Address, t
 }
 
 func (client *Client) BroadcastTx(ctx context.Context, txInput xc.Tx) error {
+	if legacyTx, ok := txInput.(*Tx); ok && legacyTx.FeePayer != nil {
+		// fee-delegated transactions are only accepted through the klay namespace
+		bz, err := legacyTx.Serialize()
+		if err != nil {
+			return err
+		}
+		err = client.evmClient.EthClient.Client().CallContext(ctx, nil, "klay_sendRawTransaction", hexutil.Encode(bz))
+		if err != nil {
+			return fmt.Errorf("sending transaction '%v': %v", legacyTx.Hash(), err)
+		}
+		return nil
+	}
 	return client.evmClient.BroadcastTx(ctx, txInput)
 }
 
@@ -115,10 +155,18 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (*xclie
 	return client.evmClient.FetchTxInfo(ctx, txHash)
 }
 
+func (client *Client) Simulate(ctx context.Context, tx xc.Tx) (*xclient.TxInfo, error) {
+	return client.evmClient.Simulate(ctx, tx)
+}
+
 func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (*xc.BigInt, error) {
 	return client.evmClient.FetchNativeBalance(ctx, address)
 }
 
+func (client *Client) FetchBalanceAt(ctx context.Context, address xc.Address, contract xc.ContractAddress, args xclient.ReadArgs) (*xc.BigInt, error) {
+	return client.evmClient.FetchBalanceAt(ctx, address, contract, args)
+}
+
 func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (*xc.BigInt, error) {
 	return client.evmClient.FetchBalance(ctx, address)
 }
@@ -130,3 +178,7 @@ func (client *Client) FetchBalanceForAsset(ctx context.Context, address xc.Addre
 func (client *Client) EstimateGasFee(ctx context.Context, tx xc.Tx) (*xc.BigInt, error) {
 	return client.evmClient.EstimateGasFee(ctx, tx)
 }
+
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc.ContractAddress) (*xclient.TokenMetadata, error) {
+	return client.evmClient.FetchTokenMetadata(ctx, contract)
+}
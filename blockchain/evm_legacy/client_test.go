@@ -2,16 +2,25 @@ package evm_legacy_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	evmclient "github.com/CustodyOne/chainkit/blockchain/evm/client"
+	evminput "github.com/CustodyOne/chainkit/blockchain/evm/tx_input"
 	"github.com/CustodyOne/chainkit/blockchain/evm_legacy"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
 	testtypes "github.com/CustodyOne/chainkit/testutil/types"
 	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
+var txpoolUnsupported = `{"jsonrpc":"2.0","id":0,"error":{"code":-32601,"message":"the method txpool_contentFrom does not exist/is not available"}}`
+
 func TestNewClient(t *testing.T) {
 	client, err := evm_legacy.NewClient(&xc.ChainConfig{})
 	require.NoError(t, err)
@@ -35,6 +44,8 @@ func TestFetchTxInput(t *testing.T) {
 				`"0x6"`,
 				// eth_gasPrice
 				`"0xba43b7400"`,
+				// txpool_contentFrom
+				txpoolUnsupported,
 				// eth_estimateGas
 				`"0x52e4"`,
 			},
@@ -53,6 +64,8 @@ func TestFetchTxInput(t *testing.T) {
 				`"0x6"`,
 				// eth_gasPrice
 				`"0xba43b7400"`,
+				// txpool_contentFrom
+				txpoolUnsupported,
 				// eth_estimateGas
 				`"0x52e4"`,
 			},
@@ -116,3 +129,228 @@ func TestFetchBalance_ArbETH(t *testing.T) {
 	require.NotNil(t, balance)
 	log.Printf("OpenWorld balance: %v\n", balance)
 }
+
+func TestFetchTxInputWithFeePayer(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, []string{
+		// eth_getTransactionCount
+		`"0x6"`,
+		// eth_gasPrice
+		`"0xba43b7400"`,
+		// txpool_contentFrom
+		txpoolUnsupported,
+		// eth_estimateGas
+		`"0x5208"`,
+	})
+	defer close()
+	client, err := evm_legacy.NewClient(&xc.ChainConfig{
+		Client:   &xc.ClientConfig{URL: server.URL},
+		Chain:    xc.KLAY,
+		Protocol: xc.ProtocolEVMLegacy,
+	})
+	require.NoError(t, err)
+	feePayer := xc.Address("0x5A0043070275d9f6054307Ee7348bD660849D90f")
+	args, err := xcbuilder.NewTransferArgs(
+		"0x724435CC1B2821362c2CD425F2744Bd7347bf299",
+		"0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F",
+		xc.NewBigIntFromUint64(1),
+		xcbuilder.WithFeePayer(feePayer),
+	)
+	require.NoError(t, err)
+
+	input, err := client.FetchTransferInput(context.Background(), args)
+	require.NoError(t, err)
+	require.Equal(t, feePayer, input.(*evm_legacy.TxInput).FeePayer)
+	require.EqualValues(t, 21_000+evmclient.KaiaFeeDelegationGas, input.(*evm_legacy.TxInput).GasLimit)
+}
+
+func TestBroadcastFeeDelegatedTx(t *testing.T) {
+	methods := []string{}
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		methods = append(methods, request.Method)
+		return `"0x1111111111111111111111111111111111111111111111111111111111111111"`, true
+	})
+	defer server.Close()
+	cfg := &xc.ChainConfig{
+		Client:   &xc.ClientConfig{URL: server.URL},
+		Chain:    xc.KLAY,
+		ChainID:  8217,
+		Protocol: xc.ProtocolEVMLegacy,
+	}
+	client, err := evm_legacy.NewClient(cfg)
+	require.NoError(t, err)
+
+	args, err := xcbuilder.NewTransferArgs(
+		"0x724435CC1B2821362c2CD425F2744Bd7347bf299",
+		"0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F",
+		xc.NewBigIntFromUint64(1),
+	)
+	require.NoError(t, err)
+	input := evm_legacy.NewTxInput()
+	input.FeePayer = "0x5A0043070275d9f6054307Ee7348bD660849D90f"
+	builder, _ := evm_legacy.NewTxBuilder(cfg)
+	trans, err := builder.NewTransfer(args, input)
+	require.NoError(t, err)
+
+	err = client.BroadcastTx(context.Background(), trans)
+	require.NoError(t, err)
+	require.Equal(t, []string{"klay_sendRawTransaction"}, methods)
+}
+
+func TestEstimateFeeDelegatedGasFee(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	estimatedFrom := []string{}
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		switch request.Method {
+		case "eth_estimateGas":
+			var msg struct {
+				From string `json:"from"`
+			}
+			require.NoError(t, json.Unmarshal(request.Params[0], &msg))
+			estimatedFrom = append(estimatedFrom, msg.From)
+			return `"0x5208"`, true
+		case "eth_gasPrice":
+			return `"0xba43b7400"`, true
+		}
+		return "", false
+	})
+	defer server.Close()
+	cfg := &xc.ChainConfig{
+		Client:   &xc.ClientConfig{URL: server.URL},
+		Chain:    xc.KLAY,
+		ChainID:  8217,
+		Protocol: xc.ProtocolEVMLegacy,
+	}
+	client, err := evm_legacy.NewClient(cfg)
+	require.NoError(t, err)
+
+	args, err := xcbuilder.NewTransferArgs(xc.Address(from.String()), "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F", xc.NewBigIntFromUint64(1))
+	require.NoError(t, err)
+	input := evm_legacy.NewTxInput()
+	input.FeePayer = "0x5A0043070275d9f6054307Ee7348bD660849D90f"
+	input.GasLimit = 21_000 + evmclient.KaiaFeeDelegationGas
+	builder, _ := evm_legacy.NewTxBuilder(cfg)
+	trans, err := builder.NewTransfer(args, input)
+	require.NoError(t, err)
+	sighashes, err := trans.Sighashes()
+	require.NoError(t, err)
+	sig, err := crypto.Sign(sighashes[0], key)
+	require.NoError(t, err)
+	require.NoError(t, trans.AddSignatures(sig))
+
+	// the sender is recovered from the Kaia signature, and the fee payer pays for the extra gas
+	fee, err := client.EstimateGasFee(context.Background(), trans)
+	require.NoError(t, err)
+	require.Equal(t, []string{strings.ToLower(from.String())}, estimatedFrom)
+	require.EqualValues(t, (21_000+evmclient.KaiaFeeDelegationGas)*50_000_000_000, fee.Uint64())
+}
+
+// Responds to the given methods, and rejects all others as unsupported
+func mockLegacyRpc(t *testing.T, responses map[string]string) *httptest.Server {
+	return testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		method := request.Method
+		if method == "eth_getBlockByNumber" {
+			method += string(request.Params[0])
+		}
+		result, ok := responses[method]
+		return result, ok
+	})
+}
+
+func TestFetchGasPrice(t *testing.T) {
+	from := xc.Address("0x724435CC1B2821362c2CD425F2744Bd7347bf299")
+	gwei := func(amount uint64) xc.BigInt {
+		return xc.NewBigIntFromUint64(amount * 1_000_000_000)
+	}
+	blocks := map[string]string{
+		"eth_blockNumber": `"0x2"`,
+		// system transactions are free and not sampled
+		`eth_getBlockByNumber"0x2"`: `{"transactions":[{"gasPrice":"0x0"},{"gasPrice":"0x3b9aca00"},{"gasPrice":"0x77359400"}]}`,
+		`eth_getBlockByNumber"0x1"`: `{"transactions":[{"gasPrice":"0xb2d05e00"},{"gasPrice":"0xee6b2800"}]}`,
+		`eth_getBlockByNumber"0x0"`: `{"transactions":[]}`,
+	}
+	for _, v := range []struct {
+		name      string
+		chain     xc.ChainConfig
+		responses map[string]string
+		gasPrice  xc.BigInt
+	}{
+		{
+			name:      "eth_gasPrice",
+			responses: map[string]string{"eth_gasPrice": `"0xba43b7400"`},
+			gasPrice:  gwei(50),
+		},
+		{
+			name:      "minimum gas price",
+			chain:     xc.ChainConfig{ChainMinGasPrice: 60},
+			responses: map[string]string{"eth_gasPrice": `"0xba43b7400"`},
+			gasPrice:  gwei(60),
+		},
+		{
+			name:      "sampled median",
+			chain:     xc.ChainConfig{ChainGasPricePercentile: 50},
+			responses: blocks,
+			gasPrice:  gwei(2),
+		},
+		{
+			name:      "sampled high percentile with multiplier",
+			chain:     xc.ChainConfig{ChainGasPricePercentile: 90, ChainGasMultiplier: 1.5},
+			responses: blocks,
+			gasPrice:  gwei(6),
+		},
+		{
+			name:      "sampling falls back to eth_gasPrice",
+			chain:     xc.ChainConfig{ChainGasPricePercentile: 50},
+			responses: map[string]string{"eth_blockNumber": `"0x0"`, `eth_getBlockByNumber"0x0"`: `{"transactions":[]}`, "eth_gasPrice": `"0xba43b7400"`},
+			gasPrice:  gwei(50),
+		},
+		{
+			name: "replace pending tx",
+			responses: map[string]string{
+				"eth_gasPrice":       `"0xba43b7400"`,
+				"txpool_contentFrom": fmt.Sprintf(`{"pending":{"6":{"from":"%s","gasPrice":"0xba43b7400","hash":"0x11"}}}`, from),
+			},
+			gasPrice: xc.NewBigIntFromUint64(57_500_000_000),
+		},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			v.responses["eth_getTransactionCount"] = `"0x6"`
+			v.responses["eth_estimateGas"] = `"0x5208"`
+			server := mockLegacyRpc(t, v.responses)
+			defer server.Close()
+			cfg := v.chain
+			cfg.Chain = xc.BNB
+			cfg.Client = &xc.ClientConfig{URL: server.URL}
+			client, err := evm_legacy.NewClient(&cfg)
+			require.NoError(t, err)
+			args, err := xcbuilder.NewTransferArgs(from, "0x3ad57b83B2E3dC5648F32e98e386935A9B10bb9F", xc.NewBigIntFromUint64(1))
+			require.NoError(t, err)
+
+			input, err := client.FetchTransferInput(context.Background(), args)
+			require.NoError(t, err)
+			require.Equal(t, v.gasPrice.String(), input.(*evm_legacy.TxInput).GasPrice.String())
+		})
+	}
+}
+
+func TestTxInputGasFeePriority(t *testing.T) {
+	input := evm_legacy.NewTxInput()
+	input.GasPrice = xc.NewBigIntFromUint64(100)
+	input.GasTipCap = xc.NewBigIntFromUint64(10)
+	require.NoError(t, input.SetGasFeePriority(xc.Aggressive))
+	require.EqualValues(t, 150, input.GasPrice.Uint64())
+	require.EqualValues(t, 10, input.GasTipCap.Uint64(), "only the gas price is used")
+}
+
+func TestTxInputConflicts(t *testing.T) {
+	input := &evm_legacy.TxInput{Nonce: 5}
+	same := &evm_legacy.TxInput{Nonce: 5}
+	next := &evm_legacy.TxInput{Nonce: 6}
+
+	require.False(t, input.IndependentOf(same))
+	require.True(t, input.IndependentOf(next))
+	require.True(t, input.SafeFromDoubleSend(same))
+	require.False(t, input.SafeFromDoubleSend(same, next))
+	require.False(t, input.SafeFromDoubleSend(&evminput.TxInput{Nonce: 5}), "different input types are not comparable")
+}
@@ -0,0 +1,120 @@
+package evm_legacy
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+
+	evmaddress "github.com/CustodyOne/chainkit/blockchain/evm/address"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+// Number of recent blocks to sample gas prices from, when a percentile is configured
+const GasPriceSampleBlocks = 5
+
+// A pending transaction must be replaced with a higher gas price (nodes require at least 10%)
+const ReplacementGasPriceMultiplier = 1.15
+
+// ChainMinGasPrice and ChainMaxGasPrice are configured in gwei
+func GweiToWei(gwei float64) xc.BigInt {
+	return xc.BigInt(*decimal.NewFromFloat(gwei).Shift(9).BigInt())
+}
+
+type sampledBlock struct {
+	Transactions []struct {
+		GasPrice hexutil.Big `json:"gasPrice"`
+	} `json:"transactions"`
+}
+
+// SampleGasPrice returns the given percentile of the gas prices paid in the most recent blocks
+func (client *Client) SampleGasPrice(ctx context.Context, percentile float64) (xc.BigInt, error) {
+	latest, err := client.evmClient.EthClient.BlockNumber(ctx)
+	if err != nil {
+		return xc.BigInt{}, err
+	}
+	prices := []*big.Int{}
+	for i := uint64(0); i < GasPriceSampleBlocks && i <= latest; i++ {
+		var block sampledBlock
+		err = client.evmClient.EthClient.Client().CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeUint64(latest-i), true)
+		if err != nil {
+			return xc.BigInt{}, err
+		}
+		for _, tx := range block.Transactions {
+			// skip system transactions
+			if tx.GasPrice.ToInt().Sign() > 0 {
+				prices = append(prices, tx.GasPrice.ToInt())
+			}
+		}
+	}
+	if len(prices) == 0 {
+		return xc.BigInt{}, errors.New("no transactions in recent blocks to sample gas prices from")
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	index := int(math.Ceil(percentile/100*float64(len(prices)))) - 1
+	index = max(0, min(index, len(prices)-1))
+	return xc.BigInt(*prices[index]), nil
+}
+
+// FetchGasPrice uses the configured percentile of recent gas prices, falling back to eth_gasPrice,
+// and then applies the chain's multiplier and minimum gas price.
+func (client *Client) FetchGasPrice(ctx context.Context) (xc.BigInt, error) {
+	chain := client.evmClient.Chain
+	var gasPrice xc.BigInt
+	var err error
+	if chain.ChainGasPricePercentile > 0 {
+		gasPrice, err = client.SampleGasPrice(ctx, chain.ChainGasPricePercentile)
+		if err != nil {
+			zap.S().Warn("could not sample gas prices, using eth_gasPrice", zap.Error(err))
+		}
+	}
+	if chain.ChainGasPricePercentile <= 0 || err != nil {
+		suggested, err := client.evmClient.EthClient.SuggestGasPrice(ctx)
+		if err != nil {
+			return xc.BigInt{}, err
+		}
+		gasPrice = xc.BigInt(*suggested)
+	}
+	gasPrice = gasPrice.ApplyGasPriceMultiplier(chain)
+
+	minGasPrice := GweiToWei(chain.ChainMinGasPrice)
+	if gasPrice.Cmp(&minGasPrice) < 0 {
+		gasPrice = minGasPrice
+	}
+	return gasPrice, nil
+}
+
+// Raise the gas price to replace a pending transaction from the same address, if there is one
+func (client *Client) replacePendingGasPrice(ctx context.Context, from xc.Address, gasPrice xc.BigInt) xc.BigInt {
+	fromAddr, _ := evmaddress.FromHex(from)
+	pendingTxInfo, err := client.evmClient.TxPoolContentFrom(ctx, fromAddr)
+	if err != nil {
+		zap.S().Debug("could not see pending tx pool",
+			zap.String("from", string(from)),
+			zap.Error(err),
+		)
+		return gasPrice
+	}
+	pending, ok := pendingTxInfo.InfoFor(string(from))
+	if !ok {
+		return gasPrice
+	}
+	minGasPrice := xc.MultiplyByFloat(xc.BigInt(*pending.GasPrice.ToInt()), ReplacementGasPriceMultiplier)
+	if gasPrice.Cmp(&minGasPrice) < 0 {
+		logrus.WithFields(logrus.Fields{
+			"from":          from,
+			"old-tx":        pending.Hash,
+			"old-gas-price": gasPrice.String(),
+			"new-gas-price": minGasPrice.String(),
+		}).Debug("replacing gas price because of pending tx")
+		return minGasPrice
+	}
+	return gasPrice
+}
@@ -19,8 +19,15 @@ import (
 // Max number of token transfers we can fit in a solana transaction,
 // when there's also a create ATA included.
 const MaxTokenTransfers = 20
+
+// Source token accounts in an address lookup table take one byte rather than 32, so more fit
+const MaxTokenTransfersWithLookupTables = 40
+
+// Max size of a serialized transaction
+const MaxTransactionSize = 1232
 const MaxAccountUnstakes = 20
 const MaxAccountWithdraws = 20
+const MaxAccountMerges = 10
 
 type TxBuilder struct {
 	Chain *xc_types.ChainConfig
@@ -105,7 +112,7 @@ func (b *TxBuilder) NewTokenTransfer(args *xcbuilder.TransferArgs, input xc_type
 	instructions := []solana.Instruction{}
 	if txInput.ShouldCreateATA {
 		createAta := ata.NewCreateInstruction(
-			accountFrom,
+			txInput.GetFeePayer(accountFrom),
 			accountTo,
 			accountContract,
 		).Build()
@@ -118,19 +125,58 @@ func (b *TxBuilder) NewTokenTransfer(args *xcbuilder.TransferArgs, input xc_type
 			createAta,
 		)
 	}
-	if len(txInput.SourceTokenAccounts) <= 1 {
-		// just send 1 instruction using the single ATA
+	memoText := getMemo(args, txInput)
+	if memoText == "" && txInput.ToRequiresMemo {
+		return nil, fmt.Errorf("a memo is required to transfer to %s", args.GetTo())
+	}
+	if memoText != "" && !txInput.ToRequiresMemo {
+		instructions = append(instructions, solana_types.NewMemoInstruction(memoText, accountFrom))
+	}
+	appendTransfer := func(amount uint64, source solana.PublicKey) {
+		if txInput.ToRequiresMemo {
+			// the memo must immediately precede each transfer
+			instructions = append(instructions, solana_types.NewMemoInstruction(memoText, accountFrom))
+		}
+		if txInput.TransferFee != nil {
+			instructions = append(instructions,
+				solana_types.NewTransferCheckedWithFeeInstruction(txInput.TokenProgram, &solana_types.TransferCheckedWithFee{
+					Source:      source,
+					Mint:        accountContract,
+					Destination: ataTo,
+					Owner:       accountFrom,
+					Amount:      amount,
+					Decimals:    uint8(decimals),
+					Fee:         txInput.TransferFee.Calculate(amount),
+				}),
+			)
+			return
+		}
 		instructions = append(instructions,
 			token.NewTransferCheckedInstruction(
-				args.GetAmount().Uint64(),
+				amount,
 				uint8(decimals),
-				ataFrom,
+				source,
 				accountContract,
 				ataTo,
 				accountFrom,
 				[]solana.PublicKey{},
 			).Build(),
 		)
+	}
+
+	if len(txInput.SourceTokenAccounts) <= 1 {
+		// just send 1 instruction using the single ATA
+		amount := args.GetAmount().Uint64()
+		if txInput.TransferFee != nil {
+			amount = txInput.TransferFee.PreFeeAmount(amount)
+			if len(txInput.SourceTokenAccounts) == 1 && txInput.SourceTokenAccounts[0].Balance.Uint64() < amount {
+				return nil, fmt.Errorf(
+					"cannot send %s plus the transfer fee of %d, the balance is %s",
+					args.GetAmount().String(), amount-args.GetAmount().Uint64(), txInput.SourceTokenAccounts[0].Balance.String(),
+				)
+			}
+		}
+		appendTransfer(amount, ataFrom)
 	} else {
 		// Sometimes tokens can get put into any number of auxiliary accounts.
 		// So we need to spend them like UTXO. Here we'll just send a solana
@@ -139,28 +185,26 @@ func (b *TxBuilder) NewTokenTransfer(args *xcbuilder.TransferArgs, input xc_type
 		remainingBalanceToSend := args.GetAmount()
 		for _, tokenAcc := range txInput.SourceTokenAccounts {
 			amountToSend := remainingBalanceToSend
-			if tokenAcc.Balance.Cmp(&remainingBalanceToSend) < 0 {
+			if txInput.TransferFee != nil {
+				amountToSend = xc_types.NewBigIntFromUint64(txInput.TransferFee.PreFeeAmount(remainingBalanceToSend.Uint64()))
+			}
+			if tokenAcc.Balance.Cmp(&amountToSend) < 0 {
 				// Send everything in the token account
 				amountToSend = tokenAcc.Balance
 			}
 			amountToSendUint := amountToSend.Uint64()
-			instructions = append(instructions,
-				token.NewTransferCheckedInstruction(
-					amountToSendUint,
-					uint8(decimals),
-					tokenAcc.Account,
-					accountContract,
-					ataTo,
-					accountFrom,
-					[]solana.PublicKey{},
-				).Build(),
-			)
-			remainingBalanceToSend = remainingBalanceToSend.Sub(&amountToSend)
+			appendTransfer(amountToSendUint, tokenAcc.Account)
+
+			amountReceived := amountToSend
+			if txInput.TransferFee != nil {
+				amountReceived = xc_types.NewBigIntFromUint64(amountToSendUint - txInput.TransferFee.Calculate(amountToSendUint))
+			}
+			remainingBalanceToSend = remainingBalanceToSend.Sub(&amountReceived)
 			if remainingBalanceToSend.Cmp(&zero) <= 0 {
 				// we've spent enough from source accounts to meet target balance
 				break
 			}
-			if len(instructions) > MaxTokenTransfers {
+			if len(instructions) > GetMaxTokenTransfers(txInput) {
 				return nil, errors.New("cannot send total amount in single tx, try sending smaller amount")
 			}
 		}
@@ -180,20 +224,104 @@ func (b *TxBuilder) NewTokenTransfer(args *xcbuilder.TransferArgs, input xc_type
 	return b.buildSolanaTx(instructions, accountFrom, txInput)
 }
 
+// Returns the memo of the transfer, which may also have been set on the input
+func getMemo(args *xcbuilder.TransferArgs, txInput *tx_input.TxInput) string {
+	if memo, ok := args.GetMemo(); ok {
+		return memo
+	}
+	return txInput.Memo
+}
+
 func (txBuilder TxBuilder) buildSolanaTx(instructions []solana.Instruction, accountFrom solana.PublicKey, txInput *tx_input.TxInput) (*tx.Tx, error) {
+	if txInput.UsesDurableNonce() {
+		// advancing the nonce must be the first instruction
+		nonceAuthority := txInput.NonceAuthority
+		if nonceAuthority.IsZero() {
+			nonceAuthority = accountFrom
+		}
+		instructions = append([]solana.Instruction{
+			system.NewAdvanceNonceAccountInstruction(
+				txInput.NonceAccount,
+				solana.SysVarRecentBlockHashesPubkey,
+				nonceAuthority,
+			).Build(),
+		}, instructions...)
+	}
+	if txInput.ComputeUnitLimit > 0 {
+		instructions = append(instructions, compute_budget.NewSetComputeUnitLimitInstruction(txInput.ComputeUnitLimit).Build())
+	}
+	feePayer := txInput.GetFeePayer(accountFrom)
 	tx1, err := solana.NewTransaction(
 		instructions,
 		txInput.RecentBlockHash,
-		solana.TransactionPayer(accountFrom),
+		solana.TransactionPayer(feePayer),
 	)
 	if err != nil {
 		return nil, err
 	}
+	if len(txInput.AddressLookupTables) > 0 {
+		size, err := transactionSize(tx1)
+		if err != nil {
+			return nil, err
+		}
+		if size > MaxTransactionSize {
+			// too large for a legacy message, so use a v0 message that loads accounts from the lookup tables
+			tables := map[solana.PublicKey]solana.PublicKeySlice{}
+			for _, table := range txInput.AddressLookupTables {
+				tables[table.Account] = table.Addresses
+			}
+			tx1, err = solana.NewTransaction(
+				instructions,
+				txInput.RecentBlockHash,
+				solana.TransactionPayer(feePayer),
+				solana.TransactionAddressTables(tables),
+			)
+			if err != nil {
+				return nil, err
+			}
+			size, err = transactionSize(tx1)
+			if err != nil {
+				return nil, err
+			}
+			if size > MaxTransactionSize {
+				return nil, fmt.Errorf("transaction is too large (%d bytes, the max is %d) even with address lookup tables, try sending a smaller amount", size, MaxTransactionSize)
+			}
+		}
+	}
 	return &tx.Tx{
 		SolTx: tx1,
 	}, nil
 }
 
+// GetMaxTokenTransfers returns how many source token accounts may be spent in a single transaction.
+// More may only be spent if they are in the address lookup tables.
+func GetMaxTokenTransfers(txInput *tx_input.TxInput) int {
+	if len(txInput.AddressLookupTables) == 0 {
+		return MaxTokenTransfers
+	}
+	sourceAccounts := txInput.SourceTokenAccounts
+	if len(sourceAccounts) > MaxTokenTransfersWithLookupTables {
+		sourceAccounts = sourceAccounts[:MaxTokenTransfersWithLookupTables]
+	}
+	for _, sourceAccount := range sourceAccounts {
+		if !txInput.InAddressLookupTable(sourceAccount.Account) {
+			return MaxTokenTransfers
+		}
+	}
+	return MaxTokenTransfersWithLookupTables
+}
+
+// The size of the transaction once it's signed
+func transactionSize(solTx *solana.Transaction) (int, error) {
+	message, err := solTx.Message.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	numSignatures := int(solTx.Message.Header.NumRequiredSignatures)
+	// compact-u16 length prefix, which is 1 byte for fewer than 128 signatures
+	return 1 + numSignatures*solana.SignatureLength + len(message), nil
+}
+
 func (b *TxBuilder) NewNativeTransfer(args *xcbuilder.TransferArgs, input xc_types.TxInput) (xc_types.Tx, error) {
 	txInput := input.(*tx_input.TxInput)
 
@@ -207,30 +335,24 @@ func (b *TxBuilder) NewNativeTransfer(args *xcbuilder.TransferArgs, input xc_typ
 		return nil, err
 	}
 
-	instructions := []solana.Instruction{
+	instructions := []solana.Instruction{}
+	if memoText := getMemo(args, txInput); memoText != "" {
+		instructions = append(instructions, solana_types.NewMemoInstruction(memoText, accountFrom))
+	}
+	instructions = append(instructions,
 		system.NewTransferInstruction(
 			args.GetAmount().Int().Uint64(),
 			accountFrom,
 			accountTo,
 		).Build(),
-	}
+	)
 
 	prioprityFee := txInput.GetLimitedPrioritizationFee(b.Chain)
 	if prioprityFee > 0 {
 		instructions = append(instructions, compute_budget.NewSetComputeUnitPriceInstruction(prioprityFee).Build())
 	}
 
-	solTx, err := solana.NewTransaction(
-		instructions,
-		txInput.RecentBlockHash,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return &tx.Tx{
-		SolTx: solTx,
-	}, nil
+	return b.buildSolanaTx(instructions, accountFrom, txInput)
 }
 
 func (txBuilder TxBuilder) NewTask(args *xcbuilder.TransferArgs, input xc_types.TxInput) (xc_types.Tx, error) {
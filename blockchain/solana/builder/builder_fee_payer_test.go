@@ -0,0 +1,61 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	ata "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenTransferWithFeePayer(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	feePayer := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	args, err := xcbuilder.NewTransferArgs(
+		xc_types.Address(from.String()),
+		xc_types.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"),
+		xc_types.NewBigIntFromUint64(1_000_000),
+		xcbuilder.WithAsset(&xc_types.TokenAssetConfig{
+			Contract:    "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+			Decimals:    6,
+			ChainConfig: &xc_types.ChainConfig{},
+		}),
+		xcbuilder.WithFeePayer(xc_types.Address(feePayer.String())),
+	)
+	require.NoError(t, err)
+	input := &tx_input.TxInput{
+		RecentBlockHash: solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		TokenProgram:    solana.TokenProgramID,
+		ShouldCreateATA: true,
+		FeePayer:        feePayer,
+	}
+	tx, err := txBuilder.NewTransfer(args, input)
+	require.NoError(t, err)
+	solTx := tx.(*Tx).SolTx
+
+	// the fee payer pays the fee and signs first
+	require.Equal(t, feePayer, solTx.Message.AccountKeys[0])
+	require.Equal(t, []solana.PublicKey{feePayer, from}, []solana.PublicKey(solTx.Message.Signers()))
+
+	// and funds the new token account
+	createAta := solTx.Message.Instructions[0]
+	program, err := solTx.Message.Program(createAta.ProgramIDIndex)
+	require.NoError(t, err)
+	require.Equal(t, ata.ProgramID, program)
+	require.Equal(t, feePayer, solTx.Message.AccountKeys[createAta.Accounts[0]])
+
+	// the sender still owns the tokens that are sent
+	transfers := tx.(*Tx).GetTokenTransferCheckeds()
+	require.Len(t, transfers, 1)
+	require.Equal(t, from, transfers[0].GetOwnerAccount().PublicKey)
+
+	// one signature for the sender and one for the fee payer
+	sighashes, err := tx.Sighashes()
+	require.NoError(t, err)
+	require.Len(t, sighashes, 2)
+}
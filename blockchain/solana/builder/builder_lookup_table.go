@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Max number of addresses that can be added to a lookup table in a single transaction
+const MaxLookupTableExtendAddresses = 20
+
+// NewCreateAddressLookupTable creates an address lookup table owned by the sender, optionally adding
+// the first addresses to it.  The table address is derived from the sender and the recent slot.
+func (txBuilder TxBuilder) NewCreateAddressLookupTable(from xc_types.Address, addresses []solana.PublicKey, input *tx_input.CreateAddressLookupTableInput) (xc_types.Tx, error) {
+	if len(addresses) > MaxLookupTableExtendAddresses {
+		return nil, fmt.Errorf("cannot add more than %d addresses to a lookup table in a single tx", MaxLookupTableExtendAddresses)
+	}
+	authority, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	create, table, err := solana_types.NewCreateLookupTableInstruction(authority, authority, input.RecentSlot)
+	if err != nil {
+		return nil, err
+	}
+	instructions := []solana.Instruction{create}
+	if len(addresses) > 0 {
+		instructions = append(instructions, solana_types.NewExtendLookupTableInstruction(table, authority, authority, addresses))
+	}
+	return txBuilder.buildSolanaTx(instructions, authority, &input.TxInput)
+}
+
+// NewExtendAddressLookupTable adds addresses to an address lookup table owned by the sender
+func (txBuilder TxBuilder) NewExtendAddressLookupTable(from xc_types.Address, table solana.PublicKey, addresses []solana.PublicKey, input *tx_input.TxInput) (xc_types.Tx, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no addresses to add to lookup table")
+	}
+	if len(addresses) > MaxLookupTableExtendAddresses {
+		return nil, fmt.Errorf("cannot add more than %d addresses to a lookup table in a single tx", MaxLookupTableExtendAddresses)
+	}
+	authority, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	instructions := []solana.Instruction{
+		solana_types.NewExtendLookupTableInstruction(table, authority, authority, addresses),
+	}
+	return txBuilder.buildSolanaTx(instructions, authority, input)
+}
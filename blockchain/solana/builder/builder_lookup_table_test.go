@@ -0,0 +1,139 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	"github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenTransferWithLookupTables(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	args, err := xcbuilder.NewTransferArgs(
+		xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+		xc_types.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"),
+		xc_types.NewBigIntFromUint64(300),
+		xcbuilder.WithAsset(&xc_types.TokenAssetConfig{
+			Contract:    "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+			Decimals:    6,
+			ChainConfig: &xc_types.ChainConfig{},
+		}),
+	)
+	require.NoError(t, err)
+
+	// more source accounts than fit in a legacy transaction
+	sourceAccounts := []*tx_input.TokenAccount{}
+	sourceAddresses := []solana.PublicKey{}
+	for i := 0; i < 30; i++ {
+		account, _ := solana.NewRandomPrivateKey()
+		sourceAccounts = append(sourceAccounts, &tx_input.TokenAccount{
+			Account: account.PublicKey(),
+			Balance: xc_types.NewBigIntFromUint64(10),
+		})
+		sourceAddresses = append(sourceAddresses, account.PublicKey())
+	}
+	input := &TxInput{
+		RecentBlockHash:     solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		TokenProgram:        solana.TokenProgramID,
+		SourceTokenAccounts: sourceAccounts,
+	}
+	_, err = txBuilder.NewTokenTransfer(args, input)
+	require.ErrorContains(t, err, "cannot send")
+
+	// a table without the source accounts does not raise the limit
+	table := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	input.AddressLookupTables = []*tx_input.AddressLookupTable{
+		{Account: table, Addresses: []solana.PublicKey{solana.TokenProgramID}},
+	}
+	require.Equal(t, builder.MaxTokenTransfers, builder.GetMaxTokenTransfers(input))
+	args.SetAmount(xc_types.NewBigIntFromUint64(300))
+	_, err = txBuilder.NewTokenTransfer(args, input)
+	require.ErrorContains(t, err, "cannot send")
+
+	input.AddressLookupTables = []*tx_input.AddressLookupTable{
+		{Account: table, Addresses: sourceAddresses},
+	}
+	require.Equal(t, builder.MaxTokenTransfersWithLookupTables, builder.GetMaxTokenTransfers(input))
+	args.SetAmount(xc_types.NewBigIntFromUint64(300))
+	built, err := txBuilder.NewTokenTransfer(args, input)
+	require.NoError(t, err)
+	solTx := built.(*Tx).SolTx
+	require.True(t, solTx.Message.IsVersioned())
+	require.Len(t, solTx.Message.AddressTableLookups, 1)
+	require.Equal(t, table, solTx.Message.AddressTableLookups[0].AccountKey)
+	require.Len(t, built.(*Tx).GetTokenTransferCheckeds(), 30)
+
+	bz, err := built.Serialize()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(bz)+solana.SignatureLength, builder.MaxTransactionSize)
+
+	// the instructions can be decoded again once the loaded addresses are known
+	decoded, err := solana.TransactionFromBytes(bz)
+	require.NoError(t, err)
+	loaded, err := solTx.Message.GetAddressTableLookupAccounts()
+	require.NoError(t, err)
+	parsed := tx.NewTxFrom(decoded)
+	require.Len(t, parsed.GetTokenTransferCheckeds(), 0)
+	require.NoError(t, parsed.SetLoadedAddresses(loaded, solana.PublicKeySlice{}))
+	transfers := parsed.GetTokenTransferCheckeds()
+	require.Len(t, transfers, 30)
+	require.Equal(t, sourceAddresses[0], transfers[0].GetSourceAccount().PublicKey)
+
+	// the transaction must still fit once the lookup tables are used
+	longMemo := make([]byte, 800)
+	for i := range longMemo {
+		longMemo[i] = 'a'
+	}
+	args.SetAmount(xc_types.NewBigIntFromUint64(300))
+	args.SetMemo(string(longMemo))
+	_, err = txBuilder.NewTokenTransfer(args, input)
+	require.ErrorContains(t, err, "transaction is too large")
+	args.SetMemo("")
+
+	// small transactions still use legacy messages
+	args.SetAmount(xc_types.NewBigIntFromUint64(10))
+	built, err = txBuilder.NewTokenTransfer(args, input)
+	require.NoError(t, err)
+	require.False(t, built.(*Tx).SolTx.Message.IsVersioned())
+}
+
+func TestNewCreateAddressLookupTable(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+
+	input := tx_input.NewCreateAddressLookupTableInput()
+	input.RecentBlockHash = solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK")
+	input.RecentSlot = 280799357
+
+	addresses := []solana.PublicKey{solana.TokenProgramID, solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")}
+	built, err := txBuilder.NewCreateAddressLookupTable(xc_types.Address(from.String()), addresses, input)
+	require.NoError(t, err)
+	solTx := built.(*Tx).SolTx
+	require.Len(t, solTx.Message.Instructions, 2)
+
+	table, _, err := types.FindAddressLookupTableAddress(from, input.RecentSlot)
+	require.NoError(t, err)
+	for _, instruction := range solTx.Message.Instructions {
+		program, err := solTx.Message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		require.NoError(t, err)
+		require.Equal(t, solana.AddressLookupTableProgramID, program)
+		accounts, err := instruction.ResolveInstructionAccounts(&solTx.Message)
+		require.NoError(t, err)
+		require.Equal(t, table, accounts[0].PublicKey)
+	}
+	// create, with the slot and bump seed
+	require.EqualValues(t, []byte{0, 0, 0, 0}, solTx.Message.Instructions[0].Data[:4])
+	require.Len(t, solTx.Message.Instructions[0].Data, 4+8+1)
+	// extend, with the addresses
+	require.EqualValues(t, []byte{2, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0}, solTx.Message.Instructions[1].Data[:12])
+	require.Len(t, solTx.Message.Instructions[1].Data, 12+2*32)
+
+	_, err = txBuilder.NewExtendAddressLookupTable(xc_types.Address(from.String()), table, []solana.PublicKey{}, &input.TxInput)
+	require.ErrorContains(t, err, "no addresses")
+}
@@ -0,0 +1,71 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransferWithMemo(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	from := xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := xc_types.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	token := xcbuilder.WithAsset(&xc_types.TokenAssetConfig{
+		Contract:    "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+		Decimals:    6,
+		ChainConfig: &xc_types.ChainConfig{},
+	})
+
+	vectors := []struct {
+		name      string
+		options   []xcbuilder.BuilderOption
+		inputMemo string
+		memos     []string
+	}{
+		{
+			name:    "native transfer",
+			options: []xcbuilder.BuilderOption{xcbuilder.WithMemo("1234")},
+			memos:   []string{"1234"},
+		},
+		{
+			name:      "native transfer with memo on the input",
+			inputMemo: "5678",
+			memos:     []string{"5678"},
+		},
+		{
+			name:    "token transfer",
+			options: []xcbuilder.BuilderOption{token, xcbuilder.WithMemo("1234")},
+			memos:   []string{"1234"},
+		},
+		{
+			name:  "no memo",
+			memos: []string{},
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			args, err := xcbuilder.NewTransferArgs(from, to, xc_types.NewBigIntFromUint64(100), v.options...)
+			require.NoError(t, err)
+			input := &tx_input.TxInput{
+				RecentBlockHash: solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+			}
+			input.SetMemo(v.inputMemo)
+			built, err := txBuilder.NewTransfer(args, input)
+			require.NoError(t, err)
+			require.Equal(t, v.memos, built.(*Tx).GetMemos())
+
+			if len(v.memos) > 0 {
+				// the memo comes before the transfer
+				message := built.(*Tx).SolTx.Message
+				program, err := message.ResolveProgramIDIndex(message.Instructions[0].ProgramIDIndex)
+				require.NoError(t, err)
+				require.Equal(t, solana.MemoProgramID, program)
+			}
+		})
+	}
+}
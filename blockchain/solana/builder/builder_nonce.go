@@ -0,0 +1,34 @@
+package builder
+
+import (
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+const NonceAccountSize = 80
+
+// NewCreateNonceAccount creates a durable nonce account with the sender as its authority.
+// Transactions may then use the nonce account in place of a recent block hash, so they
+// can be signed offline and broadcast later.
+func (txBuilder TxBuilder) NewCreateNonceAccount(from xc_types.Address, input *tx_input.CreateNonceAccountInput) (xc_types.Tx, error) {
+	authority, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	nonceAccount := input.NonceKey.PublicKey()
+	instructions := []solana.Instruction{
+		// create a new account for the nonce
+		system.NewCreateAccountInstruction(input.RentExemptLamports, NonceAccountSize, solana.SystemProgramID, authority, nonceAccount).Build(),
+		// initialize it with the first nonce
+		system.NewInitializeNonceAccountInstruction(authority, nonceAccount, solana.SysVarRecentBlockHashesPubkey, solana.SysVarRentPubkey).Build(),
+	}
+	tx, err := txBuilder.buildSolanaTx(instructions, authority, &input.TxInput)
+	if err != nil {
+		return nil, err
+	}
+	// The transient key behind the new nonce account must sign the transaction also
+	tx.AddTransientSigner(input.NonceKey)
+	return tx, nil
+}
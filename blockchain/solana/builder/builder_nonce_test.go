@@ -0,0 +1,75 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNativeTransferWithNonce(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	from := "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"
+	nonceAccount := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	nonce := solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK")
+
+	args, err := xcbuilder.NewTransferArgs(
+		xc_types.Address(from),
+		xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH"),
+		xc_types.NewBigIntFromUint64(1200000),
+	)
+	require.NoError(t, err)
+
+	input := &tx_input.TxInput{
+		RecentBlockHash: nonce,
+		NonceAccount:    nonceAccount,
+		NonceAuthority:  solana.MustPublicKeyFromBase58(from),
+	}
+	tx, err := txBuilder.NewNativeTransfer(args, input)
+	require.NoError(t, err)
+	solTx := tx.(*Tx).SolTx
+
+	// the nonce is used as the block hash, and advanced by the first instruction
+	require.Equal(t, nonce, solTx.Message.RecentBlockhash)
+	require.Len(t, solTx.Message.Instructions, 2)
+	firstInstruction := solTx.Message.Instructions[0]
+	accounts, err := firstInstruction.ResolveInstructionAccounts(&solTx.Message)
+	require.NoError(t, err)
+	advance, err := system.DecodeInstruction(accounts, firstInstruction.Data)
+	require.NoError(t, err)
+	advanceNonce, ok := advance.Impl.(*system.AdvanceNonceAccount)
+	require.True(t, ok)
+	require.Equal(t, nonceAccount, advanceNonce.GetNonceAccount().PublicKey)
+	require.Equal(t, solana.MustPublicKeyFromBase58(from), advanceNonce.GetNonceAuthorityAccount().PublicKey)
+	require.Len(t, tx.(*Tx).GetAdvanceNonceAccounts(), 1)
+	require.Len(t, tx.(*Tx).GetSystemTransfers(), 1)
+}
+
+func TestNewCreateNonceAccount(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	from := "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"
+	nonceKey, _ := solana.NewRandomPrivateKey()
+
+	input := tx_input.NewCreateNonceAccountInput()
+	input.RecentBlockHash = solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK")
+	input.NonceKey = nonceKey
+	input.RentExemptLamports = 1447680
+
+	tx, err := txBuilder.NewCreateNonceAccount(xc_types.Address(from), input)
+	require.NoError(t, err)
+
+	createAccounts := tx.(*Tx).GetCreateAccounts()
+	require.Len(t, createAccounts, 1)
+	require.Equal(t, nonceKey.PublicKey(), createAccounts[0].NewAccount)
+	require.EqualValues(t, 1447680, createAccounts[0].Lamports)
+
+	initializes := tx.(*Tx).GetInitializeNonceAccounts()
+	require.Len(t, initializes, 1)
+	require.Equal(t, nonceKey.PublicKey(), initializes[0].GetNonceAccount().PublicKey)
+	require.Equal(t, solana.MustPublicKeyFromBase58(from), *initializes[0].Authorized)
+}
@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	compute_budget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+// Max token accounts to reclaim in one transaction.  Merges both transfer and close, so take the most space.
+const MaxReclaimAccounts = 8
+
+// NewReclaimRent closes the token accounts of the sender, returning their rent to the destination.  Balances
+// are first merged into the associated token accounts, and wrapped SOL is unwrapped to the sender.  The
+// accounts are reclaimed by the actions set on the input.
+func (txBuilder TxBuilder) NewReclaimRent(from xc_types.Address, rentDestination xc_types.Address, input *tx_input.ReclaimRentInput) (xc_types.Tx, error) {
+	owner, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	destination, err := solana.PublicKeyFromBase58(string(rentDestination))
+	if err != nil {
+		return nil, err
+	}
+	existingAccounts := map[solana.PublicKey]bool{}
+	for _, account := range input.TokenAccounts {
+		existingAccounts[account.Account] = true
+	}
+
+	instructions := []solana.Instruction{}
+	reclaimed := 0
+	for _, account := range input.TokenAccounts {
+		action := account.Action
+		if action == tx_input.ReclaimNone {
+			continue
+		}
+		reclaimed++
+		if reclaimed > MaxReclaimAccounts {
+			return nil, fmt.Errorf("cannot reclaim more than %d token accounts in single tx", MaxReclaimAccounts)
+		}
+
+		if action == tx_input.ReclaimMerge {
+			ata := account.AssociatedTokenAccount(owner)
+			if !existingAccounts[ata] {
+				createAta, err := solana_types.NewCreateIdempotentAssociatedTokenAccountInstruction(owner, owner, account.Mint, account.TokenProgram)
+				if err != nil {
+					return nil, err
+				}
+				instructions = append(instructions, createAta)
+				existingAccounts[ata] = true
+			}
+			transfer, err := forTokenProgram(account.TokenProgram, token.NewTransferCheckedInstruction(
+				account.Balance.Uint64(),
+				account.Decimals,
+				account.Account,
+				account.Mint,
+				ata,
+				owner,
+				[]solana.PublicKey{},
+			).Build())
+			if err != nil {
+				return nil, err
+			}
+			instructions = append(instructions, transfer)
+		}
+		closeDestination := destination
+		if action == tx_input.ReclaimUnwrap {
+			// the balance is returned to the sender, and only the rent to the destination
+			closeDestination = owner
+		}
+		closeAccount, err := forTokenProgram(account.TokenProgram, token.NewCloseAccountInstruction(
+			account.Account,
+			closeDestination,
+			owner,
+			[]solana.PublicKey{},
+		).Build())
+		if err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, closeAccount)
+		if action == tx_input.ReclaimUnwrap && !destination.Equals(owner) && account.Rent() > 0 {
+			instructions = append(instructions, system.NewTransferInstruction(account.Rent(), owner, destination).Build())
+		}
+	}
+	if len(instructions) == 0 {
+		return nil, errors.New("no token accounts to reclaim")
+	}
+
+	priorityFee := input.GetLimitedPrioritizationFee(txBuilder.Chain)
+	if priorityFee > 0 {
+		instructions = append(instructions, compute_budget.NewSetComputeUnitPriceInstruction(priorityFee).Build())
+	}
+	return txBuilder.buildSolanaTx(instructions, owner, &input.TxInput)
+}
+
+// Rebuilds the instruction for the token program that owns the account, which may be token-2022
+func forTokenProgram(tokenProgram solana.PublicKey, instruction *token.Instruction) (solana.Instruction, error) {
+	data, err := instruction.Data()
+	if err != nil {
+		return nil, err
+	}
+	if tokenProgram.IsZero() {
+		tokenProgram = solana.TokenProgramID
+	}
+	return solana.NewInstruction(tokenProgram, instruction.Accounts(), data), nil
+}
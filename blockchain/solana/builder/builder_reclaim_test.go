@@ -0,0 +1,136 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReclaimRent(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	owner := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	rentDestination := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	mintA := solana.MustPublicKeyFromBase58("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU")
+	mintB := solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	mintC := solana.MustPublicKeyFromBase58("2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo")
+	auxA := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	auxB := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+	auxC := solana.MustPublicKeyFromBase58("GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE")
+	wrappedSol := solana.MustPublicKeyFromBase58("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh")
+
+	tokenAccount := func(account solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey, balance uint64) *tx_input.ReclaimableTokenAccount {
+		return &tx_input.ReclaimableTokenAccount{
+			Account:      account,
+			Mint:         mint,
+			TokenProgram: tokenProgram,
+			Decimals:     6,
+			Balance:      xc_types.NewBigIntFromUint64(balance),
+			Lamports:     2039280,
+		}
+	}
+	ataA := tokenAccount(solana.PublicKey{}, mintA, solana.TokenProgramID, 0)
+	ataA.Account = ataA.AssociatedTokenAccount(owner)
+
+	input := tx_input.NewReclaimRentInput()
+	input.RecentBlockHash = solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK")
+	input.TokenAccounts = []*tx_input.ReclaimableTokenAccount{
+		// empty, but the balance of the other account is merged into it
+		ataA,
+		tokenAccount(auxA, mintA, solana.TokenProgramID, 50),
+		tokenAccount(auxB, mintB, solana.TokenProgramID, 0),
+		// the associated token account does not exist yet
+		tokenAccount(auxC, mintC, solana.Token2022ProgramID, 70),
+		tokenAccount(wrappedSol, solana.SolMint, solana.TokenProgramID, 1000),
+	}
+	input.SetActions(owner)
+	require.Equal(t, tx_input.ReclaimNone, input.TokenAccounts[0].Action)
+	require.Equal(t, tx_input.ReclaimMerge, input.TokenAccounts[1].Action)
+	require.Equal(t, tx_input.ReclaimClose, input.TokenAccounts[2].Action)
+	require.Equal(t, tx_input.ReclaimMerge, input.TokenAccounts[3].Action)
+	require.Equal(t, tx_input.ReclaimUnwrap, input.TokenAccounts[4].Action)
+	require.EqualValues(t, 2038280, input.TokenAccounts[4].Rent())
+
+	built, err := txBuilder.NewReclaimRent(xc_types.Address(owner.String()), xc_types.Address(rentDestination.String()), input)
+	require.NoError(t, err)
+	message := built.(*Tx).SolTx.Message
+
+	expectedPrograms := []solana.PublicKey{
+		// merge A
+		solana.TokenProgramID, solana.TokenProgramID,
+		// close B
+		solana.TokenProgramID,
+		// create the associated token account, then merge C
+		solana.SPLAssociatedTokenAccountProgramID, solana.Token2022ProgramID, solana.Token2022ProgramID,
+		// unwrap, then send the rent to the destination
+		solana.TokenProgramID, solana.SystemProgramID,
+	}
+	require.Len(t, message.Instructions, len(expectedPrograms))
+	closed := []solana.PublicKey{}
+	for i, instruction := range message.Instructions {
+		program, err := message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		require.NoError(t, err)
+		require.Equal(t, expectedPrograms[i], program)
+		if program.Equals(solana.SPLAssociatedTokenAccountProgramID) {
+			continue
+		}
+		accounts, err := instruction.ResolveInstructionAccounts(&message)
+		require.NoError(t, err)
+		if program.Equals(solana.SystemProgramID) {
+			decoded, err := system.DecodeInstruction(accounts, instruction.Data)
+			require.NoError(t, err)
+			transfer := decoded.Impl.(*system.Transfer)
+			require.Equal(t, owner, transfer.GetFundingAccount().PublicKey)
+			require.Equal(t, rentDestination, transfer.GetRecipientAccount().PublicKey)
+			require.EqualValues(t, 2038280, *transfer.Lamports)
+			continue
+		}
+		decoded, err := token.DecodeInstruction(accounts, instruction.Data)
+		require.NoError(t, err)
+		switch inst := decoded.Impl.(type) {
+		case *token.TransferChecked:
+			require.Equal(t, solana.MustPublicKeyFromBase58(owner.String()), inst.GetOwnerAccount().PublicKey)
+		case *token.CloseAccount:
+			if inst.GetAccount().PublicKey.Equals(wrappedSol) {
+				// the unwrapped balance goes to the owner
+				require.Equal(t, owner, inst.GetDestinationAccount().PublicKey)
+			} else {
+				require.Equal(t, rentDestination, inst.GetDestinationAccount().PublicKey)
+			}
+			closed = append(closed, inst.GetAccount().PublicKey)
+		default:
+			require.Fail(t, "unexpected instruction", "%T", inst)
+		}
+	}
+	require.Equal(t, []solana.PublicKey{auxA, auxB, auxC, wrappedSol}, closed)
+	require.Len(t, built.(*Tx).GetTokenTransferCheckeds(), 2)
+
+	// nothing to reclaim
+	input.TokenAccounts = []*tx_input.ReclaimableTokenAccount{ataA}
+	ataA.Balance = xc_types.NewBigIntFromUint64(10)
+	input.SetActions(owner)
+	_, err = txBuilder.NewReclaimRent(xc_types.Address(owner.String()), xc_types.Address(rentDestination.String()), input)
+	require.ErrorContains(t, err, "no token accounts to reclaim")
+
+	// too many accounts
+	input.TokenAccounts = []*tx_input.ReclaimableTokenAccount{}
+	for i := 0; i < builder.MaxReclaimAccounts+1; i++ {
+		account, _ := solana.NewRandomPrivateKey()
+		input.TokenAccounts = append(input.TokenAccounts, tokenAccount(account.PublicKey(), mintB, solana.TokenProgramID, 0))
+	}
+	input.SetActions(owner)
+	_, err = txBuilder.NewReclaimRent(xc_types.Address(owner.String()), xc_types.Address(rentDestination.String()), input)
+	require.ErrorContains(t, err, "cannot reclaim more than")
+
+	// reclaiming to the owner only unwraps
+	input.TokenAccounts = []*tx_input.ReclaimableTokenAccount{tokenAccount(wrappedSol, solana.SolMint, solana.TokenProgramID, 1000)}
+	input.SetActions(owner)
+	built, err = txBuilder.NewReclaimRent(xc_types.Address(owner.String()), xc_types.Address(owner.String()), input)
+	require.NoError(t, err)
+	require.Len(t, built.(*Tx).SolTx.Message.Instructions, 1)
+}
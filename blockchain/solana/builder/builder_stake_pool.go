@@ -0,0 +1,97 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	compute_budget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/stake"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+// Returns the associated token account of the owner for the pool tokens
+func poolTokenAccount(owner solana.PublicKey, pool *solana_types.StakePoolAccounts) (solana.PublicKey, error) {
+	ata, err := solana_types.FindAssociatedTokenAddress(owner.String(), pool.PoolMint.String(), pool.TokenProgram)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	return solana.PublicKeyFromBase58(ata)
+}
+
+// Deposits the amount of SOL into the stake pool, minting pool tokens into the associated token account
+// of the sender, which is created if needed
+func (txBuilder TxBuilder) depositStakePool(args xcbuilder.StakeArgs, input *tx_input.StakePoolStakeInput) (xc_types.Tx, error) {
+	owner, err := solana.PublicKeyFromBase58(string(args.GetFrom()))
+	if err != nil {
+		return nil, err
+	}
+	amount := args.GetAmount().Uint64()
+	if amount == 0 {
+		return nil, fmt.Errorf("amount to stake is required")
+	}
+	tokenAccount, err := poolTokenAccount(owner, &input.StakePool)
+	if err != nil {
+		return nil, err
+	}
+	createTokenAccount, err := solana_types.NewCreateIdempotentAssociatedTokenAccountInstruction(owner, owner, input.StakePool.PoolMint, input.StakePool.TokenProgram)
+	if err != nil {
+		return nil, err
+	}
+	instructions := []solana.Instruction{
+		// set gas fee priority
+		compute_budget.NewSetComputeUnitPriceInstruction(
+			input.GetLimitedPrioritizationFee(txBuilder.Chain),
+		).Build(),
+		createTokenAccount,
+		solana_types.NewDepositSolInstruction(&input.StakePool, owner, tokenAccount, amount),
+	}
+	return txBuilder.buildSolanaTx(instructions, owner, &input.TxInput)
+}
+
+// Burns pool tokens, either withdrawing SOL from the reserve, or splitting a new stake account from a
+// validator of the pool and deactivating it
+func (txBuilder TxBuilder) withdrawStakePool(args xcbuilder.StakeArgs, input *tx_input.StakePoolUnstakeInput) (xc_types.Tx, error) {
+	owner, err := solana.PublicKeyFromBase58(string(args.GetFrom()))
+	if err != nil {
+		return nil, err
+	}
+	if input.PoolTokens == 0 {
+		return nil, fmt.Errorf("no pool tokens to unstake")
+	}
+	tokenAccount, err := poolTokenAccount(owner, &input.StakePool)
+	if err != nil {
+		return nil, err
+	}
+	instructions := []solana.Instruction{
+		// set gas fee priority
+		compute_budget.NewSetComputeUnitPriceInstruction(
+			input.GetLimitedPrioritizationFee(txBuilder.Chain),
+		).Build(),
+	}
+	if input.IsInstant() {
+		instructions = append(instructions,
+			solana_types.NewWithdrawSolInstruction(&input.StakePool, owner, tokenAccount, owner, input.PoolTokens),
+		)
+		return txBuilder.buildSolanaTx(instructions, owner, &input.TxInput)
+	}
+
+	stakeAccountPub := input.StakingKey.PublicKey()
+	instructions = append(instructions,
+		// the new account must already be rent exempt when splitting delegated stake
+		system.NewCreateAccountInstruction(input.RentExemptReserve, StakeAccountSize, solana.StakeProgramID, owner, stakeAccountPub).Build(),
+		solana_types.NewWithdrawStakeInstruction(&input.StakePool, owner, tokenAccount, input.ValidatorStake, stakeAccountPub, input.PoolTokens),
+		// start the cooldown, so the stake can be withdrawn once inactive
+		stake.NewDeactivateInstruction(stakeAccountPub, owner).Build(),
+	)
+	tx, err := txBuilder.buildSolanaTx(instructions, owner, &input.TxInput)
+	if err != nil {
+		return nil, err
+	}
+	// The transient key behind the new stake account must sign the transaction also
+	tx.AddTransientSigner(input.StakingKey)
+	return tx, nil
+}
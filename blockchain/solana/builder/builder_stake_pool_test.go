@@ -0,0 +1,131 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+var testStakePool = solana_types.StakePoolAccounts{
+	Program:           solana_types.StakePoolProgramID,
+	StakePool:         solana.MustPublicKeyFromBase58("stk9ApL5HeVAwPLr3TLhDXdZS8ptVu7zp6ov8HFDuMi"),
+	WithdrawAuthority: solana.MustPublicKeyFromBase58("6iQKfEyhr3bZMotVkW6beNZz5CPAkiwvgV2CTje9pVSS"),
+	ValidatorList:     solana.MustPublicKeyFromBase58("3R3nGZpQs2aZo5FDQvd2MUQ6R7KhAPainds6uT6uE2mn"),
+	ReserveStake:      solana.MustPublicKeyFromBase58("BgKUXdS29YcHCFrPm5M8oLHiTzZaMDjsebggjoaQ6KFL"),
+	PoolMint:          solana.MustPublicKeyFromBase58("bSo13r4TkiE4KumL71LsHTPpL2euBYLFx6h9HP3piy1"),
+	ManagerFeeAccount: solana.MustPublicKeyFromBase58("feeeFLLsam6xZJFc6UQFrHqkvVt4jfmVvi2BRLkUZ4i"),
+	TokenProgram:      solana.TokenProgramID,
+}
+
+func TestDepositStakePool(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+
+	from := xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	input := &tx_input.StakePoolStakeInput{
+		TxInput: tx_input.TxInput{
+			RecentBlockHash:   solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+			PrioritizationFee: xc_types.NewBigIntFromUint64(100000),
+		},
+		StakePool: testStakePool,
+	}
+	args, err := xcbuilder.NewStakeArgs(xc_types.SOL, from, xc_types.NewBigIntFromUint64(1_500_000_000), xcbuilder.WithStakingProvider(xc_types.SplStakePool))
+	require.NoError(t, err)
+	tx, err := txBuilder.Stake(args, input)
+	require.NoError(t, err)
+
+	tokenAccount, err := solana_types.FindAssociatedTokenAddress(string(from), testStakePool.PoolMint.String(), solana.TokenProgramID)
+	require.NoError(t, err)
+	deposits := tx.(*Tx).GetStakePoolInstructions(testStakePool.Program)
+	require.Len(t, deposits, 1)
+	require.Equal(t, solana_types.StakePoolDepositSolInstruction, deposits[0].Instruction)
+	require.EqualValues(t, 1_500_000_000, deposits[0].Amount)
+	require.Equal(t, testStakePool.StakePool, deposits[0].Accounts[0].PublicKey)
+	require.Equal(t, tokenAccount, deposits[0].Accounts[4].PublicKey.String())
+	// only the sender signs
+	require.Len(t, tx.(*Tx).SolTx.Message.Signers(), 1)
+
+	args, err = xcbuilder.NewStakeArgs(xc_types.SOL, from, xc_types.NewBigIntFromUint64(0), xcbuilder.WithStakingProvider(xc_types.SplStakePool))
+	require.NoError(t, err)
+	_, err = txBuilder.Stake(args, input)
+	require.ErrorContains(t, err, "amount to stake is required")
+}
+
+func TestWithdrawStakePool(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+
+	from := xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	args, err := xcbuilder.NewStakeArgs(xc_types.SOL, from, xc_types.NewBigIntFromUint64(1_000_000_000), xcbuilder.WithStakingProvider(xc_types.SplStakePool))
+	require.NoError(t, err)
+	txInput := tx_input.TxInput{
+		RecentBlockHash:   solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		PrioritizationFee: xc_types.NewBigIntFromUint64(100000),
+	}
+
+	t.Run("instant", func(t *testing.T) {
+		input := &tx_input.StakePoolUnstakeInput{
+			TxInput:    txInput,
+			StakePool:  testStakePool,
+			PoolTokens: 850_000_000,
+		}
+		require.True(t, input.IsInstant())
+		tx, err := txBuilder.Unstake(args, input)
+		require.NoError(t, err)
+
+		withdrawals := tx.(*Tx).GetStakePoolInstructions(testStakePool.Program)
+		require.Len(t, withdrawals, 1)
+		require.Equal(t, solana_types.StakePoolWithdrawSolInstruction, withdrawals[0].Instruction)
+		require.EqualValues(t, 850_000_000, withdrawals[0].Amount)
+		// the SOL is sent to the owner
+		require.Equal(t, string(from), withdrawals[0].Accounts[5].PublicKey.String())
+		require.Empty(t, tx.(*Tx).GetCreateAccounts())
+		require.Empty(t, tx.(*Tx).GetDeactivateStakes())
+		require.Len(t, tx.(*Tx).SolTx.Message.Signers(), 1)
+	})
+
+	t.Run("delayed", func(t *testing.T) {
+		stakeKey, _ := solana.NewRandomPrivateKey()
+		input := &tx_input.StakePoolUnstakeInput{
+			TxInput:           txInput,
+			StakePool:         testStakePool,
+			PoolTokens:        850_000_000,
+			ValidatorStake:    solana.MustPublicKeyFromBase58("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh"),
+			StakingKey:        stakeKey,
+			RentExemptReserve: 2282880,
+		}
+		require.False(t, input.IsInstant())
+		tx, err := txBuilder.Unstake(args, input)
+		require.NoError(t, err)
+
+		createAccounts := tx.(*Tx).GetCreateAccounts()
+		require.Len(t, createAccounts, 1)
+		require.EqualValues(t, 2282880, createAccounts[0].Lamports)
+		require.Equal(t, stakeKey.PublicKey(), createAccounts[0].NewAccount)
+
+		withdrawals := tx.(*Tx).GetStakePoolInstructions(testStakePool.Program)
+		require.Len(t, withdrawals, 1)
+		require.Equal(t, solana_types.StakePoolWithdrawStakeInstruction, withdrawals[0].Instruction)
+		require.EqualValues(t, 850_000_000, withdrawals[0].Amount)
+		require.Equal(t, input.ValidatorStake, withdrawals[0].Accounts[3].PublicKey)
+		require.Equal(t, stakeKey.PublicKey(), withdrawals[0].Accounts[4].PublicKey)
+
+		deactivates := tx.(*Tx).GetDeactivateStakes()
+		require.Len(t, deactivates, 1)
+		require.Equal(t, stakeKey.PublicKey(), deactivates[0].GetStakeAccount().PublicKey)
+		require.Len(t, tx.(*Tx).SolTx.Message.Signers(), 2)
+	})
+
+	t.Run("no pool tokens", func(t *testing.T) {
+		input := &tx_input.StakePoolUnstakeInput{
+			TxInput:   txInput,
+			StakePool: testStakePool,
+		}
+		_, err := txBuilder.Unstake(args, input)
+		require.ErrorContains(t, err, "no pool tokens to unstake")
+	})
+}
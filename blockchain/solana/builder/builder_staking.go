@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
 	xcbuilder "github.com/CustodyOne/chainkit/builder"
 	xc_types "github.com/CustodyOne/chainkit/types"
 	"github.com/gagliardetto/solana-go"
@@ -17,6 +18,9 @@ const RentExemptLamportsThresholdHuman = "0.003"
 const StakeAccountSize = 200
 
 func (txBuilder TxBuilder) Stake(args xcbuilder.StakeArgs, input xc_types.StakeTxInput) (xc_types.Tx, error) {
+	if poolInput, ok := input.(*tx_input.StakePoolStakeInput); ok {
+		return txBuilder.depositStakePool(args, poolInput)
+	}
 	stakeInput, ok := input.(*tx_input.StakingInput)
 	if !ok {
 		return nil, fmt.Errorf("invalid input %T, expected %T", input, stakeInput)
@@ -66,6 +70,9 @@ func (txBuilder TxBuilder) Stake(args xcbuilder.StakeArgs, input xc_types.StakeT
 }
 
 func (txBuilder TxBuilder) Unstake(args xcbuilder.StakeArgs, input xc_types.UnstakeTxInput) (xc_types.Tx, error) {
+	if poolInput, ok := input.(*tx_input.StakePoolUnstakeInput); ok {
+		return txBuilder.withdrawStakePool(args, poolInput)
+	}
 	unstakeInput, ok := input.(*tx_input.UnstakingInput)
 	if !ok {
 		return nil, fmt.Errorf("invalid input %T, expected %T", input, unstakeInput)
@@ -226,3 +233,112 @@ func (txBuilder TxBuilder) Withdraw(args xcbuilder.StakeArgs, input xc_types.Wit
 	}
 	return tx, nil
 }
+
+// MergeStake merges compatible stake accounts into the destination, so that their rent is returned
+func (txBuilder TxBuilder) MergeStake(args xcbuilder.StakeArgs, input *tx_input.MergeStakeInput) (xc_types.Tx, error) {
+	stakingAuth, err := solana.PublicKeyFromBase58(string(args.GetFrom()))
+	if err != nil {
+		return nil, err
+	}
+	if len(input.Sources) == 0 {
+		return nil, fmt.Errorf("no stake accounts to merge")
+	}
+	if len(input.Sources) > MaxAccountMerges {
+		return nil, fmt.Errorf("cannot merge more than %d stake accounts in single tx", MaxAccountMerges)
+	}
+
+	instructions := []solana.Instruction{
+		// set gas fee priority
+		compute_budget.NewSetComputeUnitPriceInstruction(
+			input.GetLimitedPrioritizationFee(txBuilder.Chain),
+		).Build(),
+	}
+	for _, source := range input.Sources {
+		if source.Equals(input.Destination) {
+			return nil, fmt.Errorf("cannot merge stake account %s into itself", source)
+		}
+		instructions = append(instructions,
+			solana_types.NewMergeStakeInstruction(input.Destination, source, stakingAuth),
+		)
+	}
+	return txBuilder.buildSolanaTx(instructions, stakingAuth, &input.TxInput)
+}
+
+// SplitStake moves the amount of the stake account into a new stake account, delegated to the same validator
+func (txBuilder TxBuilder) SplitStake(args xcbuilder.StakeArgs, input *tx_input.SplitStakeInput) (xc_types.Tx, error) {
+	stakingAuth, err := solana.PublicKeyFromBase58(string(args.GetFrom()))
+	if err != nil {
+		return nil, err
+	}
+	amount := args.GetAmount().Uint64()
+	if amount == 0 {
+		return nil, fmt.Errorf("amount to split is required")
+	}
+	if input.Lamports < input.RentExemptReserve || amount > input.Lamports-input.RentExemptReserve {
+		return nil, fmt.Errorf("insufficient amount in stake account to split")
+	}
+	stakeAccountPub := input.StakingKey.PublicKey()
+
+	instructions := []solana.Instruction{
+		// set gas fee priority
+		compute_budget.NewSetComputeUnitPriceInstruction(
+			input.GetLimitedPrioritizationFee(txBuilder.Chain),
+		).Build(),
+		// the new account must already be rent exempt when splitting delegated stake
+		system.NewCreateAccountInstruction(input.RentExemptReserve, StakeAccountSize, solana.StakeProgramID, stakingAuth, stakeAccountPub).Build(),
+		stake.NewSplitInstruction(amount, input.StakeAccount, stakeAccountPub, stakingAuth).Build(),
+	}
+	tx, err := txBuilder.buildSolanaTx(instructions, stakingAuth, &input.TxInput)
+	if err != nil {
+		return nil, err
+	}
+	// The transient key behind the new stake account must sign the transaction also
+	tx.AddTransientSigner(input.StakingKey)
+	return tx, nil
+}
+
+// RedelegateStake moves the stake account to a new validator.  Active stake that cannot be redelegated is
+// deactivated instead, and must be redelegated again once inactive.
+func (txBuilder TxBuilder) RedelegateStake(args xcbuilder.StakeArgs, input *tx_input.RedelegateStakeInput) (xc_types.Tx, error) {
+	stakingAuth, err := solana.PublicKeyFromBase58(string(args.GetFrom()))
+	if err != nil {
+		return nil, err
+	}
+	instructions := []solana.Instruction{
+		// set gas fee priority
+		compute_budget.NewSetComputeUnitPriceInstruction(
+			input.GetLimitedPrioritizationFee(txBuilder.Chain),
+		).Build(),
+	}
+	didCreate := false
+	switch input.Action {
+	case tx_input.RedelegateDelegate:
+		delegate := stake.NewDelegateStakeInstruction(input.ValidatorVoteAccount, stakingAuth, input.StakeAccount)
+		// only the stake authority signs for an existing stake account
+		delegate.AccountMetaSlice[0] = solana.Meta(input.StakeAccount).WRITE()
+		instructions = append(instructions, delegate.Build())
+	case tx_input.RedelegateMove:
+		stakeAccountPub := input.StakingKey.PublicKey()
+		instructions = append(instructions,
+			// create a new account for the redelegated stake, which the stake is moved into
+			system.NewCreateAccountInstruction(0, StakeAccountSize, solana.StakeProgramID, stakingAuth, stakeAccountPub).Build(),
+			solana_types.NewRedelegateStakeInstruction(input.StakeAccount, stakeAccountPub, input.ValidatorVoteAccount, stakingAuth),
+		)
+		didCreate = true
+	case tx_input.RedelegateDeactivate:
+		instructions = append(instructions,
+			stake.NewDeactivateInstruction(input.StakeAccount, stakingAuth).Build(),
+		)
+	default:
+		return nil, fmt.Errorf("invalid redelegate action '%s'", input.Action)
+	}
+	tx, err := txBuilder.buildSolanaTx(instructions, stakingAuth, &input.TxInput)
+	if err != nil {
+		return nil, err
+	}
+	if didCreate {
+		// The transient key behind the new stake account must sign the transaction also
+		tx.AddTransientSigner(input.StakingKey)
+	}
+	return tx, nil
+}
@@ -158,3 +158,155 @@ func TestNewWithdrawTransfer(t *testing.T) {
 	fmt.Println(total)
 	require.EqualValues(t, amount.Uint64(), total)
 }
+
+func TestMergeStake(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+
+	from := xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	validator := "J2nUHEAgZFRyuJbFjdqPrAa9gyWDuc7hErtDQHPhsYRp"
+	args, err := xcbuilder.NewStakeArgs(xc_types.SOL, from, xc_types.NewBigIntFromUint64(0), xcbuilder.WithValidator(validator))
+	require.NoError(t, err)
+
+	destination := solana.MustPublicKeyFromBase58("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh")
+	sources := []solana.PublicKey{
+		solana.MustPublicKeyFromBase58("CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC"),
+		solana.MustPublicKeyFromBase58("BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C"),
+	}
+	input := &tx_input.MergeStakeInput{
+		TxInput: tx_input.TxInput{
+			RecentBlockHash:   solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+			PrioritizationFee: xc_types.NewBigIntFromUint64(100000),
+		},
+		Destination: destination,
+		Sources:     sources,
+	}
+	tx, err := txBuilder.MergeStake(args, input)
+	require.NoError(t, err)
+
+	merges := tx.(*Tx).GetMergeStakes()
+	require.Len(t, merges, 2)
+	for i, merge := range merges {
+		require.Equal(t, destination, merge.Destination)
+		require.Equal(t, sources[i], merge.Source)
+		require.Equal(t, solana.MustPublicKeyFromBase58(string(from)), merge.StakeAuthority)
+	}
+
+	// cannot merge into itself
+	input.Sources = []solana.PublicKey{destination}
+	_, err = txBuilder.MergeStake(args, input)
+	require.ErrorContains(t, err, "into itself")
+
+	input.Sources = []solana.PublicKey{}
+	_, err = txBuilder.MergeStake(args, input)
+	require.ErrorContains(t, err, "no stake accounts to merge")
+
+	for i := 0; i < builder.MaxAccountMerges+1; i++ {
+		input.Sources = append(input.Sources, sources[0])
+	}
+	_, err = txBuilder.MergeStake(args, input)
+	require.ErrorContains(t, err, "cannot merge more than")
+}
+
+func TestSplitStake(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+
+	from := xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	validator := "J2nUHEAgZFRyuJbFjdqPrAa9gyWDuc7hErtDQHPhsYRp"
+	stakeKey, _ := solana.NewRandomPrivateKey()
+	input := &tx_input.SplitStakeInput{
+		TxInput: tx_input.TxInput{
+			RecentBlockHash:   solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+			PrioritizationFee: xc_types.NewBigIntFromUint64(100000),
+		},
+		StakingKey:        stakeKey,
+		StakeAccount:      solana.MustPublicKeyFromBase58("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh"),
+		Lamports:          40016458,
+		RentExemptReserve: 2282880,
+	}
+
+	vectors := []struct {
+		amount uint64
+		err    string
+	}{
+		{amount: 10_000_000},
+		// all of the stake
+		{amount: 37733578},
+		{amount: 37733579, err: "insufficient amount in stake account to split"},
+		{amount: 0, err: "amount to split is required"},
+	}
+	for _, v := range vectors {
+		t.Run(fmt.Sprint(v.amount), func(t *testing.T) {
+			args, err := xcbuilder.NewStakeArgs(xc_types.SOL, from, xc_types.NewBigIntFromUint64(v.amount), xcbuilder.WithValidator(validator))
+			require.NoError(t, err)
+			tx, err := txBuilder.SplitStake(args, input)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+
+			createAccounts := tx.(*Tx).GetCreateAccounts()
+			require.Len(t, createAccounts, 1)
+			// the new account is funded with its rent
+			require.EqualValues(t, 2282880, createAccounts[0].Lamports)
+			require.Equal(t, stakeKey.PublicKey(), createAccounts[0].NewAccount)
+
+			splits := tx.(*Tx).GetSplitStakes()
+			require.Len(t, splits, 1)
+			require.Equal(t, v.amount, *splits[0].Lamports)
+			require.Equal(t, input.StakeAccount, splits[0].GetStakeAccount().PublicKey)
+			require.Equal(t, stakeKey.PublicKey(), splits[0].GetNewStakeAccount().PublicKey)
+			require.Len(t, tx.(*Tx).SolTx.Message.Signers(), 2)
+		})
+	}
+}
+
+func TestRedelegateStake(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+
+	from := xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	validator := "J2nUHEAgZFRyuJbFjdqPrAa9gyWDuc7hErtDQHPhsYRp"
+	args, err := xcbuilder.NewStakeArgs(xc_types.SOL, from, xc_types.NewBigIntFromUint64(0), xcbuilder.WithValidator(validator))
+	require.NoError(t, err)
+
+	stakeKey, _ := solana.NewRandomPrivateKey()
+	input := &tx_input.RedelegateStakeInput{
+		TxInput: tx_input.TxInput{
+			RecentBlockHash:   solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+			PrioritizationFee: xc_types.NewBigIntFromUint64(100000),
+		},
+		StakingKey:           stakeKey,
+		StakeAccount:         solana.MustPublicKeyFromBase58("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh"),
+		ValidatorVoteAccount: solana.MustPublicKeyFromBase58(validator),
+	}
+
+	input.Action = tx_input.RedelegateDelegate
+	tx, err := txBuilder.RedelegateStake(args, input)
+	require.NoError(t, err)
+	stakes := tx.(*Tx).GetDelegateStake()
+	require.Len(t, stakes, 1)
+	require.Equal(t, input.ValidatorVoteAccount, stakes[0].GetVoteAccount().PublicKey)
+	require.Equal(t, input.StakeAccount, stakes[0].GetStakeAccount().PublicKey)
+	require.Len(t, tx.(*Tx).SolTx.Message.Signers(), 1)
+
+	input.Action = tx_input.RedelegateMove
+	tx, err = txBuilder.RedelegateStake(args, input)
+	require.NoError(t, err)
+	require.Len(t, tx.(*Tx).GetCreateAccounts(), 1)
+	redelegates := tx.(*Tx).GetRedelegateStakes()
+	require.Len(t, redelegates, 1)
+	require.Equal(t, input.StakeAccount, redelegates[0].StakeAccount)
+	require.Equal(t, stakeKey.PublicKey(), redelegates[0].NewStakeAccount)
+	require.Equal(t, input.ValidatorVoteAccount, redelegates[0].VoteAccount)
+	require.Len(t, tx.(*Tx).SolTx.Message.Signers(), 2)
+
+	input.Action = tx_input.RedelegateDeactivate
+	tx, err = txBuilder.RedelegateStake(args, input)
+	require.NoError(t, err)
+	require.Len(t, tx.(*Tx).GetDeactivateStakes(), 1)
+	require.Len(t, tx.(*Tx).GetDelegateStake(), 0)
+
+	input.Action = ""
+	_, err = txBuilder.RedelegateStake(args, input)
+	require.ErrorContains(t, err, "invalid redelegate action")
+}
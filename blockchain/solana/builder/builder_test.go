@@ -45,6 +45,30 @@ func TestNewNativeTransfer(t *testing.T) {
 	require.Equal(t, uint16(0x2), solTx.Message.Instructions[0].ProgramIDIndex) // system tx
 }
 
+func TestNewNativeTransferWithComputeUnitLimit(t *testing.T) {
+	builder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	args, err := xcbuilder.NewTransferArgs(
+		xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+		xc_types.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"),
+		xc_types.NewBigIntFromUint64(1200000),
+	)
+	require.NoError(t, err)
+
+	input := &tx_input.TxInput{
+		PrioritizationFee: xc_types.NewBigIntFromUint64(1000),
+		ComputeUnitLimit:  450,
+	}
+	tx, err := builder.NewNativeTransfer(args, input)
+	require.NoError(t, err)
+	require.Len(t, tx.(*Tx).SolTx.Message.Instructions, 3)
+
+	limits := tx.(*Tx).GetComputeUnitLimits()
+	require.Len(t, limits, 1)
+	require.EqualValues(t, 450, limits[0].Units)
+	require.EqualValues(t, 450, tx.(*Tx).ComputeUnitLimit())
+	require.EqualValues(t, 1000, tx.(*Tx).ComputeUnitPrice())
+}
+
 func TestNewNativeTransferErr(t *testing.T) {
 
 	builder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
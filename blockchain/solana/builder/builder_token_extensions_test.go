@@ -0,0 +1,150 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	"github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenTransferWithTransferFee(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	mint := "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
+	source1 := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	source2 := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+	newArgs := func(amount uint64) *xcbuilder.TransferArgs {
+		args, err := xcbuilder.NewTransferArgs(
+			xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+			xc_types.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"),
+			xc_types.NewBigIntFromUint64(amount),
+			xcbuilder.WithAsset(&xc_types.TokenAssetConfig{
+				Contract:    xc_types.ContractAddress(mint),
+				Decimals:    6,
+				ChainConfig: &xc_types.ChainConfig{},
+			}),
+		)
+		require.NoError(t, err)
+		return args
+	}
+	// 1% fee, up to 5000
+	transferFee := &types.TransferFee{BasisPoints: 100, MaximumFee: 5000}
+
+	vectors := []struct {
+		name           string
+		amount         uint64
+		sourceAccounts []*tx_input.TokenAccount
+		expected       []*types.TransferCheckedWithFee
+	}{
+		{
+			name:   "fee is added to the amount",
+			amount: 100_000,
+			expected: []*types.TransferCheckedWithFee{
+				{Amount: 101_011, Fee: 1011},
+			},
+		},
+		{
+			name:   "fee is capped at the maximum",
+			amount: 1_000_000,
+			expected: []*types.TransferCheckedWithFee{
+				{Amount: 1_005_000, Fee: 5000},
+			},
+		},
+		{
+			name:   "fee is charged on each source account",
+			amount: 100_000,
+			sourceAccounts: []*tx_input.TokenAccount{
+				{Account: source1, Balance: xc_types.NewBigIntFromUint64(60_000)},
+				{Account: source2, Balance: xc_types.NewBigIntFromUint64(60_000)},
+			},
+			expected: []*types.TransferCheckedWithFee{
+				{Source: source1, Amount: 60_000, Fee: 600},
+				{Source: source2, Amount: 41_011, Fee: 411},
+			},
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			input := &tx_input.TxInput{
+				RecentBlockHash:     solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+				TokenProgram:        solana.Token2022ProgramID,
+				SourceTokenAccounts: v.sourceAccounts,
+				TransferFee:         transferFee,
+			}
+			built, err := txBuilder.NewTokenTransfer(newArgs(v.amount), input)
+			require.NoError(t, err)
+
+			transfers := built.(*Tx).GetTokenTransferCheckedWithFees()
+			require.Len(t, transfers, len(v.expected))
+			received := uint64(0)
+			for i, expected := range v.expected {
+				require.Equal(t, expected.Amount, transfers[i].Amount)
+				require.Equal(t, expected.Fee, transfers[i].Fee)
+				require.EqualValues(t, 6, transfers[i].Decimals)
+				require.Equal(t, solana.MustPublicKeyFromBase58(mint), transfers[i].Mint)
+				if !expected.Source.IsZero() {
+					require.Equal(t, expected.Source, transfers[i].Source)
+				}
+				received += transfers[i].Amount - transfers[i].Fee
+			}
+			// the recipient receives the full amount
+			require.Equal(t, v.amount, received)
+			require.Len(t, built.(*Tx).GetTokenTransferCheckeds(), 0)
+		})
+	}
+
+	// the fee must be covered by the balance of the single source account
+	input := &tx_input.TxInput{
+		RecentBlockHash: solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		TokenProgram:    solana.Token2022ProgramID,
+		SourceTokenAccounts: []*tx_input.TokenAccount{
+			{Account: source1, Balance: xc_types.NewBigIntFromUint64(100_000)},
+		},
+		TransferFee: transferFee,
+	}
+	_, err := txBuilder.NewTokenTransfer(newArgs(100_000), input)
+	require.ErrorContains(t, err, "cannot send 100000 plus the transfer fee of 1011")
+}
+
+func TestNewTokenTransferWithRequiredMemo(t *testing.T) {
+	txBuilder, _ := builder.NewTxBuilder(&xc_types.ChainConfig{})
+	args, err := xcbuilder.NewTransferArgs(
+		xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+		xc_types.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"),
+		xc_types.NewBigIntFromUint64(100),
+		xcbuilder.WithAsset(&xc_types.TokenAssetConfig{
+			Contract:    "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+			Decimals:    6,
+			ChainConfig: &xc_types.ChainConfig{},
+		}),
+		xcbuilder.WithMemo("invoice 42"),
+	)
+	require.NoError(t, err)
+
+	input := &tx_input.TxInput{
+		RecentBlockHash: solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		TokenProgram:    solana.Token2022ProgramID,
+		ToRequiresMemo:  true,
+	}
+	built, err := txBuilder.NewTokenTransfer(args, input)
+	require.NoError(t, err)
+
+	// the memo immediately precedes the transfer
+	message := built.(*Tx).SolTx.Message
+	require.Len(t, message.Instructions, 2)
+	memoProgram, err := message.ResolveProgramIDIndex(message.Instructions[0].ProgramIDIndex)
+	require.NoError(t, err)
+	require.Equal(t, solana.MemoProgramID, memoProgram)
+	require.Equal(t, "invoice 42", string(message.Instructions[0].Data))
+	require.Len(t, built.(*Tx).GetTokenTransferCheckeds(), 1)
+
+	// the transfer would fail without a memo
+	input.Memo = ""
+	args.SetMemo("")
+	_, err = txBuilder.NewTokenTransfer(args, input)
+	require.ErrorContains(t, err, "a memo is required")
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
 	"github.com/CustodyOne/chainkit/blockchain/solana/tx"
@@ -27,6 +29,7 @@ type Client struct {
 
 var _ xcclient.IClient = &Client{}
 var _ xcclient.StakingClient = &Client{}
+var _ xcclient.BalanceAtClient = &Client{}
 
 func NewClient(cfg *xc.ChainConfig) (*Client, error) {
 	endpoint := cfg.Client.URL
@@ -37,6 +40,11 @@ func NewClient(cfg *xc.ChainConfig) (*Client, error) {
 	return &Client{cfg: cfg, client: client}, nil
 }
 
+// RpcClient returns the underlying RPC client, for staking providers that read their own program accounts
+func (client *Client) RpcClient() *rpc.Client {
+	return client.client
+}
+
 func (client *Client) FetchBaseInput(ctx context.Context, fromAddr xc.Address) (*tx_input.TxInput, error) {
 	txInput := tx_input.NewTxInput()
 
@@ -53,15 +61,53 @@ func (client *Client) FetchBaseInput(ctx context.Context, fromAddr xc.Address) (
 	return txInput, nil
 }
 
+// Solana specific builder options that apply to any transaction
+type BaseInputArgs interface {
+	GetNonceAccount() (string, bool)
+	GetAddressLookupTables() ([]string, bool)
+}
+
+// Fetches the base input, using the nonce account instead of a recent block hash
+// and resolving the address lookup tables if they are set
+func (client *Client) FetchBaseInputForArgs(ctx context.Context, from xc.Address, args BaseInputArgs) (*tx_input.TxInput, error) {
+	txInput, err := client.FetchBaseInput(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if nonceAccount, ok := args.GetNonceAccount(); ok {
+		err = client.SetDurableNonce(ctx, txInput, from, nonceAccount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tables, ok := args.GetAddressLookupTables(); ok && len(tables) > 0 {
+		txInput.AddressLookupTables, err = client.FetchAddressLookupTables(ctx, tables)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return txInput, nil
+}
+
 func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.TransferArgs) (xc.TxInput, error) {
-	txInput, err := client.FetchBaseInput(ctx, args.GetFrom())
+	txInput, err := client.FetchBaseInputForArgs(ctx, args.GetFrom(), args)
 	if err != nil {
 		return nil, err
 	}
+	if feePayer, ok := args.GetFeePayer(); ok {
+		txInput.FeePayer, err = solana.PublicKeyFromBase58(string(feePayer))
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee payer: %v", err)
+		}
+	}
 
 	asset, _ := args.GetAsset()
 	if asset == nil {
-		return txInput, nil
+		accountFrom, err := solana.PublicKeyFromBase58(string(args.GetFrom()))
+		if err != nil {
+			return nil, err
+		}
+		return client.setComputeBudget(ctx, txInput, args, []solana.PublicKey{accountFrom})
 	}
 
 	mint, err := solana.PublicKeyFromBase58(string(asset.GetContract()))
@@ -74,6 +120,12 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 		return nil, err
 	}
 	txInput.TokenProgram = mintInfo.Value.Owner
+	if txInput.TokenProgram.Equals(solana.Token2022ProgramID) {
+		err = client.setMintExtensions(ctx, txInput, mint, mintInfo.Value.Data.GetBinary())
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// get account info - check if to is an owner or ata
 	accountTo, err := solana.PublicKeyFromBase58(string(args.GetTo()))
@@ -100,10 +152,15 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 		ataTo = solana.MustPublicKeyFromBase58(ataToStr)
 	}
 
-	_, err = client.client.GetAccountInfo(ctx, ataTo)
+	ataToInfo, err := client.client.GetAccountInfo(ctx, ataTo)
 	if err != nil {
 		// if the ATA doesn't exist yet, we will create when sending tokens
 		txInput.ShouldCreateATA = true
+	} else if txInput.TokenProgram.Equals(solana.Token2022ProgramID) {
+		err = setDestinationExtensions(txInput, ataTo, ataToInfo.Value.Data.GetBinary())
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Fetch all token accounts as if they are utxo
@@ -127,8 +184,9 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 		sort.Slice(txInput.SourceTokenAccounts, func(i, j int) bool {
 			return txInput.SourceTokenAccounts[i].Balance.Cmp(&txInput.SourceTokenAccounts[j].Balance) > 0
 		})
-		if len(txInput.SourceTokenAccounts) > builder.MaxTokenTransfers {
-			txInput.SourceTokenAccounts = txInput.SourceTokenAccounts[:builder.MaxTokenTransfers]
+		maxTokenTransfers := builder.GetMaxTokenTransfers(txInput)
+		if len(txInput.SourceTokenAccounts) > maxTokenTransfers {
+			txInput.SourceTokenAccounts = txInput.SourceTokenAccounts[:maxTokenTransfers]
 		}
 
 		if len(tokenAccounts) == 0 {
@@ -137,35 +195,32 @@ func (client *Client) FetchTransferInput(ctx context.Context, args *xcbuilder.Tr
 		}
 	}
 
-	// fetch priority fee info
-	accountsToLock := solana.PublicKeySlice{}
-	accountsToLock = append(accountsToLock, mint)
-	fees, err := client.client.GetRecentPrioritizationFees(ctx, accountsToLock)
+	return client.setComputeBudget(ctx, txInput, args, []solana.PublicKey{mint})
+}
+
+// Sets the priority fee for the accounts that the transfer writes to, and the compute unit limit
+func (client *Client) setComputeBudget(ctx context.Context, txInput *tx_input.TxInput, args *xcbuilder.TransferArgs, accountsToLock []solana.PublicKey) (xc.TxInput, error) {
+	percentiles, err := client.FetchPrioritizationFeePercentiles(ctx, accountsToLock)
 	if err != nil {
 		return txInput, fmt.Errorf("could not lookup priority fees: %v", err)
 	}
-	priority_fee_count := uint64(0)
-	// start with 100 min priority fee, then average in the recent priority fees paid.
-	priority_fee_sum := uint64(100)
-	for _, fee := range fees {
-		if fee.PrioritizationFee > 0 {
-			priority_fee_sum += fee.PrioritizationFee
-			priority_fee_count += 1
-		}
-	}
-	if priority_fee_count > 0 {
-		txInput.PrioritizationFee = xc.NewBigIntFromUint64(
-			priority_fee_sum / priority_fee_count,
-		)
-	} else {
-		// default 100
-		txInput.PrioritizationFee = xc.NewBigIntFromUint64(
-			100,
-		)
+	// without a priority in the arguments, the fee may be recomputed for another priority later
+	priority, _ := args.GetPriority()
+	fee, err := percentiles.ForPriority(priority)
+	if err != nil {
+		return txInput, err
 	}
-	// apply multiplier
-	txInput.PrioritizationFee = txInput.PrioritizationFee.ApplyGasPriceMultiplier(client.cfg)
+	txInput.PrioritizationFee = fee
+	txInput.PrioritizationFeePercentiles = percentiles
+	txInput.PrioritizationFeePriority = priority
 
+	client.SetComputeUnitLimit(ctx, txInput, func() (xc.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(client.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.NewTransfer(args, txInput)
+	})
 	return txInput, nil
 }
 
@@ -173,14 +228,11 @@ func (a *Client) EstimateGasFee(ctx context.Context, _tx xc.Tx) (*xc.BigInt, err
 	tx := _tx.(*tx.Tx)
 	solanaTx := tx.SolTx
 
-	feeCalc, err := a.client.GetFeeForMessage(ctx, solanaTx.Message.ToBase64(), rpc.CommitmentFinalized)
-	if err != nil {
-		return nil, err
+	if solanaTx == nil {
+		return nil, errors.New("transaction not initialized")
 	}
-
-	value := *feeCalc.Value
-
-	fee := xc.NewBigIntFromUint64(value)
+	// the base fee for the signatures plus the priority fee for the compute unit limit
+	fee := tx.Fee()
 	return &fee, nil
 }
 
@@ -198,17 +250,47 @@ func (a *Client) BroadcastTx(ctx context.Context, _tx xc.Tx) error {
 }
 
 func (a *Client) FetchBalance(ctx context.Context, address xc.Address) (*xc.BigInt, error) {
-	addr := solana.MustPublicKeyFromBase58(string(address))
-	out, err := a.client.GetBalance(ctx, addr, rpc.CommitmentFinalized)
+	return a.FetchBalanceAt(ctx, address, "", xcclient.ReadArgs{})
+}
+
+func (a *Client) FetchBalanceForAsset(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress) (*xc.BigInt, error) {
+	return a.FetchBalanceAt(ctx, address, contractAddress, xcclient.ReadArgs{})
+}
+
+// Solana reads at `finalized` unless a lower commitment is requested
+func commitmentForArgs(args xcclient.ReadArgs) (rpc.CommitmentType, error) {
+	if _, ok := args.GetHeight(); ok {
+		return "", errors.New("reading balances at a historical height is not supported on solana")
+	}
+	commitment, _ := args.GetCommitment()
+	switch commitment {
+	case xcclient.CommitmentLatest:
+		return rpc.CommitmentProcessed, nil
+	case xcclient.CommitmentConfirmed:
+		return rpc.CommitmentConfirmed, nil
+	}
+	return rpc.CommitmentFinalized, nil
+}
+
+func (a *Client) FetchBalanceAt(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress, args xcclient.ReadArgs) (*xc.BigInt, error) {
+	commitment, err := commitmentForArgs(args)
 	if err != nil {
 		return nil, err
 	}
-	balance := xc.NewBigIntFromUint64(out.Value)
-	return &balance, nil
-}
+	if contractAddress == "" || string(contractAddress) == string(a.cfg.Chain) {
+		addr, err := solana.PublicKeyFromBase58(string(address))
+		if err != nil {
+			return nil, err
+		}
+		out, err := a.client.GetBalance(ctx, addr, commitment)
+		if err != nil {
+			return nil, err
+		}
+		balance := xc.NewBigIntFromUint64(out.Value)
+		return &balance, nil
+	}
 
-func (a *Client) FetchBalanceForAsset(ctx context.Context, address xc.Address, contractAddress xc.ContractAddress) (*xc.BigInt, error) {
-	tokenAccounts, err := a.GetTokenAccountsByOwner(ctx, string(address), string(contractAddress))
+	tokenAccounts, err := a.getTokenAccountsByOwner(ctx, string(address), string(contractAddress), commitment)
 	if err != nil {
 		return nil, err
 	}
@@ -233,6 +315,10 @@ type TokenAccountWithInfo struct {
 
 // Get all token accounts for a given token that are owned by an address.
 func (client *Client) GetTokenAccountsByOwner(ctx context.Context, addr string, contract string) ([]*TokenAccountWithInfo, error) {
+	return client.getTokenAccountsByOwner(ctx, addr, contract, rpc.CommitmentFinalized)
+}
+
+func (client *Client) getTokenAccountsByOwner(ctx context.Context, addr string, contract string, commitment rpc.CommitmentType) ([]*TokenAccountWithInfo, error) {
 	address, err := solana.PublicKeyFromBase58(addr)
 	if err != nil {
 		return nil, err
@@ -246,7 +332,7 @@ func (client *Client) GetTokenAccountsByOwner(ctx context.Context, addr string,
 		Mint: &mint,
 	}
 	opts := rpc.GetTokenAccountsOpts{
-		Commitment: rpc.CommitmentFinalized,
+		Commitment: commitment,
 		// required to be able to parse extra data as json
 		Encoding: "jsonParsed",
 	}
@@ -269,13 +355,11 @@ func (client *Client) GetTokenAccountsByOwner(ctx context.Context, addr string,
 	return tokenAccounts, nil
 }
 
-// FetchLegacyTxInfo returns tx info for a Solana tx
-func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHash xc.TxHash) (*xc.LegacyTxInfo, error) {
-	result := &xc.LegacyTxInfo{}
-
+// Fetches the transaction and its metadata, resolving the accounts loaded from address lookup tables
+func (client *Client) fetchTransaction(ctx context.Context, txHash xc.TxHash) (*rpc.GetTransactionResult, *tx.Tx, error) {
 	txSig, err := solana.SignatureFromBase58(string(txHash))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// confusingly, '0' is the latest version, which comes after 'legacy' (no version).
 	maxVersion := uint64(0)
@@ -289,18 +373,51 @@ func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHash xc.TxHash) (
 		},
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	if res == nil || res.Transaction == nil {
-		return nil, errors.New("invalid transaction in response")
+	if res == nil || res.Transaction == nil || res.Meta == nil {
+		return nil, nil, errors.New("invalid transaction in response")
 	}
 
 	solTx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(res.Transaction.GetBinary()))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	tx := tx.NewTxFrom(solTx)
 	meta := res.Meta
+	if solTx.Message.IsVersioned() && solTx.Message.NumLookups() > 0 {
+		err = tx.SetLoadedAddresses(meta.LoadedAddresses.Writable, meta.LoadedAddresses.ReadOnly)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not resolve address table lookups: %v", err)
+		}
+	}
+	return res, tx, nil
+}
+
+// Returns the number of slots since the transaction, which is 0 if the latest slot cannot be fetched
+func (client *Client) fetchConfirmations(ctx context.Context, slot uint64) int64 {
+	recent, err := client.client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		// ignore
+		logrus.WithError(err).Warn("failed to get latest blockhash")
+		return 0
+	}
+	return int64(recent.Context.Slot) - int64(slot)
+}
+
+// FetchLegacyTxInfo returns tx info for a Solana tx
+func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHash xc.TxHash) (*xc.LegacyTxInfo, error) {
+	res, tx, err := client.fetchTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	return client.legacyTxInfo(ctx, txHash, res, tx), nil
+}
+
+// Decodes the transfers of the top-level instructions of the transaction
+func (client *Client) legacyTxInfo(ctx context.Context, txHash xc.TxHash, res *rpc.GetTransactionResult, tx *tx.Tx) *xc.LegacyTxInfo {
+	result := &xc.LegacyTxInfo{}
+	meta := res.Meta
 	if res.BlockTime != nil {
 		result.BlockTime = res.BlockTime.Time().Unix()
 	}
@@ -311,13 +428,7 @@ func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHash xc.TxHash) (
 			result.BlockTime = int64(*res.BlockTime)
 		}
 
-		recent, err := client.client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
-		if err != nil {
-			// ignore
-			logrus.WithError(err).Warn("failed to get latest blockhash")
-		} else {
-			result.Confirmations = int64(recent.Context.Slot) - result.BlockIndex
-		}
+		result.Confirmations = client.fetchConfirmations(ctx, res.Slot)
 	}
 	result.Fee = xc.NewBigIntFromUint64(meta.Fee)
 
@@ -391,6 +502,28 @@ func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHash xc.TxHash) (
 			ContractAddress: contract,
 		})
 	}
+	for _, instr := range tx.GetTokenTransferCheckedWithFees() {
+		contract := xc.ContractAddress(instr.Mint.String())
+		to := xc.Address(instr.Destination.String())
+		tokenAccountInfo, err := client.LookupTokenAccount(ctx, instr.Destination)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to lookup token account")
+		} else {
+			to = xc.Address(tokenAccountInfo.Parsed.Info.Owner)
+		}
+
+		// the mint withholds the fee from the amount sent
+		sources = append(sources, &xc.LegacyTxInfoEndpoint{
+			Address:         xc.Address(instr.Owner.String()),
+			Amount:          xc.NewBigIntFromUint64(instr.Amount),
+			ContractAddress: contract,
+		})
+		dests = append(dests, &xc.LegacyTxInfoEndpoint{
+			Address:         to,
+			Amount:          xc.NewBigIntFromUint64(instr.Amount - instr.Fee),
+			ContractAddress: contract,
+		})
+	}
 	for _, instr := range tx.GetTokenTransfers() {
 		from := instr.GetOwnerAccount().PublicKey.String()
 		toTokenAccount := instr.GetDestinationAccount().PublicKey
@@ -418,39 +551,19 @@ func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHash xc.TxHash) (
 			ContractAddress: contract,
 		})
 	}
-	for _, instr := range tx.GetDelegateStake() {
-		xcStake := &xcclient.Stake{
-			Account:   instr.GetStakeAccount().PublicKey.String(),
-			Validator: instr.GetVoteAccount().PublicKey.String(),
-			Address:   instr.GetStakeAuthority().PublicKey.String(),
-			// Needs to be looked up from separate instruction
-			Balance: xc.BigInt{},
-		}
-		for _, createAccount := range tx.GetCreateAccounts() {
-			if createAccount.NewAccount.Equals(instr.GetStakeAccount().PublicKey) {
-				xcStake.Balance = xc.NewBigIntFromUint64(createAccount.Lamports)
-			}
-		}
-
-		result.AddStakeEvent(xcStake)
+	for _, ev := range client.stakeEvents(ctx, tx) {
+		result.AddStakeEvent(ev)
 	}
-	for _, instr := range tx.GetDeactivateStakes() {
-		xcStake := &xcclient.Unstake{
-			Account: instr.GetStakeAccount().PublicKey.String(),
-			Address: instr.GetStakeAuthority().PublicKey.String(),
 
-			// Needs to be looked up
-			Balance:   xc.BigInt{},
-			Validator: "",
-		}
-		stakeAccountInfo, err := client.LookupStakeAccount(ctx, instr.GetStakeAccount().PublicKey)
-		if err != nil {
-			logrus.WithError(err).Warn("failed to lookup stake account")
-		} else {
-			xcStake.Validator = stakeAccountInfo.Parsed.Info.Stake.Delegation.Voter
-			xcStake.Balance = xc.NewBigIntFromStr(stakeAccountInfo.Parsed.Info.Stake.Delegation.Stake)
+	// memos apply to the whole transaction, and are repeated when a recipient requires them on each transfer
+	memos := []string{}
+	for _, memo := range tx.GetMemos() {
+		if !slices.Contains(memos, memo) {
+			memos = append(memos, memo)
 		}
-		result.AddStakeEvent(xcStake)
+	}
+	for _, dest := range dests {
+		dest.Memo = strings.Join(memos, "\n")
 	}
 
 	if len(sources) > 0 {
@@ -461,11 +574,18 @@ func (client *Client) FetchLegacyTxInfo(ctx context.Context, txHash xc.TxHash) (
 		result.Amount = dests[0].Amount
 		result.ContractAddress = dests[0].ContractAddress
 	}
+	if len(tx.SolTx.Message.AccountKeys) > 0 {
+		// the first account pays the fee
+		feePayer := xc.Address(tx.SolTx.Message.AccountKeys[0].String())
+		if feePayer != result.From {
+			result.FeePayer = feePayer
+		}
+	}
 
 	result.Sources = sources
 	result.Destinations = dests
 
-	return result, nil
+	return result
 }
 
 func (client *Client) LookupTokenAccount(ctx context.Context, tokenAccount solana.PublicKey) (solana_types.TokenAccountInfo, error) {
@@ -18,12 +18,12 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-type parsedStakeAccount struct {
+type ParsedStakeAccount struct {
 	Account      *rpc.KeyedAccount
 	StakeAccount types.StakeAccount
 }
 
-func (client *Client) GetStakeAccounts(ctx context.Context, address xc_types.Address) ([]*parsedStakeAccount, error) {
+func (client *Client) GetStakeAccounts(ctx context.Context, address xc_types.Address) ([]*ParsedStakeAccount, error) {
 	stakeAuthority, err := solana.PublicKeyFromBase58(string(address))
 	if err != nil {
 		return nil, err
@@ -43,14 +43,14 @@ func (client *Client) GetStakeAccounts(ctx context.Context, address xc_types.Add
 	if err != nil {
 		return nil, err
 	}
-	var stakeAccounts []*parsedStakeAccount
+	var stakeAccounts []*ParsedStakeAccount
 	for _, acc := range res {
 		var stakeAccount types.StakeAccount
 		err := json.Unmarshal(acc.Account.Data.GetRawJSON(), &stakeAccount)
 		if err != nil {
 			return nil, err
 		}
-		stakeAccounts = append(stakeAccounts, &parsedStakeAccount{
+		stakeAccounts = append(stakeAccounts, &ParsedStakeAccount{
 			Account:      acc,
 			StakeAccount: stakeAccount,
 		})
@@ -58,6 +58,35 @@ func (client *Client) GetStakeAccounts(ctx context.Context, address xc_types.Add
 	return stakeAccounts, nil
 
 }
+
+// Looks up the vote account of the validator, which may be given by its vote or identity pubkey
+func (client *Client) getValidatorVoteAccount(ctx context.Context, validatorAddress string) (solana.PublicKey, error) {
+	validatorPubkey, err := solana.PublicKeyFromBase58(validatorAddress)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("invalid base58 for validator address: %v", err)
+	}
+
+	voteAccounts, err := client.client.GetVoteAccounts(ctx, &rpc.GetVoteAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	for _, voteAccount := range voteAccounts.Current {
+		if voteAccount.VotePubkey == validatorPubkey {
+			return voteAccount.VotePubkey, nil
+		}
+		if voteAccount.NodePubkey == validatorPubkey {
+			logrus.WithFields(logrus.Fields{
+				"identity": voteAccount.NodePubkey.String(),
+				"vote":     voteAccount.VotePubkey.String(),
+			}).Warn("validator identity pubkey was input, using the vote pubkey instead")
+			return voteAccount.VotePubkey, nil
+		}
+	}
+	return solana.PublicKey{}, fmt.Errorf("validator vote account not found: %s", validatorAddress)
+}
+
 func (client *Client) FetchStakeBalance(ctx context.Context, args xclient.StakedBalanceArgs) ([]*xclient.StakedBalance, error) {
 	stakeAccounts, err := client.GetStakeAccounts(ctx, args.GetFrom())
 	if err != nil {
@@ -70,6 +99,8 @@ func (client *Client) FetchStakeBalance(ctx context.Context, args xclient.Staked
 	}
 
 	stakedBalances := []*xclient.StakedBalance{}
+	// stake accounts are grouped by the validator that they are delegated to
+	validatorBalances := map[string]*xclient.StakedBalance{}
 
 	for _, stake := range stakeAccounts {
 		validator := stake.StakeAccount.Parsed.Info.Stake.Delegation.Voter
@@ -101,14 +132,21 @@ func (client *Client) FetchStakeBalance(ctx context.Context, args xclient.Staked
 			&rentReserve,
 		)
 
-		stakedBalances = append(stakedBalances, stakedBalance)
+		validatorBalance, ok := validatorBalances[validator]
+		if !ok {
+			validatorBalance = xclient.NewStakedBalances(xclient.StakedBalanceState{}, validator, "")
+			validatorBalances[validator] = validatorBalance
+			stakedBalances = append(stakedBalances, validatorBalance)
+		}
+		validatorBalance.Balance = validatorBalance.Balance.Add(stakedBalance.Balance)
+		validatorBalance.Accounts = append(validatorBalance.Accounts, stakedBalance)
 	}
 
 	return stakedBalances, nil
 }
 
 func (client *Client) FetchStakingInput(ctx context.Context, args xcbuilder.StakeArgs) (xc_types.StakeTxInput, error) {
-	txInput, err := client.FetchBaseInput(ctx, args.GetFrom())
+	txInput, err := client.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
 	if err != nil {
 		return nil, err
 	}
@@ -126,38 +164,18 @@ func (client *Client) FetchStakingInput(ctx context.Context, args xcbuilder.Stak
 	if !ok {
 		return nil, errors.New("validator to be delegated to is required")
 	}
-	validatorPubkey, err := solana.PublicKeyFromBase58(string(validatorAddress))
-	if err != nil {
-		return nil, fmt.Errorf("invalid base58 for validator address: %v", err)
-	}
-
-	voteAccounts, err := client.client.GetVoteAccounts(ctx, &rpc.GetVoteAccountsOpts{
-		Commitment: rpc.CommitmentFinalized,
-	})
+	stakeInput.ValidatorVoteAccount, err = client.getValidatorVoteAccount(ctx, validatorAddress)
 	if err != nil {
 		return nil, err
 	}
-	found := false
-	for _, voteAccount := range voteAccounts.Current {
-		if voteAccount.VotePubkey == validatorPubkey {
-			stakeInput.ValidatorVoteAccount = voteAccount.VotePubkey
-			found = true
-			break
-		}
-		if voteAccount.NodePubkey == validatorPubkey {
-			stakeInput.ValidatorVoteAccount = voteAccount.VotePubkey
-			logrus.WithFields(logrus.Fields{
-				"identity": voteAccount.NodePubkey.String(),
-				"vote":     voteAccount.VotePubkey.String(),
-			}).Warn("validator identity pubkey was input, using the vote pubkey instead")
-			found = true
-			break
-		}
-	}
-	if !found {
-		return nil, fmt.Errorf("validator vote account not found: %s", validatorAddress)
-	}
 
+	client.SetComputeUnitLimit(ctx, &stakeInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(client.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.Stake(args, &stakeInput)
+	})
 	return &stakeInput, nil
 }
 
@@ -180,7 +198,7 @@ func (client *Client) FetchUnstakingInput(ctx context.Context, args xcbuilder.St
 		return nil, fmt.Errorf("amount to unstake is below the rent exempt threshold (%s SOL)", builder.RentExemptLamportsThresholdHuman)
 	}
 
-	txInput, err := client.FetchBaseInput(ctx, args.GetFrom())
+	txInput, err := client.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
 	if err != nil {
 		return nil, err
 	}
@@ -234,6 +252,13 @@ func (client *Client) FetchUnstakingInput(ctx context.Context, args xcbuilder.St
 		StakingKey:     privKey,
 		EligibleStakes: matchingStakeAccounts,
 	}
+	client.SetComputeUnitLimit(ctx, &unstakeInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(client.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.Unstake(args, &unstakeInput)
+	})
 	return &unstakeInput, nil
 }
 
@@ -242,7 +267,7 @@ func (client *Client) FetchWithdrawInput(ctx context.Context, args xcbuilder.Sta
 	if err != nil {
 		return nil, err
 	}
-	txInput, err := client.FetchBaseInput(ctx, args.GetFrom())
+	txInput, err := client.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
 	if err != nil {
 		return nil, err
 	}
@@ -291,5 +316,12 @@ func (client *Client) FetchWithdrawInput(ctx context.Context, args xcbuilder.Sta
 		TxInput:        *txInput,
 		EligibleStakes: matchingStakeAccounts,
 	}
+	client.SetComputeUnitLimit(ctx, &withdrawInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(client.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.Withdraw(args, &withdrawInput)
+	})
 	return &withdrawInput, nil
 }
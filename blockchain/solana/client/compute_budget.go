@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// Margin over the compute units consumed in simulation
+const ComputeUnitLimitMargin = 1.2
+
+// Minimum priority fee, in micro-lamports per compute unit
+const MinPrioritizationFee = 100
+
+// FetchPrioritizationFeePercentiles returns the percentiles of the recent priority fees paid to write
+// to the accounts, for each priority.
+func (client *Client) FetchPrioritizationFeePercentiles(ctx context.Context, accounts []solana.PublicKey) (*tx_input.PrioritizationFeePercentiles, error) {
+	recentFees, err := client.client.GetRecentPrioritizationFees(ctx, accounts)
+	if err != nil {
+		return nil, err
+	}
+	fees := []uint64{}
+	for _, fee := range recentFees {
+		if fee.PrioritizationFee > 0 {
+			fees = append(fees, fee.PrioritizationFee)
+		}
+	}
+	sort.Slice(fees, func(i, j int) bool {
+		return fees[i] < fees[j]
+	})
+	percentile := func(percentile float64) xc.BigInt {
+		fee := uint64(MinPrioritizationFee)
+		if len(fees) > 0 {
+			index := int(math.Ceil(percentile/100*float64(len(fees)))) - 1
+			fee = max(fee, fees[max(0, index)])
+		}
+		return xc.NewBigIntFromUint64(fee).ApplyGasPriceMultiplier(client.cfg)
+	}
+	return &tx_input.PrioritizationFeePercentiles{
+		Low:            percentile(25),
+		Market:         percentile(50),
+		Aggressive:     percentile(75),
+		VeryAggressive: percentile(95),
+	}, nil
+}
+
+// FetchPrioritizationFee returns the percentile of the recent priority fees paid to write to the accounts,
+// that matches the priority.
+func (client *Client) FetchPrioritizationFee(ctx context.Context, accounts []solana.PublicKey, priority xc.GasFeePriority) (xc.BigInt, error) {
+	percentiles, err := client.FetchPrioritizationFeePercentiles(ctx, accounts)
+	if err != nil {
+		return xc.BigInt{}, err
+	}
+	return percentiles.ForPriority(priority)
+}
+
+// SimulateComputeUnits returns the compute units that the transaction consumes
+func (client *Client) SimulateComputeUnits(ctx context.Context, built xc.Tx) (uint64, error) {
+	solTx, ok := built.(*tx.Tx)
+	if !ok || solTx.SolTx == nil {
+		return 0, fmt.Errorf("unsupported transaction type %T", built)
+	}
+	// the signatures must still be present
+	simTx := *solTx.SolTx
+	simTx.Signatures = append([]solana.Signature{}, simTx.Signatures...)
+	for len(simTx.Signatures) < int(simTx.Message.Header.NumRequiredSignatures) {
+		simTx.Signatures = append(simTx.Signatures, solana.Signature{})
+	}
+	simulated, err := client.client.SimulateTransactionWithOpts(ctx, &simTx, &rpc.SimulateTransactionOpts{
+		Commitment:             rpc.CommitmentConfirmed,
+		ReplaceRecentBlockhash: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not simulate tx: %v", err)
+	}
+	if simulated.Value.Err != nil {
+		errBz, _ := json.Marshal(simulated.Value.Err)
+		return 0, fmt.Errorf("simulated tx failed: %s", string(errBz))
+	}
+	if simulated.Value.UnitsConsumed == nil {
+		return 0, fmt.Errorf("simulation did not report the compute units consumed")
+	}
+	return *simulated.Value.UnitsConsumed, nil
+}
+
+// Sets the compute unit limit from simulating the transaction.  If the transaction cannot be
+// simulated, the default limit is used.
+func (client *Client) SetComputeUnitLimit(ctx context.Context, txInput *tx_input.TxInput, build func() (xc.Tx, error)) {
+	// simulate with the limit instruction included, as it consumes units also
+	txInput.ComputeUnitLimit = tx.MaxComputeUnitLimit
+	built, err := build()
+	var unitsConsumed uint64
+	if err == nil {
+		unitsConsumed, err = client.SimulateComputeUnits(ctx, built)
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("could not simulate compute units, using the default limit")
+		txInput.ComputeUnitLimit = 0
+		return
+	}
+	limit := math.Ceil(float64(unitsConsumed) * ComputeUnitLimitMargin)
+	txInput.ComputeUnitLimit = uint32(min(limit, tx.MaxComputeUnitLimit))
+}
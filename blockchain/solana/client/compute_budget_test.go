@@ -0,0 +1,174 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchPrioritizationFee(t *testing.T) {
+	recentFees := `[{"slot":1,"prioritizationFee":0},{"slot":2,"prioritizationFee":4000},{"slot":3,"prioritizationFee":1000},{"slot":4,"prioritizationFee":3000},{"slot":5,"prioritizationFee":2000}]`
+
+	vectors := []struct {
+		priority xc_types.GasFeePriority
+		resp     string
+		expected uint64
+		err      string
+	}{
+		{priority: xc_types.Low, resp: recentFees, expected: 1000},
+		{priority: "", resp: recentFees, expected: 2000},
+		{priority: xc_types.Market, resp: recentFees, expected: 2000},
+		{priority: xc_types.Aggressive, resp: recentFees, expected: 3000},
+		{priority: xc_types.VeryAggressive, resp: recentFees, expected: 4000},
+		{priority: "2", resp: recentFees, expected: 4000},
+		// no recent fees uses the minimum
+		{priority: xc_types.Market, resp: `[]`, expected: client.MinPrioritizationFee},
+		{priority: "invalid", resp: recentFees, err: "invalid"},
+	}
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("%d - %s", i, v.priority), func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, v.resp)
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+
+			fee, err := cli.FetchPrioritizationFee(context.Background(), []solana.PublicKey{}, v.priority)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, v.expected, fee.Uint64())
+		})
+	}
+}
+
+func TestFetchTransferInputComputeUnitLimit(t *testing.T) {
+	blockhash := `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+	recentFees := `[{"slot":1,"prioritizationFee":2000}]`
+
+	vectors := []struct {
+		name     string
+		resp     []string
+		expected uint32
+	}{
+		{
+			name: "limit from the simulation",
+			resp: []string{
+				blockhash,
+				recentFees,
+				`{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`,
+			},
+			expected: 1200,
+		},
+		{
+			name: "default limit when the simulation fails",
+			resp: []string{
+				blockhash,
+				recentFees,
+				`{"context":{"slot":1},"value":{"err":"AccountNotFound","logs":[],"unitsConsumed":0}}`,
+			},
+			expected: 0,
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, v.resp)
+			defer close()
+
+			chainCfg := &xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}}
+			cli, err := client.NewClient(chainCfg)
+			require.NoError(t, err)
+
+			args, err := xcbuilder.NewTransferArgs(
+				xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+				xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH"),
+				xc_types.NewBigIntFromUint64(1),
+			)
+			require.NoError(t, err)
+
+			input, err := cli.FetchTransferInput(context.Background(), args)
+			require.NoError(t, err)
+			txInput := input.(*tx_input.TxInput)
+			require.EqualValues(t, 2000, txInput.PrioritizationFee.Uint64())
+			require.Equal(t, v.expected, txInput.ComputeUnitLimit)
+			// every percentile of a single recent fee is that fee
+			require.NoError(t, txInput.SetGasFeePriority(xc_types.Aggressive))
+			require.EqualValues(t, 2000, txInput.PrioritizationFee.Uint64())
+
+			// the fee is charged on the limit
+			txBuilder, err := builder.NewTxBuilder(chainCfg)
+			require.NoError(t, err)
+			tx, err := txBuilder.NewTransfer(args, txInput)
+			require.NoError(t, err)
+			fee, err := cli.EstimateGasFee(context.Background(), tx)
+			require.NoError(t, err)
+			if v.expected > 0 {
+				// 5000 + ceil(2000 * 1200 / 1e6)
+				require.Equal(t, "5003", fee.String())
+			} else {
+				// 5000 + 2000 * 200000 / 1e6
+				require.Equal(t, "5400", fee.String())
+			}
+		})
+	}
+}
+
+func TestFetchTransferInputPriority(t *testing.T) {
+	blockhash := `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+	recentFees := `[{"slot":1,"prioritizationFee":4000},{"slot":2,"prioritizationFee":1000},{"slot":3,"prioritizationFee":3000},{"slot":4,"prioritizationFee":2000}]`
+	simulation := `{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`
+
+	vectors := []struct {
+		name     string
+		priority xc_types.GasFeePriority
+		fetched  uint64
+		set      xc_types.GasFeePriority
+		expected uint64
+	}{
+		{name: "market fee recomputed for another priority", fetched: 2000, set: xc_types.Aggressive, expected: 3000},
+		{name: "market fee recomputed for a lower priority", fetched: 2000, set: xc_types.Low, expected: 1000},
+		{name: "market fee recomputed for a custom priority", fetched: 2000, set: "1.5", expected: 3000},
+		{name: "requested priority is kept", priority: xc_types.Aggressive, fetched: 3000, set: xc_types.Low, expected: 3000},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, []string{blockhash, recentFees, simulation})
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+
+			options := []xcbuilder.BuilderOption{}
+			if v.priority != "" {
+				options = append(options, xcbuilder.WithPriority(v.priority))
+			}
+			args, err := xcbuilder.NewTransferArgs(
+				xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+				xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH"),
+				xc_types.NewBigIntFromUint64(1),
+				options...,
+			)
+			require.NoError(t, err)
+
+			input, err := cli.FetchTransferInput(context.Background(), args)
+			require.NoError(t, err)
+			txInput := input.(*tx_input.TxInput)
+			require.Equal(t, v.priority, txInput.PrioritizationFeePriority)
+			require.EqualValues(t, v.fetched, txInput.PrioritizationFee.Uint64())
+
+			require.NoError(t, txInput.SetGasFeePriority(v.set))
+			require.EqualValues(t, v.expected, txInput.PrioritizationFee.Uint64())
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// FetchAddressLookupTables reads the addresses in each lookup table, so the builder can
+// reference them from a v0 message.
+func (client *Client) FetchAddressLookupTables(ctx context.Context, tables []string) ([]*tx_input.AddressLookupTable, error) {
+	tableKeys := make([]solana.PublicKey, len(tables))
+	for i, table := range tables {
+		tableKey, err := solana.PublicKeyFromBase58(table)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address lookup table %s: %v", table, err)
+		}
+		tableKeys[i] = tableKey
+	}
+	accounts, err := client.client.GetMultipleAccountsWithOpts(ctx, tableKeys, &rpc.GetMultipleAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+		Encoding:   solana.EncodingBase64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch address lookup tables: %v", err)
+	}
+	if len(accounts.Value) != len(tableKeys) {
+		return nil, fmt.Errorf("expected %d address lookup tables, got %d", len(tableKeys), len(accounts.Value))
+	}
+	results := []*tx_input.AddressLookupTable{}
+	for i, account := range accounts.Value {
+		if account == nil {
+			return nil, fmt.Errorf("address lookup table not found: %s", tableKeys[i])
+		}
+		if !account.Owner.Equals(solana.AddressLookupTableProgramID) {
+			return nil, fmt.Errorf("account %s is not an address lookup table", tableKeys[i])
+		}
+		state, err := solana_types.ParseAddressLookupTable(account.Data.GetBinary())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", tableKeys[i], err)
+		}
+		results = append(results, &tx_input.AddressLookupTable{
+			Account:   tableKeys[i],
+			Addresses: state.Addresses,
+		})
+	}
+	return results, nil
+}
+
+// FetchCreateAddressLookupTableInput returns the input to create a new lookup table, which is derived from a recent slot
+func (client *Client) FetchCreateAddressLookupTableInput(ctx context.Context, from xc.Address) (*tx_input.CreateAddressLookupTableInput, error) {
+	txInput, err := client.FetchBaseInput(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	// the slot must be recent, but also finalized so that it can be found in the slot hashes
+	slot, err := client.client.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("could not get recent slot: %v", err)
+	}
+	input := tx_input.NewCreateAddressLookupTableInput()
+	input.TxInput = *txInput
+	input.RecentSlot = slot
+	return input, nil
+}
@@ -0,0 +1,143 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	"github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+)
+
+func mockLookupTable(t *testing.T, deactivationSlot uint64, addresses ...solana.PublicKey) []byte {
+	authority := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	buf := new(bytes.Buffer)
+	err := addresslookuptable.AddressLookupTableState{
+		TypeIndex:        1,
+		DeactivationSlot: deactivationSlot,
+		Authority:        &authority,
+		Addresses:        addresses,
+	}.MarshalWithEncoder(bin.NewBinEncoder(buf))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestFetchTransferInputWithLookupTables(t *testing.T) {
+	table := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	address1 := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	address2 := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+	blockhash := `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+
+	vectors := []struct {
+		name     string
+		accounts string
+		expected []*tx_input.AddressLookupTable
+		err      string
+	}{
+		{
+			name:     "active table",
+			accounts: fmt.Sprintf("[%s]", mockAccount(solana.AddressLookupTableProgramID, mockLookupTable(t, math.MaxUint64, address1, address2))),
+			expected: []*tx_input.AddressLookupTable{
+				{Account: table, Addresses: []solana.PublicKey{address1, address2}},
+			},
+		},
+		{
+			name:     "deactivated table",
+			accounts: fmt.Sprintf("[%s]", mockAccount(solana.AddressLookupTableProgramID, mockLookupTable(t, 100, address1))),
+			err:      "address lookup table is deactivated",
+		},
+		{
+			name:     "not a table",
+			accounts: fmt.Sprintf("[%s]", mockAccount(solana.SystemProgramID, []byte{})),
+			err:      "is not an address lookup table",
+		},
+		{
+			name:     "missing",
+			accounts: "[null]",
+			err:      "address lookup table not found",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, []string{
+				blockhash,
+				fmt.Sprintf(`{"context":{"slot":1},"value":%s}`, v.accounts),
+				`[]`,
+				`{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`,
+			})
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+			args, err := builder.NewTransferArgs(
+				xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+				xc_types.Address(address1.String()),
+				xc_types.NewBigIntFromUint64(1),
+				builder.WithAddressLookupTables(table.String()),
+			)
+			require.NoError(t, err)
+
+			input, err := cli.FetchTransferInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v.expected, input.(*tx_input.TxInput).AddressLookupTables)
+		})
+	}
+}
+
+func TestFetchLegacyTxInfoV0(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	table := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	to1 := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	to2 := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+
+	// both recipients are loaded from the lookup table
+	solTx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(100, from, to1).Build(),
+			system.NewTransferInstruction(200, from, to2).Build(),
+		},
+		solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		solana.TransactionPayer(from),
+		solana.TransactionAddressTables(map[solana.PublicKey]solana.PublicKeySlice{table: {to2, to1}}),
+	)
+	require.NoError(t, err)
+	solTx.Signatures = []solana.Signature{{1}}
+	loaded, err := solTx.Message.GetAddressTableLookupAccounts()
+	require.NoError(t, err)
+	bz, err := solTx.MarshalBinary()
+	require.NoError(t, err)
+
+	server, close := testtypes.MockJSONRPC(t, fmt.Sprintf(
+		`{"blockTime":1700000000,"meta":{"err":null,"fee":5000,"loadedAddresses":{"readonly":[],"writable":["%s","%s"]},"postBalances":[],"preBalances":[]},"slot":0,"transaction":["%s","base64"],"version":0}`,
+		loaded[0], loaded[1], base64.StdEncoding.EncodeToString(bz),
+	))
+	defer close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+	info, err := cli.FetchLegacyTxInfo(context.Background(), xc_types.TxHash(solTx.Signatures[0].String()))
+	require.NoError(t, err)
+	require.Len(t, info.Destinations, 2)
+	require.EqualValues(t, to1.String(), info.Destinations[0].Address)
+	require.EqualValues(t, 100, info.Destinations[0].Amount.Uint64())
+	require.EqualValues(t, to2.String(), info.Destinations[1].Address)
+	require.EqualValues(t, 200, info.Destinations[1].Amount.Uint64())
+	// the sender paid the fee
+	require.EqualValues(t, from.String(), info.From)
+	require.Empty(t, info.FeePayer)
+}
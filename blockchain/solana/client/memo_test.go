@@ -0,0 +1,84 @@
+package client_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchTxInfoMemo(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+
+	vectors := []struct {
+		name     string
+		memos    []solana.Instruction
+		expected string
+	}{
+		{
+			name:     "memo v2",
+			memos:    []solana.Instruction{types.NewMemoInstruction("1234", from)},
+			expected: "1234",
+		},
+		{
+			name: "memo v1",
+			memos: []solana.Instruction{
+				solana.NewInstruction(types.MemoV1ProgramID, solana.AccountMetaSlice{}, []byte("5678")),
+			},
+			expected: "5678",
+		},
+		{
+			name: "repeated memo",
+			memos: []solana.Instruction{
+				types.NewMemoInstruction("1234", from),
+				types.NewMemoInstruction("1234", from),
+			},
+			expected: "1234",
+		},
+		{
+			name:     "no memo",
+			expected: "",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			solTx, err := solana.NewTransaction(
+				append(v.memos, system.NewTransferInstruction(100, from, to).Build()),
+				solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+				solana.TransactionPayer(from),
+			)
+			require.NoError(t, err)
+			solTx.Signatures = []solana.Signature{{1}}
+			bz, err := solTx.MarshalBinary()
+			require.NoError(t, err)
+
+			server, close := testtypes.MockJSONRPC(t, fmt.Sprintf(
+				`{"blockTime":1700000000,"meta":{"err":null,"fee":5000,"postBalances":[],"preBalances":[]},"slot":0,"transaction":["%s","base64"]}`,
+				base64.StdEncoding.EncodeToString(bz),
+			))
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Chain: xc_types.SOL, Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+			legacyInfo, err := cli.FetchLegacyTxInfo(context.Background(), xc_types.TxHash(solTx.Signatures[0].String()))
+			require.NoError(t, err)
+			require.Len(t, legacyInfo.Destinations, 1)
+			require.Equal(t, v.expected, legacyInfo.Destinations[0].Memo)
+
+			info, err := cli.FetchTxInfo(context.Background(), xc_types.TxHash(solTx.Signatures[0].String()))
+			require.NoError(t, err)
+			require.Equal(t, v.expected, info.Transfers[0].Memo)
+			require.Equal(t, xcclient.NewAddressName(xc_types.SOL, to.String()), info.Transfers[0].To[0].Address)
+		})
+	}
+}
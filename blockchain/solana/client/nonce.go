@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func (client *Client) FetchNonceAccount(ctx context.Context, nonceAccount solana.PublicKey) (solana_types.NonceAccount, error) {
+	info, err := client.client.GetAccountInfoWithOpts(ctx, nonceAccount, &rpc.GetAccountInfoOpts{
+		Commitment: rpc.CommitmentFinalized,
+		Encoding:   "jsonParsed",
+	})
+	if err != nil {
+		return solana_types.NonceAccount{}, fmt.Errorf("could not fetch nonce account %s: %v", nonceAccount, err)
+	}
+	var nonceAccountInfo solana_types.NonceAccount
+	err = json.Unmarshal(info.Value.Data.GetRawJSON(), &nonceAccountInfo)
+	if err != nil {
+		return solana_types.NonceAccount{}, fmt.Errorf("could not parse nonce account %s: %v", nonceAccount, err)
+	}
+	return nonceAccountInfo, nil
+}
+
+// SetDurableNonce uses the current nonce of the nonce account in place of the recent block hash,
+// so that the transaction does not expire.
+func (client *Client) SetDurableNonce(ctx context.Context, txInput *tx_input.TxInput, from xc.Address, nonceAccount string) error {
+	nonceAccountPubkey, err := solana.PublicKeyFromBase58(nonceAccount)
+	if err != nil {
+		return fmt.Errorf("invalid nonce account: %v", err)
+	}
+	nonceAccountInfo, err := client.FetchNonceAccount(ctx, nonceAccountPubkey)
+	if err != nil {
+		return err
+	}
+	if nonceAccountInfo.Parsed.Type != "initialized" {
+		return fmt.Errorf("nonce account %s is not initialized", nonceAccount)
+	}
+	if nonceAccountInfo.Parsed.Info.Authority != string(from) {
+		return fmt.Errorf("nonce account %s has authority %s, not %s", nonceAccount, nonceAccountInfo.Parsed.Info.Authority, from)
+	}
+	nonce, err := solana.HashFromBase58(nonceAccountInfo.Parsed.Info.Blockhash)
+	if err != nil {
+		return fmt.Errorf("invalid nonce in account %s: %v", nonceAccount, err)
+	}
+	authority, err := solana.PublicKeyFromBase58(nonceAccountInfo.Parsed.Info.Authority)
+	if err != nil {
+		return fmt.Errorf("invalid nonce authority: %v", err)
+	}
+
+	txInput.RecentBlockHash = nonce
+	txInput.NonceAccount = nonceAccountPubkey
+	txInput.NonceAuthority = authority
+	return nil
+}
+
+// FetchCreateNonceAccountInput returns the input to create a new nonce account, with a new random key
+func (client *Client) FetchCreateNonceAccountInput(ctx context.Context, from xc.Address) (*tx_input.CreateNonceAccountInput, error) {
+	txInput, err := client.FetchBaseInput(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	rentExemptLamports, err := client.client.GetMinimumBalanceForRentExemption(ctx, builder.NonceAccountSize, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("could not get rent exempt balance for nonce account: %v", err)
+	}
+	nonceKey, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	input := tx_input.NewCreateNonceAccountInput()
+	input.TxInput = *txInput
+	input.NonceKey = nonceKey
+	input.RentExemptLamports = rentExemptLamports
+	return input, nil
+}
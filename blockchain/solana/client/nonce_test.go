@@ -0,0 +1,108 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	"github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func mockNonceAccount(kind string, authority string, nonce string) string {
+	return fmt.Sprintf(
+		`{"context":{"slot":1},"value":{"data":{"parsed":{"info":{"authority":"%s","blockhash":"%s","feeCalculator":{"lamportsPerSignature":"5000"}},"type":"%s"},"program":"nonce","space":80},"executable":false,"lamports":1447680,"owner":"11111111111111111111111111111111","rentEpoch":18446744073709551615,"space":80}}`,
+		authority, nonce, kind,
+	)
+}
+
+func TestFetchTransferInputWithNonce(t *testing.T) {
+	from := "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"
+	nonceAccount := "BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"
+	nonce := "5DvYnNNqB9fFkEjc1nCKEFjuJTNwFoTAqi3QnvWBRTPa"
+	blockhash := `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+
+	vectors := []struct {
+		name     string
+		resp     []string
+		expected *tx_input.TxInput
+		err      string
+	}{
+		{
+			name: "uses the nonce as the block hash",
+			resp: []string{
+				blockhash,
+				mockNonceAccount("initialized", from, nonce),
+				// priority fees
+				`[]`,
+				// simulation
+				`{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`,
+			},
+			expected: &tx_input.TxInput{
+				RecentBlockHash: solana.MustHashFromBase58(nonce),
+				NonceAccount:    solana.MustPublicKeyFromBase58(nonceAccount),
+				NonceAuthority:  solana.MustPublicKeyFromBase58(from),
+			},
+		},
+		{
+			name: "not initialized",
+			resp: []string{blockhash, mockNonceAccount("uninitialized", from, nonce)},
+			err:  "is not initialized",
+		},
+		{
+			name: "different authority",
+			resp: []string{blockhash, mockNonceAccount("initialized", "83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH", nonce)},
+			err:  "has authority 83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, v.resp)
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+
+			args, err := builder.NewTransferArgs(
+				xc_types.Address(from),
+				xc_types.Address("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH"),
+				xc_types.NewBigIntFromUint64(1),
+				builder.WithNonceAccount(nonceAccount),
+			)
+			require.NoError(t, err)
+
+			input, err := cli.FetchTransferInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			txInput := input.(*tx_input.TxInput)
+			require.Equal(t, v.expected.RecentBlockHash, txInput.RecentBlockHash)
+			require.Equal(t, v.expected.NonceAccount, txInput.NonceAccount)
+			require.Equal(t, v.expected.NonceAuthority, txInput.NonceAuthority)
+		})
+	}
+}
+
+func TestFetchCreateNonceAccountInput(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, []string{
+		`{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`,
+		`1447680`,
+	})
+	defer close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+
+	input, err := cli.FetchCreateNonceAccountInput(context.Background(), xc_types.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"))
+	require.NoError(t, err)
+	require.EqualValues(t, 1447680, input.RentExemptLamports)
+	require.False(t, input.NonceKey.PublicKey().IsZero())
+	require.Equal(t, solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"), input.RecentBlockHash)
+}
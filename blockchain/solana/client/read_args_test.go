@@ -0,0 +1,50 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	xclient "github.com/CustodyOne/chainkit/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchBalanceAt(t *testing.T) {
+	commitments := []string{}
+	server := testtypes.MockJSONRPCMethods(t, func(request *testtypes.RPCRequest) (string, bool) {
+		var opts struct {
+			Commitment string `json:"commitment"`
+		}
+		require.NoError(t, json.Unmarshal(request.Params[1], &opts))
+		commitments = append(commitments, opts.Commitment)
+		return `{"context":{"slot":100},"value":1500}`, true
+	})
+	defer server.Close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{
+		Chain:  xc_types.SOL,
+		Client: &xc_types.ClientConfig{URL: server.URL},
+	})
+	require.NoError(t, err)
+	address := xc_types.Address("DBomk9vPzgLWpDBvvQpJUAB1aFz8EHsPq6xEuA1cGMcV")
+
+	for _, commitment := range []xclient.Commitment{xclient.CommitmentLatest, xclient.CommitmentConfirmed, xclient.CommitmentFinalized} {
+		args, err := xclient.NewReadArgs(xclient.ReadOptionCommitment(commitment))
+		require.NoError(t, err)
+		balance, err := cli.FetchBalanceAt(context.Background(), address, "", args)
+		require.NoError(t, err)
+		require.Equal(t, "1500", balance.String())
+	}
+	// defaults to finalized
+	_, err = cli.FetchBalance(context.Background(), address)
+	require.NoError(t, err)
+	require.Equal(t, []string{"processed", "confirmed", "finalized", "finalized"}, commitments)
+
+	args, err := xclient.NewReadArgs(xclient.ReadOptionHeight(100))
+	require.NoError(t, err)
+	_, err = cli.FetchBalanceAt(context.Background(), address, "", args)
+	require.ErrorContains(t, err, "not supported")
+}
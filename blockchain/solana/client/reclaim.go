@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var _ xcclient.RentReclaimClient = &Client{}
+
+// Lists the token accounts of the owner, for both token programs, that could be closed
+func (client *Client) getClosableTokenAccounts(ctx context.Context, owner solana.PublicKey) ([]*tx_input.ReclaimableTokenAccount, error) {
+	accounts := []*tx_input.ReclaimableTokenAccount{}
+	for _, tokenProgram := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+		out, err := client.client.GetTokenAccountsByOwner(ctx, owner,
+			&rpc.GetTokenAccountsConfig{
+				ProgramId: &tokenProgram,
+			},
+			&rpc.GetTokenAccountsOpts{
+				Commitment: rpc.CommitmentFinalized,
+				Encoding:   "jsonParsed",
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not list token accounts: %v", err)
+		}
+		for _, acc := range out.Value {
+			info, err := solana_types.ParseRpcData(acc.Account.Data)
+			if err != nil {
+				return nil, err
+			}
+			// frozen accounts cannot be closed, nor can accounts with transfer fees to harvest
+			if info.Parsed.Info.State == "frozen" || info.Parsed.Info.WithheldAmount() > 0 {
+				continue
+			}
+			mint, err := solana.PublicKeyFromBase58(info.Parsed.Info.Mint)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, &tx_input.ReclaimableTokenAccount{
+				Account:      acc.Pubkey,
+				Mint:         mint,
+				TokenProgram: tokenProgram,
+				Decimals:     uint8(info.Parsed.Info.TokenAmount.Decimals),
+				Balance:      xc.NewBigIntFromStr(info.Parsed.Info.TokenAmount.Amount),
+				Lamports:     acc.Account.Lamports,
+			})
+		}
+	}
+	return accounts, nil
+}
+
+// FetchReclaimRentInput returns the token accounts of the owner to close, merge or unwrap.  At most
+// builder.MaxReclaimAccounts are reclaimed, so it may need to be repeated.
+func (client *Client) FetchReclaimRentInput(ctx context.Context, from xc.Address) (*tx_input.ReclaimRentInput, error) {
+	owner, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	txInput, err := client.FetchBaseInput(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	tokenAccounts, err := client.getClosableTokenAccounts(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	input := tx_input.NewReclaimRentInput()
+	input.TxInput = *txInput
+	input.TokenAccounts = tokenAccounts
+	input.SetActions(owner)
+
+	// keep the accounts that are merged into, so that they are not created again
+	limited := []*tx_input.ReclaimableTokenAccount{}
+	reclaimed := 0
+	for _, account := range tokenAccounts {
+		if account.Action != tx_input.ReclaimNone {
+			if reclaimed == builder.MaxReclaimAccounts {
+				continue
+			}
+			reclaimed++
+		}
+		limited = append(limited, account)
+	}
+	input.TokenAccounts = limited
+	return input, nil
+}
+
+// FetchReclaimableRent reports the rent that closing, merging and unwrapping the token accounts of the address returns
+func (client *Client) FetchReclaimableRent(ctx context.Context, address xc.Address) (*xcclient.ReclaimableRent, error) {
+	owner, err := solana.PublicKeyFromBase58(string(address))
+	if err != nil {
+		return nil, err
+	}
+	tokenAccounts, err := client.getClosableTokenAccounts(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	input := tx_input.NewReclaimRentInput()
+	input.TokenAccounts = tokenAccounts
+	input.SetActions(owner)
+
+	reclaimable := &xcclient.ReclaimableRent{
+		Address:  address,
+		Rent:     xc.NewBigIntFromUint64(0),
+		Accounts: []*xcclient.ReclaimableAccount{},
+	}
+	for _, account := range tokenAccounts {
+		action := account.Action
+		if action == tx_input.ReclaimNone {
+			continue
+		}
+		rent := xc.NewBigIntFromUint64(account.Rent())
+		reclaimable.Rent = reclaimable.Rent.Add(&rent)
+		reclaimable.Accounts = append(reclaimable.Accounts, &xcclient.ReclaimableAccount{
+			Account:  account.Account.String(),
+			Contract: xc.ContractAddress(account.Mint.String()),
+			Action:   string(action),
+			Balance:  account.Balance,
+			Rent:     rent,
+		})
+	}
+	return reclaimable, nil
+}
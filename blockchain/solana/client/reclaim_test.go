@@ -0,0 +1,129 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	reclaimOwner = "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"
+	reclaimMintA = "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
+	reclaimMintB = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	reclaimAuxA  = "BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"
+	reclaimAuxB  = "3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H"
+	reclaimAuxC  = "GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE"
+	reclaimWsol  = "6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh"
+	reclaimFee   = "9NmqDDZa7mH1DBM4zeq9cm7VcRn2un1i2TwuMvjBoVhU"
+)
+
+func reclaimTokenAccount(pubkey string, mint string, program string, state string, amount string, lamports uint64, extensions string) string {
+	return fmt.Sprintf(
+		`{"account":{"data":{"parsed":{"info":{"extensions":[%s],"isNative":false,"mint":"%s","owner":"%s","state":"%s","tokenAmount":{"amount":"%s","decimals":6,"uiAmount":0,"uiAmountString":"0"}},"type":"account"},"program":"spl-token","space":165},"executable":false,"lamports":%d,"owner":"%s","rentEpoch":0},"pubkey":"%s"}`,
+		extensions, mint, reclaimOwner, state, amount, lamports, program, pubkey,
+	)
+}
+
+func reclaimTokenAccounts(accounts ...string) string {
+	return fmt.Sprintf(`{"context":{"slot":1},"value":[%s]}`, strings.Join(accounts, ","))
+}
+
+func reclaimResponses() []string {
+	ataA, _ := solana_types.FindAssociatedTokenAddress(reclaimOwner, reclaimMintA, solana.TokenProgramID)
+	return []string{
+		reclaimTokenAccounts(
+			// kept, as the balance of the other account is merged into it
+			reclaimTokenAccount(ataA, reclaimMintA, solana.TokenProgramID.String(), "initialized", "0", 2039280, ""),
+			reclaimTokenAccount(reclaimAuxA, reclaimMintA, solana.TokenProgramID.String(), "initialized", "50", 2039280, ""),
+			// frozen accounts cannot be closed
+			reclaimTokenAccount(reclaimAuxB, reclaimMintB, solana.TokenProgramID.String(), "frozen", "0", 2039280, ""),
+			reclaimTokenAccount(reclaimWsol, solana.SolMint.String(), solana.TokenProgramID.String(), "initialized", "1000", 2040280, ""),
+		),
+		reclaimTokenAccounts(
+			reclaimTokenAccount(reclaimAuxC, reclaimMintB, solana.Token2022ProgramID.String(), "initialized", "0", 2074080, ""),
+			// withheld fees must be harvested first
+			reclaimTokenAccount(reclaimFee, reclaimMintB, solana.Token2022ProgramID.String(), "initialized", "0", 2074080,
+				`{"extension":"transferFeeAmount","state":{"withheldAmount":5}}`),
+		),
+	}
+}
+
+func TestFetchReclaimableRent(t *testing.T) {
+	server, close := testtypes.MockJSONRPC(t, reclaimResponses())
+	defer close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+
+	reclaimable, err := cli.FetchReclaimableRent(context.Background(), xc_types.Address(reclaimOwner))
+	require.NoError(t, err)
+	require.Equal(t, "6152640", reclaimable.Rent.String())
+	require.Len(t, reclaimable.Accounts, 3)
+
+	require.Equal(t, reclaimAuxA, reclaimable.Accounts[0].Account)
+	require.Equal(t, string(tx_input.ReclaimMerge), reclaimable.Accounts[0].Action)
+	require.Equal(t, "50", reclaimable.Accounts[0].Balance.String())
+
+	require.Equal(t, reclaimWsol, reclaimable.Accounts[1].Account)
+	require.Equal(t, string(tx_input.ReclaimUnwrap), reclaimable.Accounts[1].Action)
+	require.Equal(t, "2039280", reclaimable.Accounts[1].Rent.String())
+
+	require.Equal(t, reclaimAuxC, reclaimable.Accounts[2].Account)
+	require.Equal(t, string(tx_input.ReclaimClose), reclaimable.Accounts[2].Action)
+	require.Equal(t, xc_types.ContractAddress(reclaimMintB), reclaimable.Accounts[2].Contract)
+}
+
+func TestFetchReclaimRentInput(t *testing.T) {
+	blockhash := `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+	server, close := testtypes.MockJSONRPC(t, append([]string{blockhash}, reclaimResponses()...))
+	defer close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+
+	input, err := cli.FetchReclaimRentInput(context.Background(), xc_types.Address(reclaimOwner))
+	require.NoError(t, err)
+	require.Equal(t, "DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK", input.RecentBlockHash.String())
+	// the associated token account that is merged into is included
+	require.Len(t, input.TokenAccounts, 4)
+	require.Equal(t, solana.Token2022ProgramID, input.TokenAccounts[3].TokenProgram)
+	require.EqualValues(t, 2074080, input.TokenAccounts[3].Lamports)
+}
+
+func TestFetchReclaimRentInputLimit(t *testing.T) {
+	blockhash := `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+	ataA, _ := solana_types.FindAssociatedTokenAddress(reclaimOwner, reclaimMintA, solana.TokenProgramID)
+	// the associated token account is empty, and more accounts are merged into it than fit in one transaction
+	accounts := []string{
+		reclaimTokenAccount(ataA, reclaimMintA, solana.TokenProgramID.String(), "initialized", "0", 2039280, ""),
+	}
+	for i := 0; i < builder.MaxReclaimAccounts+2; i++ {
+		account, _ := solana.NewRandomPrivateKey()
+		accounts = append(accounts, reclaimTokenAccount(account.PublicKey().String(), reclaimMintA, solana.TokenProgramID.String(), "initialized", "50", 2039280, ""))
+	}
+	server, close := testtypes.MockJSONRPC(t, []string{blockhash, reclaimTokenAccounts(accounts...), reclaimTokenAccounts()})
+	defer close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+
+	input, err := cli.FetchReclaimRentInput(context.Background(), xc_types.Address(reclaimOwner))
+	require.NoError(t, err)
+	require.Len(t, input.TokenAccounts, builder.MaxReclaimAccounts+1)
+	// the associated token account is still kept, though some of the accounts merged into it are left out
+	require.Equal(t, ataA, input.TokenAccounts[0].Account.String())
+	require.Equal(t, tx_input.ReclaimNone, input.TokenAccounts[0].Action)
+	for _, account := range input.TokenAccounts[1:] {
+		require.Equal(t, tx_input.ReclaimMerge, account.Action)
+	}
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var _ xcclient.SimulationClient = &Client{}
+
+// A balance of an account in the transaction, either lamports or a token amount
+type simulatedBalance struct {
+	// the wallet address, which is the owner for token accounts
+	address  solana.PublicKey
+	contract xc.ContractAddress
+	amount   *big.Int
+}
+
+// Simulate runs the transaction with simulateTransaction, and compares the state of the accounts
+// in the transaction before and after to predict the balance changes.  Signatures are not verified,
+// so unsigned transactions may be simulated.
+func (client *Client) Simulate(ctx context.Context, trans xc.Tx) (*xcclient.TxInfo, error) {
+	solTx, ok := trans.(*tx.Tx)
+	if !ok || solTx.SolTx == nil {
+		return nil, fmt.Errorf("unsupported transaction type %T", trans)
+	}
+	// the signatures must still be present
+	simTx := *solTx.SolTx
+	simTx.Signatures = append([]solana.Signature{}, simTx.Signatures...)
+	for len(simTx.Signatures) < int(simTx.Message.Header.NumRequiredSignatures) {
+		simTx.Signatures = append(simTx.Signatures, solana.Signature{})
+	}
+	// includes the accounts loaded from address lookup tables
+	accounts, err := simTx.Message.GetAllKeys()
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve accounts: %v", err)
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("transaction has no accounts")
+	}
+	feePayer := accounts[0]
+
+	before, err := client.client.GetMultipleAccountsWithOpts(ctx, accounts, &rpc.GetMultipleAccountsOpts{
+		Commitment: rpc.CommitmentConfirmed,
+		Encoding:   solana.EncodingBase64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch accounts: %v", err)
+	}
+	simulated, err := client.client.SimulateTransactionWithOpts(ctx, &simTx, &rpc.SimulateTransactionOpts{
+		Commitment:             rpc.CommitmentConfirmed,
+		ReplaceRecentBlockhash: true,
+		Accounts: &rpc.SimulateTransactionAccountsOpts{
+			Encoding:  solana.EncodingBase64,
+			Addresses: accounts,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not simulate tx: %v", err)
+	}
+	feeResult, err := client.client.GetFeeForMessage(ctx, simTx.Message.ToBase64(), rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch fee: %v", err)
+	}
+	fee := uint64(0)
+	if feeResult.Value != nil {
+		fee = *feeResult.Value
+	}
+
+	chain := client.cfg.Chain
+	var errMsg *string
+	if simulated.Value.Err != nil {
+		errBz, _ := json.Marshal(simulated.Value.Err)
+		msg := string(errBz)
+		errMsg = &msg
+	}
+	info := xcclient.NewSimulatedTxInfo(chain, string(solTx.Hash()), errMsg)
+
+	if errMsg == nil {
+		if len(before.Value) != len(accounts) || len(simulated.Value.Accounts) != len(accounts) {
+			return nil, fmt.Errorf("expected %d accounts in simulation", len(accounts))
+		}
+		balances := newBalanceDeltas()
+		for i, address := range accounts {
+			for _, balance := range simulatedBalances(address, before.Value[i]) {
+				balances.add(balance.contract, balance.address, new(big.Int).Neg(balance.amount))
+			}
+			for _, balance := range simulatedBalances(address, simulated.Value.Accounts[i]) {
+				balances.add(balance.contract, balance.address, balance.amount)
+			}
+		}
+		// the fee is reported separately
+		balances.add("", feePayer, new(big.Int).SetUint64(fee))
+
+		for _, tf := range balances.transfers(chain) {
+			info.AddTransfer(tf)
+		}
+	}
+
+	info.AddFee(xc.Address(feePayer.String()), "", xc.NewBigIntFromUint64(fee), nil)
+	info.Fees = info.CalculateFees()
+	return info, nil
+}
+
+// Lamports of an account, and the token balance credited to the owner if it is a token account
+func simulatedBalances(address solana.PublicKey, account *rpc.Account) []*simulatedBalance {
+	if account == nil {
+		// does not exist
+		return nil
+	}
+	balances := []*simulatedBalance{{
+		address: address,
+		amount:  new(big.Int).SetUint64(account.Lamports),
+	}}
+	if account.Owner.Equals(solana.TokenProgramID) || account.Owner.Equals(solana.Token2022ProgramID) {
+		if tokenAccount, err := solana_types.ParseTokenAccountBalance(account.Data.GetBinary()); err == nil {
+			balances = append(balances, &simulatedBalance{
+				address:  tokenAccount.Owner,
+				contract: xc.ContractAddress(tokenAccount.Mint.String()),
+				amount:   new(big.Int).SetUint64(tokenAccount.Amount),
+			})
+		}
+	}
+	return balances
+}
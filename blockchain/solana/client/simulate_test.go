@@ -0,0 +1,178 @@
+package client_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	solana_sdk "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/stretchr/testify/require"
+)
+
+func mockLamports(lamports uint64, owner solana_sdk.PublicKey, data []byte) string {
+	return strings.Replace(mockAccount(owner, data), `"lamports":1461600`, fmt.Sprintf(`"lamports":%d`, lamports), 1)
+}
+
+func tokenAccountData(mint solana_sdk.PublicKey, owner solana_sdk.PublicKey, amount uint64) []byte {
+	data := make([]byte, 165)
+	copy(data[0:32], mint[:])
+	copy(data[32:64], owner[:])
+	binary.LittleEndian.PutUint64(data[64:72], amount)
+	return data
+}
+
+func TestSimulate(t *testing.T) {
+	from := solana_sdk.MustPublicKeyFromBase58("DBomk9vPzgLWpDBvvQpJUAB1aFz8EHsPq6xEuA1cGMcV")
+	to := solana_sdk.MustPublicKeyFromBase58("8FLngQGnatEDQwNBV27yFxuWDhvQfriaCL56fx84TxoN")
+	mint := solana_sdk.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	fromToken, _, _ := solana_sdk.FindAssociatedTokenAddress(from, mint)
+	toToken, _, _ := solana_sdk.FindAssociatedTokenAddress(to, mint)
+
+	solTx, err := solana_sdk.NewTransaction(
+		[]solana_sdk.Instruction{
+			system.NewTransferInstruction(1000, from, to).Build(),
+			token.NewTransferInstruction(100, fromToken, toToken, from, nil).Build(),
+		},
+		solana_sdk.Hash{},
+		solana_sdk.TransactionPayer(from),
+	)
+	require.NoError(t, err)
+
+	before := map[solana_sdk.PublicKey]string{
+		from:      mockLamports(1_000_000, solana_sdk.SystemProgramID, nil),
+		fromToken: mockLamports(2039280, solana_sdk.TokenProgramID, tokenAccountData(mint, from, 500)),
+		toToken:   mockLamports(2039280, solana_sdk.TokenProgramID, tokenAccountData(mint, to, 0)),
+	}
+	after := map[solana_sdk.PublicKey]string{
+		from:      mockLamports(1_000_000-1000-5000, solana_sdk.SystemProgramID, nil),
+		to:        mockLamports(1000, solana_sdk.SystemProgramID, nil),
+		fromToken: mockLamports(2039280, solana_sdk.TokenProgramID, tokenAccountData(mint, from, 400)),
+		toToken:   mockLamports(2039280, solana_sdk.TokenProgramID, tokenAccountData(mint, to, 100)),
+	}
+	accountsFor := func(states map[solana_sdk.PublicKey]string) string {
+		accounts := []string{}
+		for _, key := range solTx.Message.AccountKeys {
+			if state, ok := states[key]; ok {
+				accounts = append(accounts, state)
+			} else {
+				accounts = append(accounts, "null")
+			}
+		}
+		return "[" + strings.Join(accounts, ",") + "]"
+	}
+
+	vectors := []struct {
+		name      string
+		simulated string
+		err       string
+	}{
+		{"success", fmt.Sprintf(`{"context":{"slot":100},"value":{"err":null,"logs":[],"accounts":%s,"unitsConsumed":1000}}`, accountsFor(after)), ""},
+		{"failure", `{"context":{"slot":100},"value":{"err":{"InstructionError":[1,{"Custom":1}]},"logs":[],"accounts":null,"unitsConsumed":1000}}`, `{"InstructionError":[1,{"Custom":1}]}`},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, []string{
+				fmt.Sprintf(`{"context":{"slot":100},"value":%s}`, accountsFor(before)),
+				v.simulated,
+				`{"context":{"slot":100},"value":5000}`,
+			})
+			defer close()
+			cli, err := client.NewClient(&xc_types.ChainConfig{Chain: xc_types.SOL, Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+
+			// unsigned
+			info, err := cli.Simulate(context.Background(), tx.NewTxFrom(solTx))
+			require.NoError(t, err)
+			require.Len(t, info.Fees, 1)
+			require.Equal(t, "5000", info.Fees[0].Balance.String())
+			if v.err != "" {
+				require.Equal(t, v.err, *info.Error)
+				require.Len(t, info.Transfers, 1)
+				return
+			}
+			require.Nil(t, info.Error)
+
+			// native, token, fee
+			require.Len(t, info.Transfers, 3)
+			native := info.Transfers[0]
+			require.Len(t, native.From, 1)
+			require.Contains(t, string(native.From[0].Address), from.String())
+			require.Equal(t, "1000", native.From[0].Balance.String())
+			require.Len(t, native.To, 1)
+			require.Equal(t, "1000", native.To[0].Balance.String())
+
+			tokens := info.Transfers[1]
+			require.EqualValues(t, mint.String(), tokens.From[0].Contract)
+			require.Contains(t, string(tokens.From[0].Address), from.String())
+			require.Equal(t, "100", tokens.From[0].Balance.String())
+			require.Contains(t, string(tokens.To[0].Address), to.String())
+			require.Equal(t, "100", tokens.To[0].Balance.String())
+		})
+	}
+}
+
+func TestSimulateToken2022Mint(t *testing.T) {
+	from := solana_sdk.MustPublicKeyFromBase58("DBomk9vPzgLWpDBvvQpJUAB1aFz8EHsPq6xEuA1cGMcV")
+	to := solana_sdk.MustPublicKeyFromBase58("8FLngQGnatEDQwNBV27yFxuWDhvQfriaCL56fx84TxoN")
+	mint := solana_sdk.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+
+	solTx, err := solana_sdk.NewTransaction(
+		[]solana_sdk.Instruction{
+			system.NewTransferInstruction(1000, from, to).Build(),
+			solana_sdk.NewInstruction(solana_sdk.Token2022ProgramID, solana_sdk.AccountMetaSlice{solana_sdk.Meta(mint).WRITE()}, []byte{}),
+		},
+		solana_sdk.Hash{},
+		solana_sdk.TransactionPayer(from),
+	)
+	require.NoError(t, err)
+
+	// a mint with extensions is larger than a token account, and its supply is where the amount of a token account would be
+	mintData := func(supply uint64) []byte {
+		data := tokenAccountData(from, to, supply)
+		return append(data, 1, 0, 0, 0)
+	}
+	before := map[solana_sdk.PublicKey]string{
+		from: mockLamports(1_000_000, solana_sdk.SystemProgramID, nil),
+		mint: mockLamports(2039280, solana_sdk.Token2022ProgramID, mintData(500)),
+	}
+	after := map[solana_sdk.PublicKey]string{
+		from: mockLamports(1_000_000-1000-5000, solana_sdk.SystemProgramID, nil),
+		to:   mockLamports(1000, solana_sdk.SystemProgramID, nil),
+		mint: mockLamports(2039280, solana_sdk.Token2022ProgramID, mintData(400)),
+	}
+	accountsFor := func(states map[solana_sdk.PublicKey]string) string {
+		accounts := []string{}
+		for _, key := range solTx.Message.AccountKeys {
+			if state, ok := states[key]; ok {
+				accounts = append(accounts, state)
+			} else {
+				accounts = append(accounts, "null")
+			}
+		}
+		return "[" + strings.Join(accounts, ",") + "]"
+	}
+	server, close := testtypes.MockJSONRPC(t, []string{
+		fmt.Sprintf(`{"context":{"slot":100},"value":%s}`, accountsFor(before)),
+		fmt.Sprintf(`{"context":{"slot":100},"value":{"err":null,"logs":[],"accounts":%s,"unitsConsumed":1000}}`, accountsFor(after)),
+		`{"context":{"slot":100},"value":5000}`,
+	})
+	defer close()
+	cli, err := client.NewClient(&xc_types.ChainConfig{Chain: xc_types.SOL, Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+
+	info, err := cli.Simulate(context.Background(), tx.NewTxFrom(solTx))
+	require.NoError(t, err)
+	// native and fee, the mint is not read as a token account
+	require.Len(t, info.Transfers, 2)
+	for _, tf := range info.Transfers {
+		require.NotEqualValues(t, from.String(), tf.From[0].Contract)
+	}
+}
@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// Stake accounts can only be merged if they have the same authorities and lockup, and are either both inactive,
+// both activating in the same epoch, or both active with the same validator.  Deactivating accounts cannot be
+// merged, nor can accounts that may still be partially active.
+func stakeMergeGroup(stake *ParsedStakeAccount, epoch uint64) (string, bool) {
+	meta := stake.StakeAccount.Parsed.Info.Meta
+	delegation := stake.StakeAccount.Parsed.Info.Stake.Delegation
+	group := fmt.Sprintf("%s/%s/%s/%d/%d", meta.Authorized.Staker, meta.Authorized.Withdrawer,
+		meta.Lockup.Custodian, meta.Lockup.Epoch, meta.Lockup.UnixTimestamp)
+	activationEpoch := xc_types.NewBigIntFromStr(delegation.ActivationEpoch).Uint64()
+	deactivationEpoch := xc_types.NewBigIntFromStr(delegation.DeactivationEpoch).Uint64()
+	state := stake.StakeAccount.GetState(epoch)
+	switch state {
+	case xclient.Inactive:
+		if deactivationEpoch != activationEpoch && deactivationEpoch+1 >= epoch {
+			// may still be cooling down
+			return "", false
+		}
+		return fmt.Sprintf("%s/%s", state, group), true
+	case xclient.Activating:
+		return fmt.Sprintf("%s/%d/%s/%s", state, activationEpoch, delegation.Voter, group), true
+	case xclient.Active:
+		if activationEpoch+1 >= epoch {
+			// may still be warming up
+			return "", false
+		}
+		return fmt.Sprintf("%s/%s/%s", state, delegation.Voter, group), true
+	}
+	return "", false
+}
+
+// Looks up the stake account of the authority that is set in the arguments
+func (client *Client) getStakeAccountForArgs(ctx context.Context, args xcbuilder.StakeArgs) (*ParsedStakeAccount, error) {
+	inputAccount, ok := args.GetStakeAccount()
+	if !ok {
+		return nil, fmt.Errorf("stake account is required")
+	}
+	stakeAccounts, err := client.GetStakeAccounts(ctx, args.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	for _, stake := range stakeAccounts {
+		if stake.Account.Pubkey.String() == inputAccount {
+			return stake, nil
+		}
+	}
+	return nil, fmt.Errorf("stake account not found: %s", inputAccount)
+}
+
+// FetchMergeStakeInput finds the stake accounts delegated to the validator that can be merged.  The
+// destination is the stake account in the arguments if set, otherwise the largest account of the largest
+// compatible group.  At most builder.MaxAccountMerges are merged, so it may need to be repeated.
+func (client *Client) FetchMergeStakeInput(ctx context.Context, args xcbuilder.StakeArgs) (*tx_input.MergeStakeInput, error) {
+	stakeAccounts, err := client.GetStakeAccounts(ctx, args.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	txInput, err := client.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
+	if err != nil {
+		return nil, err
+	}
+	// Set default fee for now
+	txInput.PrioritizationFee = xc_types.NewBigIntFromUint64(100000)
+	epochInfo, err := client.client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, _ := args.GetValidator()
+	inputAccount, hasInputAccount := args.GetStakeAccount()
+	groups := map[string][]*ParsedStakeAccount{}
+	// keep the order of the groups so that the choice of group is deterministic
+	groupOrder := []string{}
+	destinationGroup := ""
+	for _, stake := range stakeAccounts {
+		if stake.StakeAccount.Parsed.Info.Stake.Delegation.Voter != validator {
+			continue
+		}
+		group, ok := stakeMergeGroup(stake, epochInfo.Epoch)
+		if hasInputAccount && stake.Account.Pubkey.String() == inputAccount {
+			if !ok {
+				return nil, fmt.Errorf("stake account %s is deactivating or partially active and cannot be merged", inputAccount)
+			}
+			destinationGroup = group
+		}
+		if !ok {
+			continue
+		}
+		if _, exists := groups[group]; !exists {
+			groupOrder = append(groupOrder, group)
+		}
+		groups[group] = append(groups[group], stake)
+	}
+	if hasInputAccount && destinationGroup == "" {
+		return nil, fmt.Errorf("stake account not found for validator %s: %s", validator, inputAccount)
+	}
+	if destinationGroup == "" {
+		for _, group := range groupOrder {
+			if destinationGroup == "" || len(groups[group]) > len(groups[destinationGroup]) {
+				destinationGroup = group
+			}
+		}
+	}
+	compatible := groups[destinationGroup]
+	if len(compatible) < 2 {
+		return nil, fmt.Errorf("no compatible stake accounts to merge for validator: %s", validator)
+	}
+
+	destination := compatible[0]
+	for _, stake := range compatible {
+		if hasInputAccount {
+			if stake.Account.Pubkey.String() == inputAccount {
+				destination = stake
+			}
+		} else if stake.Account.Account.Lamports > destination.Account.Account.Lamports {
+			destination = stake
+		}
+	}
+	mergeInput := &tx_input.MergeStakeInput{
+		TxInput:     *txInput,
+		Destination: destination.Account.Pubkey,
+		Sources:     []solana.PublicKey{},
+	}
+	for _, stake := range compatible {
+		if stake == destination {
+			continue
+		}
+		if len(mergeInput.Sources) == builder.MaxAccountMerges {
+			break
+		}
+		mergeInput.Sources = append(mergeInput.Sources, stake.Account.Pubkey)
+	}
+
+	client.SetComputeUnitLimit(ctx, &mergeInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(client.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.MergeStake(args, mergeInput)
+	})
+	return mergeInput, nil
+}
+
+// FetchSplitStakeInput returns the input to split the amount of the stake account in the arguments into a new account
+func (client *Client) FetchSplitStakeInput(ctx context.Context, args xcbuilder.StakeArgs) (*tx_input.SplitStakeInput, error) {
+	stake, err := client.getStakeAccountForArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	txInput, err := client.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
+	if err != nil {
+		return nil, err
+	}
+	// Set default fee for now
+	txInput.PrioritizationFee = xc_types.NewBigIntFromUint64(100000)
+	privKey, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	splitInput := &tx_input.SplitStakeInput{
+		TxInput:           *txInput,
+		StakingKey:        privKey,
+		StakeAccount:      stake.Account.Pubkey,
+		Lamports:          stake.Account.Account.Lamports,
+		RentExemptReserve: xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Meta.RentExemptReserve).Uint64(),
+	}
+	client.SetComputeUnitLimit(ctx, &splitInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(client.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.SplitStake(args, splitInput)
+	})
+	return splitInput, nil
+}
+
+// FetchRedelegateStakeInput returns the input to move the stake account in the arguments to the validator in the
+// arguments.  Inactive stake is delegated directly.  Active stake is redelegated if the cluster supports it, and
+// otherwise deactivated, so it must be redelegated again once it is inactive.
+func (client *Client) FetchRedelegateStakeInput(ctx context.Context, args xcbuilder.StakeArgs) (*tx_input.RedelegateStakeInput, error) {
+	stake, err := client.getStakeAccountForArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	validatorAddress, ok := args.GetValidator()
+	if !ok {
+		return nil, fmt.Errorf("validator to be delegated to is required")
+	}
+	txInput, err := client.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
+	if err != nil {
+		return nil, err
+	}
+	// Set default fee for now
+	txInput.PrioritizationFee = xc_types.NewBigIntFromUint64(100000)
+	epochInfo, err := client.client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+	voteAccount, err := client.getValidatorVoteAccount(ctx, validatorAddress)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	redelegateInput := &tx_input.RedelegateStakeInput{
+		TxInput:              *txInput,
+		StakingKey:           privKey,
+		StakeAccount:         stake.Account.Pubkey,
+		ValidatorVoteAccount: voteAccount,
+	}
+	build := func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(client.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.RedelegateStake(args, redelegateInput)
+	}
+
+	currentVoter := stake.StakeAccount.Parsed.Info.Stake.Delegation.Voter
+	switch state := stake.StakeAccount.GetState(epochInfo.Epoch); state {
+	case xclient.Inactive:
+		redelegateInput.Action = tx_input.RedelegateDelegate
+	case xclient.Deactivating:
+		// the deactivation can only be cancelled in the epoch that it was requested
+		deactivationEpoch := xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Stake.Delegation.DeactivationEpoch).Uint64()
+		if currentVoter != voteAccount.String() || deactivationEpoch != epochInfo.Epoch {
+			return nil, fmt.Errorf("stake account is deactivating, and can be redelegated once inactive")
+		}
+		// delegating to the same validator reactivates the stake
+		redelegateInput.Action = tx_input.RedelegateDelegate
+	default:
+		if currentVoter == voteAccount.String() {
+			return nil, fmt.Errorf("stake account is already delegated to validator: %s", validatorAddress)
+		}
+		redelegateInput.Action = tx_input.RedelegateMove
+		built, err := build()
+		if err == nil {
+			_, err = client.SimulateComputeUnits(ctx, built)
+		}
+		if err != nil {
+			logrus.WithError(err).Warn("could not redelegate, deactivating the stake to delegate once inactive")
+			redelegateInput.Action = tx_input.RedelegateDeactivate
+		}
+	}
+
+	client.SetComputeUnitLimit(ctx, &redelegateInput.TxInput, build)
+	return redelegateInput, nil
+}
@@ -0,0 +1,273 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	"github.com/CustodyOne/chainkit/builder"
+	xclient "github.com/CustodyOne/chainkit/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stakeAuthority    = "83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH"
+	stakeValidator    = "J2nUHEAgZFRyuJbFjdqPrAa9gyWDuc7hErtDQHPhsYRp"
+	stakeValidator2   = "CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu"
+	stakeBlockhash    = `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+	stakeEpochInfo    = `{"absoluteSlot":166598,"blockHeight":166500,"epoch":652,"slotIndex":2790,"slotsInEpoch":8192,"transactionCount":22661093}`
+	stakeSimulated    = `{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`
+	stakeVoteAccounts = `{"current":[{"activatedStake":1,"commission":0,"epochCredits":[],"epochVoteAccount":true,"lastVote":1,"nodePubkey":"9iYWs8cCVpnQ3Vd2RSLzAVM8GtNCdtqSeHtazPy37YhE","rootSlot":1,"votePubkey":"CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu"}],"delinquent":[]}`
+)
+
+func stakeAccountJson(pubkey string, voter string, activationEpoch string, deactivationEpoch string, stake uint64, staker string) string {
+	return fmt.Sprintf(
+		`{"account":{"data":{"parsed":{"info":{"meta":{"authorized":{"staker":"%s","withdrawer":"%s"},"lockup":{"custodian":"11111111111111111111111111111111","epoch":0,"unixTimestamp":0},"rentExemptReserve":"2282880"},"stake":{"creditsObserved":101316504,"delegation":{"activationEpoch":"%s","deactivationEpoch":"%s","stake":"%d","voter":"%s","warmupCooldownRate":0.25}}},"type":"delegated"},"program":"stake","space":200},"executable":false,"lamports":%d,"owner":"Stake11111111111111111111111111111111111111","rentEpoch":18446744073709552000,"space":200},"pubkey":"%s"}`,
+		staker, staker, activationEpoch, deactivationEpoch, stake, voter, stake+2282880, pubkey,
+	)
+}
+
+func stakeAccountsJson(accounts ...string) string {
+	return "[" + strings.Join(accounts, ",") + "]"
+}
+
+const notDeactivated = "18446744073709551615"
+
+func newStakeClient(t *testing.T, resp []string) (*client.Client, func()) {
+	server, close := testtypes.MockJSONRPC(t, resp)
+	cli, err := client.NewClient(&xc_types.ChainConfig{
+		Client:   &xc_types.ClientConfig{URL: server.URL},
+		Chain:    "SOL",
+		Decimals: 9,
+	})
+	require.NoError(t, err)
+	return cli, close
+}
+
+func TestFetchStakeBalanceGroupedByValidator(t *testing.T) {
+	cli, close := newStakeClient(t, []string{
+		stakeAccountsJson(
+			stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator, "650", notDeactivated, 10_000_000, stakeAuthority),
+			stakeAccountJson("CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC", stakeValidator2, "650", notDeactivated, 5_000_000, stakeAuthority),
+			stakeAccountJson("BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C", stakeValidator, "650", "652", 20_000_000, stakeAuthority),
+		),
+		stakeEpochInfo,
+	})
+	defer close()
+
+	args, err := xclient.NewStakeBalanceArgs(xc_types.Address(stakeAuthority))
+	require.NoError(t, err)
+	balances, err := cli.FetchStakeBalance(context.Background(), args)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+
+	require.Equal(t, stakeValidator, balances[0].Validator)
+	require.Equal(t, "10000000", balances[0].Balance.Active.String())
+	require.Equal(t, "20000000", balances[0].Balance.Deactivating.String())
+	// the rent of both accounts
+	require.Equal(t, "4565760", balances[0].Balance.Inactive.String())
+	require.Len(t, balances[0].Accounts, 2)
+	require.Equal(t, "BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C", balances[0].Accounts[1].Account)
+	require.Equal(t, "20000000", balances[0].Accounts[1].Balance.Deactivating.String())
+
+	require.Equal(t, stakeValidator2, balances[1].Validator)
+	require.Equal(t, "5000000", balances[1].Balance.Active.String())
+	require.Len(t, balances[1].Accounts, 1)
+}
+
+func TestFetchMergeStakeInput(t *testing.T) {
+	stakeAccounts := stakeAccountsJson(
+		stakeAccountJson("CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC", stakeValidator, "650", notDeactivated, 5_000_000, stakeAuthority),
+		stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator, "650", notDeactivated, 10_000_000, stakeAuthority),
+		stakeAccountJson("GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE", stakeValidator, "640", notDeactivated, 3_000_000, stakeAuthority),
+		// activated in the last epoch, so may still be warming up
+		stakeAccountJson("9NmqDDZa7mH1DBM4zeq9cm7VcRn2un1i2TwuMvjBoVhU", stakeValidator, "651", notDeactivated, 3_000_000, stakeAuthority),
+		// activating, which cannot merge with active stake
+		stakeAccountJson("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H", stakeValidator, "652", notDeactivated, 2_000_000, stakeAuthority),
+		stakeAccountJson("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11", stakeValidator, "652", notDeactivated, 1_000_000, stakeAuthority),
+		// different staker
+		stakeAccountJson("FXw5CT4CeyZoBd5Nzqad2CoPUxSwJhx23dDkhxq4sDHs", stakeValidator, "650", notDeactivated, 3_000_000, "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"),
+		// deactivating
+		stakeAccountJson("BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C", stakeValidator, "650", "652", 20_000_000, stakeAuthority),
+		// inactive, which cannot merge with active stake
+		stakeAccountJson("8zrSGLMdE6dK57Q7a8N8TDohmyft1MrsLYdRqhDvCerc", stakeValidator, "649", "650", 1_000_000, stakeAuthority),
+		// other validator
+		stakeAccountJson("GSQJ1PmGtY11efVjmEuUyim4PqXKsB7tnPp1jvpoFeRz", stakeValidator2, "650", notDeactivated, 3_000_000, stakeAuthority),
+	)
+
+	vectors := []struct {
+		name         string
+		stakeAccount string
+		destination  string
+		sources      []string
+		err          string
+	}{
+		{
+			name:        "largest account of the largest group",
+			destination: "6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh",
+			sources:     []string{"CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC", "GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE"},
+		},
+		{
+			name:         "destination from the arguments",
+			stakeAccount: "CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC",
+			destination:  "CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC",
+			sources:      []string{"6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", "GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE"},
+		},
+		{
+			name:         "no compatible accounts",
+			stakeAccount: "8zrSGLMdE6dK57Q7a8N8TDohmyft1MrsLYdRqhDvCerc",
+			err:          "no compatible stake accounts to merge",
+		},
+		{
+			name:         "activating",
+			stakeAccount: "BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11",
+			destination:  "BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11",
+			sources:      []string{"3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H"},
+		},
+		{
+			name:         "deactivating",
+			stakeAccount: "BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C",
+			err:          "deactivating or partially active and cannot be merged",
+		},
+		{
+			name:         "partially active",
+			stakeAccount: "9NmqDDZa7mH1DBM4zeq9cm7VcRn2un1i2TwuMvjBoVhU",
+			err:          "deactivating or partially active and cannot be merged",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			cli, close := newStakeClient(t, []string{stakeAccounts, stakeBlockhash, stakeEpochInfo, stakeSimulated})
+			defer close()
+
+			options := []builder.BuilderOption{builder.WithValidator(stakeValidator)}
+			if v.stakeAccount != "" {
+				options = append(options, builder.WithStakeAccount(v.stakeAccount))
+			}
+			args, err := builder.NewStakeArgs(xc_types.SOL, xc_types.Address(stakeAuthority), xc_types.NewBigIntFromUint64(0), options...)
+			require.NoError(t, err)
+
+			input, err := cli.FetchMergeStakeInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v.destination, input.Destination.String())
+			sources := []string{}
+			for _, source := range input.Sources {
+				sources = append(sources, source.String())
+			}
+			require.Equal(t, v.sources, sources)
+			require.EqualValues(t, 1200, input.ComputeUnitLimit)
+		})
+	}
+}
+
+func TestFetchSplitStakeInput(t *testing.T) {
+	cli, close := newStakeClient(t, []string{
+		stakeAccountsJson(
+			stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator, "650", notDeactivated, 10_000_000, stakeAuthority),
+		),
+		stakeBlockhash,
+		stakeSimulated,
+	})
+	defer close()
+
+	args, err := builder.NewStakeArgs(xc_types.SOL, xc_types.Address(stakeAuthority), xc_types.NewBigIntFromUint64(4_000_000),
+		builder.WithValidator(stakeValidator),
+		builder.WithStakeAccount("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh"),
+	)
+	require.NoError(t, err)
+	input, err := cli.FetchSplitStakeInput(context.Background(), args)
+	require.NoError(t, err)
+	require.Len(t, input.StakingKey, 64)
+	require.Equal(t, "6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", input.StakeAccount.String())
+	require.EqualValues(t, 12282880, input.Lamports)
+	require.EqualValues(t, 2282880, input.RentExemptReserve)
+	require.EqualValues(t, 1200, input.ComputeUnitLimit)
+
+	// the stake account is required
+	args, err = builder.NewStakeArgs(xc_types.SOL, xc_types.Address(stakeAuthority), xc_types.NewBigIntFromUint64(4_000_000),
+		builder.WithValidator(stakeValidator),
+	)
+	require.NoError(t, err)
+	_, err = cli.FetchSplitStakeInput(context.Background(), args)
+	require.ErrorContains(t, err, "stake account is required")
+}
+
+func TestFetchRedelegateStakeInput(t *testing.T) {
+	simulateFailed := `{"context":{"slot":1},"value":{"err":{"InstructionError":[2,"InvalidInstructionData"]},"logs":[],"unitsConsumed":0}}`
+
+	vectors := []struct {
+		name      string
+		stake     string
+		validator string
+		resp      []string
+		expected  tx_input.RedelegateAction
+		err       string
+	}{
+		{
+			name:      "inactive stake is delegated",
+			stake:     stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator, "649", "650", 10_000_000, stakeAuthority),
+			validator: stakeValidator2,
+			resp:      []string{stakeSimulated},
+			expected:  tx_input.RedelegateDelegate,
+		},
+		{
+			name:      "active stake is redelegated",
+			stake:     stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator, "650", notDeactivated, 10_000_000, stakeAuthority),
+			validator: stakeValidator2,
+			resp:      []string{stakeSimulated, stakeSimulated},
+			expected:  tx_input.RedelegateMove,
+		},
+		{
+			name:  "active stake is deactivated when redelegate is not available",
+			stake: stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator, "650", notDeactivated, 10_000_000, stakeAuthority),
+			// the identity of the validator is looked up
+			validator: "9iYWs8cCVpnQ3Vd2RSLzAVM8GtNCdtqSeHtazPy37YhE",
+			resp:      []string{simulateFailed, stakeSimulated},
+			expected:  tx_input.RedelegateDeactivate,
+		},
+		{
+			name:      "already delegated",
+			stake:     stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator2, "650", notDeactivated, 10_000_000, stakeAuthority),
+			validator: stakeValidator2,
+			err:       "already delegated",
+		},
+		{
+			name:      "deactivating",
+			stake:     stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", stakeValidator, "650", "652", 10_000_000, stakeAuthority),
+			validator: stakeValidator2,
+			err:       "can be redelegated once inactive",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			resp := append([]string{stakeAccountsJson(v.stake), stakeBlockhash, stakeEpochInfo, stakeVoteAccounts}, v.resp...)
+			cli, close := newStakeClient(t, resp)
+			defer close()
+
+			args, err := builder.NewStakeArgs(xc_types.SOL, xc_types.Address(stakeAuthority), xc_types.NewBigIntFromUint64(0),
+				builder.WithValidator(v.validator),
+				builder.WithStakeAccount("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh"),
+			)
+			require.NoError(t, err)
+			input, err := cli.FetchRedelegateStakeInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v.expected, input.Action)
+			require.Equal(t, solana.MustPublicKeyFromBase58(stakeValidator2), input.ValidatorVoteAccount)
+			require.EqualValues(t, 1200, input.ComputeUnitLimit)
+		})
+	}
+}
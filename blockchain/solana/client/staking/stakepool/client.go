@@ -0,0 +1,406 @@
+package stakepool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/builder"
+	solclient "github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Client for liquid staking with an SPL stake pool.  Deposited SOL is held as pool tokens, which are
+// redeemed instantly from the reserve of the pool when it has enough SOL, or otherwise as a stake account
+// split from one of the validators of the pool, which is deactivated and withdrawn once inactive.
+//
+// The pool is the validator of the staking arguments, or the stake contract of the chain if not set.
+type Client struct {
+	rpcClient *solclient.Client
+	chain     *xc_types.ChainConfig
+}
+
+var _ xcclient.StakingClient = &Client{}
+
+func NewClient(rpcClient *solclient.Client, chain *xc_types.ChainConfig) (xcclient.StakingClient, error) {
+	return &Client{rpcClient, chain}, nil
+}
+
+// Returns the address of the stake pool to use
+func (cli *Client) stakePoolAddress(validator string, ok bool) (solana.PublicKey, error) {
+	if !ok || validator == "" {
+		validator = cli.chain.Staking.StakeContract
+	}
+	if validator == "" {
+		return solana.PublicKey{}, fmt.Errorf("stake pool is required, either as the validator or as the stake contract of %s", cli.chain.Chain)
+	}
+	pool, err := solana.PublicKeyFromBase58(validator)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("invalid base58 for stake pool address: %v", err)
+	}
+	return pool, nil
+}
+
+// Fetches and parses the stake pool, which is owned by the program that it is deployed under
+func (cli *Client) fetchStakePool(ctx context.Context, address solana.PublicKey) (*solana_types.StakePoolAccounts, *solana_types.StakePool, error) {
+	info, err := cli.rpcClient.RpcClient().GetAccountInfoWithOpts(ctx, address, &rpc.GetAccountInfoOpts{
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get stake pool %s: %v", address, err)
+	}
+	pool, err := solana_types.ParseStakePool(info.Value.Data.GetBinary())
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse stake pool %s: %v", address, err)
+	}
+	program := info.Value.Owner
+	withdrawAuthority, err := solana_types.FindStakePoolWithdrawAuthority(program, address)
+	if err != nil {
+		return nil, nil, err
+	}
+	accounts := &solana_types.StakePoolAccounts{
+		Program:           program,
+		StakePool:         address,
+		WithdrawAuthority: withdrawAuthority,
+		ValidatorList:     pool.ValidatorList,
+		ReserveStake:      pool.ReserveStake,
+		PoolMint:          pool.PoolMint,
+		ManagerFeeAccount: pool.ManagerFeeAccount,
+		TokenProgram:      pool.TokenProgram,
+	}
+	return accounts, pool, nil
+}
+
+// The pool rejects deposits and withdrawals until it has been updated for the current epoch
+func (cli *Client) checkUpdated(ctx context.Context, address solana.PublicKey, pool *solana_types.StakePool) error {
+	epochInfo, err := cli.rpcClient.RpcClient().GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return err
+	}
+	if pool.LastUpdateEpoch < epochInfo.Epoch {
+		return fmt.Errorf("stake pool %s has not been updated for epoch %d", address, epochInfo.Epoch)
+	}
+	return nil
+}
+
+func (cli *Client) fetchValidators(ctx context.Context, pool *solana_types.StakePool) ([]*solana_types.ValidatorStakeInfo, error) {
+	info, err := cli.rpcClient.RpcClient().GetAccountInfoWithOpts(ctx, pool.ValidatorList, &rpc.GetAccountInfoOpts{
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get validator list %s: %v", pool.ValidatorList, err)
+	}
+	return solana_types.ParseValidatorList(info.Value.Data.GetBinary())
+}
+
+// Returns the associated token account of the owner for the pool tokens, and its balance
+func (cli *Client) fetchPoolTokens(ctx context.Context, owner xc_types.Address, pool *solana_types.StakePool) (string, uint64, error) {
+	tokenAccount, err := solana_types.FindAssociatedTokenAddress(string(owner), pool.PoolMint.String(), pool.TokenProgram)
+	if err != nil {
+		return "", 0, err
+	}
+	tokenAccounts, err := cli.rpcClient.GetTokenAccountsByOwner(ctx, string(owner), pool.PoolMint.String())
+	if err != nil {
+		return "", 0, err
+	}
+	for _, account := range tokenAccounts {
+		if account.Account.Pubkey.String() == tokenAccount {
+			amount := xc_types.NewBigIntFromStr(account.Info.Parsed.Info.TokenAmount.Amount)
+			return tokenAccount, amount.Uint64(), nil
+		}
+	}
+	return tokenAccount, 0, nil
+}
+
+// FetchStakeBalance reports the pool tokens (in SOL) as active, with the pool as the validator and the token
+// account as the account.  If the stake account of a delayed withdrawal from the pool is set in the arguments,
+// it is reported as deactivating or inactive until it is withdrawn.
+func (cli *Client) FetchStakeBalance(ctx context.Context, args xcclient.StakedBalanceArgs) ([]*xcclient.StakedBalance, error) {
+	address, err := cli.stakePoolAddress(args.GetValidator())
+	if err != nil {
+		return nil, err
+	}
+	_, pool, err := cli.fetchStakePool(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	balances := []*xcclient.StakedBalance{}
+	tokenAccount, poolTokens, err := cli.fetchPoolTokens(ctx, args.GetFrom(), pool)
+	if err != nil {
+		return nil, err
+	}
+	if poolTokens > 0 {
+		lamports := xc_types.NewBigIntFromUint64(pool.LamportsForPoolTokens(poolTokens))
+		balances = append(balances, xcclient.NewStakedBalance(lamports, xcclient.Active, address.String(), tokenAccount))
+	}
+
+	account, ok := args.GetAccount()
+	if !ok || account == tokenAccount {
+		return balances, nil
+	}
+	validators, err := cli.fetchValidators(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	stake, err := cli.fetchWithdrawnStakeAccount(ctx, args.GetFrom(), account, validators)
+	if err != nil {
+		return nil, err
+	}
+	epochInfo, err := cli.rpcClient.RpcClient().GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+	state := stake.StakeAccount.GetState(epochInfo.Epoch)
+	if state == xcclient.Deactivating || state == xcclient.Inactive {
+		balance := xcclient.NewStakedBalance(
+			xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Stake.Delegation.Stake),
+			state,
+			address.String(),
+			stake.Account.Pubkey.String(),
+		)
+		rentReserve := xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Meta.RentExemptReserve)
+		balance.Balance.Inactive = balance.Balance.Inactive.Add(&rentReserve)
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// Returns the stake account of the owner that a delayed withdrawal split from the pool, which must be delegated
+// to a validator of the pool.  Other stake accounts delegated to the same validators cannot be told apart, so
+// only the account that the withdrawal created is looked up.
+func (cli *Client) fetchWithdrawnStakeAccount(ctx context.Context, owner xc_types.Address, account string, validators []*solana_types.ValidatorStakeInfo) (*solclient.ParsedStakeAccount, error) {
+	stakeAccounts, err := cli.rpcClient.GetStakeAccounts(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, stake := range stakeAccounts {
+		if stake.Account.Pubkey.String() != account {
+			continue
+		}
+		for _, validator := range validators {
+			if validator.VoteAccount.String() == stake.StakeAccount.Parsed.Info.Stake.Delegation.Voter {
+				return stake, nil
+			}
+		}
+		return nil, fmt.Errorf("stake account %s is not delegated to a validator of the stake pool", account)
+	}
+	return nil, fmt.Errorf("stake account not found: %s", account)
+}
+
+// FetchStakingInput deposits SOL into the reserve of the pool
+func (cli *Client) FetchStakingInput(ctx context.Context, args xcbuilder.StakeArgs) (xc_types.StakeTxInput, error) {
+	address, err := cli.stakePoolAddress(args.GetValidator())
+	if err != nil {
+		return nil, err
+	}
+	accounts, pool, err := cli.fetchStakePool(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if pool.SolDepositAuthority != nil {
+		return nil, fmt.Errorf("stake pool %s only accepts deposits from %s", address, pool.SolDepositAuthority)
+	}
+	err = cli.checkUpdated(ctx, address, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	txInput, err := cli.rpcClient.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
+	if err != nil {
+		return nil, err
+	}
+	// Set default fee for now
+	txInput.PrioritizationFee = xc_types.NewBigIntFromUint64(100000)
+	stakeInput := tx_input.StakePoolStakeInput{
+		TxInput:   *txInput,
+		StakePool: *accounts,
+	}
+	cli.rpcClient.SetComputeUnitLimit(ctx, &stakeInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(cli.chain)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.Stake(args, &stakeInput)
+	})
+	return &stakeInput, nil
+}
+
+// FetchUnstakingInput burns the pool tokens worth the amount of SOL.  The SOL is withdrawn from the reserve
+// when it can cover it, and otherwise from the preferred validator of the pool if it has enough stake, or the
+// validator with the most stake.  The new stake account is withdrawn once it is inactive.
+func (cli *Client) FetchUnstakingInput(ctx context.Context, args xcbuilder.StakeArgs) (xc_types.UnstakeTxInput, error) {
+	lamports := args.GetAmount().Uint64()
+	if lamports == 0 {
+		return nil, errors.New("amount to unstake is required")
+	}
+	address, err := cli.stakePoolAddress(args.GetValidator())
+	if err != nil {
+		return nil, err
+	}
+	accounts, pool, err := cli.fetchStakePool(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	err = cli.checkUpdated(ctx, address, pool)
+	if err != nil {
+		return nil, err
+	}
+	_, balance, err := cli.fetchPoolTokens(ctx, args.GetFrom(), pool)
+	if err != nil {
+		return nil, err
+	}
+
+	poolAccounts, err := cli.rpcClient.RpcClient().GetMultipleAccountsWithOpts(ctx, []solana.PublicKey{pool.ValidatorList, pool.ReserveStake}, &rpc.GetMultipleAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(poolAccounts.Value) != 2 || poolAccounts.Value[0] == nil || poolAccounts.Value[1] == nil {
+		return nil, fmt.Errorf("validator list or reserve of stake pool %s not found", address)
+	}
+	validators, err := solana_types.ParseValidatorList(poolAccounts.Value[0].Data.GetBinary())
+	if err != nil {
+		return nil, err
+	}
+	reserveLamports := poolAccounts.Value[1].Lamports
+	rentExemptReserve, err := cli.rpcClient.RpcClient().GetMinimumBalanceForRentExemption(ctx, builder.StakeAccountSize, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+
+	unstakeInput := tx_input.StakePoolUnstakeInput{
+		StakePool: *accounts,
+	}
+	if pool.SolWithdrawAuthority == nil && reserveLamports >= lamports+rentExemptReserve {
+		unstakeInput.PoolTokens, err = pool.PoolTokensForWithdrawal(lamports, pool.SolWithdrawalFee)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		required := lamports + rentExemptReserve + solana_types.StakePoolMinimumActiveStake
+		validator := withdrawValidator(pool, validators, required)
+		if validator == nil || validator.ActiveStakeLamports < required {
+			return nil, fmt.Errorf("stake pool %s does not have enough SOL in its reserve or a validator to unstake %s", address, args.GetAmount().String())
+		}
+		unstakeInput.ValidatorStake, err = solana_types.FindValidatorStakeAccount(accounts.Program, address, validator)
+		if err != nil {
+			return nil, err
+		}
+		unstakeInput.StakingKey, err = solana.NewRandomPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		unstakeInput.RentExemptReserve = rentExemptReserve
+		unstakeInput.PoolTokens, err = pool.PoolTokensForWithdrawal(lamports, pool.StakeWithdrawalFee)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if unstakeInput.PoolTokens > balance {
+		return nil, fmt.Errorf("insufficient pool tokens to unstake, %d are required but the balance is %d", unstakeInput.PoolTokens, balance)
+	}
+
+	txInput, err := cli.rpcClient.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
+	if err != nil {
+		return nil, err
+	}
+	// Set default fee for now
+	txInput.PrioritizationFee = xc_types.NewBigIntFromUint64(100000)
+	unstakeInput.TxInput = *txInput
+	cli.rpcClient.SetComputeUnitLimit(ctx, &unstakeInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(cli.chain)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.Unstake(args, &unstakeInput)
+	})
+	return &unstakeInput, nil
+}
+
+// Returns the preferred withdraw validator of the pool if set and it has enough stake, or the active validator
+// with the most stake
+func withdrawValidator(pool *solana_types.StakePool, validators []*solana_types.ValidatorStakeInfo, lamports uint64) *solana_types.ValidatorStakeInfo {
+	var largest *solana_types.ValidatorStakeInfo
+	for _, validator := range validators {
+		if pool.PreferredWithdrawValidator != nil && validator.VoteAccount.Equals(*pool.PreferredWithdrawValidator) &&
+			validator.ActiveStakeLamports >= lamports {
+			return validator
+		}
+		if validator.Status != 0 {
+			continue
+		}
+		if largest == nil || validator.ActiveStakeLamports > largest.ActiveStakeLamports {
+			largest = validator
+		}
+	}
+	return largest
+}
+
+// FetchWithdrawInput withdraws the stake account in the arguments, that a delayed withdrawal split from the pool,
+// once it is inactive.  This is an ordinary stake account, so it is withdrawn the same as natively staked SOL.
+func (cli *Client) FetchWithdrawInput(ctx context.Context, args xcbuilder.StakeArgs) (xc_types.WithdrawTxInput, error) {
+	inputAccount, ok := args.GetStakeAccount()
+	if !ok {
+		return nil, errors.New("the stake account withdrawn from the stake pool is required")
+	}
+	address, err := cli.stakePoolAddress(args.GetValidator())
+	if err != nil {
+		return nil, err
+	}
+	_, pool, err := cli.fetchStakePool(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	validators, err := cli.fetchValidators(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	stake, err := cli.fetchWithdrawnStakeAccount(ctx, args.GetFrom(), inputAccount, validators)
+	if err != nil {
+		return nil, err
+	}
+	txInput, err := cli.rpcClient.FetchBaseInputForArgs(ctx, args.GetFrom(), &args)
+	if err != nil {
+		return nil, err
+	}
+	// Set default fee for now
+	txInput.PrioritizationFee = xc_types.NewBigIntFromUint64(100000)
+	epochInfo, err := cli.rpcClient.RpcClient().GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+	if stake.StakeAccount.GetState(epochInfo.Epoch) != xcclient.Inactive {
+		return nil, fmt.Errorf("stake account %s withdrawn from stake pool %s is not inactive yet", inputAccount, address)
+	}
+
+	amountStake := xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Stake.Delegation.Stake)
+	amountRentReserve := xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Meta.RentExemptReserve)
+	eligibleStakes := []*tx_input.ExistingStake{
+		{
+			ActivationEpoch:   xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Stake.Delegation.ActivationEpoch),
+			DeactivationEpoch: xc_types.NewBigIntFromStr(stake.StakeAccount.Parsed.Info.Stake.Delegation.DeactivationEpoch),
+			AmountActive:      xc_types.NewBigIntFromUint64(0),
+			AmountInactive:    amountStake.Add(&amountRentReserve),
+			StakeAccount:      stake.Account.Pubkey,
+		},
+	}
+	withdrawInput := tx_input.WithdrawInput{
+		TxInput:        *txInput,
+		EligibleStakes: eligibleStakes,
+	}
+	cli.rpcClient.SetComputeUnitLimit(ctx, &withdrawInput.TxInput, func() (xc_types.Tx, error) {
+		txBuilder, err := builder.NewTxBuilder(cli.chain)
+		if err != nil {
+			return nil, err
+		}
+		return txBuilder.Withdraw(args, &withdrawInput)
+	})
+	return &withdrawInput, nil
+}
@@ -0,0 +1,471 @@
+package stakepool_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	solclient "github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/client/staking/stakepool"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	owner          = "83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH"
+	poolAddress    = "stk9ApL5HeVAwPLr3TLhDXdZS8ptVu7zp6ov8HFDuMi"
+	poolMint       = "bSo13r4TkiE4KumL71LsHTPpL2euBYLFx6h9HP3piy1"
+	validatorList  = "3R3nGZpQs2aZo5FDQvd2MUQ6R7KhAPainds6uT6uE2mn"
+	reserveStake   = "BgKUXdS29YcHCFrPm5M8oLHiTzZaMDjsebggjoaQ6KFL"
+	managerFee     = "feeeFLLsam6xZJFc6UQFrHqkvVt4jfmVvi2BRLkUZ4i"
+	poolValidator  = "J2nUHEAgZFRyuJbFjdqPrAa9gyWDuc7hErtDQHPhsYRp"
+	poolValidator2 = "CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu"
+	otherValidator = "9iYWs8cCVpnQ3Vd2RSLzAVM8GtNCdtqSeHtazPy37YhE"
+
+	blockhash      = `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+	epochInfo      = `{"absoluteSlot":166598,"blockHeight":166500,"epoch":652,"slotIndex":2790,"slotsInEpoch":8192,"transactionCount":22661093}`
+	simulated      = `{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`
+	rent           = `2282880`
+	notDeactivated = "18446744073709551615"
+)
+
+// 1.1 SOL per pool token, with a 0.1% withdrawal fee
+type testPool struct {
+	lastUpdateEpoch            uint64
+	solDepositAuthority        *solana.PublicKey
+	preferredWithdrawValidator *solana.PublicKey
+}
+
+func (pool testPool) encode() []byte {
+	pubkey := func(data []byte, address string) []byte {
+		return append(data, solana.MustPublicKeyFromBase58(address).Bytes()...)
+	}
+	u64 := func(data []byte, value uint64) []byte {
+		return binary.LittleEndian.AppendUint64(data, value)
+	}
+	fee := func(data []byte, denominator, numerator uint64) []byte {
+		return u64(u64(data, denominator), numerator)
+	}
+	data := []byte{1}
+	// manager, staker and stake deposit authority
+	data = pubkey(data, owner)
+	data = pubkey(data, owner)
+	data = pubkey(data, owner)
+	data = append(data, 255)
+	data = pubkey(data, validatorList)
+	data = pubkey(data, reserveStake)
+	data = pubkey(data, poolMint)
+	data = pubkey(data, managerFee)
+	data = append(data, solana.TokenProgramID.Bytes()...)
+	data = u64(data, 1_100_000_000_000)
+	data = u64(data, 1_000_000_000_000)
+	data = u64(data, pool.lastUpdateEpoch)
+	data = append(data, make([]byte, 48)...)
+	// epoch fee, and a future epoch fee
+	data = fee(data, 100, 5)
+	data = fee(append(data, 2), 100, 4)
+	// no preferred deposit validator
+	data = append(data, 0)
+	if pool.preferredWithdrawValidator != nil {
+		data = append(append(data, 1), pool.preferredWithdrawValidator.Bytes()...)
+	} else {
+		data = append(data, 0)
+	}
+	// stake deposit and withdrawal fees
+	data = fee(data, 0, 0)
+	data = fee(data, 1000, 1)
+	data = append(data, 0, 0)
+	if pool.solDepositAuthority != nil {
+		data = append(append(data, 1), pool.solDepositAuthority.Bytes()...)
+	} else {
+		data = append(data, 0)
+	}
+	data = fee(data, 0, 0)
+	data = append(data, 0, 0)
+	data = fee(data, 1000, 1)
+	// the later fields are not read
+	return append(data, make([]byte, 33)...)
+}
+
+func accountJson(data []byte, lamports uint64, accountOwner string) string {
+	return fmt.Sprintf(
+		`{"data":["%s","base64"],"executable":false,"lamports":%d,"owner":"%s","rentEpoch":0,"space":%d}`,
+		base64.StdEncoding.EncodeToString(data), lamports, accountOwner, len(data),
+	)
+}
+
+func poolJson(pool testPool) string {
+	return fmt.Sprintf(`{"context":{"slot":1},"value":%s}`, accountJson(pool.encode(), 1_000_000, solana_types.StakePoolProgramID.String()))
+}
+
+type testValidator struct {
+	vote        string
+	activeStake uint64
+	status      uint8
+}
+
+func validatorListData(validators ...testValidator) []byte {
+	data := []byte{2}
+	data = binary.LittleEndian.AppendUint32(data, 100)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(validators)))
+	for _, validator := range validators {
+		data = binary.LittleEndian.AppendUint64(data, validator.activeStake)
+		data = binary.LittleEndian.AppendUint64(data, 0)
+		data = append(data, make([]byte, 20)...)
+		data = binary.LittleEndian.AppendUint32(data, 0)
+		data = append(data, validator.status)
+		data = append(data, solana.MustPublicKeyFromBase58(validator.vote).Bytes()...)
+	}
+	return data
+}
+
+func validatorListJson(validators ...testValidator) string {
+	return fmt.Sprintf(`{"context":{"slot":1},"value":%s}`, accountJson(validatorListData(validators...), 1_000_000, solana_types.StakePoolProgramID.String()))
+}
+
+// The validator list and reserve of the pool
+func poolAccountsJson(reserveLamports uint64, validators ...testValidator) string {
+	return fmt.Sprintf(`{"context":{"slot":1},"value":[%s,%s]}`,
+		accountJson(validatorListData(validators...), 1_000_000, solana_types.StakePoolProgramID.String()),
+		accountJson(make([]byte, 200), reserveLamports, solana.StakeProgramID.String()),
+	)
+}
+
+func poolTokensJson(amount uint64) string {
+	tokenAccount, _ := solana_types.FindAssociatedTokenAddress(owner, poolMint, solana.TokenProgramID)
+	return fmt.Sprintf(
+		`{"context":{"slot":1},"value":[{"account":{"data":{"parsed":{"info":{"isNative":false,"mint":"%s","owner":"%s","state":"initialized","tokenAmount":{"amount":"%d","decimals":9,"uiAmount":0,"uiAmountString":"0"}},"type":"account"},"program":"spl-token","space":165},"executable":false,"lamports":2039280,"owner":"%s","rentEpoch":0},"pubkey":"%s"}]}`,
+		poolMint, owner, amount, solana.TokenProgramID, tokenAccount,
+	)
+}
+
+func stakeAccountJson(pubkey string, voter string, deactivationEpoch string, stake uint64) string {
+	return fmt.Sprintf(
+		`{"account":{"data":{"parsed":{"info":{"meta":{"authorized":{"staker":"%s","withdrawer":"%s"},"lockup":{"custodian":"11111111111111111111111111111111","epoch":0,"unixTimestamp":0},"rentExemptReserve":"2282880"},"stake":{"creditsObserved":101316504,"delegation":{"activationEpoch":"600","deactivationEpoch":"%s","stake":"%d","voter":"%s","warmupCooldownRate":0.25}}},"type":"delegated"},"program":"stake","space":200},"executable":false,"lamports":%d,"owner":"Stake11111111111111111111111111111111111111","rentEpoch":18446744073709552000,"space":200},"pubkey":"%s"}`,
+		owner, owner, deactivationEpoch, stake, voter, stake+2282880, pubkey,
+	)
+}
+
+func stakeAccountsJson(accounts ...string) string {
+	return "[" + strings.Join(accounts, ",") + "]"
+}
+
+func newClient(t *testing.T, resp []string) (xcclient.StakingClient, func()) {
+	server, close := testtypes.MockJSONRPC(t, resp)
+	chain := &xc_types.ChainConfig{
+		Client:   &xc_types.ClientConfig{URL: server.URL},
+		Chain:    xc_types.SOL,
+		Decimals: 9,
+	}
+	rpcClient, err := solclient.NewClient(chain)
+	require.NoError(t, err)
+	cli, err := stakepool.NewClient(rpcClient, chain)
+	require.NoError(t, err)
+	return cli, close
+}
+
+func stakeArgs(t *testing.T, amount uint64) xcbuilder.StakeArgs {
+	args, err := xcbuilder.NewStakeArgs(xc_types.SOL, owner, xc_types.NewBigIntFromUint64(amount),
+		xcbuilder.WithStakingProvider(xc_types.SplStakePool),
+		xcbuilder.WithValidator(poolAddress),
+	)
+	require.NoError(t, err)
+	return args
+}
+
+func TestFetchStakingInput(t *testing.T) {
+	cli, close := newClient(t, []string{poolJson(testPool{lastUpdateEpoch: 652}), epochInfo, blockhash, simulated})
+	defer close()
+
+	input, err := cli.FetchStakingInput(context.Background(), stakeArgs(t, 1_000_000_000))
+	require.NoError(t, err)
+	poolInput := input.(*tx_input.StakePoolStakeInput)
+	withdrawAuthority, err := solana_types.FindStakePoolWithdrawAuthority(solana_types.StakePoolProgramID, solana.MustPublicKeyFromBase58(poolAddress))
+	require.NoError(t, err)
+	require.Equal(t, solana_types.StakePoolAccounts{
+		Program:           solana_types.StakePoolProgramID,
+		StakePool:         solana.MustPublicKeyFromBase58(poolAddress),
+		WithdrawAuthority: withdrawAuthority,
+		ValidatorList:     solana.MustPublicKeyFromBase58(validatorList),
+		ReserveStake:      solana.MustPublicKeyFromBase58(reserveStake),
+		PoolMint:          solana.MustPublicKeyFromBase58(poolMint),
+		ManagerFeeAccount: solana.MustPublicKeyFromBase58(managerFee),
+		TokenProgram:      solana.TokenProgramID,
+	}, poolInput.StakePool)
+	require.Equal(t, "DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK", poolInput.RecentBlockHash.String())
+	require.EqualValues(t, 1200, poolInput.ComputeUnitLimit)
+}
+
+func TestFetchStakingInputErr(t *testing.T) {
+	depositAuthority := solana.MustPublicKeyFromBase58(otherValidator)
+	vectors := []struct {
+		name string
+		pool testPool
+		err  string
+	}{
+		{name: "not updated", pool: testPool{lastUpdateEpoch: 651}, err: "has not been updated for epoch 652"},
+		{name: "deposit authority", pool: testPool{lastUpdateEpoch: 652, solDepositAuthority: &depositAuthority}, err: "only accepts deposits from"},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			cli, close := newClient(t, []string{poolJson(v.pool), epochInfo})
+			defer close()
+			_, err := cli.FetchStakingInput(context.Background(), stakeArgs(t, 1_000_000_000))
+			require.ErrorContains(t, err, v.err)
+		})
+	}
+}
+
+func TestFetchUnstakingInput(t *testing.T) {
+	pool, err := solana_types.ParseStakePool(testPool{lastUpdateEpoch: 652}.encode())
+	require.NoError(t, err)
+	validators := []testValidator{
+		{vote: poolValidator, activeStake: 100_000_000_000},
+		{vote: poolValidator2, activeStake: 500_000_000_000},
+		// removing from the pool
+		{vote: otherValidator, activeStake: 900_000_000_000, status: 1},
+	}
+
+	t.Run("instant", func(t *testing.T) {
+		cli, close := newClient(t, []string{
+			poolJson(testPool{lastUpdateEpoch: 652}),
+			epochInfo,
+			poolTokensJson(5_000_000_000),
+			poolAccountsJson(20_000_000_000, validators...),
+			rent,
+			blockhash,
+			simulated,
+		})
+		defer close()
+		input, err := cli.FetchUnstakingInput(context.Background(), stakeArgs(t, 2_000_000_000))
+		require.NoError(t, err)
+		unstakeInput := input.(*tx_input.StakePoolUnstakeInput)
+		require.True(t, unstakeInput.IsInstant())
+		poolTokens, err := pool.PoolTokensForWithdrawal(2_000_000_000, pool.SolWithdrawalFee)
+		require.NoError(t, err)
+		require.Equal(t, poolTokens, unstakeInput.PoolTokens)
+		// 2 SOL at 1.1 SOL per token, plus the fee
+		require.EqualValues(t, 1_820_001_821, unstakeInput.PoolTokens)
+	})
+
+	t.Run("delayed", func(t *testing.T) {
+		cli, close := newClient(t, []string{
+			poolJson(testPool{lastUpdateEpoch: 652}),
+			epochInfo,
+			poolTokensJson(5_000_000_000),
+			// the reserve cannot cover it
+			poolAccountsJson(1_000_000_000, validators...),
+			rent,
+			blockhash,
+			simulated,
+		})
+		defer close()
+		input, err := cli.FetchUnstakingInput(context.Background(), stakeArgs(t, 2_000_000_000))
+		require.NoError(t, err)
+		unstakeInput := input.(*tx_input.StakePoolUnstakeInput)
+		require.False(t, unstakeInput.IsInstant())
+		// from the active validator with the most stake
+		validatorStake, err := solana_types.FindValidatorStakeAccount(solana_types.StakePoolProgramID, solana.MustPublicKeyFromBase58(poolAddress), &solana_types.ValidatorStakeInfo{
+			VoteAccount: solana.MustPublicKeyFromBase58(poolValidator2),
+		})
+		require.NoError(t, err)
+		require.Equal(t, validatorStake, unstakeInput.ValidatorStake)
+		require.EqualValues(t, 2282880, unstakeInput.RentExemptReserve)
+		require.NotEmpty(t, unstakeInput.StakingKey)
+		poolTokens, err := pool.PoolTokensForWithdrawal(2_000_000_000, pool.StakeWithdrawalFee)
+		require.NoError(t, err)
+		require.Equal(t, poolTokens, unstakeInput.PoolTokens)
+	})
+
+	t.Run("preferred validator", func(t *testing.T) {
+		for _, v := range []struct {
+			lamports uint64
+			expected string
+		}{
+			{lamports: 2_000_000_000, expected: poolValidator},
+			// the preferred validator does not have enough stake
+			{lamports: 200_000_000_000, expected: poolValidator2},
+		} {
+			preferred := solana.MustPublicKeyFromBase58(poolValidator)
+			cli, close := newClient(t, []string{
+				poolJson(testPool{lastUpdateEpoch: 652, preferredWithdrawValidator: &preferred}),
+				epochInfo,
+				poolTokensJson(500_000_000_000),
+				poolAccountsJson(1_000_000_000, validators...),
+				rent,
+				blockhash,
+				simulated,
+			})
+			input, err := cli.FetchUnstakingInput(context.Background(), stakeArgs(t, v.lamports))
+			close()
+			require.NoError(t, err)
+			validatorStake, err := solana_types.FindValidatorStakeAccount(solana_types.StakePoolProgramID, solana.MustPublicKeyFromBase58(poolAddress), &solana_types.ValidatorStakeInfo{
+				VoteAccount: solana.MustPublicKeyFromBase58(v.expected),
+			})
+			require.NoError(t, err)
+			require.Equal(t, validatorStake, input.(*tx_input.StakePoolUnstakeInput).ValidatorStake)
+		}
+	})
+
+	t.Run("insufficient pool tokens", func(t *testing.T) {
+		cli, close := newClient(t, []string{
+			poolJson(testPool{lastUpdateEpoch: 652}),
+			epochInfo,
+			poolTokensJson(1_000_000_000),
+			poolAccountsJson(20_000_000_000, validators...),
+			rent,
+		})
+		defer close()
+		_, err := cli.FetchUnstakingInput(context.Background(), stakeArgs(t, 2_000_000_000))
+		require.ErrorContains(t, err, "insufficient pool tokens to unstake")
+	})
+
+	t.Run("no validator with enough stake", func(t *testing.T) {
+		cli, close := newClient(t, []string{
+			poolJson(testPool{lastUpdateEpoch: 652}),
+			epochInfo,
+			poolTokensJson(1_000_000_000_000),
+			poolAccountsJson(1_000_000_000, validators...),
+			rent,
+		})
+		defer close()
+		_, err := cli.FetchUnstakingInput(context.Background(), stakeArgs(t, 600_000_000_000))
+		require.ErrorContains(t, err, "does not have enough SOL in its reserve or a validator")
+	})
+}
+
+func TestFetchStakeBalance(t *testing.T) {
+	stakeAccounts := stakeAccountsJson(
+		// withdrawn from the pool
+		stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", poolValidator, "652", 3_000_000_000),
+		stakeAccountJson("CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC", poolValidator, "640", 4_000_000_000),
+		// natively staked
+		stakeAccountJson("BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C", otherValidator, "640", 5_000_000_000),
+		stakeAccountJson("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb", poolValidator, notDeactivated, 6_000_000_000),
+	)
+	tokenAccount, _ := solana_types.FindAssociatedTokenAddress(owner, poolMint, solana.TokenProgramID)
+	validatorList := validatorListJson(testValidator{vote: poolValidator, activeStake: 100_000_000_000})
+
+	vectors := []struct {
+		name     string
+		account  string
+		resp     []string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "pool tokens",
+			resp:     []string{poolJson(testPool{lastUpdateEpoch: 652}), poolTokensJson(1_000_000_000)},
+			expected: map[string]string{},
+		},
+		{
+			name:     "deactivating",
+			account:  "6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh",
+			resp:     []string{poolJson(testPool{lastUpdateEpoch: 652}), poolTokensJson(1_000_000_000), validatorList, stakeAccounts, epochInfo},
+			expected: map[string]string{"deactivating": "3000000000", "inactive": "2282880"},
+		},
+		{
+			name:     "inactive",
+			account:  "CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC",
+			resp:     []string{poolJson(testPool{lastUpdateEpoch: 652}), poolTokensJson(1_000_000_000), validatorList, stakeAccounts, epochInfo},
+			expected: map[string]string{"deactivating": "0", "inactive": "4002282880"},
+		},
+		{
+			name:    "natively staked",
+			account: "BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C",
+			resp:    []string{poolJson(testPool{lastUpdateEpoch: 652}), poolTokensJson(1_000_000_000), validatorList, stakeAccounts},
+			err:     "is not delegated to a validator of the stake pool",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			cli, close := newClient(t, v.resp)
+			defer close()
+
+			options := []xcclient.StakedBalanceOption{xcclient.StakeBalanceOptionValidator(poolAddress)}
+			if v.account != "" {
+				options = append(options, xcclient.StakeBalanceOptionAccount(v.account))
+			}
+			args, err := xcclient.NewStakeBalanceArgs(owner, options...)
+			require.NoError(t, err)
+			balances, err := cli.FetchStakeBalance(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, poolAddress, balances[0].Validator)
+			require.Equal(t, tokenAccount, balances[0].Account)
+			require.Equal(t, "1100000000", balances[0].Balance.Active.String())
+			if len(v.expected) == 0 {
+				require.Len(t, balances, 1)
+				return
+			}
+			require.Len(t, balances, 2)
+			require.Equal(t, v.account, balances[1].Account)
+			require.Equal(t, v.expected["deactivating"], balances[1].Balance.Deactivating.String())
+			require.Equal(t, v.expected["inactive"], balances[1].Balance.Inactive.String())
+		})
+	}
+}
+
+func TestFetchWithdrawInput(t *testing.T) {
+	resp := []string{
+		poolJson(testPool{lastUpdateEpoch: 652}),
+		validatorListJson(testValidator{vote: poolValidator, activeStake: 100_000_000_000}),
+		stakeAccountsJson(
+			stakeAccountJson("6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", poolValidator, "652", 3_000_000_000),
+			stakeAccountJson("CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC", poolValidator, "640", 4_000_000_000),
+			stakeAccountJson("BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C", otherValidator, "640", 5_000_000_000),
+		),
+		blockhash,
+		epochInfo,
+		simulated,
+	}
+	vectors := []struct {
+		name    string
+		account string
+		err     string
+	}{
+		{name: "inactive", account: "CCTFhyxoUHGmdQvuUxFquyYMK4H5hdqwCCN7XAXtK9HC"},
+		{name: "deactivating", account: "6LFjBX1yUwSr8SWsyZUc5okZiVo8ZdmVQ9keJAazRmnh", err: "is not inactive yet"},
+		{name: "natively staked", account: "BYoo5izmpyrkc4fKkJy2gp6Bwc9evt4vgCYYMY3NHu9C", err: "is not delegated to a validator of the stake pool"},
+		{name: "stake account is required", err: "stake account withdrawn from the stake pool is required"},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			cli, close := newClient(t, resp)
+			defer close()
+
+			options := []xcbuilder.BuilderOption{
+				xcbuilder.WithStakingProvider(xc_types.SplStakePool),
+				xcbuilder.WithValidator(poolAddress),
+			}
+			if v.account != "" {
+				options = append(options, xcbuilder.WithStakeAccount(v.account))
+			}
+			args, err := xcbuilder.NewStakeArgs(xc_types.SOL, owner, xc_types.NewBigIntFromUint64(0), options...)
+			require.NoError(t, err)
+			input, err := cli.FetchWithdrawInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			withdrawInput := input.(*tx_input.WithdrawInput)
+			require.Len(t, withdrawInput.EligibleStakes, 1)
+			require.Equal(t, v.account, withdrawInput.EligibleStakes[0].StakeAccount.String())
+			require.Equal(t, "4002282880", withdrawInput.EligibleStakes[0].AmountInactive.String())
+		})
+	}
+}
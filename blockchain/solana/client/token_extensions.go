@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Sets the transfer fee of a Token-2022 mint, and refuses mints that cannot be transferred
+func (client *Client) setMintExtensions(ctx context.Context, txInput *tx_input.TxInput, mint solana.PublicKey, data []byte) error {
+	extensions, err := solana_types.ParseMintExtensions(data)
+	if err != nil {
+		return fmt.Errorf("could not parse extensions of mint %s: %v", mint, err)
+	}
+	if extensions.NonTransferable {
+		return fmt.Errorf("mint %s is non-transferable", mint)
+	}
+	config := extensions.TransferFeeConfig
+	if config == nil {
+		return nil
+	}
+	fee := config.Newer
+	if config.Older.BasisPoints != config.Newer.BasisPoints || config.Older.MaximumFee != config.Newer.MaximumFee {
+		// a fee update only takes effect in a later epoch
+		epochInfo, err := client.client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+		if err != nil {
+			return fmt.Errorf("could not get epoch for the transfer fee: %v", err)
+		}
+		fee = config.ForEpoch(epochInfo.Epoch)
+	}
+	if fee.BasisPoints > 0 {
+		txInput.TransferFee = &fee
+	}
+	return nil
+}
+
+// Checks that a Token-2022 destination account can receive the transfer, and whether it requires a memo
+func setDestinationExtensions(txInput *tx_input.TxInput, account solana.PublicKey, data []byte) error {
+	extensions, err := solana_types.ParseTokenAccountExtensions(data)
+	if err != nil {
+		return fmt.Errorf("could not parse extensions of token account %s: %v", account, err)
+	}
+	if extensions.RejectsNonConfidentialCredits {
+		return fmt.Errorf("token account %s only accepts confidential transfers", account)
+	}
+	txInput.ToRequiresMemo = extensions.RequiresIncomingMemos
+	return nil
+}
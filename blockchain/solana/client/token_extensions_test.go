@@ -0,0 +1,213 @@
+package client_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx_input"
+	"github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+// Token-2022 account data, with the extensions after the base layout
+func token2022Data(accountType byte, extensions ...[]byte) []byte {
+	data := make([]byte, 165)
+	data = append(data, accountType)
+	for _, extension := range extensions {
+		data = append(data, extension...)
+	}
+	return data
+}
+
+func tokenExtension(extensionType types.ExtensionType, value []byte) []byte {
+	data := binary.LittleEndian.AppendUint16(nil, uint16(extensionType))
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(value)))
+	return append(data, value...)
+}
+
+func transferFeeConfig(older types.TransferFee, newer types.TransferFee) []byte {
+	// config and withdraw authorities, then the withheld amount
+	data := make([]byte, 72)
+	for _, fee := range []types.TransferFee{older, newer} {
+		data = binary.LittleEndian.AppendUint64(data, fee.Epoch)
+		data = binary.LittleEndian.AppendUint64(data, fee.MaximumFee)
+		data = binary.LittleEndian.AppendUint16(data, fee.BasisPoints)
+	}
+	return tokenExtension(types.ExtensionTransferFeeConfig, data)
+}
+
+func TestFetchTransferInputToken2022(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	mint := solana.MustPublicKeyFromBase58("2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo")
+	sourceAccount := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+
+	blockhash := `{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`
+	notTokenAccount := `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid param: not a Token account"},"id":0}`
+	epochInfo := `{"absoluteSlot":1,"blockHeight":1,"epoch":100,"slotIndex":1,"slotsInEpoch":432000,"transactionCount":1}`
+	tokenAccounts := fmt.Sprintf(
+		`{"context":{"slot":1},"value":[{"account":{"data":{"parsed":{"info":{"isNative":false,"mint":"%s","owner":"%s","state":"initialized","tokenAmount":{"amount":"1000000","decimals":6,"uiAmount":1,"uiAmountString":"1"}},"type":"account"},"program":"spl-token-2022","space":165},"executable":false,"lamports":2039280,"owner":"%s","rentEpoch":0},"pubkey":"%s"}]}`,
+		mint, from, solana.Token2022ProgramID, sourceAccount,
+	)
+	mintAccount := func(extensions ...[]byte) string {
+		return fmt.Sprintf(`{"context":{"slot":1},"value":%s}`, mockAccount(solana.Token2022ProgramID, token2022Data(1, extensions...)))
+	}
+	destinationAccount := func(extensions ...[]byte) string {
+		return fmt.Sprintf(`{"context":{"slot":1},"value":%s}`, mockAccount(solana.Token2022ProgramID, token2022Data(2, extensions...)))
+	}
+	fee := types.TransferFee{Epoch: 10, MaximumFee: 5000, BasisPoints: 100}
+	newFee := types.TransferFee{Epoch: 200, MaximumFee: 5000, BasisPoints: 200}
+
+	vectors := []struct {
+		name         string
+		resp         []string
+		transferFee  *types.TransferFee
+		requiresMemo bool
+		memo         string
+		err          string
+	}{
+		{
+			name: "transfer fee and required memo",
+			resp: []string{
+				blockhash,
+				mintAccount(transferFeeConfig(fee, fee)),
+				notTokenAccount,
+				destinationAccount(tokenExtension(types.ExtensionMemoTransfer, []byte{1})),
+				tokenAccounts,
+				`[]`,
+				`{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`,
+			},
+			transferFee:  &fee,
+			requiresMemo: true,
+			memo:         "invoice 42",
+		},
+		{
+			name: "pending transfer fee update",
+			resp: []string{
+				blockhash,
+				mintAccount(transferFeeConfig(fee, newFee)),
+				epochInfo,
+				notTokenAccount,
+				destinationAccount(),
+				tokenAccounts,
+				`[]`,
+				`{"context":{"slot":1},"value":{"err":null,"logs":[],"unitsConsumed":1000}}`,
+			},
+			transferFee: &fee,
+		},
+		{
+			name: "non-transferable mint",
+			resp: []string{
+				blockhash,
+				mintAccount(tokenExtension(types.ExtensionNonTransferable, []byte{})),
+			},
+			err: "is non-transferable",
+		},
+		{
+			name: "destination only accepts confidential transfers",
+			resp: []string{
+				blockhash,
+				mintAccount(),
+				notTokenAccount,
+				destinationAccount(tokenExtension(types.ExtensionConfidentialTransferAccount, make([]byte, 295))),
+			},
+			err: "only accepts confidential transfers",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, v.resp)
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+			args, err := xcbuilder.NewTransferArgs(
+				xc_types.Address(from.String()),
+				xc_types.Address(to.String()),
+				xc_types.NewBigIntFromUint64(1000),
+				xcbuilder.WithAsset(&xc_types.TokenAssetConfig{
+					Contract: xc_types.ContractAddress(mint.String()),
+					Decimals: 6,
+				}),
+			)
+			require.NoError(t, err)
+			if v.memo != "" {
+				args.SetMemo(v.memo)
+			}
+
+			input, err := cli.FetchTransferInput(context.Background(), args)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			txInput := input.(*tx_input.TxInput)
+			require.Equal(t, solana.Token2022ProgramID, txInput.TokenProgram)
+			require.Equal(t, v.transferFee, txInput.TransferFee)
+			require.Equal(t, v.requiresMemo, txInput.ToRequiresMemo)
+			require.EqualValues(t, 1200, txInput.ComputeUnitLimit)
+		})
+	}
+}
+
+func TestFetchLegacyTxInfoTransferFee(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	mint := solana.MustPublicKeyFromBase58("2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo")
+	sourceAccount := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	destinationAccount := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+
+	solTx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			types.NewTransferCheckedWithFeeInstruction(solana.Token2022ProgramID, &types.TransferCheckedWithFee{
+				Source:      sourceAccount,
+				Mint:        mint,
+				Destination: destinationAccount,
+				Owner:       from,
+				Amount:      101_011,
+				Decimals:    6,
+				Fee:         1011,
+			}),
+		},
+		solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		solana.TransactionPayer(from),
+	)
+	require.NoError(t, err)
+	solTx.Signatures = []solana.Signature{{1}}
+	bz, err := solTx.MarshalBinary()
+	require.NoError(t, err)
+
+	server, close := testtypes.MockJSONRPC(t, []string{
+		fmt.Sprintf(
+			`{"blockTime":1700000000,"meta":{"err":null,"fee":5000,"postBalances":[],"preBalances":[]},"slot":0,"transaction":["%s","base64"]}`,
+			base64.StdEncoding.EncodeToString(bz),
+		),
+		fmt.Sprintf(
+			`{"context":{"slot":1},"value":{"data":{"parsed":{"info":{"isNative":false,"mint":"%s","owner":"%s","state":"initialized","tokenAmount":{"amount":"100000","decimals":6,"uiAmount":0.1,"uiAmountString":"0.1"}},"type":"account"},"program":"spl-token-2022","space":165},"executable":false,"lamports":2039280,"owner":"%s","rentEpoch":0}}`,
+			mint, to, solana.Token2022ProgramID,
+		),
+	})
+	defer close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+	info, err := cli.FetchLegacyTxInfo(context.Background(), xc_types.TxHash(solTx.Signatures[0].String()))
+	require.NoError(t, err)
+
+	// the withheld fee is the difference between what was sent and received
+	require.Len(t, info.Sources, 1)
+	require.EqualValues(t, from.String(), info.Sources[0].Address)
+	require.EqualValues(t, 101_011, info.Sources[0].Amount.Uint64())
+	require.Len(t, info.Destinations, 1)
+	require.EqualValues(t, to.String(), info.Destinations[0].Address)
+	require.EqualValues(t, mint.String(), info.Destinations[0].ContractAddress)
+	require.EqualValues(t, 100_000, info.Destinations[0].Amount.Uint64())
+}
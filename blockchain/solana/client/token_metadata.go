@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var _ xcclient.TokenMetadataClient = &Client{}
+
+// FetchTokenMetadata reads the decimals from the mint account, and the name + symbol
+// from the Metaplex metadata account if there is one.
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc.ContractAddress) (*xcclient.TokenMetadata, error) {
+	mint, err := solana.PublicKeyFromBase58(string(contract))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mint address: %s: %v", contract, err)
+	}
+	metadataAddress, err := solana_types.FindMetadataAddress(mint)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := client.client.GetMultipleAccountsWithOpts(ctx, []solana.PublicKey{mint, metadataAddress}, &rpc.GetMultipleAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts.Value) != 2 || accounts.Value[0] == nil {
+		return nil, fmt.Errorf("mint account not found: %s", contract)
+	}
+	mintAccount := accounts.Value[0]
+	if !mintAccount.Owner.Equals(solana.TokenProgramID) && !mintAccount.Owner.Equals(solana.Token2022ProgramID) {
+		return nil, fmt.Errorf("account %s is not a token mint", contract)
+	}
+	decimals, err := solana_types.ParseMintDecimals(mintAccount.Data.GetBinary())
+	if err != nil {
+		return nil, err
+	}
+	result := &xcclient.TokenMetadata{
+		Contract: contract,
+		Decimals: decimals,
+	}
+	if metadataAccount := accounts.Value[1]; metadataAccount != nil {
+		metadata, err := solana_types.ParseMetadata(metadataAccount.Data.GetBinary())
+		if err != nil {
+			return nil, fmt.Errorf("could not parse metadata of %s: %v", contract, err)
+		}
+		result.Name = metadata.Name
+		result.Symbol = metadata.Symbol
+	}
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	solana_sdk "github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func mockAccount(owner solana_sdk.PublicKey, data []byte) string {
+	return fmt.Sprintf(
+		`{"data":["%s","base64"],"executable":false,"lamports":1461600,"owner":"%s","rentEpoch":0}`,
+		base64.StdEncoding.EncodeToString(data), owner.String(),
+	)
+}
+
+func borshString(value string, padding int) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(padding))
+	data = append(data, []byte(value)...)
+	return append(data, make([]byte, padding-len(value))...)
+}
+
+func TestFetchTokenMetadata(t *testing.T) {
+	mint := solana_sdk.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	mintData := make([]byte, 82)
+	mintData[44] = 6
+
+	metadataData := []byte{4}
+	metadataData = append(metadataData, make([]byte, 32)...)
+	metadataData = append(metadataData, mint[:]...)
+	metadataData = append(metadataData, borshString("USD Coin", 32)...)
+	metadataData = append(metadataData, borshString("USDC", 10)...)
+	metadataData = append(metadataData, borshString("", 200)...)
+
+	vectors := []struct {
+		name     string
+		accounts string
+		symbol   string
+		decimals int32
+		err      string
+	}{
+		{
+			name: "with metaplex metadata",
+			accounts: fmt.Sprintf("[%s,%s]",
+				mockAccount(solana_sdk.TokenProgramID, mintData),
+				mockAccount(solana_sdk.MustPublicKeyFromBase58("metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s"), metadataData),
+			),
+			symbol:   "USDC",
+			decimals: 6,
+		},
+		{
+			name:     "token-2022 without metadata",
+			accounts: fmt.Sprintf("[%s,null]", mockAccount(solana_sdk.Token2022ProgramID, mintData)),
+			decimals: 6,
+		},
+		{
+			name:     "not a mint",
+			accounts: fmt.Sprintf("[%s,null]", mockAccount(solana_sdk.SystemProgramID, []byte{})),
+			err:      "is not a token mint",
+		},
+		{
+			name:     "missing",
+			accounts: "[null,null]",
+			err:      "mint account not found",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, fmt.Sprintf(`{"context":{"slot":1},"value":%s}`, v.accounts))
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+
+			metadata, err := cli.FetchTokenMetadata(context.Background(), xc_types.ContractAddress(mint.String()))
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v.symbol, metadata.Symbol)
+			require.Equal(t, v.decimals, metadata.Decimals)
+		})
+	}
+}
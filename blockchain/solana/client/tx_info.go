@@ -0,0 +1,312 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/tx"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// Net change in balances by asset and then by address, keeping the order that they are first seen
+type balanceDeltas struct {
+	deltas    map[xc.ContractAddress]map[solana.PublicKey]*big.Int
+	assets    []xc.ContractAddress
+	addresses map[xc.ContractAddress][]solana.PublicKey
+}
+
+func newBalanceDeltas() *balanceDeltas {
+	return &balanceDeltas{
+		deltas:    map[xc.ContractAddress]map[solana.PublicKey]*big.Int{},
+		addresses: map[xc.ContractAddress][]solana.PublicKey{},
+	}
+}
+
+func (balances *balanceDeltas) add(contract xc.ContractAddress, address solana.PublicKey, amount *big.Int) {
+	if _, ok := balances.deltas[contract]; !ok {
+		balances.deltas[contract] = map[solana.PublicKey]*big.Int{}
+		balances.assets = append(balances.assets, contract)
+	}
+	delta, ok := balances.deltas[contract][address]
+	if !ok {
+		delta = new(big.Int)
+		balances.deltas[contract][address] = delta
+		balances.addresses[contract] = append(balances.addresses[contract], address)
+	}
+	delta.Add(delta, amount)
+}
+
+// Moves the amount out of the net changes in balance, if the balance of the source decreased and the balance of
+// the destination increased by at least the amount
+func (balances *balanceDeltas) pair(contract xc.ContractAddress, from solana.PublicKey, to solana.PublicKey, amount *big.Int) bool {
+	if amount.Sign() <= 0 || from.Equals(to) {
+		return false
+	}
+	fromDelta, ok := balances.deltas[contract][from]
+	if !ok || new(big.Int).Neg(fromDelta).Cmp(amount) < 0 {
+		return false
+	}
+	toDelta, ok := balances.deltas[contract][to]
+	if !ok || toDelta.Cmp(amount) < 0 {
+		return false
+	}
+	fromDelta.Add(fromDelta, amount)
+	toDelta.Sub(toDelta, amount)
+	return true
+}
+
+// Returns a transfer for each of the system and token transfers of the instructions that the net changes in
+// balance account for, which are then moved out of the changes
+func (balances *balanceDeltas) pairTransfers(chain xc.NativeAsset, decoded *tx.Tx, tokenAccountOwners map[solana.PublicKey]solana.PublicKey, tokenAccountMints map[solana.PublicKey]solana.PublicKey) []*xcclient.Transfer {
+	transfers := []*xcclient.Transfer{}
+	pair := func(contract xc.ContractAddress, from solana.PublicKey, to solana.PublicKey, amount uint64) {
+		if !balances.pair(contract, from, to, new(big.Int).SetUint64(amount)) {
+			return
+		}
+		tf := xcclient.NewTransfer(chain)
+		tf.AddSource(xc.Address(from.String()), contract, xc.NewBigIntFromUint64(amount), nil)
+		tf.AddDestination(xc.Address(to.String()), contract, xc.NewBigIntFromUint64(amount), nil)
+		transfers = append(transfers, tf)
+	}
+	// token balances are credited to the owner of the token account
+	owner := func(tokenAccount solana.PublicKey) solana.PublicKey {
+		if owner, ok := tokenAccountOwners[tokenAccount]; ok {
+			return owner
+		}
+		return tokenAccount
+	}
+
+	for _, instr := range decoded.GetSystemTransfers() {
+		pair("", instr.GetFundingAccount().PublicKey, instr.GetRecipientAccount().PublicKey, *instr.Lamports)
+	}
+	for _, instr := range decoded.GetTokenTransferCheckeds() {
+		contract := xc.ContractAddress(instr.GetMintAccount().PublicKey.String())
+		pair(contract, owner(instr.GetSourceAccount().PublicKey), owner(instr.GetDestinationAccount().PublicKey), *instr.Amount)
+	}
+	for _, instr := range decoded.GetTokenTransferCheckedWithFees() {
+		// the fee is withheld from the amount received
+		pair(xc.ContractAddress(instr.Mint.String()), owner(instr.Source), owner(instr.Destination), instr.Amount-instr.Fee)
+	}
+	for _, instr := range decoded.GetTokenTransfers() {
+		mint, ok := tokenAccountMints[instr.GetSourceAccount().PublicKey]
+		if !ok {
+			continue
+		}
+		pair(xc.ContractAddress(mint.String()), owner(instr.GetSourceAccount().PublicKey), owner(instr.GetDestinationAccount().PublicKey), *instr.Amount)
+	}
+	return transfers
+}
+
+// Returns a transfer for each asset, from the addresses that decreased in balance to the addresses that increased
+func (balances *balanceDeltas) transfers(chain xc.NativeAsset) []*xcclient.Transfer {
+	transfers := []*xcclient.Transfer{}
+	for _, contract := range balances.assets {
+		tf := xcclient.NewTransfer(chain)
+		for _, address := range balances.addresses[contract] {
+			delta := balances.deltas[contract][address]
+			switch delta.Sign() {
+			case -1:
+				tf.AddSource(xc.Address(address.String()), contract, xc.BigInt(*new(big.Int).Neg(delta)), nil)
+			case 1:
+				tf.AddDestination(xc.Address(address.String()), contract, xc.BigInt(*delta), nil)
+			}
+		}
+		if len(tf.From) > 0 || len(tf.To) > 0 {
+			transfers = append(transfers, tf)
+		}
+	}
+	return transfers
+}
+
+// FetchTxInfo reports the change in the balances of each account of the transaction, from the balances before
+// and after it that are recorded in its metadata.  This includes transfers made by programs that the
+// transaction invokes, and the rent of accounts that it creates or closes.  Token balances are credited to the
+// owner of the token account.  The instructions that programs invoked are decoded with the top-level
+// instructions for staking and memos, and to pair the sources and destinations of system and token transfers.
+// Any change in balance that is not paired is reported as a transfer from all of the addresses that decreased
+// in balance to all of those that increased.
+//
+// Transactions from before balances were recorded are reported from their top-level instructions.
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (*xcclient.TxInfo, error) {
+	res, tx, err := client.fetchTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	meta := res.Meta
+	chain := client.cfg.Chain
+	// includes the accounts loaded from address lookup tables
+	accounts, err := tx.SolTx.Message.GetAllKeys()
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve accounts: %v", err)
+	}
+	if len(accounts) == 0 || len(meta.PreBalances) != len(accounts) || len(meta.PostBalances) != len(accounts) {
+		logrus.WithField("tx", txHash).Debug("transaction does not record balances, decoding the instructions instead")
+		legacyTx := client.legacyTxInfo(ctx, txHash, res, tx)
+		txInfo := xcclient.TxInfoFromLegacy(chain, legacyTx, xcclient.Account)
+		if meta.Err == nil {
+			for _, instr := range tx.GetTokenTransferCheckedWithFees() {
+				if instr.Fee > 0 {
+					txInfo.AddFee(xc.Address(instr.Owner.String()), xc.ContractAddress(instr.Mint.String()), xc.NewBigIntFromUint64(instr.Fee), nil)
+				}
+			}
+			txInfo.Fees = txInfo.CalculateFees()
+		}
+		// only finalized transactions are looked up
+		txInfo.Finalized = txInfo.Block.Height > 0
+		return txInfo, nil
+	}
+	feePayer := accounts[0]
+
+	var errMsg *string
+	if meta.Err != nil {
+		errBz, _ := json.Marshal(meta.Err)
+		msg := string(errBz)
+		errMsg = &msg
+	}
+	blockTime := time.Unix(0, 0)
+	if res.BlockTime != nil {
+		blockTime = res.BlockTime.Time()
+	}
+	confirmations := int64(0)
+	if res.Slot > 0 {
+		confirmations = client.fetchConfirmations(ctx, res.Slot)
+	}
+	info := xcclient.NewTxInfo(xcclient.NewBlock(res.Slot, "", blockTime), chain, string(txHash), uint64(max(confirmations, 0)), errMsg)
+	// only finalized transactions are looked up
+	info.Finalized = res.Slot > 0
+
+	balances := newBalanceDeltas()
+	for i, address := range accounts {
+		lamports := new(big.Int).SetUint64(meta.PostBalances[i])
+		balances.add("", address, lamports.Sub(lamports, new(big.Int).SetUint64(meta.PreBalances[i])))
+	}
+	// the fee is reported separately
+	balances.add("", feePayer, new(big.Int).SetUint64(meta.Fee))
+	tokenAccountOwners := map[solana.PublicKey]solana.PublicKey{}
+	tokenAccountMints := map[solana.PublicKey]solana.PublicKey{}
+	addTokenBalances := func(tokenBalances []rpc.TokenBalance, sign int64) {
+		for _, balance := range tokenBalances {
+			if int(balance.AccountIndex) >= len(accounts) || balance.UiTokenAmount == nil {
+				continue
+			}
+			amount, ok := new(big.Int).SetString(balance.UiTokenAmount.Amount, 10)
+			if !ok {
+				continue
+			}
+			owner := accounts[balance.AccountIndex]
+			if balance.Owner != nil {
+				owner = *balance.Owner
+			}
+			tokenAccountOwners[accounts[balance.AccountIndex]] = owner
+			tokenAccountMints[accounts[balance.AccountIndex]] = balance.Mint
+			balances.add(xc.ContractAddress(balance.Mint.String()), owner, amount.Mul(amount, big.NewInt(sign)))
+		}
+	}
+	addTokenBalances(meta.PreTokenBalances, -1)
+	addTokenBalances(meta.PostTokenBalances, 1)
+
+	innerTx := tx.InnerTx(meta.InnerInstructions)
+	// Token-2022 mints withhold their transfer fees from the amount received, which are reported as fees
+	type withheldFee struct {
+		owner    solana.PublicKey
+		contract xc.ContractAddress
+		amount   uint64
+	}
+	withheldFees := []withheldFee{}
+	if meta.Err == nil {
+		for _, instr := range append(tx.GetTokenTransferCheckedWithFees(), innerTx.GetTokenTransferCheckedWithFees()...) {
+			if instr.Fee == 0 {
+				continue
+			}
+			owner, ok := tokenAccountOwners[instr.Source]
+			if !ok {
+				owner = instr.Owner
+			}
+			contract := xc.ContractAddress(instr.Mint.String())
+			balances.add(contract, owner, new(big.Int).SetUint64(instr.Fee))
+			withheldFees = append(withheldFees, withheldFee{owner, contract, instr.Fee})
+		}
+	}
+	memos := []string{}
+	for _, memo := range append(tx.GetMemos(), innerTx.GetMemos()...) {
+		if !slices.Contains(memos, memo) {
+			memos = append(memos, memo)
+		}
+	}
+	transfers := []*xcclient.Transfer{}
+	if meta.Err == nil {
+		transfers = append(transfers, balances.pairTransfers(chain, tx, tokenAccountOwners, tokenAccountMints)...)
+		transfers = append(transfers, balances.pairTransfers(chain, innerTx, tokenAccountOwners, tokenAccountMints)...)
+	}
+	for _, tf := range append(transfers, balances.transfers(chain)...) {
+		if len(tf.To) > 0 {
+			tf.SetMemo(strings.Join(memos, "\n"))
+		}
+		info.AddTransfer(tf)
+	}
+	if meta.Fee > 0 {
+		info.AddFee(xc.Address(feePayer.String()), "", xc.NewBigIntFromUint64(meta.Fee), nil)
+	}
+	for _, fee := range withheldFees {
+		info.AddFee(xc.Address(fee.owner.String()), fee.contract, xc.NewBigIntFromUint64(fee.amount), nil)
+	}
+	info.Fees = info.CalculateFees()
+
+	for _, ev := range append(client.stakeEvents(ctx, tx), client.stakeEvents(ctx, innerTx)...) {
+		switch ev := ev.(type) {
+		case *xcclient.Stake:
+			info.Stakes = append(info.Stakes, ev)
+		case *xcclient.Unstake:
+			info.Unstakes = append(info.Unstakes, ev)
+		}
+	}
+	return info, nil
+}
+
+// Returns the stake delegations and deactivations of the instructions
+func (client *Client) stakeEvents(ctx context.Context, tx *tx.Tx) []xc.StakeEvent {
+	events := []xc.StakeEvent{}
+	for _, instr := range tx.GetDelegateStake() {
+		xcStake := &xcclient.Stake{
+			Account:   instr.GetStakeAccount().PublicKey.String(),
+			Validator: instr.GetVoteAccount().PublicKey.String(),
+			Address:   instr.GetStakeAuthority().PublicKey.String(),
+			// Needs to be looked up from separate instruction
+			Balance: xc.BigInt{},
+		}
+		for _, createAccount := range tx.GetCreateAccounts() {
+			if createAccount.NewAccount.Equals(instr.GetStakeAccount().PublicKey) {
+				xcStake.Balance = xc.NewBigIntFromUint64(createAccount.Lamports)
+			}
+		}
+		events = append(events, xcStake)
+	}
+	for _, instr := range tx.GetDeactivateStakes() {
+		xcStake := &xcclient.Unstake{
+			Account: instr.GetStakeAccount().PublicKey.String(),
+			Address: instr.GetStakeAuthority().PublicKey.String(),
+
+			// Needs to be looked up
+			Balance:   xc.BigInt{},
+			Validator: "",
+		}
+		stakeAccountInfo, err := client.LookupStakeAccount(ctx, instr.GetStakeAccount().PublicKey)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to lookup stake account")
+		} else {
+			xcStake.Validator = stakeAccountInfo.Parsed.Info.Stake.Delegation.Voter
+			xcStake.Balance = xc.NewBigIntFromStr(stakeAccountInfo.Parsed.Info.Stake.Delegation.Stake)
+		}
+		events = append(events, xcStake)
+	}
+	return events
+}
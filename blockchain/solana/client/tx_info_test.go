@@ -0,0 +1,383 @@
+package client_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchTxInfoFromBalances(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	fromTokenAccount := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+	toTokenAccount := solana.MustPublicKeyFromBase58("GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE")
+	mint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	// a program that pays out through cross-program invocations
+	program := solana.MustPublicKeyFromBase58("9NmqDDZa7mH1DBM4zeq9cm7VcRn2un1i2TwuMvjBoVhU")
+
+	solTx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			solana.NewInstruction(program, solana.AccountMetaSlice{
+				solana.Meta(from).WRITE().SIGNER(),
+				solana.Meta(to).WRITE(),
+				solana.Meta(fromTokenAccount).WRITE(),
+				solana.Meta(toTokenAccount).WRITE(),
+				solana.Meta(solana.MemoProgramID),
+			}, []byte{1}),
+		},
+		solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		solana.TransactionPayer(from),
+	)
+	require.NoError(t, err)
+	solTx.Signatures = []solana.Signature{{1}}
+	bz, err := solTx.MarshalBinary()
+	require.NoError(t, err)
+	index := func(account solana.PublicKey) int {
+		for i, key := range solTx.Message.AccountKeys {
+			if key.Equals(account) {
+				return i
+			}
+		}
+		require.Fail(t, "account not found", account.String())
+		return -1
+	}
+	accounts := []solana.PublicKey{from, to, fromTokenAccount, toTokenAccount, program, solana.MemoProgramID}
+	balances := func(amounts map[solana.PublicKey]uint64) string {
+		values := make([]uint64, len(solTx.Message.AccountKeys))
+		for _, account := range accounts {
+			values[index(account)] = amounts[account]
+		}
+		bz, _ := json.Marshal(values)
+		return string(bz)
+	}
+	tokenBalance := func(account solana.PublicKey, owner solana.PublicKey, amount uint64) string {
+		return fmt.Sprintf(
+			`{"accountIndex":%d,"mint":"%s","owner":"%s","programId":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA","uiTokenAmount":{"amount":"%d","decimals":6,"uiAmount":0,"uiAmountString":"0"}}`,
+			index(account), mint, owner, amount,
+		)
+	}
+	preBalances := balances(map[solana.PublicKey]uint64{from: 10_000_000_000, to: 1_000, fromTokenAccount: 2_039_280, program: 1})
+	// the program sends 0.001 SOL, and creates the token account of the recipient
+	postBalances := balances(map[solana.PublicKey]uint64{from: 10_000_000_000 - 5000 - 1_000_000 - 2_039_280, to: 1_001_000, fromTokenAccount: 2_039_280, toTokenAccount: 2_039_280, program: 1})
+	innerInstructions := fmt.Sprintf(
+		`[{"index":0,"instructions":[{"programIdIndex":%d,"accounts":[%d],"data":"%s","stackHeight":2}]}]`,
+		index(solana.MemoProgramID), index(from), solana.Base58("payout"),
+	)
+
+	vectors := []struct {
+		name string
+		err  string
+	}{
+		{name: "success", err: "null"},
+		{name: "failed", err: `{"InstructionError":[0,{"Custom":1}]}`},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			post := postBalances
+			preTokenBalances := "[" + tokenBalance(fromTokenAccount, from, 500) + "]"
+			postTokenBalances := "[" + tokenBalance(fromTokenAccount, from, 400) + "," + tokenBalance(toTokenAccount, to, 100) + "]"
+			if v.err != "null" {
+				// only the fee is charged
+				post = balances(map[solana.PublicKey]uint64{from: 10_000_000_000 - 5000, to: 1_000, fromTokenAccount: 2_039_280, program: 1})
+				postTokenBalances = preTokenBalances
+			}
+			server, close := testtypes.MockJSONRPC(t, []string{
+				fmt.Sprintf(
+					`{"blockTime":1700000000,"meta":{"err":%s,"fee":5000,"innerInstructions":%s,"preBalances":%s,"postBalances":%s,"preTokenBalances":%s,"postTokenBalances":%s},"slot":100,"transaction":["%s","base64"]}`,
+					v.err, innerInstructions, preBalances, post, preTokenBalances, postTokenBalances, base64.StdEncoding.EncodeToString(bz),
+				),
+				`{"context":{"slot":150},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`,
+			})
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Chain: xc_types.SOL, Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+			info, err := cli.FetchTxInfo(context.Background(), xc_types.TxHash(solTx.Signatures[0].String()))
+			require.NoError(t, err)
+			require.EqualValues(t, 100, info.Block.Height)
+			require.EqualValues(t, 50, info.Confirmations)
+			require.True(t, info.Finalized)
+			require.Len(t, info.Fees, 1)
+			require.Equal(t, "5000", info.Fees[0].Balance.String())
+
+			if v.err != "null" {
+				require.NotNil(t, info.Error)
+				require.Equal(t, v.err, *info.Error)
+				// only the fee
+				require.Len(t, info.Transfers, 1)
+				require.Empty(t, info.Transfers[0].To)
+				return
+			}
+			require.Nil(t, info.Error)
+			require.Len(t, info.Transfers, 3)
+
+			native := info.Transfers[0]
+			require.Len(t, native.From, 1)
+			require.Equal(t, xcclient.NewAddressName(xc_types.SOL, from.String()), native.From[0].Address)
+			require.Equal(t, "3039280", native.From[0].Balance.String())
+			require.Len(t, native.To, 2)
+			require.Equal(t, xcclient.NewAddressName(xc_types.SOL, to.String()), native.To[0].Address)
+			require.Equal(t, "1000000", native.To[0].Balance.String())
+			// the rent of the new token account
+			require.Equal(t, xcclient.NewAddressName(xc_types.SOL, toTokenAccount.String()), native.To[1].Address)
+			require.Equal(t, "2039280", native.To[1].Balance.String())
+			// decoded from the inner instructions
+			require.Equal(t, "payout", native.Memo)
+
+			// token balances are credited to the owners
+			token := info.Transfers[1]
+			require.Equal(t, xc_types.ContractAddress(mint), token.From[0].Contract)
+			require.Equal(t, xcclient.NewAddressName(xc_types.SOL, from.String()), token.From[0].Address)
+			require.Equal(t, "100", token.From[0].Balance.String())
+			require.Len(t, token.To, 1)
+			require.Equal(t, xcclient.NewAddressName(xc_types.SOL, to.String()), token.To[0].Address)
+			require.Equal(t, "100", token.To[0].Balance.String())
+
+			fee := info.Transfers[2]
+			require.Equal(t, "5000", fee.From[0].Balance.String())
+			require.Empty(t, fee.To)
+		})
+	}
+}
+
+func TestFetchTxInfoWithheldTransferFee(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	fromTokenAccount := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+	toTokenAccount := solana.MustPublicKeyFromBase58("GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE")
+	mint := solana.MustPublicKeyFromBase58("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU")
+
+	solTx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			types.NewTransferCheckedWithFeeInstruction(solana.Token2022ProgramID, &types.TransferCheckedWithFee{
+				Source:      fromTokenAccount,
+				Mint:        mint,
+				Destination: toTokenAccount,
+				Owner:       from,
+				Amount:      101_011,
+				Decimals:    6,
+				Fee:         1011,
+			}),
+		},
+		solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		solana.TransactionPayer(from),
+	)
+	require.NoError(t, err)
+	solTx.Signatures = []solana.Signature{{1}}
+	bz, err := solTx.MarshalBinary()
+	require.NoError(t, err)
+	index := func(account solana.PublicKey) int {
+		for i, key := range solTx.Message.AccountKeys {
+			if key.Equals(account) {
+				return i
+			}
+		}
+		require.Fail(t, "account not found", account.String())
+		return -1
+	}
+	tokenBalance := func(account solana.PublicKey, owner solana.PublicKey, amount uint64) string {
+		return fmt.Sprintf(
+			`{"accountIndex":%d,"mint":"%s","owner":"%s","programId":"TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb","uiTokenAmount":{"amount":"%d","decimals":6,"uiAmount":0,"uiAmountString":"0"}}`,
+			index(account), mint, owner, amount,
+		)
+	}
+	// only the fee is paid in SOL
+	preBalances := make([]uint64, len(solTx.Message.AccountKeys))
+	preBalances[index(from)] = 10_000
+	postBalances := make([]uint64, len(solTx.Message.AccountKeys))
+	postBalances[index(from)] = 5_000
+	preBz, _ := json.Marshal(preBalances)
+	postBz, _ := json.Marshal(postBalances)
+	preTokenBalances := "[" + tokenBalance(fromTokenAccount, from, 200_000) + "," + tokenBalance(toTokenAccount, to, 0) + "]"
+	// the recipient receives the amount without the withheld fee
+	postTokenBalances := "[" + tokenBalance(fromTokenAccount, from, 98_989) + "," + tokenBalance(toTokenAccount, to, 100_000) + "]"
+
+	vectors := []struct {
+		name         string
+		preBalances  string
+		postBalances string
+	}{
+		{name: "balances", preBalances: string(preBz), postBalances: string(postBz)},
+		// decoded from the instructions
+		{name: "legacy", preBalances: "[]", postBalances: "[]"},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, []string{
+				fmt.Sprintf(
+					`{"blockTime":1700000000,"meta":{"err":null,"fee":5000,"innerInstructions":[],"preBalances":%s,"postBalances":%s,"preTokenBalances":%s,"postTokenBalances":%s},"slot":100,"transaction":["%s","base64"]}`,
+					v.preBalances, v.postBalances, preTokenBalances, postTokenBalances, base64.StdEncoding.EncodeToString(bz),
+				),
+				`{"context":{"slot":150},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`,
+				// the token account of the recipient cannot be looked up
+				`{"context":{"slot":150},"value":null}`,
+			})
+			defer close()
+
+			cli, err := client.NewClient(&xc_types.ChainConfig{Chain: xc_types.SOL, Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+			info, err := cli.FetchTxInfo(context.Background(), xc_types.TxHash(solTx.Signatures[0].String()))
+			require.NoError(t, err)
+
+			received := false
+			withheld := false
+			for _, tf := range info.Transfers {
+				for _, source := range tf.From {
+					if source.Contract != xc_types.ContractAddress(mint.String()) {
+						continue
+					}
+					require.Equal(t, xcclient.NewAddressName(xc_types.SOL, from.String()), source.Address)
+					if len(tf.To) == 0 {
+						require.Equal(t, "1011", source.Balance.String())
+						withheld = true
+					} else {
+						require.Equal(t, "100000", source.Balance.String())
+						require.Equal(t, "100000", tf.To[0].Balance.String())
+						received = true
+					}
+				}
+			}
+			require.True(t, received)
+			require.True(t, withheld)
+
+			fees := map[xc_types.ContractAddress]string{}
+			for _, fee := range info.Fees {
+				fees[fee.Contract] = fee.Balance.String()
+			}
+			require.Equal(t, map[xc_types.ContractAddress]string{"SOL": "5000", xc_types.ContractAddress(mint.String()): "1011"}, fees)
+		})
+	}
+}
+
+func TestFetchTxInfoPairsInnerTransfers(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to1 := solana.MustPublicKeyFromBase58("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	to2 := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	fromTokenAccount := solana.MustPublicKeyFromBase58("3m8Ct5n9feJFEuuXFb67oqt9XEJeBYkGyEdQRX33QQ5H")
+	toTokenAccount := solana.MustPublicKeyFromBase58("GuXr1c5KyuJxpsoKMDiDBAJZq4GczPMNUmp4UKY9LbAE")
+	mint := solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	// a program that pays out through cross-program invocations
+	program := solana.MustPublicKeyFromBase58("9NmqDDZa7mH1DBM4zeq9cm7VcRn2un1i2TwuMvjBoVhU")
+
+	solTx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			solana.NewInstruction(program, solana.AccountMetaSlice{
+				solana.Meta(from).WRITE().SIGNER(),
+				solana.Meta(to1).WRITE(),
+				solana.Meta(to2).WRITE(),
+				solana.Meta(fromTokenAccount).WRITE(),
+				solana.Meta(toTokenAccount).WRITE(),
+				solana.Meta(mint),
+				solana.Meta(solana.SystemProgramID),
+				solana.Meta(solana.TokenProgramID),
+			}, []byte{1}),
+		},
+		solana.MustHashFromBase58("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"),
+		solana.TransactionPayer(from),
+	)
+	require.NoError(t, err)
+	solTx.Signatures = []solana.Signature{{1}}
+	bz, err := solTx.MarshalBinary()
+	require.NoError(t, err)
+	index := func(account solana.PublicKey) int {
+		for i, key := range solTx.Message.AccountKeys {
+			if key.Equals(account) {
+				return i
+			}
+		}
+		require.Fail(t, "account not found", account.String())
+		return -1
+	}
+	innerInstruction := func(instruction solana.Instruction) string {
+		data, err := instruction.Data()
+		require.NoError(t, err)
+		accounts := []int{}
+		for _, account := range instruction.Accounts() {
+			accounts = append(accounts, index(account.PublicKey))
+		}
+		accountsBz, _ := json.Marshal(accounts)
+		return fmt.Sprintf(`{"programIdIndex":%d,"accounts":%s,"data":"%s","stackHeight":2}`, index(instruction.ProgramID()), accountsBz, solana.Base58(data))
+	}
+	innerInstructions := fmt.Sprintf(`[{"index":0,"instructions":[%s,%s,%s]}]`,
+		innerInstruction(system.NewTransferInstruction(1_000_000, from, to1).Build()),
+		innerInstruction(system.NewTransferInstruction(2_000_000, from, to2).Build()),
+		innerInstruction(token.NewTransferCheckedInstruction(100, 6, fromTokenAccount, mint, toTokenAccount, from, []solana.PublicKey{}).Build()),
+	)
+
+	balances := func(amounts map[solana.PublicKey]uint64) string {
+		values := make([]uint64, len(solTx.Message.AccountKeys))
+		for account, amount := range amounts {
+			values[index(account)] = amount
+		}
+		bz, _ := json.Marshal(values)
+		return string(bz)
+	}
+	tokenBalance := func(account solana.PublicKey, owner solana.PublicKey, amount uint64) string {
+		return fmt.Sprintf(
+			`{"accountIndex":%d,"mint":"%s","owner":"%s","programId":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA","uiTokenAmount":{"amount":"%d","decimals":6,"uiAmount":0,"uiAmountString":"0"}}`,
+			index(account), mint, owner, amount,
+		)
+	}
+	preBalances := balances(map[solana.PublicKey]uint64{from: 10_000_000_000, program: 1})
+	// the program also keeps 1000 lamports, which no instruction that is decoded accounts for
+	postBalances := balances(map[solana.PublicKey]uint64{from: 10_000_000_000 - 5000 - 3_000_000 - 1000, to1: 1_000_000, to2: 2_000_000, program: 1001})
+	preTokenBalances := "[" + tokenBalance(fromTokenAccount, from, 500) + "," + tokenBalance(toTokenAccount, to1, 0) + "]"
+	postTokenBalances := "[" + tokenBalance(fromTokenAccount, from, 400) + "," + tokenBalance(toTokenAccount, to1, 100) + "]"
+
+	server, close := testtypes.MockJSONRPC(t, []string{
+		fmt.Sprintf(
+			`{"blockTime":1700000000,"meta":{"err":null,"fee":5000,"innerInstructions":%s,"preBalances":%s,"postBalances":%s,"preTokenBalances":%s,"postTokenBalances":%s},"slot":100,"transaction":["%s","base64"]}`,
+			innerInstructions, preBalances, postBalances, preTokenBalances, postTokenBalances, base64.StdEncoding.EncodeToString(bz),
+		),
+		`{"context":{"slot":150},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`,
+	})
+	defer close()
+
+	cli, err := client.NewClient(&xc_types.ChainConfig{Chain: xc_types.SOL, Client: &xc_types.ClientConfig{URL: server.URL}})
+	require.NoError(t, err)
+	info, err := cli.FetchTxInfo(context.Background(), xc_types.TxHash(solTx.Signatures[0].String()))
+	require.NoError(t, err)
+
+	type movement struct {
+		from     solana.PublicKey
+		to       solana.PublicKey
+		contract xc_types.ContractAddress
+		amount   string
+	}
+	expected := []movement{
+		// each payout is paired with its recipient
+		{from, to1, "SOL", "1000000"},
+		{from, to2, "SOL", "2000000"},
+		{from, to1, xc_types.ContractAddress(mint.String()), "100"},
+		// the rest of the change in balances
+		{from, program, "SOL", "1000"},
+	}
+	require.Len(t, info.Transfers, len(expected)+1)
+	for i, expected := range expected {
+		tf := info.Transfers[i]
+		require.Len(t, tf.From, 1)
+		require.Len(t, tf.To, 1)
+		require.Equal(t, xcclient.NewAddressName(xc_types.SOL, expected.from.String()), tf.From[0].Address)
+		require.Equal(t, xcclient.NewAddressName(xc_types.SOL, expected.to.String()), tf.To[0].Address)
+		require.Equal(t, expected.contract, tf.From[0].Contract)
+		require.Equal(t, expected.amount, tf.From[0].Balance.String())
+		require.Equal(t, expected.amount, tf.To[0].Balance.String())
+	}
+	fee := info.Transfers[len(expected)]
+	require.Equal(t, "5000", fee.From[0].Balance.String())
+	require.Empty(t, fee.To)
+	require.Len(t, info.Fees, 1)
+	require.Equal(t, "5000", info.Fees[0].Balance.String())
+}
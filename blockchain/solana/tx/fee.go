@@ -0,0 +1,64 @@
+package tx
+
+import (
+	"math/big"
+
+	"github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Base fee charged for each signature
+const LamportsPerSignature = 5000
+
+// Compute units allowed for each instruction, when the limit is not set
+const DefaultInstructionComputeUnits = 200_000
+
+// Max compute units a transaction may use
+const MaxComputeUnitLimit = 1_400_000
+
+// Compute unit prices are in micro-lamports
+const microLamportsPerLamport = 1_000_000
+
+// ComputeUnitLimit returns the compute units the transaction may use, which is either set by
+// an instruction, or is the default for each instruction.
+func (tx Tx) ComputeUnitLimit() uint32 {
+	if limits := tx.GetComputeUnitLimits(); len(limits) > 0 {
+		return limits[len(limits)-1].Units
+	}
+	if tx.SolTx == nil {
+		return 0
+	}
+	count := uint32(0)
+	for _, instruction := range tx.SolTx.Message.Instructions {
+		program, err := tx.SolTx.Message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		if err == nil && !program.Equals(solana.ComputeBudget) {
+			count++
+		}
+	}
+	return min(count*DefaultInstructionComputeUnits, MaxComputeUnitLimit)
+}
+
+// ComputeUnitPrice returns the priority fee per compute unit, in micro-lamports
+func (tx Tx) ComputeUnitPrice() uint64 {
+	if prices := tx.GetComputeUnitPrices(); len(prices) > 0 {
+		return prices[len(prices)-1].MicroLamports
+	}
+	return 0
+}
+
+// Fee returns the base fee for the signatures plus the priority fee, which is charged on the
+// compute unit limit rather than the units consumed.
+func (tx Tx) Fee() types.BigInt {
+	if tx.SolTx == nil {
+		return types.NewBigIntFromUint64(0)
+	}
+	baseFee := new(big.Int).SetUint64(uint64(tx.SolTx.Message.Header.NumRequiredSignatures) * LamportsPerSignature)
+
+	priorityFee := new(big.Int).SetUint64(tx.ComputeUnitPrice())
+	priorityFee.Mul(priorityFee, new(big.Int).SetUint64(uint64(tx.ComputeUnitLimit())))
+	// round up
+	priorityFee.Add(priorityFee, big.NewInt(microLamportsPerLamport-1))
+	priorityFee.Div(priorityFee, big.NewInt(microLamportsPerLamport))
+
+	return types.BigInt(*baseFee.Add(baseFee, priorityFee))
+}
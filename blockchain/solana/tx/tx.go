@@ -5,10 +5,12 @@ import (
 	"fmt"
 
 	bin "github.com/gagliardetto/binary"
+	compute_budget "github.com/gagliardetto/solana-go/programs/compute-budget"
 	"github.com/gagliardetto/solana-go/programs/stake"
 	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/programs/vote"
 
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
 	"github.com/CustodyOne/chainkit/types"
 	"github.com/gagliardetto/solana-go"
 	solana_sdk "github.com/gagliardetto/solana-go"
@@ -31,7 +33,9 @@ func (tx *Tx) Hash() types.TxHash {
 	return types.TxHash("")
 }
 
-// Sighashes returns the tx payload to sign, aka sighashes
+// Sighashes returns the tx payload to sign, aka sighashes.  Each account that must sign, other than
+// the transient signers, signs the same message: the sender first, then the fee payer if another
+// account pays the fee.
 func (tx Tx) Sighashes() ([]types.TxDataToSign, error) {
 	if tx.SolTx == nil {
 		return nil, errors.New("transaction not initialized")
@@ -40,7 +44,32 @@ func (tx Tx) Sighashes() ([]types.TxDataToSign, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to encode message for signing: %w", err)
 	}
-	return []types.TxDataToSign{messageContent}, nil
+	sighashes := []types.TxDataToSign{messageContent}
+	for len(sighashes) < len(tx.signers()) {
+		sighashes = append(sighashes, messageContent)
+	}
+	return sighashes, nil
+}
+
+// Returns the accounts that must sign, other than the transient signers, in the order of their
+// sighashes.  The fee payer is the first signer of the message, but signs last.
+func (tx Tx) signers() []solana.PublicKey {
+	signers := []solana.PublicKey{}
+	for _, signer := range tx.SolTx.Message.Signers() {
+		transient := false
+		for _, transientSigner := range tx.transientSigners {
+			if transientSigner.PublicKey().Equals(signer) {
+				transient = true
+			}
+		}
+		if !transient {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 1 && signers[0].Equals(tx.SolTx.Message.AccountKeys[0]) {
+		signers = append(signers[1:], signers[0])
+	}
+	return signers
 }
 
 // Some instructions on solana require new accounts to sign the transaction
@@ -50,7 +79,8 @@ func (tx *Tx) AddTransientSigner(transientSigner solana.PrivateKey) {
 	tx.transientSigners = append(tx.transientSigners, transientSigner)
 }
 
-// AddSignatures adds a signature to Tx
+// AddSignatures adds a signature to Tx, in the order of the sighashes.  When there is more than one
+// signer, each signature must verify against the signer of its sighash.
 func (tx *Tx) AddSignatures(signatures ...types.TxSignature) error {
 	if tx.SolTx == nil {
 		return errors.New("transaction not initialized")
@@ -62,6 +92,14 @@ func (tx *Tx) AddSignatures(signatures ...types.TxSignature) error {
 		}
 		copy(solSignatures[i][:], signature)
 	}
+	signers := tx.signers()
+	if len(signers) > 1 {
+		// the signatures must be in the order of the signers in the message
+		if len(signatures) != len(signers) {
+			return fmt.Errorf("expected %d signatures, got %d", len(signers), len(signatures))
+		}
+		return tx.setSignatures(signers, solSignatures)
+	}
 	tx.SolTx.Signatures = solSignatures
 	tx.inputSignatures = signatures
 
@@ -78,6 +116,46 @@ func (tx *Tx) AddSignatures(signatures ...types.TxSignature) error {
 	return nil
 }
 
+// Places the signatures of each signer, and of the transient signers, at the position of the signer in the message
+func (tx *Tx) setSignatures(signers []solana.PublicKey, signatures []solana.Signature) error {
+	message, err := tx.SolTx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("unable to encode message for signing: %w", err)
+	}
+	// every signer signs the same message, so only the signature tells them apart
+	for i, signer := range signers {
+		if !signatures[i].Verify(signer, message) {
+			return fmt.Errorf("signature %d is not from %s", i, signer)
+		}
+	}
+	solSignatures := []solana.Signature{}
+	for _, account := range tx.SolTx.Message.Signers() {
+		found := false
+		for i, signer := range signers {
+			if signer.Equals(account) {
+				solSignatures = append(solSignatures, signatures[i])
+				found = true
+			}
+		}
+		for _, transient := range tx.transientSigners {
+			if !found && transient.PublicKey().Equals(account) {
+				sig, err := transient.Sign(message)
+				if err != nil {
+					return fmt.Errorf("unable to sign with transient signer: %v", err)
+				}
+				solSignatures = append(solSignatures, sig)
+				found = true
+			}
+		}
+	}
+	tx.SolTx.Signatures = solSignatures
+	tx.inputSignatures = []types.TxSignature{}
+	for _, sig := range solSignatures {
+		tx.inputSignatures = append(tx.inputSignatures, sig[:])
+	}
+	return nil
+}
+
 func (tx Tx) GetSignatures() []types.TxSignature {
 	return tx.inputSignatures
 }
@@ -97,6 +175,59 @@ func NewTxFrom(solTx *solana.Transaction) *Tx {
 	return tx
 }
 
+// SetLoadedAddresses resolves the address table lookups of a v0 message, using the addresses that
+// were loaded for it (reported in the transaction metadata), so that the instructions can be decoded.
+func (tx *Tx) SetLoadedAddresses(writable solana.PublicKeySlice, readonly solana.PublicKeySlice) error {
+	if tx.SolTx == nil {
+		return errors.New("transaction not initialized")
+	}
+	lookups := tx.SolTx.Message.GetAddressTableLookups()
+	if lookups.NumWritableLookups() != len(writable) || lookups.NumLookups()-lookups.NumWritableLookups() != len(readonly) {
+		return fmt.Errorf("expected %d writable and %d readonly loaded addresses", lookups.NumWritableLookups(), lookups.NumLookups()-lookups.NumWritableLookups())
+	}
+	// rebuild the part of each table that the message uses
+	tables := map[solana.PublicKey]solana.PublicKeySlice{}
+	setAddress := func(table solana.PublicKey, index uint8, address solana.PublicKey) {
+		for len(tables[table]) <= int(index) {
+			tables[table] = append(tables[table], solana.PublicKey{})
+		}
+		tables[table][index] = address
+	}
+	for _, lookup := range lookups {
+		for _, index := range lookup.WritableIndexes {
+			setAddress(lookup.AccountKey, index, writable[0])
+			writable = writable[1:]
+		}
+	}
+	for _, lookup := range lookups {
+		for _, index := range lookup.ReadonlyIndexes {
+			setAddress(lookup.AccountKey, index, readonly[0])
+			readonly = readonly[1:]
+		}
+	}
+	return tx.SolTx.Message.SetAddressTables(tables)
+}
+
+// InnerTx returns the instructions that programs invoked while processing the transaction (reported in the
+// transaction metadata) as a transaction, so that they can be decoded the same as top-level instructions.
+func (tx *Tx) InnerTx(inner []rpc.InnerInstruction) *Tx {
+	if tx.SolTx == nil {
+		return &Tx{}
+	}
+	innerTx := *tx.SolTx
+	innerTx.Message.Instructions = []solana.CompiledInstruction{}
+	for _, group := range inner {
+		for _, instruction := range group.Instructions {
+			innerTx.Message.Instructions = append(innerTx.Message.Instructions, solana.CompiledInstruction{
+				ProgramIDIndex: instruction.ProgramIDIndex,
+				Accounts:       instruction.Accounts,
+				Data:           instruction.Data,
+			})
+		}
+	}
+	return NewTxFrom(&innerTx)
+}
+
 type SolanaInstruction interface {
 	Obtain(def *bin.VariantDefinition) (typeID bin.TypeID, typeName string, impl interface{})
 }
@@ -174,6 +305,62 @@ func (tx Tx) GetSplitStakes() []*stake.Split {
 	return getall[*stake.Split](stake.DecodeInstruction, solana.StakeProgramID, tx.SolTx)
 }
 
+// Like getall, for instructions that solana-go does not decode
+func getallDecoded[T any](
+	decoder func(accounts []*solana.AccountMeta, data []byte) (T, error),
+	solanaProgram solana.PublicKey,
+	solTx *solana.Transaction,
+) []T {
+	results := []T{}
+	if solTx == nil {
+		return results
+	}
+	message := solTx.Message
+	for _, instruction := range message.Instructions {
+		program, err := message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		if err != nil || !program.Equals(solanaProgram) {
+			continue
+		}
+		accs, err := instruction.ResolveInstructionAccounts(&message)
+		if err != nil {
+			continue
+		}
+		inst, err := decoder(accs, instruction.Data)
+		if err != nil {
+			continue
+		}
+		results = append(results, inst)
+	}
+	return results
+}
+
+func (tx Tx) GetMergeStakes() []*solana_types.MergeStake {
+	return getallDecoded(solana_types.DecodeMergeStake, solana.StakeProgramID, tx.SolTx)
+}
+
+func (tx Tx) GetRedelegateStakes() []*solana_types.RedelegateStake {
+	return getallDecoded(solana_types.DecodeRedelegateStake, solana.StakeProgramID, tx.SolTx)
+}
+
+// Deposit or withdrawal of an SPL stake pool
+type StakePoolInstruction struct {
+	Instruction uint8
+	// Lamports deposited, or pool tokens burned
+	Amount   uint64
+	Accounts []*solana.AccountMeta
+}
+
+func (tx Tx) GetStakePoolInstructions(program solana.PublicKey) []*StakePoolInstruction {
+	decoder := func(accounts []*solana.AccountMeta, data []byte) (*StakePoolInstruction, error) {
+		instruction, amount, err := solana_types.DecodeStakePoolInstruction(data)
+		if err != nil {
+			return nil, err
+		}
+		return &StakePoolInstruction{instruction, amount, accounts}, nil
+	}
+	return getallDecoded(decoder, program, tx.SolTx)
+}
+
 func (tx Tx) GetStakeWithdraws() []*stake.Withdraw {
 	return getall[*stake.Withdraw](stake.DecodeInstruction, solana.StakeProgramID, tx.SolTx)
 }
@@ -182,10 +369,26 @@ func (tx Tx) GetSystemTransfers() []*system.Transfer {
 	return getall[*system.Transfer](system.DecodeInstruction, solana.SystemProgramID, tx.SolTx)
 }
 
+func (tx Tx) GetAdvanceNonceAccounts() []*system.AdvanceNonceAccount {
+	return getall[*system.AdvanceNonceAccount](system.DecodeInstruction, solana.SystemProgramID, tx.SolTx)
+}
+
+func (tx Tx) GetInitializeNonceAccounts() []*system.InitializeNonceAccount {
+	return getall[*system.InitializeNonceAccount](system.DecodeInstruction, solana.SystemProgramID, tx.SolTx)
+}
+
 func (tx Tx) GetVoteWithdraws() []*vote.Withdraw {
 	return getall[*vote.Withdraw](vote.DecodeInstruction, solana.VoteProgramID, tx.SolTx)
 }
 
+func (tx Tx) GetComputeUnitLimits() []*compute_budget.SetComputeUnitLimit {
+	return getall[*compute_budget.SetComputeUnitLimit](compute_budget.DecodeInstruction, solana.ComputeBudget, tx.SolTx)
+}
+
+func (tx Tx) GetComputeUnitPrices() []*compute_budget.SetComputeUnitPrice {
+	return getall[*compute_budget.SetComputeUnitPrice](compute_budget.DecodeInstruction, solana.ComputeBudget, tx.SolTx)
+}
+
 func (tx Tx) GetTokenTransferCheckeds() []*token.TransferChecked {
 	return append(
 		getall[*token.TransferChecked](token.DecodeInstruction, solana.TokenProgramID, tx.SolTx),
@@ -193,6 +396,28 @@ func (tx Tx) GetTokenTransferCheckeds() []*token.TransferChecked {
 	)
 }
 
+// Token-2022 transfers from fee-bearing mints, which solana-go does not decode
+func (tx Tx) GetTokenTransferCheckedWithFees() []*solana_types.TransferCheckedWithFee {
+	return getallDecoded(solana_types.DecodeTransferCheckedWithFee, solana.Token2022ProgramID, tx.SolTx)
+}
+
+// Memos from either version of the memo program, in the order of the instructions
+func (tx Tx) GetMemos() []string {
+	memos := []string{}
+	if tx.SolTx == nil {
+		return memos
+	}
+	message := tx.SolTx.Message
+	for _, instruction := range message.Instructions {
+		program, err := message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		if err != nil || !solana_types.IsMemoProgram(program) {
+			continue
+		}
+		memos = append(memos, string(instruction.Data))
+	}
+	return memos
+}
+
 func (tx Tx) GetTokenTransfers() []*token.Transfer {
 	return append(
 		getall[*token.Transfer](token.DecodeInstruction, solana.TokenProgramID, tx.SolTx),
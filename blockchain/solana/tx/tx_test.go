@@ -1,11 +1,14 @@
 package tx_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/CustodyOne/chainkit/blockchain/solana/tx"
 	xc_types "github.com/CustodyOne/chainkit/types"
 	"github.com/gagliardetto/solana-go"
+	compute_budget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
 	"github.com/test-go/testify/require"
 )
 
@@ -59,3 +62,76 @@ func TestTxSerialize(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, serialized, []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0})
 }
+
+func TestTxFee(t *testing.T) {
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := solana.MustPublicKeyFromBase58("83wDqn8DFg5oh1WetQJwcyZySjxGkxWVKf3p39T6GMQH")
+	newTx := func(instructions ...solana.Instruction) tx.Tx {
+		instructions = append(instructions, system.NewTransferInstruction(100, from, to).Build())
+		solTx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(from))
+		require.NoError(t, err)
+		return tx.Tx{SolTx: solTx}
+	}
+
+	// no priority fee
+	tx1 := newTx()
+	require.EqualValues(t, tx.DefaultInstructionComputeUnits, tx1.ComputeUnitLimit())
+	require.EqualValues(t, 0, tx1.ComputeUnitPrice())
+	require.Equal(t, "5000", tx1.Fee().String())
+
+	// priority fee on the default limit
+	tx1 = newTx(compute_budget.NewSetComputeUnitPriceInstruction(1000).Build())
+	require.EqualValues(t, tx.DefaultInstructionComputeUnits, tx1.ComputeUnitLimit())
+	require.EqualValues(t, 1000, tx1.ComputeUnitPrice())
+	require.Equal(t, "5200", tx1.Fee().String())
+
+	// priority fee on an explicit limit, rounded up
+	tx1 = newTx(
+		compute_budget.NewSetComputeUnitPriceInstruction(1000).Build(),
+		compute_budget.NewSetComputeUnitLimitInstruction(300).Build(),
+	)
+	require.EqualValues(t, 300, tx1.ComputeUnitLimit())
+	require.Equal(t, "5001", tx1.Fee().String())
+
+	require.Equal(t, "0", tx.Tx{}.Fee().String())
+}
+
+func TestTxSignaturesWithFeePayer(t *testing.T) {
+	from, _ := solana.NewRandomPrivateKey()
+	feePayer, _ := solana.NewRandomPrivateKey()
+	transient, _ := solana.NewRandomPrivateKey()
+	solTx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewCreateAccountInstruction(100, 0, solana.SystemProgramID, from.PublicKey(), transient.PublicKey()).Build(),
+		},
+		solana.Hash{},
+		solana.TransactionPayer(feePayer.PublicKey()),
+	)
+	require.NoError(t, err)
+	tx1 := tx.Tx{SolTx: solTx}
+	tx1.AddTransientSigner(transient)
+
+	// the sender signs first, then the fee payer
+	sighashes, err := tx1.Sighashes()
+	require.NoError(t, err)
+	require.Len(t, sighashes, 2)
+	fromSig, err := from.Sign(sighashes[0])
+	require.NoError(t, err)
+	feePayerSig, err := feePayer.Sign(sighashes[1])
+	require.NoError(t, err)
+
+	err = tx1.AddSignatures(fromSig[:])
+	require.EqualError(t, err, "expected 2 signatures, got 1")
+
+	// the sighashes are identical, so signatures in the wrong order are rejected
+	err = tx1.AddSignatures(feePayerSig[:], fromSig[:])
+	require.EqualError(t, err, fmt.Sprintf("signature 0 is not from %s", from.PublicKey()))
+
+	err = tx1.AddSignatures(fromSig[:], feePayerSig[:])
+	require.NoError(t, err)
+	// the signatures are in the order of the signers of the message
+	require.Len(t, tx1.SolTx.Signatures, 3)
+	require.Equal(t, feePayerSig, tx1.SolTx.Signatures[0])
+	require.NoError(t, tx1.SolTx.VerifySignatures())
+	require.Len(t, tx1.GetSignatures(), 3)
+}
@@ -3,6 +3,7 @@ package tx_input
 import (
 	"time"
 
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
 	xc_types "github.com/CustodyOne/chainkit/types"
 	"github.com/gagliardetto/solana-go"
 	"github.com/shopspring/decimal"
@@ -23,13 +24,62 @@ type TxInput struct {
 	SourceTokenAccounts []*TokenAccount  `json:"source_token_accounts,omitempty"`
 	PrioritizationFee   xc_types.BigInt  `json:"prioritization_fee,omitempty"`
 	Timestamp           int64            `json:"timestamp,omitempty"`
+	// Token-2022 transfer fee in effect for the mint.  The fee is added on top of the amount,
+	// so that the recipient receives the full amount.
+	TransferFee *solana_types.TransferFee `json:"transfer_fee,omitempty"`
+	// Token-2022 destination account that requires a memo before incoming transfers
+	ToRequiresMemo bool `json:"to_requires_memo,omitempty"`
+	// Memo to include with the transfer
+	Memo string `json:"memo,omitempty"`
+	// Compute units measured by simulating the transaction, with a margin.  The priority fee
+	// is charged on the limit, so this is much cheaper than the default limit.
+	ComputeUnitLimit uint32 `json:"compute_unit_limit,omitempty"`
+	// Priority requested in the transfer arguments, that the PrioritizationFee was looked up for
+	PrioritizationFeePriority xc_types.GasFeePriority `json:"prioritization_fee_priority,omitempty"`
+	// Percentiles of the recent priority fees, from which the fee for another priority is chosen
+	PrioritizationFeePercentiles *PrioritizationFeePercentiles `json:"prioritization_fee_percentiles,omitempty"`
+
+	// Durable nonce account, in which case the RecentBlockHash is the current nonce.  The
+	// transaction then does not expire until the nonce is advanced.
+	NonceAccount   solana.PublicKey `json:"nonce_account,omitempty"`
+	NonceAuthority solana.PublicKey `json:"nonce_authority,omitempty"`
+
+	// Account that pays the fee, and the rent of accounts that the transfer creates, instead of the
+	// sender.  The fee payer must also sign the transaction.
+	FeePayer solana.PublicKey `json:"fee_payer,omitempty"`
+
+	// Resolved address lookup tables, which the builder uses when the transaction would not fit otherwise
+	AddressLookupTables []*AddressLookupTable `json:"address_lookup_tables,omitempty"`
+}
+
+var _ xc_types.TxInputWithMemo = &TxInput{}
+
+type AddressLookupTable struct {
+	Account   solana.PublicKey   `json:"account"`
+	Addresses []solana.PublicKey `json:"addresses"`
 }
 
 func (input *TxInput) GetProtocol() xc_types.Protocol {
 	return xc_types.ProtocolSolana
 }
 
+func (input *TxInput) SetMemo(memo string) {
+	input.Memo = memo
+}
+
 func (input *TxInput) SetGasFeePriority(other xc_types.GasFeePriority) error {
+	if input.PrioritizationFeePriority != "" {
+		// the fee is already the percentile of the recent fees for the requested priority
+		return nil
+	}
+	if input.PrioritizationFeePercentiles != nil {
+		fee, err := input.PrioritizationFeePercentiles.ForPriority(other)
+		if err != nil {
+			return err
+		}
+		input.PrioritizationFee = fee
+		return nil
+	}
 	multiplier, err := other.GetDefault()
 	if err != nil {
 		return err
@@ -39,6 +89,35 @@ func (input *TxInput) SetGasFeePriority(other xc_types.GasFeePriority) error {
 	return nil
 }
 
+// Priority fees paid by recent transactions, in micro-lamports per compute unit
+type PrioritizationFeePercentiles struct {
+	Low            xc_types.BigInt `json:"low"`
+	Market         xc_types.BigInt `json:"market"`
+	Aggressive     xc_types.BigInt `json:"aggressive"`
+	VeryAggressive xc_types.BigInt `json:"very_aggressive"`
+}
+
+// ForPriority returns the percentile of the recent fees for the priority.  Custom priorities
+// multiply the market fee.
+func (fees *PrioritizationFeePercentiles) ForPriority(priority xc_types.GasFeePriority) (xc_types.BigInt, error) {
+	switch priority {
+	case xc_types.Low:
+		return fees.Low, nil
+	case xc_types.Market, "":
+		return fees.Market, nil
+	case xc_types.Aggressive:
+		return fees.Aggressive, nil
+	case xc_types.VeryAggressive:
+		return fees.VeryAggressive, nil
+	}
+	multiplier, err := priority.AsCustom()
+	if err != nil {
+		return xc_types.BigInt{}, err
+	}
+	multipliedFee := multiplier.Mul(decimal.NewFromBigInt(fees.Market.Int(), 0)).BigInt()
+	return xc_types.BigInt(*multipliedFee), nil
+}
+
 type TokenAccount struct {
 	Account solana.PublicKey `json:"account,omitempty"`
 	Balance xc_types.BigInt  `json:"balance,omitempty"`
@@ -64,7 +143,36 @@ func (input *TxInput) GetLimitedPrioritizationFee(chain *xc_types.ChainConfig) u
 	return fee
 }
 
+// UsesDurableNonce returns whether the transaction uses a durable nonce rather than a recent block hash
+func (input *TxInput) UsesDurableNonce() bool {
+	return !input.NonceAccount.IsZero()
+}
+
+// InAddressLookupTable returns whether the account is in one of the address lookup tables
+func (input *TxInput) InAddressLookupTable(account solana.PublicKey) bool {
+	for _, table := range input.AddressLookupTables {
+		for _, address := range table.Addresses {
+			if address.Equals(account) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetFeePayer returns the account that pays the fee, which is the sender unless another account is set
+func (input *TxInput) GetFeePayer(from solana.PublicKey) solana.PublicKey {
+	if !input.FeePayer.IsZero() {
+		return input.FeePayer
+	}
+	return from
+}
+
 func (input *TxInput) IndependentOf(other xc_types.TxInput) (independent bool) {
+	// transactions using the same nonce conflict, as only one of them can advance it
+	if oldInput, ok := other.(*TxInput); ok && input.UsesDurableNonce() && oldInput.UsesDurableNonce() {
+		return !(input.NonceAccount.Equals(oldInput.NonceAccount) && input.RecentBlockHash.Equals(oldInput.RecentBlockHash))
+	}
 	// no conflicts on solana as txs are easily parallelizeable through
 	// the recent-block-hash mechanism.
 	return true
@@ -76,7 +184,13 @@ func (input *TxInput) SafeFromDoubleSend(others ...xc_types.TxInput) (safe bool)
 	}
 	for _, other := range others {
 		oldInput, ok := other.(*TxInput)
-		if ok {
+		if ok && oldInput.UsesDurableNonce() {
+			// a durable nonce does not expire, so only one of the transactions using the same nonce
+			// can be included.  If the nonce has advanced, the old transaction may have been included.
+			if !input.NonceAccount.Equals(oldInput.NonceAccount) || !oldInput.RecentBlockHash.Equals(input.RecentBlockHash) {
+				return false
+			}
+		} else if ok {
 			diff := input.Timestamp - oldInput.Timestamp
 			// solana blockhash lasts only ~1 minute -> we'll require a 5 min period
 			// and different hash to consider it safe from double-send.
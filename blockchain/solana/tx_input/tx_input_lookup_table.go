@@ -0,0 +1,12 @@
+package tx_input
+
+// Input to create a new address lookup table
+type CreateAddressLookupTableInput struct {
+	TxInput
+	// The lookup table address is derived from a recent slot
+	RecentSlot uint64 `json:"recent_slot"`
+}
+
+func NewCreateAddressLookupTableInput() *CreateAddressLookupTableInput {
+	return &CreateAddressLookupTableInput{}
+}
@@ -0,0 +1,18 @@
+package tx_input
+
+import (
+	"github.com/gagliardetto/solana-go"
+)
+
+// Input to create and initialize a new durable nonce account
+type CreateNonceAccountInput struct {
+	TxInput
+	// The new nonce account to create
+	NonceKey solana.PrivateKey `json:"nonce_key"`
+	// Lamports needed for the nonce account to be rent exempt
+	RentExemptLamports uint64 `json:"rent_exempt_lamports"`
+}
+
+func NewCreateNonceAccountInput() *CreateNonceAccountInput {
+	return &CreateNonceAccountInput{}
+}
@@ -0,0 +1,92 @@
+package tx_input
+
+import (
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+)
+
+// What is done with a token account to reclaim its rent
+type ReclaimAction string
+
+const (
+	// Keep the associated token account, which holds the balance
+	ReclaimNone ReclaimAction = ""
+	// Close the empty account
+	ReclaimClose ReclaimAction = "close"
+	// Transfer the balance to the associated token account, then close the account
+	ReclaimMerge ReclaimAction = "merge"
+	// Close the wrapped SOL account, which returns the balance as SOL
+	ReclaimUnwrap ReclaimAction = "unwrap"
+)
+
+// Input to close, merge and unwrap the token accounts of an address, to reclaim their rent
+type ReclaimRentInput struct {
+	TxInput
+	TokenAccounts []*ReclaimableTokenAccount `json:"token_accounts"`
+}
+
+type ReclaimableTokenAccount struct {
+	Account      solana.PublicKey `json:"account"`
+	Mint         solana.PublicKey `json:"mint"`
+	TokenProgram solana.PublicKey `json:"token_program"`
+	Decimals     uint8            `json:"decimals"`
+	// Token balance of the account
+	Balance xc_types.BigInt `json:"balance"`
+	// Lamports held by the account, which for wrapped SOL includes the balance
+	Lamports uint64 `json:"lamports"`
+	// How the account is reclaimed, decided from all of the token accounts of the owner
+	Action ReclaimAction `json:"action,omitempty"`
+}
+
+func NewReclaimRentInput() *ReclaimRentInput {
+	return &ReclaimRentInput{}
+}
+
+// Action returns how the account is reclaimed.  Balances are merged into the associated token account of the
+// owner, which is kept unless it is empty and nothing is merged into it.
+func (input *ReclaimRentInput) Action(owner solana.PublicKey, account *ReclaimableTokenAccount) ReclaimAction {
+	if account.Mint.Equals(solana.SolMint) {
+		return ReclaimUnwrap
+	}
+	if !account.Account.Equals(account.AssociatedTokenAccount(owner)) {
+		if account.Balance.Sign() == 0 {
+			return ReclaimClose
+		}
+		return ReclaimMerge
+	}
+	if account.Balance.Sign() != 0 {
+		return ReclaimNone
+	}
+	for _, other := range input.TokenAccounts {
+		if other.Mint.Equals(account.Mint) && other.Balance.Sign() != 0 {
+			return ReclaimNone
+		}
+	}
+	return ReclaimClose
+}
+
+// SetActions decides how each account is reclaimed.  This must be done before any accounts are left out
+// of the input, as whether an associated token account is kept depends on the others of the same mint.
+func (input *ReclaimRentInput) SetActions(owner solana.PublicKey) {
+	for _, account := range input.TokenAccounts {
+		account.Action = input.Action(owner, account)
+	}
+}
+
+// AssociatedTokenAccount returns the account that balances are merged into
+func (account *ReclaimableTokenAccount) AssociatedTokenAccount(owner solana.PublicKey) solana.PublicKey {
+	ata, err := solana_types.FindAssociatedTokenAddress(owner.String(), account.Mint.String(), account.TokenProgram)
+	if err != nil {
+		return solana.PublicKey{}
+	}
+	return solana.MustPublicKeyFromBase58(ata)
+}
+
+// Rent returns the lamports reclaimed by closing the account, apart from any unwrapped balance
+func (account *ReclaimableTokenAccount) Rent() uint64 {
+	if account.Mint.Equals(solana.SolMint) && account.Lamports >= account.Balance.Uint64() {
+		return account.Lamports - account.Balance.Uint64()
+	}
+	return account.Lamports
+}
@@ -0,0 +1,58 @@
+package tx_input
+
+import (
+	solana_types "github.com/CustodyOne/chainkit/blockchain/solana/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Deposit SOL into an SPL stake pool, minting pool tokens
+type StakePoolStakeInput struct {
+	TxInput
+	StakePool solana_types.StakePoolAccounts `json:"stake_pool"`
+}
+
+// Burn pool tokens of an SPL stake pool.  The SOL is withdrawn instantly from the reserve of the pool
+// if the validator stake is not set.  Otherwise the stake is split from the validator stake into a new
+// stake account and deactivated, which is withdrawn once inactive.
+type StakePoolUnstakeInput struct {
+	TxInput
+	StakePool  solana_types.StakePoolAccounts `json:"stake_pool"`
+	PoolTokens uint64                         `json:"pool_tokens"`
+
+	ValidatorStake solana.PublicKey `json:"validator_stake,omitempty"`
+	// The new staking account to create for a delayed withdrawal.  Its public key must be kept to withdraw
+	// the stake once inactive.
+	StakingKey        solana.PrivateKey `json:"staking_key,omitempty"`
+	RentExemptReserve uint64            `json:"rent_exempt_reserve,omitempty"`
+}
+
+var _ xc_types.TxVariantInput = &StakePoolStakeInput{}
+var _ xc_types.StakeTxInput = &StakePoolStakeInput{}
+var _ xc_types.TxVariantInput = &StakePoolUnstakeInput{}
+var _ xc_types.UnstakeTxInput = &StakePoolUnstakeInput{}
+
+func NewStakePoolStakeInput() *StakePoolStakeInput {
+	return &StakePoolStakeInput{}
+}
+func NewStakePoolUnstakeInput() *StakePoolUnstakeInput {
+	return &StakePoolUnstakeInput{}
+}
+
+func (*StakePoolStakeInput) GetVariant() xc_types.TxVariantInputType {
+	return xc_types.NewStakingInputType(xc_types.ProtocolSolana, string(xc_types.SplStakePool))
+}
+func (*StakePoolUnstakeInput) GetVariant() xc_types.TxVariantInputType {
+	return xc_types.NewUnstakingInputType(xc_types.ProtocolSolana, string(xc_types.SplStakePool))
+}
+
+// Mark as valid for staking transactions
+func (*StakePoolStakeInput) Staking() {}
+
+// Mark as valid for un-staking transactions
+func (*StakePoolUnstakeInput) Unstaking() {}
+
+// IsInstant is set when the SOL is withdrawn from the reserve of the pool
+func (input *StakePoolUnstakeInput) IsInstant() bool {
+	return input.ValidatorStake.IsZero()
+}
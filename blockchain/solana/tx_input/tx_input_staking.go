@@ -61,3 +61,44 @@ func (*WithdrawInput) Withdrawing() {}
 func (*WithdrawInput) GetVariant() xc_types.TxVariantInputType {
 	return xc_types.NewWithdrawingInputType(xc_types.ProtocolSolana, string(xc_types.Native))
 }
+
+// Input to merge stake accounts into one, returning the rent of the merged accounts
+type MergeStakeInput struct {
+	TxInput
+	Destination solana.PublicKey `json:"destination"`
+	// Compatible stake accounts to merge into the destination, which are closed
+	Sources []solana.PublicKey `json:"sources"`
+}
+
+// Input to split an amount of a stake account into a new stake account
+type SplitStakeInput struct {
+	TxInput
+	// The new staking account to create
+	StakingKey   solana.PrivateKey `json:"staking_key"`
+	StakeAccount solana.PublicKey  `json:"stake_account"`
+	// Lamports held by the stake account, including the rent exempt reserve
+	Lamports          uint64 `json:"lamports"`
+	RentExemptReserve uint64 `json:"rent_exempt_reserve"`
+}
+
+// How a stake account is moved to a new validator
+type RedelegateAction string
+
+const (
+	// The stake is inactive, so is delegated to the new validator directly
+	RedelegateDelegate RedelegateAction = "delegate"
+	// The active stake is moved into a new stake account with the redelegate instruction
+	RedelegateMove RedelegateAction = "redelegate"
+	// The redelegate instruction is not available, so the stake is deactivated to be delegated once inactive
+	RedelegateDeactivate RedelegateAction = "deactivate"
+)
+
+// Input to move a stake account to a new validator
+type RedelegateStakeInput struct {
+	TxInput
+	// The new staking account to create when redelegating active stake
+	StakingKey           solana.PrivateKey `json:"staking_key"`
+	StakeAccount         solana.PublicKey  `json:"stake_account"`
+	ValidatorVoteAccount solana.PublicKey  `json:"validator_vote_account"`
+	Action               RedelegateAction  `json:"action"`
+}
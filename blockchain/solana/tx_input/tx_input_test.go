@@ -79,6 +79,50 @@ func TestTxInputConflicts(t *testing.T) {
 			independent:     true,
 			doubleSpendSafe: false,
 		},
+		{
+			// the nonce may have advanced because the old transaction was included
+			newInput: &TxInput{
+				RecentBlockHash: solana.Hash([32]byte{2}),
+				NonceAccount:    solana.PublicKey([32]byte{9}),
+				Timestamp:       startTime,
+			},
+			oldInput: &TxInput{
+				RecentBlockHash: solana.Hash([32]byte{1}),
+				NonceAccount:    solana.PublicKey([32]byte{9}),
+				Timestamp:       startTime - int64(SafetyTimeoutMargin.Seconds()) - 1,
+			},
+			independent:     true,
+			doubleSpendSafe: false,
+		},
+		{
+			// same nonce, only one of the transactions can be included
+			newInput: &TxInput{
+				RecentBlockHash: solana.Hash([32]byte{1}),
+				NonceAccount:    solana.PublicKey([32]byte{9}),
+				Timestamp:       startTime,
+			},
+			oldInput: &TxInput{
+				RecentBlockHash: solana.Hash([32]byte{1}),
+				NonceAccount:    solana.PublicKey([32]byte{9}),
+				Timestamp:       startTime - int64(SafetyTimeoutMargin.Seconds()) - 1,
+			},
+			independent:     false,
+			doubleSpendSafe: true,
+		},
+		{
+			// durable nonces do not expire, so the timeout does not apply
+			newInput: &TxInput{
+				RecentBlockHash: solana.Hash([32]byte{1}),
+				Timestamp:       startTime,
+			},
+			oldInput: &TxInput{
+				RecentBlockHash: solana.Hash([32]byte{2}),
+				NonceAccount:    solana.PublicKey([32]byte{9}),
+				Timestamp:       startTime - int64(SafetyTimeoutMargin.Seconds()) - 1,
+			},
+			independent:     true,
+			doubleSpendSafe: false,
+		},
 	}
 	for i, v := range vectors {
 		newBz, _ := json.Marshal(v.newInput)
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+)
+
+// Address lookup table instructions, which solana-go does not provide builders for
+const (
+	createLookupTableInstruction uint32 = 0
+	extendLookupTableInstruction uint32 = 2
+)
+
+// FindAddressLookupTableAddress returns the lookup table that the authority creates at the recent slot
+func FindAddressLookupTableAddress(authority solana.PublicKey, recentSlot uint64) (solana.PublicKey, uint8, error) {
+	return solana.FindProgramAddress(
+		[][]byte{
+			authority[:],
+			binary.LittleEndian.AppendUint64(nil, recentSlot),
+		},
+		solana.AddressLookupTableProgramID,
+	)
+}
+
+// NewCreateLookupTableInstruction creates a new lookup table at the address derived from the authority and recent slot
+func NewCreateLookupTableInstruction(authority solana.PublicKey, payer solana.PublicKey, recentSlot uint64) (solana.Instruction, solana.PublicKey, error) {
+	table, bump, err := FindAddressLookupTableAddress(authority, recentSlot)
+	if err != nil {
+		return nil, solana.PublicKey{}, err
+	}
+	data := binary.LittleEndian.AppendUint32(nil, createLookupTableInstruction)
+	data = binary.LittleEndian.AppendUint64(data, recentSlot)
+	data = append(data, bump)
+	return solana.NewInstruction(
+		solana.AddressLookupTableProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(table).WRITE(),
+			solana.Meta(authority).SIGNER(),
+			solana.Meta(payer).WRITE().SIGNER(),
+			solana.Meta(solana.SystemProgramID),
+		},
+		data,
+	), table, nil
+}
+
+// NewExtendLookupTableInstruction appends addresses to a lookup table
+func NewExtendLookupTableInstruction(table solana.PublicKey, authority solana.PublicKey, payer solana.PublicKey, addresses []solana.PublicKey) solana.Instruction {
+	data := binary.LittleEndian.AppendUint32(nil, extendLookupTableInstruction)
+	data = binary.LittleEndian.AppendUint64(data, uint64(len(addresses)))
+	for _, address := range addresses {
+		data = append(data, address[:]...)
+	}
+	return solana.NewInstruction(
+		solana.AddressLookupTableProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(table).WRITE(),
+			solana.Meta(authority).SIGNER(),
+			solana.Meta(payer).WRITE().SIGNER(),
+			solana.Meta(solana.SystemProgramID),
+		},
+		data,
+	)
+}
+
+// ParseAddressLookupTable decodes a lookup table account, which must not be deactivated
+func ParseAddressLookupTable(data []byte) (*addresslookuptable.AddressLookupTableState, error) {
+	state, err := addresslookuptable.DecodeAddressLookupTableState(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address lookup table: %v", err)
+	}
+	if !state.IsActive() {
+		return nil, fmt.Errorf("address lookup table is deactivated")
+	}
+	return state, nil
+}
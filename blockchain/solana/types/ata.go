@@ -28,3 +28,29 @@ func FindAssociatedTokenAddress(addr string, contract string, tokenProgram solan
 	}
 	return associatedAddr.String(), nil
 }
+
+// Associated token account instruction that succeeds if the account already exists, which solana-go does not provide
+const createIdempotentInstruction byte = 1
+
+// NewCreateIdempotentAssociatedTokenAccountInstruction creates the associated token account, if it does not already exist
+func NewCreateIdempotentAssociatedTokenAccountInstruction(payer solana.PublicKey, owner solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey) (solana.Instruction, error) {
+	if tokenProgram.IsZero() {
+		tokenProgram = solana.TokenProgramID
+	}
+	ata, err := FindAssociatedTokenAddress(owner.String(), mint.String(), tokenProgram)
+	if err != nil {
+		return nil, err
+	}
+	return solana.NewInstruction(
+		solana.SPLAssociatedTokenAccountProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(payer).WRITE().SIGNER(),
+			solana.Meta(solana.MustPublicKeyFromBase58(ata)).WRITE(),
+			solana.Meta(owner),
+			solana.Meta(mint),
+			solana.Meta(solana.SystemProgramID),
+			solana.Meta(tokenProgram),
+		},
+		[]byte{createIdempotentInstruction},
+	), nil
+}
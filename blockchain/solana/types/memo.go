@@ -0,0 +1,23 @@
+package types
+
+import "github.com/gagliardetto/solana-go"
+
+// NewMemoInstruction creates a memo signed by the signer.  The memo program takes the raw UTF-8
+// text as the instruction data, whereas solana-go's memo builder prefixes it with the length.
+func NewMemoInstruction(text string, signer solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(
+		solana.MemoProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(signer).SIGNER(),
+		},
+		[]byte(text),
+	)
+}
+
+// The original memo program, which some wallets and exchanges still use
+var MemoV1ProgramID = solana.MustPublicKeyFromBase58("Memo1UhkJRfHyvLMcVucJwxXeuD728EqVDDwQDxFMNo")
+
+// IsMemoProgram returns if the program is either version of the memo program
+func IsMemoProgram(program solana.PublicKey) bool {
+	return program.Equals(solana.MemoProgramID) || program.Equals(MemoV1ProgramID)
+}
@@ -0,0 +1,28 @@
+package types
+
+// NonceAccount represents a durable nonce account, as returned with jsonParsed encoding
+type NonceAccount struct {
+	Parsed  NonceParsed `json:"parsed"`
+	Program string      `json:"program"`
+	Space   int         `json:"space"`
+}
+
+// NonceParsed represents the parsed data section
+type NonceParsed struct {
+	Info NonceInfo `json:"info"`
+	// "initialized" once the account can be used
+	Type string `json:"type"`
+}
+
+// NonceInfo represents the info section
+type NonceInfo struct {
+	// The account that must sign to advance the nonce
+	Authority string `json:"authority"`
+	// The current nonce, used in place of a recent block hash
+	Blockhash     string        `json:"blockhash"`
+	FeeCalculator FeeCalculator `json:"feeCalculator"`
+}
+
+type FeeCalculator struct {
+	LamportsPerSignature string `json:"lamportsPerSignature"`
+}
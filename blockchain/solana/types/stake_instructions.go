@@ -0,0 +1,90 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Stake program instructions that solana-go does not provide builders for
+const (
+	stakeMergeInstruction      uint32 = 7
+	stakeRedelegateInstruction uint32 = 15
+)
+
+// NewMergeStakeInstruction merges the source stake account into the destination, closing the source.  The
+// accounts must have the same authorities and lockup, and be either inactive or delegated to the same validator.
+func NewMergeStakeInstruction(destination solana.PublicKey, source solana.PublicKey, stakeAuthority solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(
+		solana.StakeProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(destination).WRITE(),
+			solana.Meta(source).WRITE(),
+			solana.Meta(solana.SysVarClockPubkey),
+			solana.Meta(solana.SysVarStakeHistoryPubkey),
+			solana.Meta(stakeAuthority).SIGNER(),
+		},
+		binary.LittleEndian.AppendUint32(nil, stakeMergeInstruction),
+	)
+}
+
+// NewRedelegateStakeInstruction moves the active stake to a new validator, into the uninitialized stake account,
+// which must already be allocated and assigned to the stake program.  Clusters may have the instruction disabled.
+func NewRedelegateStakeInstruction(stakeAccount solana.PublicKey, newStakeAccount solana.PublicKey, voteAccount solana.PublicKey, stakeAuthority solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(
+		solana.StakeProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(stakeAccount).WRITE(),
+			solana.Meta(newStakeAccount).WRITE(),
+			solana.Meta(voteAccount),
+			solana.Meta(solana.SysVarStakeConfigPubkey),
+			solana.Meta(stakeAuthority).SIGNER(),
+		},
+		binary.LittleEndian.AppendUint32(nil, stakeRedelegateInstruction),
+	)
+}
+
+type MergeStake struct {
+	Destination    solana.PublicKey
+	Source         solana.PublicKey
+	StakeAuthority solana.PublicKey
+}
+
+// DecodeMergeStake decodes the instruction, returning an error if it is another instruction
+func DecodeMergeStake(accounts []*solana.AccountMeta, data []byte) (*MergeStake, error) {
+	if len(data) < 4 || binary.LittleEndian.Uint32(data) != stakeMergeInstruction {
+		return nil, errors.New("not a merge stake instruction")
+	}
+	if len(accounts) < 5 {
+		return nil, errors.New("invalid merge stake instruction")
+	}
+	return &MergeStake{
+		Destination:    accounts[0].PublicKey,
+		Source:         accounts[1].PublicKey,
+		StakeAuthority: accounts[4].PublicKey,
+	}, nil
+}
+
+type RedelegateStake struct {
+	StakeAccount    solana.PublicKey
+	NewStakeAccount solana.PublicKey
+	VoteAccount     solana.PublicKey
+	StakeAuthority  solana.PublicKey
+}
+
+// DecodeRedelegateStake decodes the instruction, returning an error if it is another instruction
+func DecodeRedelegateStake(accounts []*solana.AccountMeta, data []byte) (*RedelegateStake, error) {
+	if len(data) < 4 || binary.LittleEndian.Uint32(data) != stakeRedelegateInstruction {
+		return nil, errors.New("not a redelegate stake instruction")
+	}
+	if len(accounts) < 5 {
+		return nil, errors.New("invalid redelegate stake instruction")
+	}
+	return &RedelegateStake{
+		StakeAccount:    accounts[0].PublicKey,
+		NewStakeAccount: accounts[1].PublicKey,
+		VoteAccount:     accounts[2].PublicKey,
+		StakeAuthority:  accounts[4].PublicKey,
+	}, nil
+}
@@ -0,0 +1,349 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// The SPL stake pool program.  Pools may be deployed under other program IDs, so the owner of the
+// pool account should be used.
+var StakePoolProgramID = solana.MustPublicKeyFromBase58("SPoo1Ku8WFXoNDMHPsrGSTSG1Y47rzgn41SLUNakuHy")
+
+// Stake pool instructions, which solana-go does not provide builders for
+const (
+	StakePoolWithdrawStakeInstruction uint8 = 10
+	StakePoolDepositSolInstruction    uint8 = 14
+	StakePoolWithdrawSolInstruction   uint8 = 16
+)
+
+const (
+	stakePoolAccountType     = 1
+	validatorListAccountType = 2
+	validatorStakeInfoSize   = 73
+)
+
+// Stake that must remain delegated in a validator stake account of a pool, in addition to its rent
+const StakePoolMinimumActiveStake = 1_000_000
+
+// Fee charged by a stake pool, as a fraction of the pool tokens
+type StakePoolFee struct {
+	Denominator uint64 `json:"denominator"`
+	Numerator   uint64 `json:"numerator"`
+}
+
+// Apply returns the fee charged on the amount, which is rounded up
+func (fee StakePoolFee) Apply(amount uint64) uint64 {
+	if fee.Denominator == 0 || fee.Numerator == 0 {
+		return 0
+	}
+	return mulDivCeil(amount, fee.Numerator, fee.Denominator)
+}
+
+// StakePool is the state of an SPL stake pool, omitting the fields that deposits and withdrawals do not use
+type StakePool struct {
+	StakeDepositAuthority      solana.PublicKey
+	ValidatorList              solana.PublicKey
+	ReserveStake               solana.PublicKey
+	PoolMint                   solana.PublicKey
+	ManagerFeeAccount          solana.PublicKey
+	TokenProgram               solana.PublicKey
+	TotalLamports              uint64
+	PoolTokenSupply            uint64
+	LastUpdateEpoch            uint64
+	PreferredWithdrawValidator *solana.PublicKey
+	StakeWithdrawalFee         StakePoolFee
+	SolDepositAuthority        *solana.PublicKey
+	SolDepositFee              StakePoolFee
+	SolWithdrawAuthority       *solana.PublicKey
+	SolWithdrawalFee           StakePoolFee
+}
+
+// Reads the borsh encoded fields of the stake pool accounts
+type borshReader struct {
+	data []byte
+	err  error
+}
+
+func (reader *borshReader) read(n int) []byte {
+	if reader.err != nil {
+		return make([]byte, n)
+	}
+	if len(reader.data) < n {
+		reader.err = errors.New("stake pool account is too short")
+		return make([]byte, n)
+	}
+	value := reader.data[:n]
+	reader.data = reader.data[n:]
+	return value
+}
+
+func (reader *borshReader) u8() uint8   { return reader.read(1)[0] }
+func (reader *borshReader) u32() uint32 { return binary.LittleEndian.Uint32(reader.read(4)) }
+func (reader *borshReader) u64() uint64 { return binary.LittleEndian.Uint64(reader.read(8)) }
+
+func (reader *borshReader) pubkey() solana.PublicKey {
+	return solana.PublicKeyFromBytes(reader.read(32))
+}
+
+func (reader *borshReader) optionalPubkey() *solana.PublicKey {
+	if reader.u8() == 0 {
+		return nil
+	}
+	pubkey := reader.pubkey()
+	return &pubkey
+}
+
+func (reader *borshReader) fee() StakePoolFee {
+	return StakePoolFee{
+		Denominator: reader.u64(),
+		Numerator:   reader.u64(),
+	}
+}
+
+// Skips a fee that takes effect in a future epoch, which is encoded as an enum
+func (reader *borshReader) skipFutureFee() {
+	if reader.u8() != 0 {
+		reader.fee()
+	}
+}
+
+// ParseStakePool decodes the stake pool account
+func ParseStakePool(data []byte) (*StakePool, error) {
+	reader := &borshReader{data: data}
+	if accountType := reader.u8(); accountType != stakePoolAccountType {
+		return nil, fmt.Errorf("invalid stake pool account type %d", accountType)
+	}
+	pool := &StakePool{}
+	// manager and staker
+	reader.read(64)
+	pool.StakeDepositAuthority = reader.pubkey()
+	// withdraw bump seed
+	reader.u8()
+	pool.ValidatorList = reader.pubkey()
+	pool.ReserveStake = reader.pubkey()
+	pool.PoolMint = reader.pubkey()
+	pool.ManagerFeeAccount = reader.pubkey()
+	pool.TokenProgram = reader.pubkey()
+	pool.TotalLamports = reader.u64()
+	pool.PoolTokenSupply = reader.u64()
+	pool.LastUpdateEpoch = reader.u64()
+	// lockup
+	reader.read(48)
+	// epoch fee
+	reader.fee()
+	reader.skipFutureFee()
+	// preferred deposit validator
+	reader.optionalPubkey()
+	pool.PreferredWithdrawValidator = reader.optionalPubkey()
+	// stake deposit fee
+	reader.fee()
+	pool.StakeWithdrawalFee = reader.fee()
+	reader.skipFutureFee()
+	// stake referral fee
+	reader.u8()
+	pool.SolDepositAuthority = reader.optionalPubkey()
+	pool.SolDepositFee = reader.fee()
+	// sol referral fee
+	reader.u8()
+	pool.SolWithdrawAuthority = reader.optionalPubkey()
+	pool.SolWithdrawalFee = reader.fee()
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return pool, nil
+}
+
+// LamportsForPoolTokens returns the lamports that the pool tokens are worth, which is rounded down
+func (pool *StakePool) LamportsForPoolTokens(poolTokens uint64) uint64 {
+	if pool.PoolTokenSupply == 0 {
+		return 0
+	}
+	lamports := new(big.Int).Mul(new(big.Int).SetUint64(poolTokens), new(big.Int).SetUint64(pool.TotalLamports))
+	lamports.Div(lamports, new(big.Int).SetUint64(pool.PoolTokenSupply))
+	if !lamports.IsUint64() {
+		return 0
+	}
+	return lamports.Uint64()
+}
+
+// PoolTokensForWithdrawal returns the pool tokens to burn to withdraw at least the lamports, after the fee.
+// The fee is rounded up and the exchange rate is rounded down, so the pool tokens are rounded up for both.
+func (pool *StakePool) PoolTokensForWithdrawal(lamports uint64, fee StakePoolFee) (uint64, error) {
+	if lamports == 0 {
+		return 0, errors.New("must withdraw more than 0 lamports")
+	}
+	if pool.TotalLamports == 0 || pool.PoolTokenSupply == 0 {
+		return 0, errors.New("stake pool has no lamports or pool tokens to withdraw")
+	}
+	netTokens := ceilDiv(
+		new(big.Int).Mul(new(big.Int).SetUint64(lamports), new(big.Int).SetUint64(pool.PoolTokenSupply)),
+		new(big.Int).SetUint64(pool.TotalLamports),
+	)
+	poolTokens := netTokens
+	if fee.Denominator > 0 && fee.Numerator > 0 {
+		if fee.Numerator >= fee.Denominator {
+			return 0, fmt.Errorf("withdrawal fee of %d/%d leaves nothing to withdraw", fee.Numerator, fee.Denominator)
+		}
+		poolTokens = ceilDiv(
+			new(big.Int).Mul(netTokens, new(big.Int).SetUint64(fee.Denominator)),
+			new(big.Int).SetUint64(fee.Denominator-fee.Numerator),
+		)
+	}
+	if !poolTokens.IsUint64() {
+		return 0, fmt.Errorf("withdrawing %d lamports exceeds the pool token supply", lamports)
+	}
+	return poolTokens.Uint64(), nil
+}
+
+func ceilDiv(a *big.Int, b *big.Int) *big.Int {
+	result := new(big.Int).Add(a, new(big.Int).Sub(b, big.NewInt(1)))
+	return result.Div(result, b)
+}
+
+// Validator stake in a stake pool
+type ValidatorStakeInfo struct {
+	ActiveStakeLamports    uint64
+	TransientStakeLamports uint64
+	ValidatorSeedSuffix    uint32
+	// 0 when the validator is active in the pool
+	Status      uint8
+	VoteAccount solana.PublicKey
+}
+
+// ParseValidatorList decodes the validators of a stake pool
+func ParseValidatorList(data []byte) ([]*ValidatorStakeInfo, error) {
+	reader := &borshReader{data: data}
+	if accountType := reader.u8(); accountType != validatorListAccountType {
+		return nil, fmt.Errorf("invalid validator list account type %d", accountType)
+	}
+	// max validators
+	reader.u32()
+	count := int(reader.u32())
+	if reader.err != nil || len(reader.data) < count*validatorStakeInfoSize {
+		return nil, errors.New("validator list account is too short")
+	}
+	validators := []*ValidatorStakeInfo{}
+	for i := 0; i < count; i++ {
+		validator := &ValidatorStakeInfo{}
+		validator.ActiveStakeLamports = reader.u64()
+		validator.TransientStakeLamports = reader.u64()
+		// last update epoch, transient seed suffix and padding
+		reader.read(20)
+		validator.ValidatorSeedSuffix = reader.u32()
+		validator.Status = reader.u8()
+		validator.VoteAccount = reader.pubkey()
+		validators = append(validators, validator)
+	}
+	return validators, nil
+}
+
+// FindStakePoolWithdrawAuthority returns the authority of the stake pool over its stake and mint
+func FindStakePoolWithdrawAuthority(program solana.PublicKey, stakePool solana.PublicKey) (solana.PublicKey, error) {
+	authority, _, err := solana.FindProgramAddress([][]byte{stakePool[:], []byte("withdraw")}, program)
+	return authority, err
+}
+
+// FindValidatorStakeAccount returns the stake account of the pool that is delegated to the validator
+func FindValidatorStakeAccount(program solana.PublicKey, stakePool solana.PublicKey, validator *ValidatorStakeInfo) (solana.PublicKey, error) {
+	seeds := [][]byte{validator.VoteAccount[:], stakePool[:]}
+	if validator.ValidatorSeedSuffix != 0 {
+		seeds = append(seeds, binary.LittleEndian.AppendUint32(nil, validator.ValidatorSeedSuffix))
+	}
+	account, _, err := solana.FindProgramAddress(seeds, program)
+	return account, err
+}
+
+// Accounts of a stake pool that deposits and withdrawals reference
+type StakePoolAccounts struct {
+	Program           solana.PublicKey `json:"program"`
+	StakePool         solana.PublicKey `json:"stake_pool"`
+	WithdrawAuthority solana.PublicKey `json:"withdraw_authority"`
+	ValidatorList     solana.PublicKey `json:"validator_list"`
+	ReserveStake      solana.PublicKey `json:"reserve_stake"`
+	PoolMint          solana.PublicKey `json:"pool_mint"`
+	ManagerFeeAccount solana.PublicKey `json:"manager_fee_account"`
+	TokenProgram      solana.PublicKey `json:"token_program"`
+}
+
+func stakePoolInstructionData(instruction uint8, amount uint64) []byte {
+	return binary.LittleEndian.AppendUint64([]byte{instruction}, amount)
+}
+
+// NewDepositSolInstruction deposits lamports into the reserve of the pool, minting pool tokens to the token account
+func NewDepositSolInstruction(pool *StakePoolAccounts, funding solana.PublicKey, poolTokenAccount solana.PublicKey, lamports uint64) solana.Instruction {
+	return solana.NewInstruction(
+		pool.Program,
+		solana.AccountMetaSlice{
+			solana.Meta(pool.StakePool).WRITE(),
+			solana.Meta(pool.WithdrawAuthority),
+			solana.Meta(pool.ReserveStake).WRITE(),
+			solana.Meta(funding).WRITE().SIGNER(),
+			solana.Meta(poolTokenAccount).WRITE(),
+			solana.Meta(pool.ManagerFeeAccount).WRITE(),
+			// the depositor is their own referrer
+			solana.Meta(poolTokenAccount).WRITE(),
+			solana.Meta(pool.PoolMint).WRITE(),
+			solana.Meta(solana.SystemProgramID),
+			solana.Meta(pool.TokenProgram),
+		},
+		stakePoolInstructionData(StakePoolDepositSolInstruction, lamports),
+	)
+}
+
+// NewWithdrawSolInstruction burns the pool tokens to withdraw lamports from the reserve of the pool
+func NewWithdrawSolInstruction(pool *StakePoolAccounts, owner solana.PublicKey, poolTokenAccount solana.PublicKey, destination solana.PublicKey, poolTokens uint64) solana.Instruction {
+	return solana.NewInstruction(
+		pool.Program,
+		solana.AccountMetaSlice{
+			solana.Meta(pool.StakePool).WRITE(),
+			solana.Meta(pool.WithdrawAuthority),
+			solana.Meta(owner).SIGNER(),
+			solana.Meta(poolTokenAccount).WRITE(),
+			solana.Meta(pool.ReserveStake).WRITE(),
+			solana.Meta(destination).WRITE(),
+			solana.Meta(pool.ManagerFeeAccount).WRITE(),
+			solana.Meta(pool.PoolMint).WRITE(),
+			solana.Meta(solana.SysVarClockPubkey),
+			solana.Meta(solana.SysVarStakeHistoryPubkey),
+			solana.Meta(solana.StakeProgramID),
+			solana.Meta(pool.TokenProgram),
+		},
+		stakePoolInstructionData(StakePoolWithdrawSolInstruction, poolTokens),
+	)
+}
+
+// NewWithdrawStakeInstruction burns the pool tokens to split stake from a validator stake account of the pool
+// into the new stake account, which is then owned by the owner
+func NewWithdrawStakeInstruction(pool *StakePoolAccounts, owner solana.PublicKey, poolTokenAccount solana.PublicKey, validatorStake solana.PublicKey, newStakeAccount solana.PublicKey, poolTokens uint64) solana.Instruction {
+	return solana.NewInstruction(
+		pool.Program,
+		solana.AccountMetaSlice{
+			solana.Meta(pool.StakePool).WRITE(),
+			solana.Meta(pool.ValidatorList).WRITE(),
+			solana.Meta(pool.WithdrawAuthority),
+			solana.Meta(validatorStake).WRITE(),
+			solana.Meta(newStakeAccount).WRITE(),
+			solana.Meta(owner),
+			solana.Meta(owner).SIGNER(),
+			solana.Meta(poolTokenAccount).WRITE(),
+			solana.Meta(pool.ManagerFeeAccount).WRITE(),
+			solana.Meta(pool.PoolMint).WRITE(),
+			solana.Meta(solana.SysVarClockPubkey),
+			solana.Meta(pool.TokenProgram),
+			solana.Meta(solana.StakeProgramID),
+		},
+		stakePoolInstructionData(StakePoolWithdrawStakeInstruction, poolTokens),
+	)
+}
+
+// DecodeStakePoolInstruction returns the instruction and the amount of lamports or pool tokens
+func DecodeStakePoolInstruction(data []byte) (uint8, uint64, error) {
+	if len(data) < 9 {
+		return 0, 0, errors.New("invalid stake pool instruction")
+	}
+	return data[0], binary.LittleEndian.Uint64(data[1:9]), nil
+}
@@ -0,0 +1,50 @@
+package types_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/solana/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolTokensForWithdrawal(t *testing.T) {
+	// 1.1 SOL per pool token
+	pool := &types.StakePool{TotalLamports: 11_000_000_000, PoolTokenSupply: 10_000_000_000}
+
+	vectors := []struct {
+		pool     *types.StakePool
+		lamports uint64
+		fee      types.StakePoolFee
+		expected uint64
+		err      string
+	}{
+		{pool: pool, lamports: 2_000_000_000, expected: 1_818_181_819},
+		{pool: pool, lamports: 2_000_000_000, fee: types.StakePoolFee{Numerator: 1, Denominator: 1000}, expected: 1_820_001_821},
+		{pool: pool, lamports: 1, fee: types.StakePoolFee{Numerator: 1, Denominator: 1000}, expected: 2},
+		{pool: pool, lamports: 2_000_000_000, fee: types.StakePoolFee{Numerator: 999, Denominator: 1000}, expected: 1_818_181_819_000},
+		// a fee without a denominator is not charged
+		{pool: pool, lamports: 2_000_000_000, fee: types.StakePoolFee{Numerator: 1}, expected: 1_818_181_819},
+		{pool: pool, lamports: 2_000_000_000, fee: types.StakePoolFee{Numerator: 1, Denominator: 1}, err: "leaves nothing to withdraw"},
+		{pool: pool, lamports: 2_000_000_000, fee: types.StakePoolFee{Numerator: 2, Denominator: 1}, err: "leaves nothing to withdraw"},
+		{pool: pool, lamports: 0, err: "must withdraw more than 0 lamports"},
+		{pool: &types.StakePool{TotalLamports: 11_000_000_000}, lamports: 2_000_000_000, err: "no lamports or pool tokens"},
+		{pool: &types.StakePool{PoolTokenSupply: 10_000_000_000}, lamports: 2_000_000_000, err: "no lamports or pool tokens"},
+		{pool: &types.StakePool{TotalLamports: 1, PoolTokenSupply: math.MaxUint64}, lamports: 2, err: "exceeds the pool token supply"},
+	}
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			poolTokens, err := v.pool.PoolTokensForWithdrawal(v.lamports, v.fee)
+			if v.err != "" {
+				require.ErrorContains(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v.expected, poolTokens)
+			// the smallest amount of pool tokens that withdraws the lamports
+			require.GreaterOrEqual(t, v.pool.LamportsForPoolTokens(poolTokens-v.fee.Apply(poolTokens)), v.lamports)
+			require.Less(t, v.pool.LamportsForPoolTokens(poolTokens-1-v.fee.Apply(poolTokens-1)), v.lamports)
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package types
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 
+	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 )
 
@@ -25,7 +27,30 @@ type TokenAccountInfoParsedInfo struct {
 	Owner       string                                `json:"owner"`
 	State       string                                `json:"state"`
 	TokenAmount TokenAccountInfoParsedInfoTokenAmount `json:"tokenAmount"`
+	// Only set for token-2022 accounts
+	Extensions []TokenAccountInfoParsedExtension `json:"extensions,omitempty"`
 }
+type TokenAccountInfoParsedExtension struct {
+	Extension string `json:"extension"`
+	// Each extension has a different state
+	State json.RawMessage `json:"state,omitempty"`
+}
+
+// WithheldAmount returns the transfer fees withheld in a token-2022 account, which must be harvested before
+// the account can be closed
+func (info TokenAccountInfoParsedInfo) WithheldAmount() uint64 {
+	for _, extension := range info.Extensions {
+		if extension.Extension == "transferFeeAmount" {
+			var state struct {
+				WithheldAmount uint64 `json:"withheldAmount"`
+			}
+			_ = json.Unmarshal(extension.State, &state)
+			return state.WithheldAmount
+		}
+	}
+	return 0
+}
+
 type TokenAccountInfoParsedInfoTokenAmount struct {
 	Amount       string  `json:"amount"`
 	Decimals     uint64  `json:"decimals"`
@@ -47,3 +72,29 @@ func ParseRpcData[T TokenAccountInfo](data *rpc.DataBytesOrJSON) (T, error) {
 	}
 	return info, nil
 }
+
+// Size of the base token account layout, shared by the token and token-2022 programs
+const tokenAccountSize = 165
+
+// TokenAccountBalance is the start of the binary layout of a token account
+type TokenAccountBalance struct {
+	Mint   solana.PublicKey
+	Owner  solana.PublicKey
+	Amount uint64
+}
+
+// ParseTokenAccountBalance decodes the mint, owner and amount of a binary encoded token account
+func ParseTokenAccountBalance(data []byte) (*TokenAccountBalance, error) {
+	if len(data) < tokenAccountSize {
+		return nil, fmt.Errorf("invalid token account size %d", len(data))
+	}
+	// token-2022 mints with extensions are padded to the same size, and differ by the account type
+	if len(data) > tokenAccountSize && data[tokenAccountSize] != accountTypeAccount {
+		return nil, fmt.Errorf("not a token account, account type %d", data[tokenAccountSize])
+	}
+	return &TokenAccountBalance{
+		Mint:   solana.PublicKeyFromBytes(data[0:32]),
+		Owner:  solana.PublicKeyFromBytes(data[32:64]),
+		Amount: binary.LittleEndian.Uint64(data[64:72]),
+	}, nil
+}
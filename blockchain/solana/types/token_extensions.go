@@ -0,0 +1,233 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Token-2022 extension types that affect transfers
+type ExtensionType uint16
+
+const (
+	ExtensionTransferFeeConfig           ExtensionType = 1
+	ExtensionTransferFeeAmount           ExtensionType = 2
+	ExtensionConfidentialTransferMint    ExtensionType = 4
+	ExtensionConfidentialTransferAccount ExtensionType = 5
+	ExtensionMemoTransfer                ExtensionType = 8
+	ExtensionNonTransferable             ExtensionType = 9
+)
+
+// Extensions start after the size of a token account, followed by the account type.  Mints are
+// padded to the same size so that the two cannot be confused.
+const (
+	accountTypeMint    = 1
+	accountTypeAccount = 2
+)
+
+// Transfer fee extension instructions, which solana-go does not provide builders for
+const (
+	transferFeeExtensionInstruction   uint8 = 26
+	transferCheckedWithFeeInstruction uint8 = 1
+)
+
+const maxFeeBasisPoints = 10_000
+
+type TransferFee struct {
+	Epoch       uint64 `json:"epoch"`
+	MaximumFee  uint64 `json:"maximum_fee"`
+	BasisPoints uint16 `json:"basis_points"`
+}
+
+// Calculate returns the fee withheld when transferring the amount, which is rounded up
+func (fee TransferFee) Calculate(amount uint64) uint64 {
+	if fee.BasisPoints == 0 || amount == 0 {
+		return 0
+	}
+	calculated := mulDivCeil(amount, uint64(fee.BasisPoints), maxFeeBasisPoints)
+	return min(calculated, fee.MaximumFee)
+}
+
+// PreFeeAmount returns the amount to transfer so that the recipient receives the post-fee amount
+func (fee TransferFee) PreFeeAmount(postFeeAmount uint64) uint64 {
+	switch {
+	case fee.BasisPoints == 0:
+		return postFeeAmount
+	case fee.BasisPoints >= maxFeeBasisPoints || postFeeAmount == 0:
+		return postFeeAmount + fee.MaximumFee
+	}
+	preFeeAmount := mulDivCeil(postFeeAmount, maxFeeBasisPoints, uint64(maxFeeBasisPoints-fee.BasisPoints))
+	if preFeeAmount-postFeeAmount >= fee.MaximumFee {
+		return postFeeAmount + fee.MaximumFee
+	}
+	return preFeeAmount
+}
+
+type TransferFeeConfig struct {
+	WithheldAmount uint64      `json:"withheld_amount"`
+	Older          TransferFee `json:"older"`
+	Newer          TransferFee `json:"newer"`
+}
+
+// ForEpoch returns the transfer fee that is in effect during the epoch
+func (config *TransferFeeConfig) ForEpoch(epoch uint64) TransferFee {
+	if epoch >= config.Newer.Epoch {
+		return config.Newer
+	}
+	return config.Older
+}
+
+type MintExtensions struct {
+	TransferFeeConfig     *TransferFeeConfig
+	NonTransferable       bool
+	ConfidentialTransfers bool
+}
+
+type TokenAccountExtensions struct {
+	// Withheld transfer fees that have not been harvested to the mint
+	WithheldAmount uint64
+	// Incoming transfers must be preceded by a memo instruction
+	RequiresIncomingMemos bool
+	// Only confidential transfers may be received
+	RejectsNonConfidentialCredits bool
+}
+
+// ParseExtensions splits the TLV encoded extensions of a Token-2022 mint or account
+func ParseExtensions(data []byte, accountType uint8) (map[ExtensionType][]byte, error) {
+	extensions := map[ExtensionType][]byte{}
+	if len(data) <= tokenAccountSize {
+		return extensions, nil
+	}
+	if data[tokenAccountSize] != accountType {
+		return nil, fmt.Errorf("invalid token account type %d, expected %d", data[tokenAccountSize], accountType)
+	}
+	tlv := data[tokenAccountSize+1:]
+	for len(tlv) >= 4 {
+		extensionType := ExtensionType(binary.LittleEndian.Uint16(tlv[0:2]))
+		length := int(binary.LittleEndian.Uint16(tlv[2:4]))
+		if extensionType == 0 {
+			// the remaining space is uninitialized
+			break
+		}
+		if len(tlv) < 4+length {
+			return nil, fmt.Errorf("invalid length %d for token extension %d", length, extensionType)
+		}
+		extensions[extensionType] = tlv[4 : 4+length]
+		tlv = tlv[4+length:]
+	}
+	return extensions, nil
+}
+
+// ParseMintExtensions decodes the Token-2022 mint extensions that affect transfers
+func ParseMintExtensions(data []byte) (*MintExtensions, error) {
+	extensions, err := ParseExtensions(data, accountTypeMint)
+	if err != nil {
+		return nil, err
+	}
+	mintExtensions := &MintExtensions{}
+	if config, ok := extensions[ExtensionTransferFeeConfig]; ok {
+		// skip the config and withdraw authorities
+		if len(config) < 108 {
+			return nil, errors.New("invalid transfer fee config")
+		}
+		mintExtensions.TransferFeeConfig = &TransferFeeConfig{
+			WithheldAmount: binary.LittleEndian.Uint64(config[64:72]),
+			Older:          parseTransferFee(config[72:90]),
+			Newer:          parseTransferFee(config[90:108]),
+		}
+	}
+	_, mintExtensions.NonTransferable = extensions[ExtensionNonTransferable]
+	_, mintExtensions.ConfidentialTransfers = extensions[ExtensionConfidentialTransferMint]
+	return mintExtensions, nil
+}
+
+// ParseTokenAccountExtensions decodes the Token-2022 account extensions that affect transfers
+func ParseTokenAccountExtensions(data []byte) (*TokenAccountExtensions, error) {
+	extensions, err := ParseExtensions(data, accountTypeAccount)
+	if err != nil {
+		return nil, err
+	}
+	accountExtensions := &TokenAccountExtensions{}
+	if withheld, ok := extensions[ExtensionTransferFeeAmount]; ok && len(withheld) >= 8 {
+		accountExtensions.WithheldAmount = binary.LittleEndian.Uint64(withheld)
+	}
+	if memoTransfer, ok := extensions[ExtensionMemoTransfer]; ok && len(memoTransfer) >= 1 {
+		accountExtensions.RequiresIncomingMemos = memoTransfer[0] != 0
+	}
+	// approved, elgamal pubkey, pending and available balances, then the credit flags
+	const allowNonConfidentialCreditsOffset = 1 + 32 + 64 + 64 + 64 + 36 + 1
+	if confidential, ok := extensions[ExtensionConfidentialTransferAccount]; ok && len(confidential) > allowNonConfidentialCreditsOffset {
+		accountExtensions.RejectsNonConfidentialCredits = confidential[allowNonConfidentialCreditsOffset] == 0
+	}
+	return accountExtensions, nil
+}
+
+// Computes ceil(a * b / c) without overflowing, saturating at the max uint64
+func mulDivCeil(a uint64, b uint64, c uint64) uint64 {
+	result := new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b))
+	result.Add(result, new(big.Int).SetUint64(c-1))
+	result.Div(result, new(big.Int).SetUint64(c))
+	if !result.IsUint64() {
+		return math.MaxUint64
+	}
+	return result.Uint64()
+}
+
+func parseTransferFee(data []byte) TransferFee {
+	return TransferFee{
+		Epoch:       binary.LittleEndian.Uint64(data[0:8]),
+		MaximumFee:  binary.LittleEndian.Uint64(data[8:16]),
+		BasisPoints: binary.LittleEndian.Uint16(data[16:18]),
+	}
+}
+
+type TransferCheckedWithFee struct {
+	Source      solana.PublicKey
+	Mint        solana.PublicKey
+	Destination solana.PublicKey
+	Owner       solana.PublicKey
+	Amount      uint64
+	Decimals    uint8
+	Fee         uint64
+}
+
+// NewTransferCheckedWithFeeInstruction transfers from a fee-bearing mint, where the fee must match what the mint withholds
+func NewTransferCheckedWithFeeInstruction(tokenProgram solana.PublicKey, transfer *TransferCheckedWithFee) solana.Instruction {
+	data := []byte{transferFeeExtensionInstruction, transferCheckedWithFeeInstruction}
+	data = binary.LittleEndian.AppendUint64(data, transfer.Amount)
+	data = append(data, transfer.Decimals)
+	data = binary.LittleEndian.AppendUint64(data, transfer.Fee)
+	return solana.NewInstruction(
+		tokenProgram,
+		solana.AccountMetaSlice{
+			solana.Meta(transfer.Source).WRITE(),
+			solana.Meta(transfer.Mint),
+			solana.Meta(transfer.Destination).WRITE(),
+			solana.Meta(transfer.Owner).SIGNER(),
+		},
+		data,
+	)
+}
+
+// DecodeTransferCheckedWithFee decodes the instruction, returning an error if it is another instruction
+func DecodeTransferCheckedWithFee(accounts []*solana.AccountMeta, data []byte) (*TransferCheckedWithFee, error) {
+	if len(data) < 2 || data[0] != transferFeeExtensionInstruction || data[1] != transferCheckedWithFeeInstruction {
+		return nil, errors.New("not a transfer checked with fee instruction")
+	}
+	if len(data) < 2+8+1+8 || len(accounts) < 4 {
+		return nil, errors.New("invalid transfer checked with fee instruction")
+	}
+	return &TransferCheckedWithFee{
+		Source:      accounts[0].PublicKey,
+		Mint:        accounts[1].PublicKey,
+		Destination: accounts[2].PublicKey,
+		Owner:       accounts[3].PublicKey,
+		Amount:      binary.LittleEndian.Uint64(data[2:10]),
+		Decimals:    data[10],
+		Fee:         binary.LittleEndian.Uint64(data[11:19]),
+	}, nil
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+var MetaplexTokenMetadataProgramID = solana.MustPublicKeyFromBase58("metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s")
+
+// Offset of the decimals in an SPL (or Token-2022) mint account
+const mintDecimalsOffset = 44
+const mintSize = 82
+
+// FindMetadataAddress returns the Metaplex metadata account of a mint
+func FindMetadataAddress(mint solana.PublicKey) (solana.PublicKey, error) {
+	addr, _, err := solana.FindProgramAddress(
+		[][]byte{
+			[]byte("metadata"),
+			MetaplexTokenMetadataProgramID[:],
+			mint[:],
+		},
+		MetaplexTokenMetadataProgramID,
+	)
+	return addr, err
+}
+
+// ParseMintDecimals reads the decimals from raw mint account data
+func ParseMintDecimals(data []byte) (int32, error) {
+	if len(data) < mintSize {
+		return 0, fmt.Errorf("invalid mint account size %d", len(data))
+	}
+	return int32(data[mintDecimalsOffset]), nil
+}
+
+// Metadata is the start of the Metaplex metadata account
+type Metadata struct {
+	UpdateAuthority solana.PublicKey
+	Mint            solana.PublicKey
+	Name            string
+	Symbol          string
+	Uri             string
+}
+
+// ParseMetadata decodes the leading fields of a Metaplex metadata account.
+// The strings are stored padded with null bytes, which are trimmed.
+func ParseMetadata(data []byte) (*Metadata, error) {
+	// key (1) + update authority (32) + mint (32)
+	offset := 1 + 32 + 32
+	if len(data) < offset {
+		return nil, errors.New("metadata account too short")
+	}
+	metadata := &Metadata{
+		UpdateAuthority: solana.PublicKeyFromBytes(data[1:33]),
+		Mint:            solana.PublicKeyFromBytes(data[33:65]),
+	}
+	fields := []*string{&metadata.Name, &metadata.Symbol, &metadata.Uri}
+	for _, field := range fields {
+		if len(data) < offset+4 {
+			return nil, errors.New("metadata account too short")
+		}
+		length := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if len(data) < offset+length {
+			return nil, errors.New("metadata account too short")
+		}
+		*field = strings.TrimSpace(strings.TrimRight(string(data[offset:offset+length]), "\x00"))
+		offset += length
+	}
+	return metadata, nil
+}
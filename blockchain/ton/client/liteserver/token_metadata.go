@@ -0,0 +1,55 @@
+package liteserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+	"github.com/xssnick/tonutils-go/ton/nft"
+)
+
+// Jettons that do not specify decimals use 9, the same as TON (TEP-64)
+const defaultJettonDecimals = 9
+
+var _ xcclient.TokenMetadataClient = &Client{}
+
+// FetchTokenMetadata reads the on-chain content of the jetton master via get_jetton_data.
+// Jettons that only have off-chain metadata are not supported, as we do not follow the URI.
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc_types.ContractAddress) (*xcclient.TokenMetadata, error) {
+	jettonAddr, err := address.ParseAddr(string(contract))
+	if err != nil {
+		return nil, err
+	}
+	data, err := jetton.NewJettonMasterClient(client.Client, jettonAddr).GetJettonData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get jetton data of '%s': %v", contract, err)
+	}
+
+	var content *nft.ContentOnchain
+	switch c := data.Content.(type) {
+	case *nft.ContentOnchain:
+		content = c
+	case *nft.ContentSemichain:
+		content = &c.ContentOnchain
+	default:
+		return nil, fmt.Errorf("jetton '%s' does not have on-chain metadata", contract)
+	}
+
+	decimals := int64(defaultJettonDecimals)
+	if value := content.GetAttribute("decimals"); value != "" {
+		decimals, err = strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jetton decimals '%s': %v", value, err)
+		}
+	}
+	return &xcclient.TokenMetadata{
+		Contract: contract,
+		Name:     content.GetAttribute("name"),
+		Symbol:   content.GetAttribute("symbol"),
+		Decimals: int32(decimals),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package tonapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	tonaddress "github.com/CustodyOne/chainkit/blockchain/ton/address"
+	tontx "github.com/CustodyOne/chainkit/blockchain/ton/tx"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/pkg/errors"
+	_tonapi "github.com/tonkeeper/tonapi-go"
+)
+
+var _ xcclient.SimulationClient = &Client{}
+
+// Simulate emulates the external message to the wallet, reporting the ton and jetton transfers
+// of the resulting event.  Unsigned transactions are emulated with a mock signature.
+func (client *Client) Simulate(ctx context.Context, _tx xc_types.Tx) (*xcclient.TxInfo, error) {
+	tx, ok := _tx.(*tontx.Tx)
+	if !ok {
+		return nil, fmt.Errorf("unsupported transaction type %T", _tx)
+	}
+	if len(tx.GetSignatures()) == 0 {
+		// sign a copy so the transaction can still be signed after
+		msg := *tx.ExternalMessage
+		tx = &tontx.Tx{
+			CellBuilder:     tx.CellBuilder,
+			ExternalMessage: &msg,
+		}
+		sighashes, err := tx.Sighashes()
+		if err != nil {
+			return nil, err
+		}
+		privateKey := make([]byte, 64)
+		err = tx.AddSignatures(ed25519.Sign(privateKey, sighashes[0]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	boc, err := tx.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Client.EmulateMessageToWallet(ctx, &_tonapi.EmulateMessageToWalletReq{
+		Boc: base64.StdEncoding.EncodeToString(boc),
+	}, _tonapi.EmulateMessageToWalletParams{})
+	if err != nil {
+		return nil, errors.Wrap(err, "EmulateMessageToWallet failed")
+	}
+
+	chain := client.cfg.Chain
+	var errMsg *string
+	if !res.Trace.Transaction.Success {
+		msg := "transaction failed"
+		if res.Trace.Transaction.Aborted {
+			msg = "transaction aborted"
+		}
+		errMsg = &msg
+	}
+	for _, action := range res.Event.Actions {
+		if errMsg == nil && action.Status == _tonapi.ActionStatusFailed {
+			msg := fmt.Sprintf("%s action failed", action.Type)
+			errMsg = &msg
+		}
+	}
+	info := xcclient.NewSimulatedTxInfo(chain, string(tx.Hash()), errMsg)
+
+	if errMsg == nil {
+		for _, action := range res.Event.Actions {
+			switch {
+			case action.TonTransfer.IsSet():
+				tf := action.TonTransfer.Value
+				from, err := tonaddress.ParseAddress(xc_types.Address(tf.Sender.Address), "")
+				if err != nil {
+					return nil, fmt.Errorf("invalid address %s: %v", tf.Sender.Address, err)
+				}
+				to, err := tonaddress.ParseAddress(xc_types.Address(tf.Recipient.Address), "")
+				if err != nil {
+					return nil, fmt.Errorf("invalid address %s: %v", tf.Recipient.Address, err)
+				}
+				info.AddSimpleTransfer(xc_types.Address(from.String()), xc_types.Address(to.String()), "", xc_types.NewBigIntFromInt64(tf.Amount), nil, tf.Comment.Value)
+			case action.JettonTransfer.IsSet():
+				tf := action.JettonTransfer.Value
+				if !tf.Sender.IsSet() || !tf.Recipient.IsSet() {
+					// minted or burned
+					continue
+				}
+				from, err := tonaddress.ParseAddress(xc_types.Address(tf.Sender.Value.Address), "")
+				if err != nil {
+					return nil, fmt.Errorf("invalid address %s: %v", tf.Sender.Value.Address, err)
+				}
+				to, err := tonaddress.ParseAddress(xc_types.Address(tf.Recipient.Value.Address), "")
+				if err != nil {
+					return nil, fmt.Errorf("invalid address %s: %v", tf.Recipient.Value.Address, err)
+				}
+				jetton, err := tonaddress.ParseAddress(xc_types.Address(tf.Jetton.Address), "")
+				if err != nil {
+					return nil, fmt.Errorf("invalid jetton address %s: %v", tf.Jetton.Address, err)
+				}
+				amount, ok := new(big.Int).SetString(tf.Amount, 10)
+				if !ok {
+					return nil, fmt.Errorf("invalid jetton amount %s", tf.Amount)
+				}
+				decimals := tf.Jetton.Decimals
+				info.AddSimpleTransfer(xc_types.Address(from.String()), xc_types.Address(to.String()), xc_types.ContractAddress(jetton.String()), xc_types.BigInt(*amount), &decimals, tf.Comment.Value)
+			}
+		}
+	}
+
+	// same as the fee estimate, the extra is the amount spent outside of the transfers
+	if res.Event.Extra < 0 {
+		from, err := tonaddress.ParseAddress(xc_types.Address(res.Event.Account.Address), "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %v", res.Event.Account.Address, err)
+		}
+		info.AddFee(xc_types.Address(from.String()), "", xc_types.NewBigIntFromInt64(-res.Event.Extra), nil)
+	}
+	info.Fees = info.CalculateFees()
+	return info, nil
+}
@@ -0,0 +1,78 @@
+package tonapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tonapi_client "github.com/CustodyOne/chainkit/blockchain/ton/client/tonapi"
+	"github.com/CustodyOne/chainkit/blockchain/ton/tx"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+func TestSimulate(t *testing.T) {
+	sender := "0:" + fmt.Sprintf("%064x", 1)
+	recipient := "0:" + fmt.Sprintf("%064x", 2)
+	jetton := "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe"
+	emulated := func(success bool, status string) string {
+		return fmt.Sprintf(`{
+			"trace": {"transaction": {"hash":"00","lt":1,"account":{"address":"%[1]s","is_scam":false,"is_wallet":true},"success":%[4]t,"utime":1,"orig_status":"active","end_status":"active","total_fees":3000000,"end_balance":0,"transaction_type":"TransOrd","state_update_old":"","state_update_new":"","out_msgs":[],"block":"","aborted":false,"destroyed":false,"raw":""}, "interfaces": [], "children": []},
+			"risk": {"transfer_all_remaining_balance":false,"ton":0,"jettons":[],"nfts":[]},
+			"event": {"event_id":"00","account":{"address":"%[1]s","is_scam":false,"is_wallet":true},"timestamp":1,"is_scam":false,"lt":1,"in_progress":false,"extra":-5000000,"actions":[
+				{"type":"TonTransfer","status":"%[5]s","TonTransfer":{"sender":{"address":"%[1]s","is_scam":false,"is_wallet":true},"recipient":{"address":"%[2]s","is_scam":false,"is_wallet":true},"amount":50000000,"comment":"hello"},"simple_preview":{"name":"","description":"","accounts":[]},"base_transactions":[]},
+				{"type":"JettonTransfer","status":"%[5]s","JettonTransfer":{"sender":{"address":"%[1]s","is_scam":false,"is_wallet":true},"recipient":{"address":"%[2]s","is_scam":false,"is_wallet":true},"senders_wallet":"%[1]s","recipients_wallet":"%[2]s","amount":"1000000","jetton":{"address":"%[3]s","name":"Tether USD","symbol":"USDT","decimals":6,"image":"","verification":"whitelist"}},"simple_preview":{"name":"","description":"","accounts":[]},"base_transactions":[]}
+			]}
+		}`, sender, recipient, jetton, success, status)
+	}
+
+	vectors := []struct {
+		name     string
+		response string
+		err      string
+	}{
+		{name: "success", response: emulated(true, "ok")},
+		{name: "failed action", response: emulated(true, "failed"), err: "TonTransfer action failed"},
+		{name: "failed transaction", response: emulated(false, "ok"), err: "transaction failed"},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				require.Equal(t, "/v2/wallet/emulate", req.URL.Path)
+				rw.Header().Set("Content-Type", "application/json")
+				rw.Write([]byte(v.response))
+			}))
+			defer server.Close()
+
+			cli, err := tonapi_client.NewClient(&xc_types.ChainConfig{Chain: xc_types.TON, Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+			from := address.MustParseRawAddr(sender)
+			unsigned := tx.NewTx(from, cell.BeginCell().MustStoreUInt(1, 32), nil)
+
+			info, err := cli.Simulate(context.Background(), unsigned)
+			require.NoError(t, err)
+			// a mock signature is only added to a copy
+			require.Empty(t, unsigned.GetSignatures())
+			require.Nil(t, unsigned.ExternalMessage.Body)
+
+			require.Len(t, info.Fees, 1)
+			require.Equal(t, "5000000", info.Fees[0].Balance.String())
+			if v.err != "" {
+				require.NotNil(t, info.Error)
+				require.Equal(t, v.err, *info.Error)
+				require.Len(t, info.Transfers, 1)
+				return
+			}
+			require.Nil(t, info.Error)
+			require.Len(t, info.Transfers, 3)
+			require.Equal(t, "50000000", info.Transfers[0].To[0].Balance.String())
+			require.Equal(t, "hello", info.Transfers[0].Memo)
+			require.Equal(t, "1000000", info.Transfers[1].To[0].Balance.String())
+			require.EqualValues(t, address.MustParseRawAddr(jetton).String(), info.Transfers[1].To[0].Contract)
+		})
+	}
+}
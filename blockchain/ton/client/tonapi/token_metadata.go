@@ -0,0 +1,40 @@
+package tonapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	_tonapi "github.com/tonkeeper/tonapi-go"
+)
+
+// Jettons that do not specify decimals use 9, the same as TON (TEP-64)
+const defaultJettonDecimals = 9
+
+var _ xcclient.TokenMetadataClient = &Client{}
+
+// FetchTokenMetadata reads the jetton master metadata as indexed by tonapi
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc_types.ContractAddress) (*xcclient.TokenMetadata, error) {
+	info, err := client.Client.GetJettonInfo(ctx, _tonapi.GetJettonInfoParams{
+		AccountID: string(contract),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get jetton info of '%s': %v", contract, err)
+	}
+	decimals := int64(defaultJettonDecimals)
+	if info.Metadata.Decimals != "" {
+		decimals, err = strconv.ParseInt(info.Metadata.Decimals, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jetton decimals '%s': %v", info.Metadata.Decimals, err)
+		}
+	}
+	return &xcclient.TokenMetadata{
+		Contract: contract,
+		Name:     info.Metadata.Name,
+		Symbol:   info.Metadata.Symbol,
+		Decimals: int32(decimals),
+		Spam:     info.Verification == _tonapi.JettonVerificationTypeBlacklist,
+	}, nil
+}
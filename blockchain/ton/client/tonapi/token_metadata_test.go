@@ -0,0 +1,54 @@
+package tonapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tonapi_client "github.com/CustodyOne/chainkit/blockchain/ton/client/tonapi"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchTokenMetadata(t *testing.T) {
+	vectors := []struct {
+		name     string
+		response string
+		symbol   string
+		decimals int32
+		spam     bool
+	}{
+		{
+			name:     "verified",
+			response: `{"mintable":true,"total_supply":"1","metadata":{"address":"0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe","name":"Tether USD","symbol":"USD₮","decimals":"6"},"verification":"whitelist","holders_count":1}`,
+			symbol:   "USD₮",
+			decimals: 6,
+		},
+		{
+			name:     "default decimals",
+			response: `{"mintable":true,"total_supply":"1","metadata":{"address":"0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe","name":"Free Airdrop","symbol":"USDT","decimals":""},"verification":"blacklist","holders_count":1}`,
+			symbol:   "USDT",
+			decimals: 9,
+			spam:     true,
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				require.Contains(t, req.URL.Path, "/v2/jettons/")
+				rw.Header().Set("Content-Type", "application/json")
+				rw.Write([]byte(v.response))
+			}))
+			defer server.Close()
+
+			cli, err := tonapi_client.NewClient(&xc_types.ChainConfig{Client: &xc_types.ClientConfig{URL: server.URL}})
+			require.NoError(t, err)
+			metadata, err := cli.FetchTokenMetadata(context.Background(), USDTJettonMainnetAddress)
+			require.NoError(t, err)
+			require.Equal(t, v.symbol, metadata.Symbol)
+			require.Equal(t, v.decimals, metadata.Decimals)
+			require.Equal(t, v.spam, metadata.Spam)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+)
+
+var _ xcclient.TokenMetadataClient = &Client{}
+
+// FetchTokenMetadata reads the TRC-20 decimals(), symbol() and name() of a token contract
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc_types.ContractAddress) (*xcclient.TokenMetadata, error) {
+	decimals, err := client.client.TRC20GetDecimals(string(contract))
+	if err != nil {
+		return nil, fmt.Errorf("could not read decimals of '%s': %v", contract, err)
+	}
+	if !decimals.IsInt64() || decimals.Int64() > 255 {
+		return nil, fmt.Errorf("invalid decimals of '%s': %v", contract, decimals)
+	}
+	symbol, err := client.client.TRC20GetSymbol(string(contract))
+	if err != nil {
+		return nil, fmt.Errorf("could not read symbol of '%s': %v", contract, err)
+	}
+	// name is optional in TRC-20
+	name, _ := client.client.TRC20GetName(string(contract))
+
+	return &xcclient.TokenMetadata{
+		Contract: contract,
+		Name:     strings.TrimSpace(name),
+		Symbol:   strings.TrimSpace(symbol),
+		Decimals: int32(decimals.Int64()),
+	}, nil
+}
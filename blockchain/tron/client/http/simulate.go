@@ -0,0 +1,132 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/CustodyOne/chainkit/blockchain/tron"
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/fbsobreira/gotron-sdk/pkg/common"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/pkg/errors"
+)
+
+var _ xcclient.SimulationClient = &Client{}
+
+// Simulate predicts the transfers of a native transfer or a smart contract call.  Contract calls are
+// executed with triggerconstantcontract, to detect if they would revert and how much energy is used.
+func (client *Client) Simulate(ctx context.Context, _tx xc_types.Tx) (*xcclient.TxInfo, error) {
+	tronTx, ok := _tx.(*tron.Tx)
+	if !ok || tronTx.TronTx == nil || tronTx.TronTx.RawData == nil {
+		return nil, fmt.Errorf("unsupported transaction type %T", _tx)
+	}
+	if len(tronTx.TronTx.RawData.Contract) != 1 {
+		return nil, fmt.Errorf("expected 1 contract in transaction, found %d", len(tronTx.TronTx.RawData.Contract))
+	}
+	contract := tronTx.TronTx.RawData.Contract[0]
+
+	params, err := client.client.GetChainParameters(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get chain params")
+	}
+	var transactionFee xc_types.BigInt
+	var energyFee xc_types.BigInt
+	for _, v := range params.ChainParameter {
+		switch v.Key {
+		case "getTransactionFee":
+			transactionFee = xc_types.NewBigIntFromInt64(v.Value)
+		case "getEnergyFee":
+			energyFee = xc_types.NewBigIntFromInt64(v.Value)
+		}
+	}
+
+	txRawData, err := tronTx.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	// unsigned transactions still need bandwidth for the signature
+	signatures := len(tronTx.GetSignatures())
+	if signatures == 0 {
+		signatures = 1
+	}
+	bandwidthUsage := xc_types.NewBigIntFromInt64(int64(len(txRawData) - 1 + signatures*65))
+	fee := transactionFee.Mul(&bandwidthUsage)
+
+	chain := client.cfg.Chain
+	hash := string(tronTx.Hash())
+	var info *xcclient.TxInfo
+	var from string
+
+	switch contract.Type {
+	case core.Transaction_Contract_TransferContract:
+		var transfer core.TransferContract
+		if err := contract.Parameter.UnmarshalTo(&transfer); err != nil {
+			return nil, fmt.Errorf("invalid transfer-contract: %v", err)
+		}
+		from = common.EncodeCheck(transfer.OwnerAddress)
+		to := common.EncodeCheck(transfer.ToAddress)
+
+		balance := int64(0)
+		account, err := client.client.GetAccount(ctx, from)
+		if err != nil && !strings.Contains(err.Error(), "could not find account") {
+			return nil, err
+		}
+		if err == nil {
+			balance = account.Balance
+		}
+		if balance < transfer.Amount {
+			msg := fmt.Sprintf("insufficient balance: %d < %d", balance, transfer.Amount)
+			info = xcclient.NewSimulatedTxInfo(chain, hash, &msg)
+		} else {
+			info = xcclient.NewSimulatedTxInfo(chain, hash, nil)
+			info.AddSimpleTransfer(xc_types.Address(from), xc_types.Address(to), "", xc_types.NewBigIntFromInt64(transfer.Amount), nil, "")
+		}
+
+	case core.Transaction_Contract_TriggerSmartContract:
+		var trigger core.TriggerSmartContract
+		if err := contract.Parameter.UnmarshalTo(&trigger); err != nil {
+			return nil, fmt.Errorf("invalid trigger-smart-contract: %v", err)
+		}
+		from = common.EncodeCheck(trigger.OwnerAddress)
+		contractAddress := common.EncodeCheck(trigger.ContractAddress)
+
+		res, err := client.client.TriggerConstantContractData(ctx, from, contractAddress, trigger.Data, trigger.CallValue)
+		if err != nil {
+			return nil, err
+		}
+		energyUsage := xc_types.NewBigIntFromInt64(res.EnergyUsed)
+		energyCost := energyFee.Mul(&energyUsage)
+		fee = fee.Add(&energyCost)
+
+		if res.Reverted() {
+			msg := "transaction reverted"
+			if res.Message != "" {
+				msg = fmt.Sprintf("%s: %s", msg, res.Message)
+			}
+			info = xcclient.NewSimulatedTxInfo(chain, hash, &msg)
+			break
+		}
+		info = xcclient.NewSimulatedTxInfo(chain, hash, nil)
+		if trigger.CallValue > 0 {
+			info.AddSimpleTransfer(xc_types.Address(from), xc_types.Address(contractAddress), "", xc_types.NewBigIntFromInt64(trigger.CallValue), nil, "")
+		}
+		data := trigger.Data
+		if len(data) == 4+32*2 && bytes.Equal(data[:4], tron.Signature("transfer(address,uint256)")) {
+			// the TVM omits the 0x41 prefix of addresses
+			to := common.EncodeCheck(append([]byte{0x41}, data[4+12:4+32]...))
+			amount := new(big.Int).SetBytes(data[4+32:])
+			info.AddSimpleTransfer(xc_types.Address(from), xc_types.Address(to), xc_types.ContractAddress(contractAddress), xc_types.BigInt(*amount), nil, "")
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported contract type %s", contract.Type)
+	}
+
+	info.AddFee(xc_types.Address(from), "", fee, nil)
+	info.Fees = info.CalculateFees()
+	return info, nil
+}
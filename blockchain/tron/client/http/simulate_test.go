@@ -0,0 +1,101 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CustodyOne/chainkit/blockchain/tron"
+	tron_http "github.com/CustodyOne/chainkit/blockchain/tron/client/http"
+	"github.com/CustodyOne/chainkit/blockchain/tron/tx_input"
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulate(t *testing.T) {
+	from := xc_types.Address("THKrowiEfCe8evdbaBzDDvQjM5DGeB3s3F")
+	to := xc_types.Address("TF17BgPaZYbz8oxbjhriubPDsA7ArKoLX3")
+	chainParams := `{"chainParameter":[{"key":"getTransactionFee","value":1000},{"key":"getEnergyFee","value":420}]}`
+
+	vectors := []struct {
+		name      string
+		contract  xc_types.ContractAddress
+		responses []string
+		transfers int
+		energy    int64
+		err       string
+	}{
+		{
+			name:      "native",
+			responses: []string{chainParams, `{"address":"THKrowiEfCe8evdbaBzDDvQjM5DGeB3s3F","balance":5000000}`},
+			transfers: 2,
+		},
+		{
+			name:      "native insufficient balance",
+			responses: []string{chainParams, `{"address":"THKrowiEfCe8evdbaBzDDvQjM5DGeB3s3F","balance":10}`},
+			transfers: 1,
+			err:       "insufficient balance: 10 < 1000000",
+		},
+		{
+			name:      "token",
+			contract:  contractJst,
+			responses: []string{chainParams, `{"result":{"result":true},"energy_used":13000,"constant_result":["0000000000000000000000000000000000000000000000000000000000000001"],"transaction":{"ret":[{}]}}`},
+			transfers: 2,
+			energy:    13000,
+		},
+		{
+			name:      "token reverted",
+			contract:  contractJst,
+			responses: []string{chainParams, `{"result":{"result":true,"message":"REVERT opcode executed"},"energy_used":500,"constant_result":[""],"transaction":{"ret":[{"ret":"FAILED","contractRet":"REVERT"}]}}`},
+			transfers: 1,
+			energy:    500,
+			err:       "transaction reverted: REVERT opcode executed",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockHTTP(t, v.responses, 200)
+			defer close()
+
+			chain := &xc_types.ChainConfig{Chain: xc_types.TRX, Client: &xc_types.ClientConfig{URL: server.URL}}
+			client, err := tron_http.NewClient(chain)
+			require.NoError(t, err)
+
+			options := []xcbuilder.BuilderOption{}
+			if v.contract != "" {
+				options = append(options, xcbuilder.WithAsset(&xc_types.TokenAssetConfig{Contract: v.contract, Decimals: 18}))
+			}
+			args, err := xcbuilder.NewTransferArgs(from, to, xc_types.NewBigIntFromUint64(1000000), options...)
+			require.NoError(t, err)
+			builder, err := tron.NewTxBuilder(chain)
+			require.NoError(t, err)
+			input := &tx_input.TxInput{RefBlockBytes: []byte{1, 2}, RefBlockHash: []byte{3, 4, 5, 6, 7, 8, 9, 10}, Expiration: 100, Timestamp: 10}
+			tx, err := builder.NewTransfer(args, input)
+			require.NoError(t, err)
+			serialized, err := tx.Serialize()
+			require.NoError(t, err)
+
+			info, err := client.Simulate(context.Background(), tx)
+			require.NoError(t, err)
+			require.Equal(t, len(v.responses), server.Counter)
+			require.Len(t, info.Transfers, v.transfers)
+			if v.err != "" {
+				require.NotNil(t, info.Error)
+				require.Equal(t, v.err, *info.Error)
+			} else {
+				require.Nil(t, info.Error)
+				require.Equal(t, "1000000", info.Transfers[0].To[0].Balance.String())
+				if v.contract == "" {
+					require.EqualValues(t, xc_types.TRX, info.Transfers[0].To[0].Contract)
+				} else {
+					require.EqualValues(t, v.contract, info.Transfers[0].To[0].Contract)
+				}
+			}
+			// bandwidth for the size of the tx and a signature, plus the energy
+			expectedFee := int64(len(serialized)-1+65)*1000 + v.energy*420
+			require.Len(t, info.Fees, 1)
+			require.EqualValues(t, expectedFee, info.Fees[0].Balance.Uint64())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	xcclient "github.com/CustodyOne/chainkit/client"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+var _ xcclient.TokenMetadataClient = &Client{}
+
+// FetchTokenMetadata reads the TRC-20 decimals(), symbol() and name() of a token contract
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc_types.ContractAddress) (*xcclient.TokenMetadata, error) {
+	decimalsResult, err := client.triggerConstant(ctx, contract, "decimals()")
+	if err != nil {
+		return nil, fmt.Errorf("could not read decimals of '%s': %v", contract, err)
+	}
+	if len(decimalsResult) != 32 {
+		return nil, fmt.Errorf("could not read decimals of '%s': unexpected result length %d", contract, len(decimalsResult))
+	}
+	decimals := new(big.Int).SetBytes(decimalsResult)
+	if !decimals.IsInt64() || decimals.Int64() > 255 {
+		return nil, fmt.Errorf("invalid decimals of '%s': %v", contract, decimals)
+	}
+
+	symbol, err := client.triggerConstantString(ctx, contract, "symbol()")
+	if err != nil {
+		return nil, fmt.Errorf("could not read symbol of '%s': %v", contract, err)
+	}
+	// name is optional in TRC-20
+	name, _ := client.triggerConstantString(ctx, contract, "name()")
+
+	return &xcclient.TokenMetadata{
+		Contract: contract,
+		Name:     name,
+		Symbol:   symbol,
+		Decimals: int32(decimals.Int64()),
+	}, nil
+}
+
+func (client *Client) triggerConstant(ctx context.Context, contract xc_types.ContractAddress, method string) ([]byte, error) {
+	// the owner does not matter for a constant call, so we use the contract itself
+	response, err := client.client.TriggerConstantContracts(ctx, string(contract), string(contract), method, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(response.ConstantResult) == 0 {
+		return nil, fmt.Errorf("no result returned calling %s", method)
+	}
+	return response.ConstantResult[0], nil
+}
+
+// Decodes an ABI string result, falling back to bytes32 as used by some early tokens
+func (client *Client) triggerConstantString(ctx context.Context, contract xc_types.ContractAddress, method string) (string, error) {
+	result, err := client.triggerConstant(ctx, contract, method)
+	if err != nil {
+		return "", err
+	}
+	stringType, _ := abi.NewType("string", "", nil)
+	values, err := abi.Arguments{{Type: stringType}}.Unpack(result)
+	if err == nil {
+		return strings.TrimSpace(values[0].(string)), nil
+	}
+	if len(result) != 32 {
+		return "", fmt.Errorf("could not decode %s result", method)
+	}
+	return strings.TrimSpace(string(bytes.TrimRight(result, "\x00"))), nil
+}
@@ -0,0 +1,34 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+
+	tron_http "github.com/CustodyOne/chainkit/blockchain/tron/client/http"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc_types "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchTokenMetadata(t *testing.T) {
+	server, close := testtypes.MockHTTP(t, []string{
+		`{"result":{"result":true},"constant_result":["0000000000000000000000000000000000000000000000000000000000000012"]}`,
+		`{"result":{"result":true},"constant_result":["000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000034a53540000000000000000000000000000000000000000000000000000000000"]}`,
+		`{"result":{"result":true},"constant_result":["4a55535400000000000000000000000000000000000000000000000000000000"]}`,
+	}, 200)
+	defer close()
+
+	client, err := tron_http.NewClient(&xc_types.ChainConfig{
+		Client: &xc_types.ClientConfig{
+			URL: server.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	metadata, err := client.FetchTokenMetadata(context.Background(), contractJst)
+	require.NoError(t, err)
+	require.Equal(t, xc_types.ContractAddress(contractJst), metadata.Contract)
+	require.Equal(t, int32(18), metadata.Decimals)
+	require.Equal(t, "JST", metadata.Symbol)
+	require.Equal(t, "JUST", metadata.Name)
+}
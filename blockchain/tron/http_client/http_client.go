@@ -136,6 +136,28 @@ type BlockResponse struct {
 type TriggerConstantContractResponse struct {
 	Error          `json:"result"`
 	ConstantResult []Bytes `json:"constant_result"`
+	EnergyUsed     int64   `json:"energy_used"`
+	Transaction    struct {
+		Ret []struct {
+			Ret         string `json:"ret"`
+			ContractRet string `json:"contractRet"`
+		} `json:"ret"`
+	} `json:"transaction"`
+}
+
+// Reverted returns if the call would fail, e.g. on a REVERT
+func (res *TriggerConstantContractResponse) Reverted() bool {
+	for _, ret := range res.Transaction.Ret {
+		if ret.Ret == "FAILED" {
+			return true
+		}
+		switch ret.ContractRet {
+		case "", "DEFAULT", "SUCCESS":
+		default:
+			return true
+		}
+	}
+	return false
 }
 
 type EstimateEnergyResponse struct {
@@ -685,6 +707,41 @@ func (c *Client) TriggerConstantContracts(ctx context.Context, ownerAddress stri
 	return parsed, nil
 }
 
+// TriggerConstantContractData executes the given call data against the latest state without a transaction
+func (c *Client) TriggerConstantContractData(ctx context.Context, ownerAddress string, contract string, data []byte, callValue int64) (*TriggerConstantContractResponse, error) {
+	req, err := postRequest(ctx, c.Url("wallet/triggerconstantcontract"), map[string]interface{}{
+		"owner_address":    ownerAddress,
+		"contract_address": contract,
+		"data":             hex.EncodeToString(data),
+		"call_value":       callValue,
+		"visible":          true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(body io.ReadCloser) {
+		if body != nil {
+			_ = body.Close()
+		}
+	}(resp.Body)
+
+	parsed, err := parseResponse(resp, &TriggerConstantContractResponse{})
+	if err != nil {
+		return nil, err
+	}
+	if err = checkError(parsed.Error); err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
 func (c *Client) ReadTrc20Balance(ctx context.Context, fromAddress string, contract string) (*big.Int, error) {
 	addrB, err := common.DecodeCheck(fromAddress)
 	if err != nil {
@@ -18,8 +18,15 @@ type builderOptions struct {
 	validator    *string
 	stakeOwner   *xc_types.Address
 	stakeAccount *string
+	provider     *xc_types.StakingProvider
 
 	asset *xc_types.IAsset
+
+	feeCurrency *xc_types.ContractAddress
+	feePayer    *xc_types.Address
+
+	nonceAccount        *string
+	addressLookupTables *[]string
 }
 
 // All ArgumentBuilders should provide base arguments for transactions
@@ -56,8 +63,19 @@ func (opts *builderOptions) GetExtra() (map[string]any, bool) {
 func (opts *builderOptions) GetValidator() (string, bool)            { return get(opts.validator) }
 func (opts *builderOptions) GetStakeOwner() (xc_types.Address, bool) { return get(opts.stakeOwner) }
 func (opts *builderOptions) GetStakeAccount() (string, bool)         { return get(opts.stakeAccount) }
+func (opts *builderOptions) GetStakingProvider() (xc_types.StakingProvider, bool) {
+	return get(opts.provider)
+}
 
 func (opts *builderOptions) GetAsset() (xc_types.IAsset, bool) { return get(opts.asset) }
+func (opts *builderOptions) GetFeeCurrency() (xc_types.ContractAddress, bool) {
+	return get(opts.feeCurrency)
+}
+func (opts *builderOptions) GetFeePayer() (xc_types.Address, bool) { return get(opts.feePayer) }
+func (opts *builderOptions) GetNonceAccount() (string, bool)       { return get(opts.nonceAccount) }
+func (opts *builderOptions) GetAddressLookupTables() ([]string, bool) {
+	return get(opts.addressLookupTables)
+}
 
 type BuilderOption func(opts *builderOptions) error
 
@@ -114,6 +132,14 @@ func WithStakeAccount(account string) BuilderOption {
 	}
 }
 
+// Set the staking provider, which determines the amounts that may be staked
+func WithStakingProvider(provider xc_types.StakingProvider) BuilderOption {
+	return func(opts *builderOptions) error {
+		opts.provider = &provider
+		return nil
+	}
+}
+
 func WithAsset(asset xc_types.IAsset) BuilderOption {
 	return func(opts *builderOptions) error {
 		if asset != nil {
@@ -123,6 +149,38 @@ func WithAsset(asset xc_types.IAsset) BuilderOption {
 	}
 }
 
+// Pay the fee in a token rather than the native asset, on chains that support it (e.g. Celo)
+func WithFeeCurrency(contract xc_types.ContractAddress) BuilderOption {
+	return func(opts *builderOptions) error {
+		opts.feeCurrency = &contract
+		return nil
+	}
+}
+
+// Have another account pay the fee, on chains with native fee delegation.  The fee payer must also sign the transaction.
+func WithFeePayer(feePayer xc_types.Address) BuilderOption {
+	return func(opts *builderOptions) error {
+		opts.feePayer = &feePayer
+		return nil
+	}
+}
+
+// Use a durable nonce account rather than a recent block hash, so the transaction does not expire (e.g. Solana)
+func WithNonceAccount(account string) BuilderOption {
+	return func(opts *builderOptions) error {
+		opts.nonceAccount = &account
+		return nil
+	}
+}
+
+// Address lookup tables that may be used to fit more accounts in a transaction (e.g. Solana)
+func WithAddressLookupTables(tables ...string) BuilderOption {
+	return func(opts *builderOptions) error {
+		opts.addressLookupTables = &tables
+		return nil
+	}
+}
+
 // Previously the chainkit abstraction would require callers to set options
 // directly on the transaction input, if the interface was implemented on the input type.
 // However, this is very clear or easy to use.  This function bridges the gap, to allow
@@ -31,8 +31,15 @@ func (args *StakeArgs) GetPublicKey() ([]byte, bool) { return args.options.GetPu
 func (args *StakeArgs) GetValidator() (string, bool)            { return args.options.GetValidator() }
 func (args *StakeArgs) GetStakeOwner() (xc_types.Address, bool) { return args.options.GetStakeOwner() }
 func (args *StakeArgs) GetStakeAccount() (string, bool)         { return args.options.GetStakeAccount() }
+func (args *StakeArgs) GetStakingProvider() (xc_types.StakingProvider, bool) {
+	return args.options.GetStakingProvider()
+}
 
 func (args *StakeArgs) GetAsset() (xc_types.IAsset, bool) { return args.options.GetAsset() }
+func (args *StakeArgs) GetNonceAccount() (string, bool)   { return args.options.GetNonceAccount() }
+func (args *StakeArgs) GetAddressLookupTables() ([]string, bool) {
+	return args.options.GetAddressLookupTables()
+}
 
 func NewStakeArgs(chain xc_types.NativeAsset, from xc_types.Address, amount xc_types.BigInt, options ...BuilderOption) (StakeArgs, error) {
 	builderOptions := builderOptions{}
@@ -51,12 +58,24 @@ func NewStakeArgs(chain xc_types.NativeAsset, from xc_types.Address, amount xc_t
 	// Chain specific validation of arguments
 	switch chain.Protocol() {
 	case xc_types.ProtocolEVM:
-		// Eth must stake or unstake in increments of 32
+		// Eth must stake or unstake in increments of 32, unless it's pooled by a liquid staking provider
+		if provider, ok := args.GetStakingProvider(); ok && provider.IsLiquid() {
+			break
+		}
+		// A withdrawal request for a single validator is checked against its credentials and balance,
+		// as compounding validators may withdraw any part of their balance above 32
+		if _, ok := args.GetValidator(); ok {
+			break
+		}
 		_, err := validation.Count32EthChunks(args.GetAmount())
 		if err != nil {
 			return args, err
 		}
 	case xc_types.ProtocolCosmos, xc_types.ProtocolSolana:
+		// liquid staking pools choose the validators themselves
+		if provider, ok := args.GetStakingProvider(); ok && provider.IsLiquid() {
+			break
+		}
 		if _, ok := args.GetValidator(); !ok {
 			return args, fmt.Errorf("validator to be delegated to is required for %s chain", chain)
 		}
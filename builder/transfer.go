@@ -70,6 +70,10 @@ func (args *TransferArgs) GetMemo() (string, bool) {
 	return args.options.GetMemo()
 }
 
+func (args *TransferArgs) GetPriority() (types.GasFeePriority, bool) {
+	return args.options.GetPriority()
+}
+
 func (args *TransferArgs) GetAsset() (types.IAsset, bool) {
 	return args.options.GetAsset()
 }
@@ -81,3 +85,19 @@ func (args *TransferArgs) GetPublicKey() ([]byte, bool) {
 func (args *TransferArgs) GetExtra() (map[string]any, bool) {
 	return args.options.GetExtra()
 }
+
+func (args *TransferArgs) GetFeeCurrency() (types.ContractAddress, bool) {
+	return args.options.GetFeeCurrency()
+}
+
+func (args *TransferArgs) GetFeePayer() (types.Address, bool) {
+	return args.options.GetFeePayer()
+}
+
+func (args *TransferArgs) GetNonceAccount() (string, bool) {
+	return args.options.GetNonceAccount()
+}
+
+func (args *TransferArgs) GetAddressLookupTables() ([]string, bool) {
+	return args.options.GetAddressLookupTables()
+}
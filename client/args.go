@@ -1,6 +1,10 @@
 package client
 
-import xc_types "github.com/CustodyOne/chainkit/types"
+import (
+	"fmt"
+
+	xc_types "github.com/CustodyOne/chainkit/types"
+)
 
 type StakedBalanceArgs struct {
 	from      xc_types.Address
@@ -51,3 +55,59 @@ func get[T any](arg *T) (T, bool) {
 	}
 	return *arg, true
 }
+
+// Commitment is how settled the chain state being read must be
+type Commitment string
+
+const (
+	// The most recent block, which may still be reorganized
+	CommitmentLatest Commitment = "latest"
+	// Voted on by a supermajority and unlikely to be reorganized (`safe` on EVM, `confirmed` on Solana)
+	CommitmentConfirmed Commitment = "confirmed"
+	// Irreversible (`finalized` on EVM and Solana)
+	CommitmentFinalized Commitment = "finalized"
+)
+
+type ReadArgs struct {
+	commitment *Commitment
+	height     *uint64
+}
+type ReadOption func(opts *ReadArgs) error
+
+func (opts *ReadArgs) GetCommitment() (Commitment, bool) { return get(opts.commitment) }
+func (opts *ReadArgs) GetHeight() (uint64, bool)         { return get(opts.height) }
+
+func NewReadArgs(options ...ReadOption) (ReadArgs, error) {
+	args := ReadArgs{}
+	for _, opt := range options {
+		err := opt(&args)
+		if err != nil {
+			return args, err
+		}
+	}
+	if args.commitment != nil && args.height != nil {
+		return args, fmt.Errorf("cannot read at both a commitment and a height")
+	}
+	return args, nil
+}
+
+// Read the state at the given commitment level
+func ReadOptionCommitment(commitment Commitment) ReadOption {
+	return func(opts *ReadArgs) error {
+		switch commitment {
+		case CommitmentLatest, CommitmentConfirmed, CommitmentFinalized:
+		default:
+			return fmt.Errorf("invalid commitment: %s", commitment)
+		}
+		opts.commitment = &commitment
+		return nil
+	}
+}
+
+// Read the historical state at the given block height
+func ReadOptionHeight(height uint64) ReadOption {
+	return func(opts *ReadArgs) error {
+		opts.height = &height
+		return nil
+	}
+}
@@ -49,6 +49,54 @@ type StakingClient interface {
 	FetchWithdrawInput(ctx context.Context, args builder.StakeArgs) (xc_types.WithdrawTxInput, error)
 }
 
+// Clients that can discover token metadata (decimals, symbol) from the chain
+type TokenMetadataClient interface {
+	FetchTokenMetadata(ctx context.Context, contract xc_types.ContractAddress) (*TokenMetadata, error)
+}
+
+type TokenMetadata struct {
+	Contract xc_types.ContractAddress `json:"contract"`
+	Name     string                   `json:"name,omitempty"`
+	Symbol   string                   `json:"symbol,omitempty"`
+	Decimals int32                    `json:"decimals"`
+	// Set if the chain or an indexer has flagged the token as spam
+	Spam bool `json:"spam,omitempty"`
+}
+
+// Clients that can read balances at a given commitment level or historical height
+type BalanceAtClient interface {
+	// Fetch the balance of the native asset, or of a token if the contract is set
+	FetchBalanceAt(ctx context.Context, address xc_types.Address, contract xc_types.ContractAddress, args ReadArgs) (*xc_types.BigInt, error)
+}
+
+// Clients that can predict what a transaction will do before it is signed or submitted
+type SimulationClient interface {
+	// The predicted transfers and fees are returned in a TxInfo with no block.  If the transaction
+	// would fail, the reason is set in the Error of the TxInfo rather than returned.
+	Simulate(ctx context.Context, tx xc_types.Tx) (*TxInfo, error)
+}
+
+// Clients that can report the rent locked in accounts that an address no longer needs
+type RentReclaimClient interface {
+	FetchReclaimableRent(ctx context.Context, address xc_types.Address) (*ReclaimableRent, error)
+}
+
+type ReclaimableRent struct {
+	Address xc_types.Address `json:"address"`
+	// Total rent that closing the accounts returns
+	Rent     xc_types.BigInt       `json:"rent"`
+	Accounts []*ReclaimableAccount `json:"accounts"`
+}
+
+type ReclaimableAccount struct {
+	Account  string                   `json:"account"`
+	Contract xc_types.ContractAddress `json:"contract"`
+	// How the account is reclaimed, e.g. closed or merged
+	Action  string          `json:"action"`
+	Balance xc_types.BigInt `json:"balance"`
+	Rent    xc_types.BigInt `json:"rent"`
+}
+
 // Special 3rd-party interface for Ethereum as ethereum doesn't understand delegated staking
 type ManualUnstakingClient interface {
 	CompleteManualUnstaking(ctx context.Context, unstake *Unstake) error
@@ -91,6 +139,16 @@ type StakedBalanceState struct {
 	Inactive     types.BigInt `json:"inactive,omitempty"`
 }
 
+// Add returns the sum of the balances in each state
+func (state StakedBalanceState) Add(other StakedBalanceState) StakedBalanceState {
+	return StakedBalanceState{
+		Active:       state.Active.Add(&other.Active),
+		Activating:   state.Activating.Add(&other.Activating),
+		Deactivating: state.Deactivating.Add(&other.Deactivating),
+		Inactive:     state.Inactive.Add(&other.Inactive),
+	}
+}
+
 type StakedBalance struct {
 	// the validator that the stake is delegated to
 	Validator string `json:"validator"`
@@ -98,6 +156,8 @@ type StakedBalance struct {
 	Account string `json:"account,omitempty"`
 	// The states balance of the balance in the validator [+account]
 	Balance StakedBalanceState `json:"balance"`
+	// Optional; the balances of each account, when they are grouped by validator
+	Accounts []*StakedBalance `json:"accounts,omitempty"`
 }
 
 func NewStakedBalances(balances StakedBalanceState, validator, account string) *StakedBalance {
@@ -149,6 +149,8 @@ type TxInfo struct {
 
 	// output-only: calculate via .CalcuateFees() method
 	Fees []*Balance `json:"fees"`
+	// optional: the portion of the fees paid for posting data to L1, on rollups
+	L1Fees []*Balance `json:"l1_fees,omitempty"`
 
 	// Native staking events
 	Stakes   []*Stake   `json:"stakes,omitempty"`
@@ -156,6 +158,8 @@ type TxInfo struct {
 
 	// required: set the confirmations at time of querying the info
 	Confirmations uint64 `json:"confirmations"`
+	// set if the block of the transaction is irreversible
+	Finalized bool `json:"finalized"`
 	// optional: set the error of the transaction if there was an error
 	Error *string `json:"error,omitempty"`
 }
@@ -198,12 +202,20 @@ func NewTxInfo(block *Block, chain xc_types.NativeAsset, hash string, confirmati
 		block,
 		transfers,
 		fees,
+		nil,
 		stakes,
 		unstakes,
 		confirmations,
+		false,
 		err,
 	}
 }
+
+// NewSimulatedTxInfo returns the info for a transaction that has not been included in a block
+func NewSimulatedTxInfo(chain xc_types.NativeAsset, hash string, err *string) *TxInfo {
+	return NewTxInfo(NewBlock(0, "", time.Time{}), chain, hash, 0, err)
+}
+
 func (info *TxInfo) AddSimpleTransfer(from xc_types.Address, to xc_types.Address, contract xc_types.ContractAddress, balance xc_types.BigInt, decimals *int, memo string) {
 	tf := NewTransfer(info.Chain)
 	tf.SetMemo(memo)
@@ -323,10 +335,17 @@ func TxInfoFromLegacy(chain xc_types.NativeAsset, legacyTx *xc_types.LegacyTxInf
 	}
 	zero := big.NewInt(0)
 	if legacyTx.Fee.Cmp((*xc_types.BigInt)(zero)) != 0 {
-		txInfo.AddFee(legacyTx.From, legacyTx.FeeContract, legacyTx.Fee, nil)
+		feePayer := legacyTx.From
+		if legacyTx.FeePayer != "" {
+			feePayer = legacyTx.FeePayer
+		}
+		txInfo.AddFee(feePayer, legacyTx.FeeContract, legacyTx.Fee, nil)
 	}
 
 	txInfo.Fees = txInfo.CalculateFees()
+	if legacyTx.L1Fee.Cmp((*xc_types.BigInt)(zero)) != 0 {
+		txInfo.L1Fees = append(txInfo.L1Fees, NewBalance(chain, legacyTx.FeeContract, legacyTx.L1Fee, nil))
+	}
 
 	for _, ev := range legacyTx.GetStakeEvents() {
 		switch ev := ev.(type) {
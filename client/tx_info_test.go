@@ -73,3 +73,46 @@ func TestTxInfoMultiLegFees(t *testing.T) {
 	require.Equal(t, "200", tx.CalculateFees()[0].Balance.String())
 	require.EqualValues(t, "BTC", tx.CalculateFees()[0].Contract)
 }
+
+func TestTxInfoFromLegacyL1Fee(t *testing.T) {
+	legacy := &xc_types.LegacyTxInfo{
+		TxID: "0x1234",
+		From: "from",
+		Fee:  xc_types.NewBigIntFromUint64(150),
+		Destinations: []*xc_types.LegacyTxInfoEndpoint{
+			{Address: "to", Amount: xc_types.NewBigIntFromUint64(10)},
+		},
+	}
+	info := client.TxInfoFromLegacy(xc_types.OptETH, legacy, client.Account)
+	require.Len(t, info.Fees, 1)
+	require.Len(t, info.L1Fees, 0)
+
+	legacy.L1Fee = xc_types.NewBigIntFromUint64(100)
+	info = client.TxInfoFromLegacy(xc_types.OptETH, legacy, client.Account)
+	require.Len(t, info.Fees, 1)
+	require.Equal(t, "150", info.Fees[0].Balance.String())
+	require.Len(t, info.L1Fees, 1)
+	require.Equal(t, "100", info.L1Fees[0].Balance.String())
+}
+
+func TestTxInfoFromLegacyFeePayer(t *testing.T) {
+	legacy := &xc_types.LegacyTxInfo{
+		TxID: "1234",
+		From: "from",
+		Fee:  xc_types.NewBigIntFromUint64(5000),
+		Destinations: []*xc_types.LegacyTxInfoEndpoint{
+			{Address: "to", Amount: xc_types.NewBigIntFromUint64(10)},
+		},
+	}
+	info := client.TxInfoFromLegacy(xc_types.SOL, legacy, client.Account)
+	require.Len(t, info.Transfers, 2)
+	require.Equal(t, client.NewAddressName(xc_types.SOL, "from"), info.Transfers[1].From[0].Address)
+
+	// the fee is charged to the fee payer rather than the sender
+	legacy.FeePayer = "payer"
+	info = client.TxInfoFromLegacy(xc_types.SOL, legacy, client.Account)
+	require.Len(t, info.Transfers, 2)
+	require.Equal(t, client.NewAddressName(xc_types.SOL, "payer"), info.Transfers[1].From[0].Address)
+	require.Empty(t, info.Transfers[1].To)
+	require.Equal(t, "5000", info.Fees[0].Balance.String())
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 
+	xcbuilder "github.com/CustodyOne/chainkit/builder"
+	xcclient "github.com/CustodyOne/chainkit/client"
 	"github.com/CustodyOne/chainkit/cmd/xc/setup"
+	xc "github.com/CustodyOne/chainkit/types"
 	"github.com/spf13/cobra"
 )
 
@@ -66,3 +69,159 @@ func CmdTxInput() *cobra.Command {
 	cmd.Flags().String("to", "", "Optional destination address")
 	return cmd
 }
+
+func CmdReclaimableRent() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reclaimable-rent <address>...",
+		Short: "Report the rent that closing unneeded accounts of each address returns.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			xcFactory := setup.UnwrapXc(cmd.Context())
+			chain := setup.UnwrapChain(cmd.Context())
+
+			client, err := xcFactory.NewClient(chain)
+			if err != nil {
+				return err
+			}
+			reclaimClient, ok := client.(xcclient.RentReclaimClient)
+			if !ok {
+				return fmt.Errorf("%s does not support reclaiming rent", chain.Chain)
+			}
+
+			reports := []*xcclient.ReclaimableRent{}
+			for _, addressRaw := range args {
+				address := xcFactory.MustAddress(chain, addressRaw)
+				report, err := reclaimClient.FetchReclaimableRent(cmd.Context(), address)
+				if err != nil {
+					return fmt.Errorf("could not fetch reclaimable rent of %s: %v", address, err)
+				}
+				reports = append(reports, report)
+			}
+
+			bz, _ := json.MarshalIndent(reports, "", "  ")
+			fmt.Println(string(bz))
+			return nil
+		},
+	}
+}
+
+func CmdStaking() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "staking",
+		Short: "Check staked balances and inputs for staking transactions.",
+	}
+	cmd.PersistentFlags().String("provider", string(xc.Native), fmt.Sprintf("Staking provider, one of %v", xc.SupportedStakingProviders))
+	cmd.PersistentFlags().String("validator", "", "Validator, or the stake pool for liquid staking providers")
+	cmd.AddCommand(CmdStakedBalance())
+	cmd.AddCommand(CmdStakingInput())
+	return cmd
+}
+
+func stakingClientFromCmd(cmd *cobra.Command) (xcclient.StakingClient, xc.StakingProvider, error) {
+	xcFactory := setup.UnwrapXc(cmd.Context())
+	chain := setup.UnwrapChain(cmd.Context())
+	providerRaw, _ := cmd.Flags().GetString("provider")
+	provider := xc.StakingProvider(providerRaw)
+	if !provider.Valid() {
+		return nil, provider, fmt.Errorf("invalid staking provider %s, must be one of %v", provider, xc.SupportedStakingProviders)
+	}
+	client, err := xcFactory.NewStakingClient(chain, provider)
+	return client, provider, err
+}
+
+func CmdStakedBalance() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "balance <address>",
+		Short: "Report the staked balances of an address.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			xcFactory := setup.UnwrapXc(cmd.Context())
+			chain := setup.UnwrapChain(cmd.Context())
+			client, _, err := stakingClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			validator, _ := cmd.Flags().GetString("validator")
+			account, _ := cmd.Flags().GetString("account")
+
+			options := []xcclient.StakedBalanceOption{}
+			if validator != "" {
+				options = append(options, xcclient.StakeBalanceOptionValidator(validator))
+			}
+			if account != "" {
+				options = append(options, xcclient.StakeBalanceOptionAccount(account))
+			}
+			balanceArgs, err := xcclient.NewStakeBalanceArgs(xcFactory.MustAddress(chain, args[0]), options...)
+			if err != nil {
+				return err
+			}
+			balances, err := client.FetchStakeBalance(cmd.Context(), balanceArgs)
+			if err != nil {
+				return fmt.Errorf("could not fetch staked balance: %v", err)
+			}
+
+			bz, _ := json.MarshalIndent(balances, "", "  ")
+			fmt.Println(string(bz))
+			return nil
+		},
+	}
+	cmd.Flags().String("account", "", "Optional stake account to report")
+	return cmd
+}
+
+func CmdStakingInput() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "input <stake|unstake|withdraw> <address>",
+		Short: "Check inputs for a new staking transaction.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			xcFactory := setup.UnwrapXc(cmd.Context())
+			chain := setup.UnwrapChain(cmd.Context())
+			client, provider, err := stakingClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			validator, _ := cmd.Flags().GetString("validator")
+			account, _ := cmd.Flags().GetString("account")
+			amountRaw, _ := cmd.Flags().GetString("amount")
+
+			amount, err := xc.NewAmountHumanReadableFromStr(amountRaw)
+			if err != nil {
+				return fmt.Errorf("invalid amount: %v", err)
+			}
+			options := []xcbuilder.BuilderOption{xcbuilder.WithStakingProvider(provider)}
+			if validator != "" {
+				options = append(options, xcbuilder.WithValidator(validator))
+			}
+			if account != "" {
+				options = append(options, xcbuilder.WithStakeAccount(account))
+			}
+			stakeArgs, err := xcbuilder.NewStakeArgs(chain.Chain, xcFactory.MustAddress(chain, args[1]), amount.ToBlockchain(chain.Decimals), options...)
+			if err != nil {
+				return err
+			}
+
+			var input xc.TxInput
+			switch args[0] {
+			case "stake":
+				input, err = client.FetchStakingInput(cmd.Context(), stakeArgs)
+			case "unstake":
+				input, err = client.FetchUnstakingInput(cmd.Context(), stakeArgs)
+			case "withdraw":
+				input, err = client.FetchWithdrawInput(cmd.Context(), stakeArgs)
+			default:
+				return fmt.Errorf("invalid staking action %s, must be stake, unstake or withdraw", args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("could not fetch %s inputs: %v", args[0], err)
+			}
+
+			bz, _ := json.MarshalIndent(input, "", "  ")
+			fmt.Println(string(bz))
+			return nil
+		},
+	}
+	cmd.Flags().String("amount", "0", "Amount to stake, unstake or withdraw, in human readable units")
+	cmd.Flags().String("account", "", "Optional stake account to unstake or withdraw")
+	return cmd
+}
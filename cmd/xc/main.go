@@ -46,6 +46,8 @@ func main() {
 
 	cmd.AddCommand(CmdTxInput())
 	cmd.AddCommand(CmdChains())
+	cmd.AddCommand(CmdReclaimableRent())
+	cmd.AddCommand(CmdStaking())
 
 	_ = cmd.Execute()
 }
@@ -38,7 +38,7 @@ func UnwrapChain(ctx context.Context) *types.ChainConfig {
 func CreateContext(xcFactory *factory.Factory, chain *types.ChainConfig) context.Context {
 	ctx := context.Background()
 	ctx = WrapXc(ctx, xcFactory)
-	// ctx = WrapChain(ctx, chain)
+	ctx = WrapChain(ctx, chain)
 	return ctx
 }
 
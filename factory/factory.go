@@ -23,13 +23,17 @@ type Factory struct {
 	AllAssets                        *sync.Map
 	callbackGetAssetConfig           func(assetID types.AssetID) (types.IAsset, error)
 	callbackGetAssetConfigByContract func(contract string, nativeAsset types.NativeAsset) (types.IAsset, error)
+	// on-chain token discovery is enabled when a policy is set
+	tokenPolicy      *TokenPolicy
+	discoveredAssets *sync.Map
 }
 
 var _ IFactory = &Factory{}
 
 func NewDefaultFactory() *Factory {
 	return &Factory{
-		AllAssets: &sync.Map{},
+		AllAssets:        &sync.Map{},
+		discoveredAssets: &sync.Map{},
 	}
 }
 
@@ -44,6 +48,11 @@ func (f *Factory) NewClient(cfg *types.ChainConfig) (xc_client.IClient, error) {
 	}
 }
 
+// NewStakingClient creates a client for staking with the provider
+func (f *Factory) NewStakingClient(cfg *types.ChainConfig, provider types.StakingProvider) (xc_client.StakingClient, error) {
+	return protocols.NewStakingClient(cfg, xc.Protocol(cfg.Client.Protocol), provider)
+}
+
 func (f *Factory) GetAssetConfig(asset string, nativeAsset types.NativeAsset) (types.IAsset, error) {
 	assetID := types.GetAssetIDFromAsset(asset, nativeAsset)
 	return f.cfgFromAsset(assetID)
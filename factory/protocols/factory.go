@@ -15,7 +15,9 @@ import (
 
 	evmbuilder "github.com/CustodyOne/chainkit/blockchain/evm/builder"
 	evmclient "github.com/CustodyOne/chainkit/blockchain/evm/client"
+	"github.com/CustodyOne/chainkit/blockchain/evm/client/staking/lido"
 	solanaclient "github.com/CustodyOne/chainkit/blockchain/solana/client"
+	"github.com/CustodyOne/chainkit/blockchain/solana/client/staking/stakepool"
 	tonclient "github.com/CustodyOne/chainkit/blockchain/ton/client"
 	tronclient "github.com/CustodyOne/chainkit/blockchain/tron/client"
 	xcbuilder "github.com/CustodyOne/chainkit/builder"
@@ -100,6 +102,30 @@ func NewClient(cfg *xc.ChainConfig, blockchain xc.Protocol) (xc_client.IClient,
 	return creator(cfg)
 }
 
+// NewStakingClient creates the client for staking with the provider.  Native staking uses the
+// client of the chain itself.
+func NewStakingClient(cfg *xc.ChainConfig, blockchain xc.Protocol, provider xc.StakingProvider) (xc_client.StakingClient, error) {
+	client, err := NewClient(cfg, blockchain)
+	if err != nil {
+		return nil, err
+	}
+	switch provider {
+	case xc.Native:
+		if stakingClient, ok := client.(xc_client.StakingClient); ok {
+			return stakingClient, nil
+		}
+	case xc.Lido:
+		if evmClient, ok := client.(*evmclient.Client); ok {
+			return lido.NewClient(evmClient, cfg)
+		}
+	case xc.SplStakePool:
+		if solanaClient, ok := client.(*solanaclient.Client); ok {
+			return stakepool.NewClient(solanaClient, cfg)
+		}
+	}
+	return nil, fmt.Errorf("staking provider %s is not supported on %s", provider, cfg.Chain)
+}
+
 func NewAddressBuilder(cfg *xc.ChainConfig) (xc.AddressBuilder, error) {
 	switch xc.Protocol(cfg.Protocol) {
 	case xc.ProtocolEVM:
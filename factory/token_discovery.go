@@ -0,0 +1,176 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	xc_client "github.com/CustodyOne/chainkit/client"
+	"github.com/CustodyOne/chainkit/types"
+)
+
+// Timeout used when discovering a token via GetAssetConfigByContract
+const DefaultTokenDiscoveryTimeout = 30 * time.Second
+
+// TokenPolicy decides which tokens discovered on-chain are trusted.  Tokens that are not trusted
+// are still resolved (so amounts can be rendered), but are flagged with `Spam`.
+type TokenPolicy struct {
+	// If set, only these contracts are trusted
+	Allow []types.ContractAddress
+	// These contracts are never trusted
+	Deny []types.ContractAddress
+}
+
+// Symbols or names advertising a website are a common pattern for airdropped spam tokens
+var urlPattern = regexp.MustCompile(`(?i)(https?://|www\.|[a-z0-9-]+\.(com|io|org|net|xyz|app|site|top|vip|finance|live|gift|claim)\b)`)
+
+// EnableTokenDiscovery allows GetAssetConfigByContract to resolve unknown contracts from on-chain metadata
+func (f *Factory) EnableTokenDiscovery(policy TokenPolicy) {
+	f.tokenPolicy = &policy
+	// previous results may have been flagged using another policy
+	f.discoveredAssets = &sync.Map{}
+}
+
+func (f *Factory) DisableTokenDiscovery() {
+	f.tokenPolicy = nil
+	f.discoveredAssets = &sync.Map{}
+}
+
+// GetAssetConfigByContract looks up a token by contract, first from the configured assets, then
+// using the registered callback, and finally from on-chain metadata if discovery is enabled.
+func (f *Factory) GetAssetConfigByContract(contract string, nativeAsset types.NativeAsset) (types.IAsset, error) {
+	if cfg, ok := f.findTokenByContract(types.ContractAddress(contract), nativeAsset); ok {
+		return f.cfgEnrichToken(cfg)
+	}
+	var callbackErr error
+	if f.callbackGetAssetConfigByContract != nil {
+		var cfg types.IAsset
+		cfg, callbackErr = f.callbackGetAssetConfigByContract(contract, nativeAsset)
+		if callbackErr == nil {
+			return cfg, nil
+		}
+	}
+	if f.tokenPolicy != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultTokenDiscoveryTimeout)
+		defer cancel()
+		return f.DiscoverToken(ctx, types.ContractAddress(contract), nativeAsset)
+	}
+	if callbackErr != nil {
+		return &types.TokenAssetConfig{}, callbackErr
+	}
+	return &types.TokenAssetConfig{}, fmt.Errorf("unknown contract: '%s'", contract)
+}
+
+// DiscoverToken builds a token config from the on-chain metadata of the contract.  Results are cached.
+func (f *Factory) DiscoverToken(ctx context.Context, contract types.ContractAddress, nativeAsset types.NativeAsset) (*types.TokenAssetConfig, error) {
+	key := string(nativeAsset) + ":" + normalizeContract(contract)
+	if cfg, ok := f.discoveredAssets.Load(key); ok {
+		return cfg.(*types.TokenAssetConfig), nil
+	}
+
+	chainI, found := f.AllAssets.Load(types.AssetID(nativeAsset))
+	if !found {
+		return &types.TokenAssetConfig{}, fmt.Errorf("unsupported native asset: %s", nativeAsset)
+	}
+	chain := chainI.(*types.ChainConfig)
+	if chain.Client == nil {
+		return &types.TokenAssetConfig{}, fmt.Errorf("no client configured for chain: %s", nativeAsset)
+	}
+	client, err := f.NewClient(chain)
+	if err != nil {
+		return &types.TokenAssetConfig{}, err
+	}
+	metadataClient, ok := client.(xc_client.TokenMetadataClient)
+	if !ok {
+		return &types.TokenAssetConfig{}, fmt.Errorf("token discovery is not supported for chain: %s", nativeAsset)
+	}
+	metadata, err := metadataClient.FetchTokenMetadata(ctx, contract)
+	if err != nil {
+		return &types.TokenAssetConfig{}, err
+	}
+
+	// make copy so edits do not persist to local store
+	native := *chain
+	cfg := &types.TokenAssetConfig{
+		Asset:       metadata.Symbol,
+		Chain:       nativeAsset,
+		Decimals:    metadata.Decimals,
+		Contract:    contract,
+		ChainConfig: &native,
+		Discovered:  true,
+		Spam:        f.isSpamToken(metadata, nativeAsset),
+	}
+	f.discoveredAssets.Store(key, cfg)
+	return cfg, nil
+}
+
+func (f *Factory) isSpamToken(metadata *xc_client.TokenMetadata, nativeAsset types.NativeAsset) bool {
+	if metadata.Spam || metadata.Symbol == "" {
+		return true
+	}
+	policy := f.tokenPolicy
+	if policy != nil {
+		if containsContract(policy.Deny, metadata.Contract) {
+			return true
+		}
+		if len(policy.Allow) > 0 && !containsContract(policy.Allow, metadata.Contract) {
+			return true
+		}
+	}
+	if urlPattern.MatchString(metadata.Symbol) || urlPattern.MatchString(metadata.Name) {
+		return true
+	}
+	// impersonating the native asset or a configured token
+	if strings.EqualFold(metadata.Symbol, string(nativeAsset)) {
+		return true
+	}
+	impersonating := false
+	f.AllAssets.Range(func(_, value any) bool {
+		if token, ok := value.(*types.TokenAssetConfig); ok && token.Chain == nativeAsset {
+			if strings.EqualFold(token.Asset, metadata.Symbol) && !sameContract(token.Contract, metadata.Contract) {
+				impersonating = true
+				return false
+			}
+		}
+		return true
+	})
+	return impersonating
+}
+
+func (f *Factory) findTokenByContract(contract types.ContractAddress, nativeAsset types.NativeAsset) (*types.TokenAssetConfig, bool) {
+	var result *types.TokenAssetConfig
+	f.AllAssets.Range(func(_, value any) bool {
+		if token, ok := value.(*types.TokenAssetConfig); ok {
+			if token.Chain == nativeAsset && sameContract(token.Contract, contract) {
+				result = token
+				return false
+			}
+		}
+		return true
+	})
+	return result, result != nil
+}
+
+// EVM addresses are hex and may be checksummed, other chains are case sensitive
+func normalizeContract(contract types.ContractAddress) string {
+	if strings.HasPrefix(string(contract), "0x") {
+		return strings.ToLower(string(contract))
+	}
+	return string(contract)
+}
+
+func sameContract(a types.ContractAddress, b types.ContractAddress) bool {
+	return normalizeContract(a) == normalizeContract(b)
+}
+
+func containsContract(list []types.ContractAddress, contract types.ContractAddress) bool {
+	for _, item := range list {
+		if sameContract(item, contract) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package factory_test
+
+import (
+	"testing"
+
+	"github.com/CustodyOne/chainkit/factory"
+	testtypes "github.com/CustodyOne/chainkit/testutil/types"
+	xc "github.com/CustodyOne/chainkit/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	usdcContract  = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	otherContract = "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+
+	decimalsResult = `"0x0000000000000000000000000000000000000000000000000000000000000012"`
+	daiSymbol      = `"0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000034441490000000000000000000000000000000000000000000000000000000000"`
+	usdcSymbol     = `"0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000045553444300000000000000000000000000000000000000000000000000000000"`
+	urlName        = `"0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000117669736974206672656564726f702e696f000000000000000000000000000000"`
+)
+
+func newDiscoveryFactory(t *testing.T, url string) *factory.Factory {
+	f := factory.NewDefaultFactory()
+	_, err := f.PutAssetConfig(&xc.ChainConfig{
+		Chain: xc.ETH,
+		Client: &xc.ClientConfig{
+			Protocol: xc.ProtocolEVM,
+			URL:      url,
+		},
+	})
+	require.NoError(t, err)
+	_, err = f.PutAssetConfig(&xc.TokenAssetConfig{
+		Asset:    "USDC",
+		Chain:    xc.ETH,
+		Contract: usdcContract,
+		Decimals: 6,
+	})
+	require.NoError(t, err)
+	return f
+}
+
+func TestGetAssetConfigByContractConfigured(t *testing.T) {
+	f := newDiscoveryFactory(t, "")
+
+	assetI, err := f.GetAssetConfigByContract("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", xc.ETH)
+	require.NoError(t, err)
+	asset := assetI.(*xc.TokenAssetConfig)
+	require.Equal(t, "USDC", asset.Asset)
+	require.EqualValues(t, 6, asset.Decimals)
+	require.False(t, asset.Discovered)
+	require.Equal(t, xc.ETH, asset.GetChain().Chain)
+
+	assetI, err = f.GetAssetConfigByContract(otherContract, xc.ETH)
+	require.EqualError(t, err, "unknown contract: '"+otherContract+"'")
+	require.Equal(t, "", assetI.(*xc.TokenAssetConfig).Asset)
+
+	f.RegisterGetAssetConfigByContractCallback(func(contract string, nativeAsset xc.NativeAsset) (xc.IAsset, error) {
+		return &xc.TokenAssetConfig{Asset: "DAI", Chain: nativeAsset, Contract: xc.ContractAddress(contract)}, nil
+	})
+	assetI, err = f.GetAssetConfigByContract(otherContract, xc.ETH)
+	require.NoError(t, err)
+	require.Equal(t, "DAI", assetI.(*xc.TokenAssetConfig).Asset)
+}
+
+func TestGetAssetConfigByContractDiscovered(t *testing.T) {
+	vectors := []struct {
+		name      string
+		responses []string
+		policy    factory.TokenPolicy
+		symbol    string
+		spam      bool
+	}{
+		{
+			name:      "trusted",
+			responses: []string{decimalsResult, daiSymbol, daiSymbol},
+			symbol:    "DAI",
+		},
+		{
+			name:      "impersonating configured token",
+			responses: []string{decimalsResult, usdcSymbol, usdcSymbol},
+			symbol:    "USDC",
+			spam:      true,
+		},
+		{
+			name:      "advertising a url",
+			responses: []string{decimalsResult, daiSymbol, urlName},
+			symbol:    "DAI",
+			spam:      true,
+		},
+		{
+			name:      "denied",
+			responses: []string{decimalsResult, daiSymbol, daiSymbol},
+			policy:    factory.TokenPolicy{Deny: []xc.ContractAddress{"0x6b175474e89094c44da98b954eedeac495271d0f"}},
+			symbol:    "DAI",
+			spam:      true,
+		},
+		{
+			name:      "not allowed",
+			responses: []string{decimalsResult, daiSymbol, daiSymbol},
+			policy:    factory.TokenPolicy{Allow: []xc.ContractAddress{usdcContract}},
+			symbol:    "DAI",
+			spam:      true,
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			server, close := testtypes.MockJSONRPC(t, v.responses)
+			defer close()
+			f := newDiscoveryFactory(t, server.URL)
+			f.EnableTokenDiscovery(v.policy)
+
+			assetI, err := f.GetAssetConfigByContract(otherContract, xc.ETH)
+			require.NoError(t, err)
+			asset := assetI.(*xc.TokenAssetConfig)
+			require.Equal(t, v.symbol, asset.Asset)
+			require.EqualValues(t, 18, asset.Decimals)
+			require.EqualValues(t, otherContract, asset.Contract)
+			require.Equal(t, xc.ETH, asset.GetChain().Chain)
+			require.True(t, asset.Discovered)
+			require.Equal(t, v.spam, asset.Spam)
+
+			// cached
+			requests := server.Counter
+			assetI, err = f.GetAssetConfigByContract(otherContract, xc.ETH)
+			require.NoError(t, err)
+			require.Equal(t, asset, assetI)
+			require.Equal(t, requests, server.Counter)
+
+			// discovered tokens are not added to the configured assets
+			_, err = f.GetAssetConfig(v.symbol, xc.ETH)
+			if v.symbol != "USDC" {
+				require.Error(t, err)
+			}
+		})
+	}
+}
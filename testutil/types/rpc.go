@@ -76,6 +76,49 @@ func MockJSONRPC(t *testing.T, response interface{}) (mock *MockJSONRPCServer, c
 	return mock, func() { mock.Close() }
 }
 
+// RPCRequest is a JSON-RPC request received by a mocked server
+type RPCRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// RPCMethodHandler returns the JSON result of a request, or the JSON of an RPC error prefixed with
+// "error:".  Returning false reports the method as not available, like a node that doesn't support it.
+type RPCMethodHandler func(request *RPCRequest) (string, bool)
+
+// ByMethod responds to the given methods, and rejects all others as unsupported
+func ByMethod(responses map[string]string) RPCMethodHandler {
+	return func(request *RPCRequest) (string, bool) {
+		result, ok := responses[request.Method]
+		return result, ok
+	}
+}
+
+// JSONRPCMethodsHandler serves JSON-RPC requests by method, see RPCMethodHandler
+func JSONRPCMethodsHandler(t *testing.T, handler RPCMethodHandler) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		var request RPCRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		result, ok := handler(&request)
+		if !ok {
+			rw.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"the method %s does not exist/is not available"}}`, request.ID, request.Method)))
+			return
+		}
+		if rpcErr, ok := strings.CutPrefix(result, "error:"); ok {
+			rw.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":%s}`, request.ID, rpcErr)))
+			return
+		}
+		rw.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID, result)))
+	}
+}
+
+// MockJSONRPCMethods creates a server that responds to each JSON-RPC request by its method, rather
+// than in order like MockJSONRPC
+func MockJSONRPCMethods(t *testing.T, handler RPCMethodHandler) *httptest.Server {
+	return httptest.NewServer(JSONRPCMethodsHandler(t, handler))
+}
+
 // MockHTTPServer is a mocked HTTP server
 type MockHTTPServer struct {
 	*httptest.Server
@@ -104,11 +104,26 @@ type ClientConfig struct {
 	Network  string   `yaml:"network,omitempty"`
 }
 
+// L1FeeModel selects how a rollup charges for posting its data to L1
+type L1FeeModel string
+
+const (
+	// L1 fee is charged in addition to L2 gas, reported by the GasPriceOracle predeploy
+	L1FeeModelOpStack L1FeeModel = "op-stack"
+	// L1 fee is charged as additional L2 gas, reported by the NodeInterface precompile
+	L1FeeModelArbitrum L1FeeModel = "arbitrum"
+)
+
 type StakingConfig struct {
 	// the contract used for staking, if relevant
 	StakeContract string `yaml:"stake_contract,omitempty"`
 	// the contract used for unstaking, if relevant
 	UnstakeContract string `yaml:"unstake_contract,omitempty"`
+	// Liquid staking contracts, if relevant: the token minted when staking, the wrapped
+	// (non-rebasing) version of it, and the queue used to redeem it.
+	LiquidToken        string `yaml:"liquid_token,omitempty"`
+	WrappedLiquidToken string `yaml:"wrapped_liquid_token,omitempty"`
+	WithdrawalQueue    string `yaml:"withdrawal_queue,omitempty"`
 	// Compatible providers for staking
 	Providers []StakingProvider `yaml:"providers,omitempty"`
 }
@@ -137,9 +152,15 @@ type ChainConfig struct {
 	ChainCoinHDPath      uint32  `yaml:"chain_coin_hd_path,omitempty"`
 	ChainIDStr           string  `yaml:"chain_id_str,omitempty"`
 	ChainGasPriceDefault float64 `yaml:"chain_gas_price_default,omitempty"`
+	// Legacy EVM only: price gas at this percentile (0-100) of the gas prices paid in recent blocks, rather than eth_gasPrice
+	ChainGasPricePercentile float64 `yaml:"chain_gas_price_percentile,omitempty"`
 
 	ExplorerURL string `yaml:"explorer_url,omitempty"`
 	NoGasFees   bool   `yaml:"no_gas_fees,omitempty"`
+	// How the L1 data fee is charged, for rollups
+	L1FeeModel L1FeeModel `yaml:"l1_fee_model,omitempty"`
+	// EVM only: set if the chain doesn't support the `finalized` block tag, so finality isn't checked
+	NoFinalityTag bool `yaml:"no_finality_tag,omitempty"`
 
 	Staking StakingConfig `yaml:"staking,omitempty"`
 
@@ -183,6 +204,10 @@ type TokenAssetConfig struct {
 	Decimals    int32           `yaml:"decimals,omitempty"`
 	Contract    ContractAddress `yaml:"contract,omitempty"`
 	ChainConfig *ChainConfig    `yaml:"-"`
+	// Set when the token was not configured but discovered from on-chain metadata
+	Discovered bool `yaml:"-"`
+	// Set when a discovered token is likely spam, e.g. impersonating another token
+	Spam bool `yaml:"-"`
 }
 
 func (c *TokenAssetConfig) String() string {
@@ -59,18 +59,27 @@ const Kiln StakingProvider = "kiln"
 const Figment StakingProvider = "figment"
 const Twinstake StakingProvider = "twinstake"
 const Native StakingProvider = "native"
+const Lido StakingProvider = "lido"
+const SplStakePool StakingProvider = "spl-stake-pool"
 
 var SupportedStakingProviders = []StakingProvider{
 	Native,
 	Kiln,
 	Figment,
 	Twinstake,
+	Lido,
+	SplStakePool,
 }
 
 func (stakingProvider StakingProvider) Valid() bool {
 	return slices.Contains(SupportedStakingProviders, stakingProvider)
 }
 
+// Liquid staking providers pool deposits and issue a token for the stake, so any amount may be staked
+func (stakingProvider StakingProvider) IsLiquid() bool {
+	return stakingProvider == Lido || stakingProvider == SplStakePool
+}
+
 type TxVariantInputType string
 
 func NewStakingInputType(blockchain Protocol, variant string) TxVariantInputType {
@@ -38,6 +38,10 @@ type LegacyTxInfo struct {
 	TimeReceived    int64                   `json:"time_received,omitempty"`
 	// If this transaction failed, this is the reason why.
 	Error string `json:"error,omitempty"`
+	// Portion of the fee paid for posting data to L1, on rollups.  This is included in Fee.
+	L1Fee BigInt `json:"l1_fee,omitempty"`
+	// Account that paid the fee, if not the sender
+	FeePayer Address `json:"fee_payer,omitempty"`
 	// to support new TxInfo model, we can't drop "change" btc movements
 	droppedBtcDestinations []*LegacyTxInfoEndpoint
 	stakeEvents            []StakeEvent